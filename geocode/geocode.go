@@ -0,0 +1,117 @@
+// Package geocode turns coordinates into a readable place name without calling external services.
+// Places are capitals and larger cities bundled in places.csv
+package geocode
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed places.csv
+var placesCSV string
+
+// EarthRadiusKm is the mean earth radius, the same sphere MySQL ST_Distance_Sphere uses
+const EarthRadiusKm = 6370.986
+
+// coordinates closer than NearbyKm to a place are named after it, farther ones are described relative to it
+const NearbyKm = 5.0
+
+// MaxDistanceKm is the farthest place that still describes coordinates, beyond it (e.g. at sea) there is no name
+const MaxDistanceKm = 300.0
+
+type Place struct {
+	Name    string
+	Country string
+	Lat     float64
+	Lng     float64
+}
+
+var (
+	places     []Place
+	placesOnce sync.Once
+)
+
+func loadPlaces() []Place {
+	placesOnce.Do(func() {
+		records, err := csv.NewReader(strings.NewReader(placesCSV)).ReadAll()
+		if err != nil {
+			panic(fmt.Sprintf("geocode: invalid places.csv: %v", err))
+		}
+
+		for i, record := range records {
+			if i == 0 {
+				continue
+			}
+			lat, latErr := strconv.ParseFloat(record[2], 64)
+			lng, lngErr := strconv.ParseFloat(record[3], 64)
+			if latErr != nil || lngErr != nil {
+				panic(fmt.Sprintf("geocode: invalid coordinates of %s in places.csv", record[0]))
+			}
+			places = append(places, Place{Name: record[0], Country: record[1], Lat: lat, Lng: lng})
+		}
+	})
+
+	return places
+}
+
+// DistanceKm is the great-circle distance between two points in degrees
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Nearest returns the bundled place closest to the coordinates and the distance to it
+func Nearest(lat, lng float64) (*Place, float64) {
+	var nearest *Place
+	best := math.Inf(1)
+	list := loadPlaces()
+	for i := range list {
+		distance := DistanceKm(lat, lng, list[i].Lat, list[i].Lng)
+		if distance < best {
+			nearest = &list[i]
+			best = distance
+		}
+	}
+
+	return nearest, best
+}
+
+// Describe names the coordinates, e.g. "Tartu, Estonia" or "35 km NE of Tartu, Estonia".
+// Empty when no bundled place is within MaxDistanceKm
+func Describe(lat, lng float64) string {
+	place, distance := Nearest(lat, lng)
+	if place == nil || distance > MaxDistanceKm {
+		return ""
+	}
+
+	name := place.Name + ", " + place.Country
+	if distance < NearbyKm {
+		return name
+	}
+
+	return fmt.Sprintf("%.0f km %s of %s", distance, compassDirection(place.Lat, place.Lng, lat, lng), name)
+}
+
+// compassDirection is the 8-wind direction of the second point as seen from the first one
+func compassDirection(fromLat, fromLng, toLat, toLng float64) string {
+	phi1 := fromLat * math.Pi / 180
+	phi2 := toLat * math.Pi / 180
+	dLambda := (toLng - fromLng) * math.Pi / 180
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	bearing := math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+
+	directions := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+	return directions[int(math.Round(bearing/45))%8]
+}
@@ -0,0 +1,31 @@
+//go:build !integration
+// +build !integration
+
+package geocode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistanceKm(t *testing.T) {
+	assert.InDelta(t, 0, DistanceKm(58.378, 26.729, 58.378, 26.729), 0.001)
+	// Tallinn to Tartu
+	assert.InDelta(t, 162, DistanceKm(59.437, 24.754, 58.378, 26.729), 3)
+}
+
+func TestNearest(t *testing.T) {
+	place, distance := Nearest(58.38, 26.72)
+
+	require.NotNil(t, place)
+	assert.Equal(t, "Tartu", place.Name)
+	assert.Less(t, distance, 1.0)
+}
+
+func TestDescribe(t *testing.T) {
+	assert.Equal(t, "Tartu, Estonia", Describe(58.3776, 26.7290))
+	assert.Equal(t, "20 km N of Tartu, Estonia", Describe(58.558, 26.729))
+	assert.Equal(t, "", Describe(0, -140), "middle of the Pacific has no name")
+}
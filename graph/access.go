@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+type accessLevel int
+
+const (
+	accessRead accessLevel = iota
+	accessWrite
+	accessOwner
+)
+
+var (
+	errApiaryReadOnly  = errors.New("apiary is shared with you read-only")
+	errApiaryOwnerOnly = errors.New("only the apiary owner can do this")
+)
+
+// actingUserID returns the user whose data a request works with. Records of shared apiaries are stored
+// under the owner, so members act as the owner once their role allows the access level.
+// Without access the caller is returned and models find nothing, as for records of other users.
+func (r *Resolver) actingUserID(ctx context.Context, entity model.AccessEntity, id string, level accessLevel) (string, error) {
+	uid := ctx.Value("userID").(string)
+	access, err := (&model.ApiaryAccess{
+		Db:     r.Db,
+		UserID: uid,
+	}).For(entity, id)
+	if err != nil {
+		return "", err
+	}
+	if access == nil || access.OwnerID == uid {
+		return uid, nil
+	}
+
+	switch {
+	case level == accessOwner:
+		return "", errApiaryOwnerOnly
+	case level == accessWrite && !access.CanEdit():
+		return "", errApiaryReadOnly
+	}
+
+	return access.OwnerID, nil
+}
+
+// objectUserID is the owner of an already resolved object, field resolvers read its relations with it.
+// Objects built without an owner fall back to the caller.
+func objectUserID(ctx context.Context, ownerID string) string {
+	if ownerID != "" {
+		return ownerID
+	}
+
+	return ctx.Value("userID").(string)
+}
+
+// apiaryAccess is the access of the caller to an already resolved apiary
+func (r *Resolver) apiaryAccess(ctx context.Context, obj *model.Apiary) (*model.Access, error) {
+	access, err := (&model.ApiaryAccess{
+		Db:     r.Db,
+		UserID: ctx.Value("userID").(string),
+	}).For(model.AccessApiary, strconv.Itoa(obj.ID))
+	if err != nil {
+		return nil, err
+	}
+	if access == nil {
+		return nil, errors.New("apiary not found")
+	}
+
+	return access, nil
+}
@@ -0,0 +1,145 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiaryGeo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("coordinates are validated, normalized and named", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		lat, lng := " 58.3776 ", "26,729"
+		badLat := "91"
+
+		// ACT
+		apiary, err := mutation.AddApiary(ctx, model.ApiaryInput{Name: "Tartu", Lat: &lat, Lng: &lng})
+		_, badErr := mutation.AddApiary(ctx, model.ApiaryInput{Name: "Nowhere", Lat: &badLat, Lng: &lng})
+		_, halfErr := mutation.AddApiary(ctx, model.ApiaryInput{Name: "Half", Lat: &lat})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, apiary.Lat)
+		assert.Equal(t, "58.377600", *apiary.Lat)
+		assert.Equal(t, "26.729000", *apiary.Lng)
+		require.NotNil(t, apiary.Location())
+		assert.Equal(t, "Tartu, Estonia", *apiary.Location())
+		assert.Error(t, badErr)
+		assert.Error(t, halfErr)
+	})
+
+	t.Run("apiariesNear and forageOverlap use the spatial column", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		addApiary := func(name string, lat string, lng string) string {
+			apiary, err := mutation.AddApiary(ctx, model.ApiaryInput{Name: name, Lat: &lat, Lng: &lng})
+			require.NoError(t, err)
+			return strconv.Itoa(apiary.ID)
+		}
+		homeID := addApiary("Home", "58.378000", "26.729000")
+		// about 2.2 km north of home
+		meadowID := addApiary("Meadow", "58.398000", "26.729000")
+		farID := addApiary("Far", "59.437000", "24.754000")
+		_, err := mutation.AddApiary(ctx, model.ApiaryInput{Name: "No coordinates"})
+		require.NoError(t, err)
+
+		// ACT
+		near, nearErr := query.ApiariesNear(ctx, 58.378, 26.729, 10)
+		overlaps, overlapErr := query.ForageOverlap(ctx, nil)
+		_, radiusErr := query.ApiariesNear(ctx, 58.378, 26.729, 0)
+
+		// ASSERT
+		require.NoError(t, nearErr)
+		require.Len(t, near, 2)
+		assert.Equal(t, homeID, strconv.Itoa(near[0].Apiary.ID))
+		assert.InDelta(t, 0, near[0].DistanceKm, 0.01)
+		assert.Equal(t, meadowID, strconv.Itoa(near[1].Apiary.ID))
+		assert.InDelta(t, 2.22, near[1].DistanceKm, 0.05)
+
+		require.NoError(t, overlapErr)
+		require.Len(t, overlaps, 1)
+		assert.Equal(t, homeID, strconv.Itoa(overlaps[0].Apiary.ID))
+		assert.Equal(t, meadowID, strconv.Itoa(overlaps[0].OtherApiary.ID))
+		assert.InDelta(t, 0.54, overlaps[0].Overlap, 0.02)
+		assert.NotEqual(t, farID, strconv.Itoa(overlaps[0].OtherApiary.ID))
+
+		assert.Error(t, radiusErr)
+	})
+
+	t.Run("apiariesNear finds apiaries across the antimeridian and near a pole", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		addApiary := func(name string, lat string, lng string) string {
+			apiary, err := mutation.AddApiary(ctx, model.ApiaryInput{Name: name, Lat: &lat, Lng: &lng})
+			require.NoError(t, err)
+			return strconv.Itoa(apiary.ID)
+		}
+		eastID := addApiary("Taveuni", "-16.800000", "-179.990000")
+		northID := addApiary("Svalbard", "88.900000", "100.000000")
+
+		// ACT
+		acrossAntimeridian, antimeridianErr := query.ApiariesNear(ctx, -16.8, 179.99, 10)
+		nearPole, poleErr := query.ApiariesNear(ctx, 88.9, -80, 300)
+
+		// ASSERT
+		require.NoError(t, antimeridianErr)
+		require.Len(t, acrossAntimeridian, 1)
+		assert.Equal(t, eastID, strconv.Itoa(acrossAntimeridian[0].Apiary.ID))
+
+		require.NoError(t, poleErr)
+		require.Len(t, nearPole, 1)
+		assert.Equal(t, northID, strconv.Itoa(nearPole[0].Apiary.ID))
+	})
+}
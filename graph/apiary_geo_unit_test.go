@@ -0,0 +1,37 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForageOverlapShare(t *testing.T) {
+	assert.Equal(t, 1.0, model.ForageOverlapShare(0, 3))
+	assert.Equal(t, 0.0, model.ForageOverlapShare(6, 3), "circles only touch")
+	assert.InDelta(t, 0.391, model.ForageOverlapShare(3, 3), 0.001)
+}
+
+func TestValidateCoordinates(t *testing.T) {
+	assert.NoError(t, model.ValidateCoordinates(58.3776, 26.729))
+	assert.NoError(t, model.ValidateCoordinates(-90, 180))
+	assert.Error(t, model.ValidateCoordinates(90.5, 0))
+	assert.Error(t, model.ValidateCoordinates(0, -180.1))
+}
+
+func TestApiaryLocation(t *testing.T) {
+	lat, lng := "58.377600", "26.729000"
+	zero := "0"
+
+	location := (&model.Apiary{Lat: &lat, Lng: &lng}).Location()
+
+	require.NotNil(t, location)
+	assert.Equal(t, "Tartu, Estonia", *location)
+	assert.Nil(t, (&model.Apiary{}).Location())
+	assert.Nil(t, (&model.Apiary{Lat: &zero, Lng: &zero}).Location(), "gulf of Guinea has no bundled place nearby")
+}
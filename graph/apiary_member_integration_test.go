@@ -0,0 +1,172 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiaryMembers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("editor and viewer reach shared hives with their role", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		ownerID := createTestUserID()
+		editorID := createTestUserID()
+		viewerID := createTestUserID()
+		defer cleanupTestData(t, db, ownerID)
+		defer cleanupTestData(t, db, editorID)
+		defer cleanupTestData(t, db, viewerID)
+
+		apiaryIDNum := createTestApiary(t, db, ownerID)
+		apiaryID := strconv.Itoa(apiaryIDNum)
+		hiveID := strconv.Itoa(createTestHive(t, db, ownerID, apiaryIDNum))
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ownerCtx := context.WithValue(context.Background(), "userID", ownerID)
+		editorCtx := context.WithValue(context.Background(), "userID", editorID)
+		viewerCtx := context.WithValue(context.Background(), "userID", viewerID)
+
+		editorInvite, err := mutation.InviteApiaryMember(ownerCtx, apiaryID, model.ApiaryRoleEditor)
+		require.NoError(t, err)
+		viewerInvite, err := mutation.InviteApiaryMember(ownerCtx, apiaryID, model.ApiaryRoleViewer)
+		require.NoError(t, err)
+
+		// ACT
+		_, err = mutation.AcceptApiaryInvite(editorCtx, editorInvite.Token)
+		require.NoError(t, err)
+		_, err = mutation.AcceptApiaryInvite(viewerCtx, viewerInvite.Token)
+		require.NoError(t, err)
+
+		notes := "checked by editor"
+		_, editorErr := mutation.UpdateHive(editorCtx, model.HiveUpdateInput{ID: hiveID, Notes: &notes})
+		_, viewerErr := mutation.DeactivateHive(viewerCtx, hiveID)
+		viewerApiaries, listErr := query.Apiaries(viewerCtx)
+		viewerHive, hiveErr := query.Hive(viewerCtx, hiveID)
+
+		// ASSERT
+		assert.NoError(t, editorErr)
+		assert.ErrorIs(t, viewerErr, errApiaryReadOnly)
+		require.NoError(t, listErr)
+		require.Len(t, viewerApiaries, 1)
+		assert.Equal(t, apiaryID, strconv.Itoa(viewerApiaries[0].ID))
+		require.NoError(t, hiveErr)
+		require.NotNil(t, viewerHive)
+		require.NotNil(t, viewerHive.Notes)
+		assert.Equal(t, notes, *viewerHive.Notes)
+		assert.Equal(t, ownerID, viewerHive.UserID)
+
+		role, err := (&apiaryResolver{Resolver: resolver}).MyRole(viewerCtx, viewerApiaries[0])
+		require.NoError(t, err)
+		assert.Equal(t, model.ApiaryRoleViewer, role)
+	})
+
+	t.Run("revoked member loses access and invites are single use", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		ownerID := createTestUserID()
+		memberID := createTestUserID()
+		otherID := createTestUserID()
+		defer cleanupTestData(t, db, ownerID)
+		defer cleanupTestData(t, db, memberID)
+		defer cleanupTestData(t, db, otherID)
+
+		apiaryIDNum := createTestApiary(t, db, ownerID)
+		apiaryID := strconv.Itoa(apiaryIDNum)
+		hiveID := strconv.Itoa(createTestHive(t, db, ownerID, apiaryIDNum))
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ownerCtx := context.WithValue(context.Background(), "userID", ownerID)
+		memberCtx := context.WithValue(context.Background(), "userID", memberID)
+		otherCtx := context.WithValue(context.Background(), "userID", otherID)
+
+		invite, err := mutation.InviteApiaryMember(ownerCtx, apiaryID, model.ApiaryRoleEditor)
+		require.NoError(t, err)
+		_, err = mutation.AcceptApiaryInvite(memberCtx, invite.Token)
+		require.NoError(t, err)
+
+		// ACT
+		_, reuseErr := mutation.AcceptApiaryInvite(otherCtx, invite.Token)
+		_, memberRevokeErr := mutation.RevokeApiaryMember(memberCtx, apiaryID, invite.Member.ID)
+		revoked, err := mutation.RevokeApiaryMember(ownerCtx, apiaryID, invite.Member.ID)
+		require.NoError(t, err)
+		hive, hiveErr := query.Hive(memberCtx, hiveID)
+		apiaries, listErr := query.Apiaries(memberCtx)
+
+		// ASSERT
+		assert.Error(t, reuseErr)
+		assert.NoError(t, memberRevokeErr, "members can leave an apiary")
+		assert.False(t, revoked, "membership was already revoked by the member")
+		require.NoError(t, hiveErr)
+		assert.Nil(t, hive)
+		require.NoError(t, listErr)
+		assert.Empty(t, apiaries)
+	})
+
+	t.Run("hives added by editors count against the plan of the owner", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		ownerID := createTestUserID()
+		editorID := createTestUserID()
+		defer cleanupTestData(t, db, ownerID)
+		defer cleanupTestData(t, db, editorID)
+
+		apiaryIDNum := createTestApiary(t, db, ownerID)
+		apiaryID := strconv.Itoa(apiaryIDNum)
+		for i := 0; i < 3; i++ {
+			createTestHive(t, db, ownerID, apiaryIDNum)
+		}
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ownerCtx := context.WithValue(context.Background(), "userID", ownerID)
+		ownerCtx = context.WithValue(ownerCtx, "billingPlan", "free")
+		editorCtx := context.WithValue(context.Background(), "userID", editorID)
+		editorCtx = context.WithValue(editorCtx, "billingPlan", "professional")
+
+		invite, err := mutation.InviteApiaryMember(ownerCtx, apiaryID, model.ApiaryRoleEditor)
+		require.NoError(t, err)
+		_, err = mutation.AcceptApiaryInvite(editorCtx, invite.Token)
+		require.NoError(t, err)
+
+		// ACT
+		_, err = mutation.AddHive(editorCtx, model.HiveInput{ApiaryID: apiaryID, BoxCount: 1, FrameCount: 1})
+
+		// ASSERT
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "free plan")
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE user_id=?", editorID))
+	})
+}
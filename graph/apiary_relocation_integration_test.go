@@ -0,0 +1,147 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiaryRelocations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("updating coordinates of a mobile apiary appends a relocation", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		mobile := model.ApiaryTypeMobile
+		lat, lng := "58.377600", "26.729000"
+		arrivedAt := "2026-04-20"
+		rapeseed := "rapeseed"
+		apiary, err := mutation.AddApiary(ctx, model.ApiaryInput{
+			Name: "Migratory", Type: &mobile, Lat: &lat, Lng: &lng, ArrivedAt: &arrivedAt, Forage: &rapeseed,
+		})
+		require.NoError(t, err)
+		apiaryID := strconv.Itoa(apiary.ID)
+
+		newLat, newLng := "58.500000", "26.900000"
+		movedAt := "2026-06-10"
+		linden := "linden"
+
+		// ACT
+		_, err = mutation.UpdateApiary(ctx, apiaryID, model.ApiaryInput{
+			Name: "Migratory", Lat: &newLat, Lng: &newLng, ArrivedAt: &movedAt, Forage: &linden,
+		})
+		require.NoError(t, err)
+		_, err = mutation.UpdateApiary(ctx, apiaryID, model.ApiaryInput{Name: "Migratory renamed", Lat: &newLat, Lng: &newLng})
+		require.NoError(t, err)
+		history, historyErr := (&apiaryResolver{Resolver: resolver}).LocationHistory(ctx, apiary)
+
+		// ASSERT
+		require.NoError(t, historyErr)
+		require.Len(t, history, 2)
+		assert.Equal(t, lat, history[0].Lat)
+		require.NotNil(t, history[0].Forage)
+		assert.Equal(t, rapeseed, *history[0].Forage)
+		require.NotNil(t, history[0].DepartedAt)
+		assert.Contains(t, *history[0].DepartedAt, movedAt)
+		assert.Equal(t, newLat, history[1].Lat)
+		assert.Nil(t, history[1].DepartedAt)
+	})
+
+	t.Run("static apiaries are not relocated and relocations can not go back in time", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		staticID := strconv.Itoa(createTestApiary(t, db, userID))
+		mobile := model.ApiaryTypeMobile
+		lat, lng := "58.377600", "26.729000"
+		arrivedAt := "2026-06-01"
+		apiary, err := mutation.AddApiary(ctx, model.ApiaryInput{Name: "Migratory", Type: &mobile, Lat: &lat, Lng: &lng, ArrivedAt: &arrivedAt})
+		require.NoError(t, err)
+
+		earlier := "2026-05-01"
+
+		// ACT
+		_, staticErr := mutation.RelocateApiary(ctx, staticID, model.ApiaryRelocationInput{Lat: "1", Lng: "2"})
+		_, backdatedErr := mutation.RelocateApiary(ctx, strconv.Itoa(apiary.ID), model.ApiaryRelocationInput{Lat: "1", Lng: "2", ArrivedAt: &earlier})
+
+		// ASSERT
+		assert.Error(t, staticErr)
+		assert.Error(t, backdatedErr)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM apiary_relocations WHERE apiary_id=?", staticID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM apiary_relocations WHERE apiary_id=?", apiary.ID))
+	})
+
+	t.Run("legacy relocations without coordinates are kept but left out of the history", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		mobile := model.ApiaryTypeMobile
+		apiary, err := mutation.AddApiary(ctx, model.ApiaryInput{Name: "Migratory", Type: &mobile})
+		require.NoError(t, err)
+		_, err = db.Exec(
+			`INSERT INTO apiary_relocations (user_id, apiary_id, lat, lng, arrived_at, forage)
+			VALUES (?, ?, NULL, NULL, '2026-04-01 00:00:00', 'heather')`,
+			userID, apiary.ID,
+		)
+		require.NoError(t, err)
+
+		movedAt := "2026-06-10"
+
+		// ACT
+		_, err = mutation.RelocateApiary(ctx, strconv.Itoa(apiary.ID), model.ApiaryRelocationInput{Lat: "58.5", Lng: "26.9", ArrivedAt: &movedAt})
+		require.NoError(t, err)
+		history, historyErr := (&apiaryResolver{Resolver: resolver}).LocationHistory(ctx, apiary)
+
+		// ASSERT
+		require.NoError(t, historyErr)
+		require.Len(t, history, 1)
+		assert.Nil(t, history[0].DepartedAt)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM apiary_relocations WHERE apiary_id=? AND lat IS NULL AND departed_at IS NOT NULL", apiary.ID))
+	})
+}
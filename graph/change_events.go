@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/Gratheon/swarm-api/redisPubSub"
+	"github.com/google/uuid"
+)
+
+func hiveChangesChannel(uid string, hiveID string) string {
+	return fmt.Sprintf("%s.subscription.hive.%s", uid, hiveID)
+}
+
+func apiaryChangesChannel(uid string, apiaryID string) string {
+	return fmt.Sprintf("%s.subscription.apiary.%s", uid, apiaryID)
+}
+
+func inventoryChangesChannel(uid string) string {
+	return fmt.Sprintf("%s.subscription.inventory", uid)
+}
+
+func newChangeEvent(entity string, entityID string, action string, data interface{}) *model.ChangeEvent {
+	event := &model.ChangeEvent{
+		EventID:    uuid.NewString(),
+		Version:    model.OutboxEventVersion,
+		Entity:     entity,
+		EntityID:   entityID,
+		Action:     action,
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if data != nil {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to marshal %s %s change event data: %v", entity, entityID, err))
+		} else {
+			payloadStr := string(payload)
+			event.Data = &payloadStr
+		}
+	}
+
+	return event
+}
+
+// subscribeToChanges decodes change events from a redis channel until the subscription context is done
+func subscribeToChanges(ctx context.Context, channel string) <-chan *model.ChangeEvent {
+	events := make(chan *model.ChangeEvent, 1)
+	payloads := redisPubSub.Subscribe(ctx, channel)
+
+	go func() {
+		defer close(events)
+
+		for payload := range payloads {
+			event := &model.ChangeEvent{}
+			if err := json.Unmarshal([]byte(payload), event); err != nil {
+				logger.Error(fmt.Sprintf("Failed to decode change event on channel %s: %v", channel, err))
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,40 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeEventChannels(t *testing.T) {
+	assert.Equal(t, "7.subscription.hive.12", hiveChangesChannel("7", "12"))
+	assert.Equal(t, "7.subscription.apiary.3", apiaryChangesChannel("7", "3"))
+	assert.Equal(t, "7.subscription.inventory", inventoryChangesChannel("7"))
+}
+
+func TestNewChangeEvent(t *testing.T) {
+	event := newChangeEvent("box", "5", "created", map[string]interface{}{"position": 2})
+
+	assert.Equal(t, "box", event.Entity)
+	assert.Equal(t, "5", event.EntityID)
+	assert.Equal(t, "created", event.Action)
+	assert.NotEmpty(t, event.EventID)
+	assert.Equal(t, model.OutboxEventVersion, event.Version)
+	require.NotNil(t, event.Data)
+	assert.JSONEq(t, `{"position":2}`, *event.Data)
+
+	_, err := time.Parse(time.RFC3339, event.OccurredAt)
+	assert.NoError(t, err)
+}
+
+func TestNewChangeEventWithoutData(t *testing.T) {
+	event := newChangeEvent("family", "9", "deleted", nil)
+
+	assert.Nil(t, event.Data)
+}
@@ -16,6 +16,7 @@ const LoadersKey = contextKey("dataloader")
 
 type Loaders struct {
 	HivesByApiaryLoader *HiveLoader
+	HiveByIDLoader      *HiveByIDLoader
 	BoxesByHiveLoader   *BoxLoader
 	FamilyByHiveLoader  *FamilyLoader
 	FramesByBoxLoader   *FrameLoader
@@ -107,80 +108,179 @@ func (l *HiveLoader) processBatch(apiaryID int, wait time.Duration) {
 	}
 }
 
-type BoxLoader struct {
+// Loaders below batch per owner, a request can read hives of apiaries shared by other users.
+// Pending loads are kept by owner and then by id, every owner gets its own query
+
+type HiveByIDLoader struct {
 	db    *sqlx.DB
 	mu    sync.Mutex
-	batch map[string]chan []*model.Box
+	batch map[string]map[string][]chan hiveByIDResult
 	timer *time.Timer
 	wait  time.Duration
 }
 
-func NewBoxLoader(db *sqlx.DB) *BoxLoader {
-	return &BoxLoader{
+// hiveByIDResult carries the hive or the error of the batch query to every caller waiting for the id
+type hiveByIDResult struct {
+	hive *model.Hive
+	err  error
+}
+
+func NewHiveByIDLoader(db *sqlx.DB) *HiveByIDLoader {
+	return &HiveByIDLoader{
 		db:    db,
-		batch: make(map[string]chan []*model.Box),
+		batch: make(map[string]map[string][]chan hiveByIDResult),
 		wait:  1 * time.Millisecond,
 	}
 }
 
-func (l *BoxLoader) Load(ctx context.Context, hiveID string, userID string) ([]*model.Box, error) {
-	resultChan := make(chan []*model.Box, 1)
+func (l *HiveByIDLoader) Load(ctx context.Context, hiveID string, userID string) (*model.Hive, error) {
+	resultChan := make(chan hiveByIDResult, 1)
 
 	l.mu.Lock()
 	needsScheduling := len(l.batch) == 0
-	l.batch[hiveID] = resultChan
+	if l.batch[userID] == nil {
+		l.batch[userID] = make(map[string][]chan hiveByIDResult)
+	}
+	l.batch[userID][hiveID] = append(l.batch[userID][hiveID], resultChan)
 
 	if needsScheduling {
-		l.timer = time.AfterFunc(l.wait, func() {
-			l.processBatch(userID)
-		})
+		l.timer = time.AfterFunc(l.wait, l.processBatch)
 	}
 	l.mu.Unlock()
 
 	select {
 	case result := <-resultChan:
-		return result, nil
+		return result.hive, result.err
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
-func (l *BoxLoader) processBatch(userID string) {
+func (l *HiveByIDLoader) processBatch() {
 	l.mu.Lock()
 	batch := l.batch
-	l.batch = make(map[string]chan []*model.Box)
+	l.batch = make(map[string]map[string][]chan hiveByIDResult)
 	l.mu.Unlock()
 
-	if len(batch) == 0 {
-		return
+	for userID, pending := range batch {
+		l.processOwnerBatch(userID, pending)
 	}
+}
 
+func (l *HiveByIDLoader) processOwnerBatch(userID string, batch map[string][]chan hiveByIDResult) {
 	hiveIDs := make([]string, 0, len(batch))
 	for hiveID := range batch {
 		hiveIDs = append(hiveIDs, hiveID)
 	}
 
+	var allHives []*model.Hive
 	query, args, err := sqlx.In(
-		`SELECT * FROM boxes 
-		WHERE active=1 AND hive_id IN (?) AND user_id=? 
-		ORDER BY hive_id, position DESC`,
+		`SELECT id, user_id, apiary_id, box_system_id, hive_type, active, hive_number, notes, color, status, added,
+		        collapse_date, collapse_cause, parent_hive_id, split_date, merged_into_hive_id, merge_date, merge_type
+		FROM hives
+		WHERE id IN (?) AND user_id=? AND active=1`,
 		hiveIDs, userID)
+	if err == nil {
+		err = l.db.Select(&allHives, l.db.Rebind(query), args...)
+	}
 
 	if err != nil {
-		for _, ch := range batch {
-			close(ch)
+		for _, channels := range batch {
+			for _, ch := range channels {
+				ch <- hiveByIDResult{err: err}
+			}
 		}
 		return
 	}
 
-	query = l.db.Rebind(query)
+	hivesByID := make(map[string]*model.Hive)
+	for _, hive := range allHives {
+		hivesByID[hive.ID] = hive
+	}
+
+	for hiveID, channels := range batch {
+		for _, ch := range channels {
+			ch <- hiveByIDResult{hive: hivesByID[hiveID]}
+		}
+	}
+}
+
+type BoxLoader struct {
+	db    *sqlx.DB
+	mu    sync.Mutex
+	batch map[string]map[string][]chan boxesResult
+	timer *time.Timer
+	wait  time.Duration
+}
+
+type boxesResult struct {
+	boxes []*model.Box
+	err   error
+}
+
+func NewBoxLoader(db *sqlx.DB) *BoxLoader {
+	return &BoxLoader{
+		db:    db,
+		batch: make(map[string]map[string][]chan boxesResult),
+		wait:  1 * time.Millisecond,
+	}
+}
+
+func (l *BoxLoader) Load(ctx context.Context, hiveID string, userID string) ([]*model.Box, error) {
+	resultChan := make(chan boxesResult, 1)
+
+	l.mu.Lock()
+	needsScheduling := len(l.batch) == 0
+	if l.batch[userID] == nil {
+		l.batch[userID] = make(map[string][]chan boxesResult)
+	}
+	l.batch[userID][hiveID] = append(l.batch[userID][hiveID], resultChan)
+
+	if needsScheduling {
+		l.timer = time.AfterFunc(l.wait, l.processBatch)
+	}
+	l.mu.Unlock()
+
+	select {
+	case result := <-resultChan:
+		return result.boxes, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *BoxLoader) processBatch() {
+	l.mu.Lock()
+	batch := l.batch
+	l.batch = make(map[string]map[string][]chan boxesResult)
+	l.mu.Unlock()
+
+	for userID, pending := range batch {
+		l.processOwnerBatch(userID, pending)
+	}
+}
+
+func (l *BoxLoader) processOwnerBatch(userID string, batch map[string][]chan boxesResult) {
+	hiveIDs := make([]string, 0, len(batch))
+	for hiveID := range batch {
+		hiveIDs = append(hiveIDs, hiveID)
+	}
 
 	var allBoxes []*model.Box
-	err = l.db.Select(&allBoxes, query, args...)
+	query, args, err := sqlx.In(
+		`SELECT * FROM boxes 
+		WHERE active=1 AND hive_id IN (?) AND user_id=? 
+		ORDER BY hive_id, position DESC`,
+		hiveIDs, userID)
+	if err == nil {
+		err = l.db.Select(&allBoxes, l.db.Rebind(query), args...)
+	}
 
 	if err != nil {
-		for _, ch := range batch {
-			close(ch)
+		for _, channels := range batch {
+			for _, ch := range channels {
+				ch <- boxesResult{err: err}
+			}
 		}
 		return
 	}
@@ -191,88 +291,93 @@ func (l *BoxLoader) processBatch(userID string) {
 		boxesByHive[hiveIDStr] = append(boxesByHive[hiveIDStr], box)
 	}
 
-	for hiveID, ch := range batch {
+	for hiveID, channels := range batch {
 		boxes := boxesByHive[hiveID]
 		if boxes == nil {
 			boxes = []*model.Box{}
 		}
-		ch <- boxes
+		for _, ch := range channels {
+			ch <- boxesResult{boxes: boxes}
+		}
 	}
 }
 
 type FamilyLoader struct {
 	db    *sqlx.DB
 	mu    sync.Mutex
-	batch map[string]chan *model.Family
+	batch map[string]map[string][]chan familyResult
 	timer *time.Timer
 	wait  time.Duration
 }
 
+type familyResult struct {
+	family *model.Family
+	err    error
+}
+
 func NewFamilyLoader(db *sqlx.DB) *FamilyLoader {
 	return &FamilyLoader{
 		db:    db,
-		batch: make(map[string]chan *model.Family),
+		batch: make(map[string]map[string][]chan familyResult),
 		wait:  1 * time.Millisecond,
 	}
 }
 
 func (l *FamilyLoader) Load(ctx context.Context, hiveID string, userID string) (*model.Family, error) {
-	resultChan := make(chan *model.Family, 1)
+	resultChan := make(chan familyResult, 1)
 
 	l.mu.Lock()
 	needsScheduling := len(l.batch) == 0
-	l.batch[hiveID] = resultChan
+	if l.batch[userID] == nil {
+		l.batch[userID] = make(map[string][]chan familyResult)
+	}
+	l.batch[userID][hiveID] = append(l.batch[userID][hiveID], resultChan)
 
 	if needsScheduling {
-		l.timer = time.AfterFunc(l.wait, func() {
-			l.processBatch(userID)
-		})
+		l.timer = time.AfterFunc(l.wait, l.processBatch)
 	}
 	l.mu.Unlock()
 
 	select {
 	case result := <-resultChan:
-		return result, nil
+		return result.family, result.err
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
-func (l *FamilyLoader) processBatch(userID string) {
+func (l *FamilyLoader) processBatch() {
 	l.mu.Lock()
 	batch := l.batch
-	l.batch = make(map[string]chan *model.Family)
+	l.batch = make(map[string]map[string][]chan familyResult)
 	l.mu.Unlock()
 
-	if len(batch) == 0 {
-		return
+	for userID, pending := range batch {
+		l.processOwnerBatch(userID, pending)
 	}
+}
 
+func (l *FamilyLoader) processOwnerBatch(userID string, batch map[string][]chan familyResult) {
 	hiveIDs := make([]string, 0, len(batch))
 	for hiveID := range batch {
 		hiveIDs = append(hiveIDs, hiveID)
 	}
 
+	var allFamilies []*model.Family
 	query, args, err := sqlx.In(
 		`SELECT * FROM families 
-		WHERE hive_id IN (?) AND user_id=?`,
+		WHERE hive_id IN (?) AND user_id=? AND active=1
+		ORDER BY is_primary DESC, id ASC`,
 		hiveIDs, userID)
-
-	if err != nil {
-		for _, ch := range batch {
-			close(ch)
-		}
-		return
+	if err == nil {
+		err = l.db.Select(&allFamilies, l.db.Rebind(query), args...)
 	}
 
-	query = l.db.Rebind(query)
-
-	var allFamilies []*model.Family
-	err = l.db.Select(&allFamilies, query, args...)
-
 	if err != nil {
-		for _, ch := range batch {
-			close(ch)
+		for _, channels := range batch {
+			for _, ch := range channels {
+				ch <- familyResult{err: err}
+			}
 		}
 		return
 	}
@@ -287,86 +392,89 @@ func (l *FamilyLoader) processBatch(userID string) {
 		}
 	}
 
-	for hiveID, ch := range batch {
-		family := familiesByHive[hiveID]
-		ch <- family
+	for hiveID, channels := range batch {
+		for _, ch := range channels {
+			ch <- familyResult{family: familiesByHive[hiveID]}
+		}
 	}
 }
 
 type FrameLoader struct {
 	db    *sqlx.DB
 	mu    sync.Mutex
-	batch map[string]chan []*model.Frame
+	batch map[string]map[string][]chan framesResult
 	timer *time.Timer
 	wait  time.Duration
 }
 
+type framesResult struct {
+	frames []*model.Frame
+	err    error
+}
+
 func NewFrameLoader(db *sqlx.DB) *FrameLoader {
 	return &FrameLoader{
 		db:    db,
-		batch: make(map[string]chan []*model.Frame),
+		batch: make(map[string]map[string][]chan framesResult),
 		wait:  1 * time.Millisecond,
 	}
 }
 
 func (l *FrameLoader) Load(ctx context.Context, boxID string, userID string) ([]*model.Frame, error) {
-	resultChan := make(chan []*model.Frame, 1)
+	resultChan := make(chan framesResult, 1)
 
 	l.mu.Lock()
 	needsScheduling := len(l.batch) == 0
-	l.batch[boxID] = resultChan
+	if l.batch[userID] == nil {
+		l.batch[userID] = make(map[string][]chan framesResult)
+	}
+	l.batch[userID][boxID] = append(l.batch[userID][boxID], resultChan)
 
 	if needsScheduling {
-		l.timer = time.AfterFunc(l.wait, func() {
-			l.processBatch(userID)
-		})
+		l.timer = time.AfterFunc(l.wait, l.processBatch)
 	}
 	l.mu.Unlock()
 
 	select {
 	case result := <-resultChan:
-		return result, nil
+		return result.frames, result.err
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
-func (l *FrameLoader) processBatch(userID string) {
+func (l *FrameLoader) processBatch() {
 	l.mu.Lock()
 	batch := l.batch
-	l.batch = make(map[string]chan []*model.Frame)
+	l.batch = make(map[string]map[string][]chan framesResult)
 	l.mu.Unlock()
 
-	if len(batch) == 0 {
-		return
+	for userID, pending := range batch {
+		l.processOwnerBatch(userID, pending)
 	}
+}
 
+func (l *FrameLoader) processOwnerBatch(userID string, batch map[string][]chan framesResult) {
 	boxIDs := make([]string, 0, len(batch))
 	for boxID := range batch {
 		boxIDs = append(boxIDs, boxID)
 	}
 
+	var allFrames []*model.Frame
 	query, args, err := sqlx.In(
 		`SELECT * FROM frames 
 		WHERE active=1 AND box_id IN (?) AND user_id=? 
 		ORDER BY box_id, position`,
 		boxIDs, userID)
-
-	if err != nil {
-		for _, ch := range batch {
-			close(ch)
-		}
-		return
+	if err == nil {
+		err = l.db.Select(&allFrames, l.db.Rebind(query), args...)
 	}
 
-	query = l.db.Rebind(query)
-
-	var allFrames []*model.Frame
-	err = l.db.Select(&allFrames, query, args...)
-
 	if err != nil {
-		for _, ch := range batch {
-			close(ch)
+		for _, channels := range batch {
+			for _, ch := range channels {
+				ch <- framesResult{err: err}
+			}
 		}
 		return
 	}
@@ -377,27 +485,35 @@ func (l *FrameLoader) processBatch(userID string) {
 		framesByBox[boxIDStr] = append(framesByBox[boxIDStr], frame)
 	}
 
-	for boxID, ch := range batch {
+	for boxID, channels := range batch {
 		frames := framesByBox[boxID]
 		if frames == nil {
 			frames = []*model.Frame{}
 		}
-		ch <- frames
+		for _, ch := range channels {
+			ch <- framesResult{frames: frames}
+		}
 	}
 }
 
 type FrameSideLoader struct {
 	db    *sqlx.DB
 	mu    sync.Mutex
-	batch map[int]chan *model.FrameSide
+	batch map[string]map[int][]chan frameSideResult
 	timer *time.Timer
 	wait  time.Duration
 }
 
+// frameSideResult carries the frame side or the error of the batch query to every caller waiting for the id
+type frameSideResult struct {
+	side *model.FrameSide
+	err  error
+}
+
 func NewFrameSideLoader(db *sqlx.DB) *FrameSideLoader {
 	return &FrameSideLoader{
 		db:    db,
-		batch: make(map[int]chan *model.FrameSide),
+		batch: make(map[string]map[int][]chan frameSideResult),
 		wait:  1 * time.Millisecond,
 	}
 }
@@ -407,62 +523,59 @@ func (l *FrameSideLoader) Load(ctx context.Context, frameSideID *int, userID str
 		return nil, nil
 	}
 
-	resultChan := make(chan *model.FrameSide, 1)
+	resultChan := make(chan frameSideResult, 1)
 
 	l.mu.Lock()
 	needsScheduling := len(l.batch) == 0
-	l.batch[*frameSideID] = resultChan
+	if l.batch[userID] == nil {
+		l.batch[userID] = make(map[int][]chan frameSideResult)
+	}
+	l.batch[userID][*frameSideID] = append(l.batch[userID][*frameSideID], resultChan)
 
 	if needsScheduling {
-		l.timer = time.AfterFunc(l.wait, func() {
-			l.processBatch(userID)
-		})
+		l.timer = time.AfterFunc(l.wait, l.processBatch)
 	}
 	l.mu.Unlock()
 
 	select {
 	case result := <-resultChan:
-		return result, nil
+		return result.side, result.err
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
-func (l *FrameSideLoader) processBatch(userID string) {
+func (l *FrameSideLoader) processBatch() {
 	l.mu.Lock()
 	batch := l.batch
-	l.batch = make(map[int]chan *model.FrameSide)
+	l.batch = make(map[string]map[int][]chan frameSideResult)
 	l.mu.Unlock()
 
-	if len(batch) == 0 {
-		return
+	for userID, pending := range batch {
+		l.processOwnerBatch(userID, pending)
 	}
+}
 
+func (l *FrameSideLoader) processOwnerBatch(userID string, batch map[int][]chan frameSideResult) {
 	frameSideIDs := make([]int, 0, len(batch))
 	for frameSideID := range batch {
 		frameSideIDs = append(frameSideIDs, frameSideID)
 	}
 
+	var allFrameSides []*model.FrameSide
 	query, args, err := sqlx.In(
 		`SELECT * FROM frames_sides 
 		WHERE id IN (?) AND user_id=?`,
 		frameSideIDs, userID)
-
-	if err != nil {
-		for _, ch := range batch {
-			close(ch)
-		}
-		return
+	if err == nil {
+		err = l.db.Select(&allFrameSides, l.db.Rebind(query), args...)
 	}
 
-	query = l.db.Rebind(query)
-
-	var allFrameSides []*model.FrameSide
-	err = l.db.Select(&allFrameSides, query, args...)
-
 	if err != nil {
-		for _, ch := range batch {
-			close(ch)
+		for _, channels := range batch {
+			for _, ch := range channels {
+				ch <- frameSideResult{err: err}
+			}
 		}
 		return
 	}
@@ -476,8 +589,9 @@ func (l *FrameSideLoader) processBatch(userID string) {
 		}
 	}
 
-	for frameSideID, ch := range batch {
-		frameSide := frameSidesMap[frameSideID]
-		ch <- frameSide
+	for frameSideID, channels := range batch {
+		for _, ch := range channels {
+			ch <- frameSideResult{side: frameSidesMap[frameSideID]}
+		}
 	}
 }
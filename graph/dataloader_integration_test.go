@@ -11,6 +11,7 @@ import (
 	"github.com/Gratheon/swarm-api/graph/model"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDataLoader(t *testing.T) {
@@ -436,4 +437,158 @@ func TestDataLoaderIntegrationWithResolvers(t *testing.T) {
 			assert.Equal(t, fmt.Sprintf("%d", rightSideID), *rightSide.ID, "Right side ID should match")
 		}
 	})
+
+	t.Run("loaders resolve own and shared hives in the same pass", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		ownerID := createTestUserID()
+		memberID := createTestUserID()
+		defer cleanupTestData(t, db, ownerID)
+		defer cleanupTestData(t, db, memberID)
+
+		sharedApiaryID := createTestApiary(t, db, ownerID)
+		sharedHiveID := createTestHive(t, db, ownerID, sharedApiaryID)
+		createTestBox(t, db, ownerID, sharedHiveID)
+		createTestQueen(t, db, ownerID, sharedHiveID)
+		ownHiveID := createTestHive(t, db, memberID, createTestApiary(t, db, memberID))
+		createTestBox(t, db, memberID, ownHiveID)
+		createTestQueen(t, db, memberID, ownHiveID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		ownerCtx := context.WithValue(context.Background(), "userID", ownerID)
+		memberCtx := context.WithValue(context.Background(), "userID", memberID)
+		invite, err := mutation.InviteApiaryMember(ownerCtx, fmt.Sprintf("%d", sharedApiaryID), model.ApiaryRoleViewer)
+		require.NoError(t, err)
+		_, err = mutation.AcceptApiaryInvite(memberCtx, invite.Token)
+		require.NoError(t, err)
+
+		memberCtx = context.WithValue(memberCtx, LoadersKey, &Loaders{
+			HivesByApiaryLoader: NewHiveLoader(db),
+			BoxesByHiveLoader:   NewBoxLoader(db),
+			FamilyByHiveLoader:  NewFamilyLoader(db),
+		})
+		apiaries, err := (&queryResolver{Resolver: resolver}).Apiaries(memberCtx)
+		require.NoError(t, err)
+		require.Len(t, apiaries, 2)
+
+		var hives []*model.Hive
+		for _, apiary := range apiaries {
+			apiaryHives, err := (&apiaryResolver{Resolver: resolver}).Hives(memberCtx, apiary, nil, nil)
+			require.NoError(t, err)
+			hives = append(hives, apiaryHives...)
+		}
+		require.Len(t, hives, 2)
+
+		type loaded struct {
+			boxes  []*model.Box
+			family *model.Family
+			err    error
+		}
+		hiveResolver := &hiveResolver{Resolver: resolver}
+		results := make([]chan loaded, len(hives))
+
+		// ACT
+		for i, hive := range hives {
+			results[i] = make(chan loaded, 2)
+			go func(hive *model.Hive, out chan loaded) {
+				boxes, err := hiveResolver.Boxes(memberCtx, hive)
+				out <- loaded{boxes: boxes, err: err}
+			}(hive, results[i])
+			go func(hive *model.Hive, out chan loaded) {
+				family, err := hiveResolver.Family(memberCtx, hive)
+				out <- loaded{family: family, err: err}
+			}(hive, results[i])
+		}
+
+		// ASSERT
+		for i, hive := range hives {
+			boxesLoaded, familyLoaded := 0, 0
+			for j := 0; j < 2; j++ {
+				result := <-results[i]
+				require.NoError(t, result.err)
+				if len(result.boxes) > 0 {
+					boxesLoaded++
+				}
+				if result.family != nil {
+					familyLoaded++
+				}
+			}
+			assert.Equal(t, 1, boxesLoaded, "hive %s must have its box", hive.ID)
+			assert.Equal(t, 1, familyLoaded, "hive %s must have its family", hive.ID)
+		}
+	})
+
+	t.Run("by id loaders answer every caller of the same id and pass query errors", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveIDNum := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		hiveID := fmt.Sprintf("%d", hiveIDNum)
+		sideID := createTestFrameSide(t, db, userID)
+		createTestFrameWithSides(t, db, userID, createTestBox(t, db, userID, hiveIDNum), 0, sideID, createTestFrameSide(t, db, userID))
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		hiveLoader := NewHiveByIDLoader(db)
+		sideLoader := NewFrameSideLoader(db)
+
+		type loaded struct {
+			hive *model.Hive
+			side *model.FrameSide
+			err  error
+		}
+		hiveResults := make(chan loaded, 2)
+		sideResults := make(chan loaded, 2)
+
+		// ACT
+		for i := 0; i < 2; i++ {
+			go func() {
+				hive, err := hiveLoader.Load(ctx, hiveID, userID)
+				hiveResults <- loaded{hive: hive, err: err}
+			}()
+			go func() {
+				side, err := sideLoader.Load(ctx, &sideID, userID)
+				sideResults <- loaded{side: side, err: err}
+			}()
+		}
+		firstHive, secondHive := <-hiveResults, <-hiveResults
+		firstSide, secondSide := <-sideResults, <-sideResults
+
+		closedDB := setupTestDB(t)
+		closedDB.Close()
+		_, closedHiveErr := NewHiveByIDLoader(closedDB).Load(ctx, hiveID, userID)
+		closedSide, closedSideErr := NewFrameSideLoader(closedDB).Load(ctx, &sideID, userID)
+		db.Close()
+
+		// ASSERT
+		for _, result := range []loaded{firstHive, secondHive} {
+			assert.NoError(t, result.err)
+			if assert.NotNil(t, result.hive) {
+				assert.Equal(t, hiveID, result.hive.ID)
+			}
+		}
+		for _, result := range []loaded{firstSide, secondSide} {
+			assert.NoError(t, result.err)
+			if assert.NotNil(t, result.side) && assert.NotNil(t, result.side.ID) {
+				assert.Equal(t, fmt.Sprintf("%d", sideID), *result.side.ID)
+			}
+		}
+		assert.Error(t, closedHiveErr)
+		assert.Error(t, closedSideErr)
+		assert.Nil(t, closedSide)
+	})
 }
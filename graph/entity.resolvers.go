@@ -7,7 +7,7 @@ package graph
 
 import (
 	"context"
-	"fmt"
+	"strconv"
 
 	"github.com/Gratheon/swarm-api/graph/generated"
 	"github.com/Gratheon/swarm-api/graph/model"
@@ -15,12 +15,43 @@ import (
 
 // FindFrameSideByID is the resolver for the findFrameSideByID field.
 func (r *entityResolver) FindFrameSideByID(ctx context.Context, id *string) (*model.FrameSide, error) {
-	panic(fmt.Errorf("not implemented"))
+	if id == nil {
+		return nil, nil
+	}
+
+	uid, err := r.actingUserID(ctx, model.AccessFrameSide, *id, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	idNum, err := strconv.Atoi(*id)
+	if err != nil {
+		return nil, err
+	}
+
+	loaders := GetLoaders(ctx)
+	if loaders != nil && loaders.FrameSideLoader != nil {
+		return loaders.FrameSideLoader.Load(ctx, &idNum, uid)
+	}
+	return (&model.FrameSide{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(&idNum)
 }
 
 // FindHiveByID is the resolver for the findHiveByID field.
 func (r *entityResolver) FindHiveByID(ctx context.Context, id string) (*model.Hive, error) {
-	panic(fmt.Errorf("not implemented"))
+	uid, err := r.actingUserID(ctx, model.AccessHive, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	loaders := GetLoaders(ctx)
+	if loaders != nil && loaders.HiveByIDLoader != nil {
+		return loaders.HiveByIDLoader.Load(ctx, id, uid)
+	}
+	return (&model.Hive{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(id)
 }
 
 // Entity returns generated.EntityResolver implementation.
@@ -0,0 +1,192 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/Gratheon/swarm-api/graph/generated"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const entitiesQuery = `query($representations: [_Any!]!) {
+	_entities(representations: $representations) {
+		__typename
+		... on Hive { id hiveNumber }
+		... on FrameSide { id }
+	}
+}`
+
+type entitiesResponse struct {
+	Entities []*struct {
+		Typename   string  `json:"__typename"`
+		ID         *string `json:"id"`
+		HiveNumber *int    `json:"hiveNumber"`
+	} `json:"_entities"`
+}
+
+func newEntitiesTestClient(db *sqlx.DB, userID string) *client.Client {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{Db: db}}))
+	srv.AddTransport(transport.POST{})
+
+	return client.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "userID", userID)
+		ctx = context.WithValue(ctx, LoadersKey, &Loaders{
+			HiveByIDLoader:  NewHiveByIDLoader(db),
+			FrameSideLoader: NewFrameSideLoader(db),
+		})
+		srv.ServeHTTP(w, r.WithContext(ctx))
+	}))
+}
+
+func TestEntityResolvers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resolves hive entities of the authenticated user in one batch", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hive1ID := createTestHive(t, db, userID, apiaryID)
+		hive2ID := createTestHive(t, db, userID, apiaryID)
+
+		c := newEntitiesTestClient(db, userID)
+		var resp entitiesResponse
+
+		// ACT
+		err := c.Post(entitiesQuery, &resp, client.Var("representations", []map[string]interface{}{
+			{"__typename": "Hive", "id": strconv.Itoa(hive1ID)},
+			{"__typename": "Hive", "id": strconv.Itoa(hive2ID)},
+		}))
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, resp.Entities, 2)
+		require.NotNil(t, resp.Entities[0])
+		require.NotNil(t, resp.Entities[1])
+		assert.Equal(t, "Hive", resp.Entities[0].Typename)
+		assert.Equal(t, strconv.Itoa(hive1ID), *resp.Entities[0].ID)
+		assert.Equal(t, strconv.Itoa(hive2ID), *resp.Entities[1].ID)
+	})
+
+	t.Run("returns null for hive entity of another user", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		ownerID := createTestUserID()
+		otherUserID := createTestUserID()
+		defer cleanupTestData(t, db, ownerID)
+
+		apiaryID := createTestApiary(t, db, ownerID)
+		hiveID := createTestHive(t, db, ownerID, apiaryID)
+
+		c := newEntitiesTestClient(db, otherUserID)
+		var resp entitiesResponse
+
+		// ACT
+		err := c.Post(entitiesQuery, &resp, client.Var("representations", []map[string]interface{}{
+			{"__typename": "Hive", "id": strconv.Itoa(hiveID)},
+		}))
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, resp.Entities, 1)
+		assert.Nil(t, resp.Entities[0])
+	})
+
+	t.Run("resolves frame side entities mixed with hives", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		boxID := createTestBox(t, db, userID, hiveID)
+		leftSideID := createTestFrameSide(t, db, userID)
+		rightSideID := createTestFrameSide(t, db, userID)
+		createTestFrameWithSides(t, db, userID, boxID, 0, leftSideID, rightSideID)
+
+		c := newEntitiesTestClient(db, userID)
+		var resp entitiesResponse
+
+		// ACT
+		err := c.Post(entitiesQuery, &resp, client.Var("representations", []map[string]interface{}{
+			{"__typename": "FrameSide", "id": strconv.Itoa(leftSideID)},
+			{"__typename": "Hive", "id": strconv.Itoa(hiveID)},
+			{"__typename": "FrameSide", "id": strconv.Itoa(rightSideID)},
+		}))
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, resp.Entities, 3)
+		require.NotNil(t, resp.Entities[0])
+		require.NotNil(t, resp.Entities[1])
+		require.NotNil(t, resp.Entities[2])
+		assert.Equal(t, "FrameSide", resp.Entities[0].Typename)
+		assert.Equal(t, strconv.Itoa(leftSideID), *resp.Entities[0].ID)
+		assert.Equal(t, "Hive", resp.Entities[1].Typename)
+		assert.Equal(t, strconv.Itoa(rightSideID), *resp.Entities[2].ID)
+	})
+
+	t.Run("returns null for frame side entity of another user", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		ownerID := createTestUserID()
+		otherUserID := createTestUserID()
+		defer cleanupTestData(t, db, ownerID)
+
+		sideID := createTestFrameSide(t, db, ownerID)
+
+		c := newEntitiesTestClient(db, otherUserID)
+		var resp entitiesResponse
+
+		// ACT
+		err := c.Post(entitiesQuery, &resp, client.Var("representations", []map[string]interface{}{
+			{"__typename": "FrameSide", "id": strconv.Itoa(sideID)},
+		}))
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, resp.Entities, 1)
+		assert.Nil(t, resp.Entities[0])
+	})
+}
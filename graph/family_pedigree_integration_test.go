@@ -0,0 +1,161 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueenPedigree(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pedigree lists ancestors up to the requested depth and mothers list daughters", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		grandmother := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+		mother := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+		droneColony := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+		daughter := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		familyFields := &familyResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		station := "Spiekeroog"
+		origin := "Buckfast B-line"
+		openMated := model.MatingTypeOpen
+		stationMated := model.MatingTypeMatingStation
+
+		_, err := mutation.SetQueenPedigree(ctx, mother, model.QueenPedigreeInput{MotherID: &grandmother, MatingType: &openMated, DroneOrigin: &origin})
+		require.NoError(t, err)
+
+		// ACT
+		family, err := mutation.SetQueenPedigree(ctx, daughter, model.QueenPedigreeInput{
+			MotherID:      &mother,
+			MatingType:    &stationMated,
+			MatingStation: &station,
+			DroneSourceID: &droneColony,
+		})
+		require.NoError(t, err)
+		fullTree, treeErr := familyFields.Pedigree(ctx, family, nil)
+		depth := 1
+		shallowTree, shallowErr := familyFields.Pedigree(ctx, family, &depth)
+		daughters, daughtersErr := familyFields.Daughters(ctx, &model.Family{ID: mother})
+
+		// ASSERT
+		require.NotNil(t, family.MatingStation)
+		assert.Equal(t, station, *family.MatingStation)
+
+		require.NoError(t, treeErr)
+		require.NotNil(t, fullTree.Mother)
+		assert.Equal(t, mother, fullTree.Mother.Family.ID)
+		assert.Equal(t, 1, fullTree.Mother.Generation)
+		require.NotNil(t, fullTree.DroneSource)
+		assert.Equal(t, droneColony, fullTree.DroneSource.Family.ID)
+		require.NotNil(t, fullTree.Mother.Mother)
+		assert.Equal(t, grandmother, fullTree.Mother.Mother.Family.ID)
+		assert.Equal(t, 2, fullTree.Mother.Mother.Generation)
+
+		require.NoError(t, shallowErr)
+		require.NotNil(t, shallowTree.Mother)
+		assert.Nil(t, shallowTree.Mother.Mother)
+
+		require.NoError(t, daughtersErr)
+		require.Len(t, daughters, 1)
+		assert.Equal(t, daughter, daughters[0].ID)
+	})
+
+	t.Run("a queen can not become mother of her own ancestor", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		mother := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+		daughter := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		station := "Spiekeroog"
+		openMated := model.MatingTypeOpen
+
+		_, err := mutation.SetQueenPedigree(ctx, daughter, model.QueenPedigreeInput{MotherID: &mother})
+		require.NoError(t, err)
+
+		// ACT
+		_, cycleErr := mutation.SetQueenPedigree(ctx, mother, model.QueenPedigreeInput{MotherID: &daughter})
+		_, selfErr := mutation.SetQueenPedigree(ctx, mother, model.QueenPedigreeInput{MotherID: &mother})
+		_, stationErr := mutation.SetQueenPedigree(ctx, mother, model.QueenPedigreeInput{MatingType: &openMated, MatingStation: &station})
+
+		// ASSERT
+		assert.Error(t, cycleErr)
+		assert.Error(t, selfErr)
+		assert.Error(t, stationErr)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM families WHERE user_id=? AND id=? AND mother_id IS NOT NULL", userID, mother))
+	})
+
+	t.Run("queen added to a queenless split is recorded as daughter of the source colony queen", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		sourceQueen := strconv.Itoa(createTestQueen(t, db, userID, sourceHiveID))
+		boxID := createTestBox(t, db, userID, sourceHiveID)
+		frameIDs := createTestFrames(t, db, userID, boxID, 2)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		splitHive, err := mutation.SplitHive(ctx, strconv.Itoa(sourceHiveID), nil, "no_queen", frameIDs)
+		require.NoError(t, err)
+		queenName := "Emergency"
+
+		// ACT
+		queen, err := mutation.AddQueenToHive(ctx, splitHive.ID, model.FamilyInput{Name: &queenName})
+		secondQueen, secondErr := mutation.AddQueenToHive(ctx, splitHive.ID, model.FamilyInput{Name: &queenName})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, queen.MotherID)
+		assert.Equal(t, sourceQueen, *queen.MotherID)
+
+		require.NoError(t, secondErr)
+		assert.Nil(t, secondQueen.MotherID)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE id=? AND split_mother_id IS NOT NULL", splitHive.ID))
+	})
+}
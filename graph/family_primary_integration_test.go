@@ -0,0 +1,139 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrimaryQueen(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first queen of a hive is primary until another queen is made primary", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := strconv.Itoa(createTestHive(t, db, userID, createTestApiary(t, db, userID)))
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		firstName := "Old"
+		secondName := "Young"
+
+		first, err := mutation.AddQueenToHive(ctx, hiveID, model.FamilyInput{Name: &firstName})
+		require.NoError(t, err)
+		second, err := mutation.AddQueenToHive(ctx, hiveID, model.FamilyInput{Name: &secondName})
+		require.NoError(t, err)
+
+		// ACT
+		primary, err := mutation.SetPrimaryQueen(ctx, hiveID, second.ID)
+		families, listErr := (&model.Family{Db: db, UserID: userID}).ListByHive(hiveID)
+
+		// ASSERT
+		assert.True(t, first.IsPrimary)
+		assert.False(t, second.IsPrimary)
+
+		require.NoError(t, err)
+		assert.True(t, primary.IsPrimary)
+
+		require.NoError(t, listErr)
+		require.Len(t, families, 2)
+		assert.Equal(t, second.ID, families[0].ID)
+		assert.False(t, families[1].IsPrimary)
+	})
+
+	t.Run("treatment of a box assigned to a second queen is recorded for her", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveIDInt := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		hiveID := strconv.Itoa(hiveIDInt)
+		boxID := strconv.Itoa(createTestBox(t, db, userID, hiveIDInt))
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenName := "Split"
+
+		_, err := mutation.AddQueenToHive(ctx, hiveID, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+		second, err := mutation.AddQueenToHive(ctx, hiveID, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+
+		// ACT
+		box, err := mutation.AssignBoxFamily(ctx, boxID, &second.ID)
+		require.NoError(t, err)
+		_, treatErr := mutation.TreatBox(ctx, model.TreatmentOfBoxInput{HiveID: hiveID, BoxID: boxID, Type: "oxalic_acid"})
+
+		// ASSERT
+		require.NotNil(t, box.FamilyID)
+		assert.Equal(t, second.ID, *box.FamilyID)
+		require.NoError(t, treatErr)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM treatments WHERE user_id=? AND box_id=? AND family_id=?", userID, boxID, second.ID))
+	})
+
+	t.Run("moving the primary queen out promotes the remaining queen and frees her boxes", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveIDInt := createTestHive(t, db, userID, apiaryID)
+		hiveID := strconv.Itoa(hiveIDInt)
+		otherHiveID := strconv.Itoa(createTestHive(t, db, userID, apiaryID))
+		boxID := strconv.Itoa(createTestBox(t, db, userID, hiveIDInt))
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenName := "Split"
+
+		first, err := mutation.AddQueenToHive(ctx, hiveID, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+		second, err := mutation.AddQueenToHive(ctx, hiveID, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+		stranger, err := mutation.AddQueenToHive(ctx, otherHiveID, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+		_, err = mutation.AssignBoxFamily(ctx, boxID, &first.ID)
+		require.NoError(t, err)
+
+		// ACT
+		_, moveErr := mutation.MoveQueenToWarehouse(ctx, hiveID, first.ID)
+		_, strangerErr := mutation.AssignBoxFamily(ctx, boxID, &stranger.ID)
+
+		// ASSERT
+		require.NoError(t, moveErr)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM families WHERE user_id=? AND id=? AND is_primary=1", userID, second.ID))
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM families WHERE user_id=? AND id=? AND is_primary=1", userID, first.ID))
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE user_id=? AND id=? AND family_id IS NOT NULL", userID, boxID))
+		assert.Error(t, strangerErr)
+	})
+}
@@ -0,0 +1,201 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("feeding is recorded into a feeder box and takes feed from the warehouse", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		feederResult := db.MustExec("INSERT INTO boxes (user_id, hive_id, position, type, active) VALUES (?, ?, 1, 'HORIZONTAL_FEEDER', 1)", userID, hiveID)
+		feederID, _ := feederResult.LastInsertId()
+		roofResult := db.MustExec("INSERT INTO boxes (user_id, hive_id, position, type, active) VALUES (?, ?, 2, 'ROOF', 1)", userID, hiveID)
+		roofID, _ := roofResult.LastInsertId()
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		hive := strconv.Itoa(hiveID)
+		feederBox := strconv.FormatInt(feederID, 10)
+		roofBox := strconv.FormatInt(roofID, 10)
+
+		_, err := mutation.SetWarehouseAutoUpdateFromHives(ctx, true)
+		require.NoError(t, err)
+		_, err = mutation.SetWarehouseFeedStock(ctx, model.FeedTypeSyrup2_1, 3)
+		require.NoError(t, err)
+
+		// ACT
+		feeding, err := mutation.RecordFeeding(ctx, hive, model.FeedTypeSyrup2_1, 3, model.FeedUnitL, &feederBox, nil, nil)
+		_, roofErr := mutation.RecordFeeding(ctx, hive, model.FeedTypeSyrup2_1, 1, model.FeedUnitL, &roofBox, nil, nil)
+		_, litersErr := mutation.RecordFeeding(ctx, hive, model.FeedTypeFondant, 1, model.FeedUnitL, nil, nil, nil)
+		stockAfterFeeding, stockErr := query.WarehouseFeedStock(ctx)
+		deleted, deleteErr := mutation.DeleteFeeding(ctx, feeding.ID)
+		stockAfterDelete, _ := query.WarehouseFeedStock(ctx)
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, feeding.BoxID)
+		assert.Equal(t, feederBox, *feeding.BoxID)
+		assert.Equal(t, 3.99, feeding.FeedKg)
+		assert.Equal(t, 2.66, feeding.SugarKg)
+		require.NotNil(t, feeding.ApiaryID)
+		assert.Equal(t, strconv.Itoa(apiaryID), *feeding.ApiaryID)
+
+		assert.Error(t, roofErr)
+		assert.Error(t, litersErr)
+
+		require.NoError(t, stockErr)
+		assert.Equal(t, 0.0, feedStock(stockAfterFeeding, model.FeedTypeSyrup2_1))
+
+		require.NoError(t, deleteErr)
+		assert.True(t, deleted)
+		assert.Equal(t, 3.0, feedStock(stockAfterDelete, model.FeedTypeSyrup2_1))
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND action='feeding' AND active=1", userID, hiveID))
+	})
+
+	t.Run("concurrent deletes give the feed back once", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		feederResult := db.MustExec("INSERT INTO boxes (user_id, hive_id, position, type, active) VALUES (?, ?, 1, 'HORIZONTAL_FEEDER', 1)", userID, hiveID)
+		feederID, _ := feederResult.LastInsertId()
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		feederBox := strconv.FormatInt(feederID, 10)
+
+		_, err := mutation.SetWarehouseAutoUpdateFromHives(ctx, true)
+		require.NoError(t, err)
+		_, err = mutation.SetWarehouseFeedStock(ctx, model.FeedTypeSyrup2_1, 3)
+		require.NoError(t, err)
+		feeding, err := mutation.RecordFeeding(ctx, strconv.Itoa(hiveID), model.FeedTypeSyrup2_1, 3, model.FeedUnitL, &feederBox, nil, nil)
+		require.NoError(t, err)
+
+		results := make(chan bool, 2)
+
+		// ACT
+		for i := 0; i < 2; i++ {
+			go func() {
+				deleted, err := mutation.DeleteFeeding(ctx, feeding.ID)
+				assert.NoError(t, err)
+				results <- deleted
+			}()
+		}
+		first, second := <-results, <-results
+		stock, stockErr := (&queryResolver{Resolver: resolver}).WarehouseFeedStock(ctx)
+
+		// ASSERT
+		assert.True(t, first != second, "exactly one delete must deactivate the feeding")
+		require.NoError(t, stockErr)
+		assert.Equal(t, 3.0, feedStock(stock, model.FeedTypeSyrup2_1))
+	})
+
+	t.Run("feeding report sums sugar fed in the season", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		otherApiaryID := createTestApiary(t, db, userID)
+		firstHiveID := createTestHive(t, db, userID, apiaryID)
+		secondHiveID := createTestHive(t, db, userID, apiaryID)
+		otherHiveID := createTestHive(t, db, userID, otherApiaryID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		_, err := mutation.SetWarehouseAutoUpdateFromHives(ctx, false)
+		require.NoError(t, err)
+
+		feed := func(hiveID int, feedType model.FeedType, amount float64, unit model.FeedUnit, date string) {
+			_, err := mutation.RecordFeeding(ctx, strconv.Itoa(hiveID), feedType, amount, unit, nil, &date, nil)
+			require.NoError(t, err)
+		}
+		feed(firstHiveID, model.FeedTypeDrySugar, 4, model.FeedUnitKg, "2026-09-01T10:00:00Z")
+		feed(firstHiveID, model.FeedTypePollenPatty, 500, model.FeedUnitG, "2026-09-02T10:00:00Z")
+		feed(secondHiveID, model.FeedTypeFondant, 2, model.FeedUnitKg, "2026-10-01T10:00:00Z")
+		feed(secondHiveID, model.FeedTypeDrySugar, 10, model.FeedUnitKg, "2025-09-01T10:00:00Z")
+		feed(otherHiveID, model.FeedTypeDrySugar, 7, model.FeedUnitKg, "2026-09-01T10:00:00Z")
+
+		season := 2026
+		apiary := strconv.Itoa(apiaryID)
+
+		// ACT
+		report, err := query.FeedingReport(ctx, &season, &apiary)
+		allApiaries, allErr := query.FeedingReport(ctx, &season, nil)
+		history, historyErr := query.ApiaryFeedings(ctx, apiary, &season)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Feedings)
+		assert.Equal(t, 5.8, report.SugarKg)
+		require.Len(t, report.ByHive, 2)
+		assert.Equal(t, strconv.Itoa(firstHiveID), report.ByHive[0].HiveID)
+		assert.Equal(t, 4.0, report.ByHive[0].SugarKg)
+		assert.Equal(t, 4.5, report.ByHive[0].FeedKg)
+		require.Len(t, report.ByFeedType, 3)
+		assert.Equal(t, model.FeedTypeDrySugar, report.ByFeedType[0].FeedType)
+
+		require.NoError(t, allErr)
+		assert.Equal(t, 12.8, allApiaries.SugarKg)
+
+		require.NoError(t, historyErr)
+		require.Len(t, history, 3)
+		assert.Equal(t, model.FeedTypeFondant, history[0].FeedType)
+	})
+}
+
+func feedStock(stock []*model.FeedStock, feedType model.FeedType) float64 {
+	for _, item := range stock {
+		if item.FeedType == feedType {
+			return item.AmountKg
+		}
+	}
+	return -1
+}
@@ -0,0 +1,37 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedAmountKg(t *testing.T) {
+	feedKg, sugarKg, err := model.FeedAmountKg(model.FeedTypeSyrup1_1, 2, model.FeedUnitL)
+	require.NoError(t, err)
+	assert.Equal(t, 2.46, feedKg)
+	assert.Equal(t, 1.23, sugarKg)
+
+	feedKg, sugarKg, err = model.FeedAmountKg(model.FeedTypeSyrup2_1, 1500, model.FeedUnitMl)
+	require.NoError(t, err)
+	assert.Equal(t, 1.995, feedKg)
+	assert.Equal(t, 1.33, sugarKg)
+
+	feedKg, sugarKg, err = model.FeedAmountKg(model.FeedTypeFondant, 2500, model.FeedUnitG)
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, feedKg)
+	assert.Equal(t, 2.25, sugarKg)
+
+	feedKg, sugarKg, err = model.FeedAmountKg(model.FeedTypePollenPatty, 0.5, model.FeedUnitKg)
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, feedKg)
+	assert.Equal(t, 0.0, sugarKg)
+
+	_, _, err = model.FeedAmountKg(model.FeedTypeFondant, 1, model.FeedUnitL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameHistory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("frames moved by a split keep the box and hive they came from", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		sourceHiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		createTestQueen(t, db, userID, sourceHiveID)
+		sourceBoxID := createTestBox(t, db, userID, sourceHiveID)
+		frameIDs := createTestFrames(t, db, userID, sourceBoxID, 3)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		frameFields := &frameResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		splitHive, err := mutation.SplitHive(ctx, strconv.Itoa(sourceHiveID), nil, "take_old_queen", frameIDs[:1])
+		require.NoError(t, err)
+		movedID, _ := strconv.Atoi(frameIDs[0])
+		stayedID, _ := strconv.Atoi(frameIDs[1])
+		movedHistory, movedErr := frameFields.History(ctx, &model.Frame{ID: movedID, UserID: userID})
+		stayedHistory, stayedErr := frameFields.History(ctx, &model.Frame{ID: stayedID, UserID: userID})
+
+		// ASSERT
+		require.NoError(t, movedErr)
+		require.Len(t, movedHistory, 1)
+		assert.Equal(t, "MOVED", movedHistory[0].MoveType)
+		require.NotNil(t, movedHistory[0].FromBoxID)
+		assert.Equal(t, strconv.Itoa(sourceBoxID), *movedHistory[0].FromBoxID)
+		require.NotNil(t, movedHistory[0].FromHiveID)
+		assert.Equal(t, strconv.Itoa(sourceHiveID), *movedHistory[0].FromHiveID)
+		require.NotNil(t, movedHistory[0].ToHiveID)
+		assert.Equal(t, splitHive.ID, *movedHistory[0].ToHiveID)
+		assert.NotEmpty(t, movedHistory[0].MovedAt)
+
+		require.NoError(t, stayedErr)
+		assert.Empty(t, stayedHistory)
+	})
+
+	t.Run("frames of boxes moved by a join and its revert record the hives they went through", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		targetHiveID := createTestHive(t, db, userID, apiaryID)
+		sourceBoxID := createTestBox(t, db, userID, sourceHiveID)
+		createTestBox(t, db, userID, targetHiveID)
+		frameIDs := createTestFrames(t, db, userID, sourceBoxID, 1)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		_, joinErr := mutation.JoinHives(ctx, strconv.Itoa(sourceHiveID), strconv.Itoa(targetHiveID), "both_queens")
+		_, revertErr := mutation.RevertMerge(ctx, strconv.Itoa(sourceHiveID))
+
+		// ASSERT
+		require.NoError(t, joinErr)
+		require.NoError(t, revertErr)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM frame_moves WHERE user_id=? AND frame_id=? AND from_hive_id=? AND to_hive_id=? AND from_box_id=to_box_id", userID, frameIDs[0], sourceHiveID, targetHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM frame_moves WHERE user_id=? AND frame_id=? AND from_hive_id=? AND to_hive_id=?", userID, frameIDs[0], targetHiveID, sourceHiveID))
+	})
+
+	t.Run("combs older than the rotation age are listed for the apiary", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		boxID := createTestBox(t, db, userID, hiveID)
+		frameIDs := createTestFrames(t, db, userID, boxID, 3)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		oldComb := time.Now().AddDate(-4, 0, 0).Format("2006-01-02")
+		youngComb := time.Now().AddDate(-1, 0, 0).Format("2006-01-02")
+		future := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+
+		// ACT
+		frame, err := mutation.SetFrameCombBuiltAt(ctx, frameIDs[0], &oldComb)
+		require.NoError(t, err)
+		_, err = mutation.SetFrameCombBuiltAt(ctx, frameIDs[1], &youngComb)
+		require.NoError(t, err)
+		_, futureErr := mutation.SetFrameCombBuiltAt(ctx, frameIDs[2], &future)
+
+		due, dueErr := query.CombsDueForRotation(ctx, strconv.Itoa(apiaryID), nil)
+		maxAgeDays := 200
+		dueSooner, soonerErr := query.CombsDueForRotation(ctx, strconv.Itoa(apiaryID), &maxAgeDays)
+
+		// ASSERT
+		require.NotNil(t, frame.CombBuiltAt)
+		assert.Error(t, futureErr)
+
+		require.NoError(t, dueErr)
+		require.Len(t, due, 1)
+		assert.Equal(t, frameIDs[0], strconv.Itoa(due[0].Frame.ID))
+		assert.Equal(t, strconv.Itoa(hiveID), due[0].HiveID)
+		assert.Equal(t, strconv.Itoa(boxID), due[0].BoxID)
+		assert.GreaterOrEqual(t, due[0].CombAgeDays, 4*365)
+
+		require.NoError(t, soonerErr)
+		require.Len(t, dueSooner, 2)
+		assert.Equal(t, frameIDs[0], strconv.Itoa(dueSooner[0].Frame.ID))
+	})
+}
@@ -0,0 +1,36 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombAgeDays(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, model.CombAgeDays(nil, now))
+	invalid := "spring"
+	assert.Nil(t, model.CombAgeDays(&invalid, now))
+
+	builtAt := "2023-05-10 12:00:00"
+	age := model.CombAgeDays(&builtAt, now)
+	require.NotNil(t, age)
+	assert.Equal(t, 1096, *age)
+
+	sameDay := "2026-05-10 08:00:00"
+	age = model.CombAgeDays(&sameDay, now)
+	require.NotNil(t, age)
+	assert.Equal(t, 0, *age)
+
+	parsedTime := "2026-05-01T12:00:00Z"
+	age = model.CombAgeDays(&parsedTime, now)
+	require.NotNil(t, age)
+	assert.Equal(t, 9, *age)
+}
@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveHiveToApiary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("moving a hive drops its old placement and extends its apiary history", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		sourceApiaryID := createTestApiary(t, db, userID)
+		targetApiaryID := createTestApiary(t, db, userID)
+		hiveID := strconv.Itoa(createTestHive(t, db, userID, sourceApiaryID))
+		db.MustExec("INSERT INTO hive_placements (user_id, apiary_id, hive_id, x, y, rotation) VALUES (?, ?, ?, 10, 20, 0)", userID, sourceApiaryID, hiveID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		movedAt := "2026-05-01"
+
+		// ACT
+		hive, err := mutation.MoveHiveToApiary(ctx, hiveID, strconv.Itoa(targetApiaryID), &movedAt)
+		require.NoError(t, err)
+		history, historyErr := (&hiveResolver{Resolver: resolver}).ApiaryHistory(ctx, hive)
+
+		// ASSERT
+		require.NotNil(t, hive)
+		assert.Equal(t, targetApiaryID, hive.ApiaryID)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hive_placements WHERE hive_id=?", hiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND action='move' AND active=1", userID, hiveID))
+
+		require.NoError(t, historyErr)
+		require.Len(t, history, 2)
+		assert.Equal(t, strconv.Itoa(sourceApiaryID), history[0].ApiaryID)
+		require.NotNil(t, history[0].Until)
+		assert.Contains(t, *history[0].Until, movedAt)
+		assert.Equal(t, strconv.Itoa(targetApiaryID), history[1].ApiaryID)
+		require.NotNil(t, history[1].Apiary)
+		assert.Nil(t, history[1].Until)
+	})
+
+	t.Run("a move can not be dated before the previous one", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		firstApiaryID := createTestApiary(t, db, userID)
+		secondApiaryID := strconv.Itoa(createTestApiary(t, db, userID))
+		hiveID := strconv.Itoa(createTestHive(t, db, userID, firstApiaryID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		firstMove := "2026-06-01"
+		earlierMove := "2026-05-01"
+
+		_, err := mutation.MoveHiveToApiary(ctx, hiveID, secondApiaryID, &firstMove)
+		require.NoError(t, err)
+
+		// ACT
+		_, sameApiaryErr := mutation.MoveHiveToApiary(ctx, hiveID, secondApiaryID, nil)
+		_, backdatedErr := mutation.MoveHiveToApiary(ctx, hiveID, strconv.Itoa(firstApiaryID), &earlierMove)
+
+		// ASSERT
+		assert.Error(t, sameApiaryErr)
+		assert.Error(t, backdatedErr)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_apiary_moves WHERE hive_id=?", hiveID))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/jmoiron/sqlx"
 )
 
 var hiveLimitByBillingPlan = map[string]int{
@@ -42,13 +43,33 @@ func getBillingPlanFromContext(ctx context.Context) string {
 	return "free"
 }
 
+// getBillingPlanOfOwner returns the plan that limits hives of ownerID.
+// Plans come with the request, so the plan of an owner is stored on their own requests
+// and collaborators adding hives to a shared apiary are limited by that stored plan.
+func getBillingPlanOfOwner(ctx context.Context, db *sqlx.DB, ownerID string) (string, error) {
+	billingPlanModel := &model.UserBillingPlan{Db: db}
+	if ownerID == ctx.Value("userID").(string) {
+		billingPlan := getBillingPlanFromContext(ctx)
+		return billingPlan, billingPlanModel.Remember(ownerID, billingPlan)
+	}
+
+	billingPlan, err := billingPlanModel.Get(ownerID)
+	if err != nil || billingPlan == nil {
+		return "free", err
+	}
+	return normalizeBillingPlan(*billingPlan), nil
+}
+
 func enforceHiveCreationLimit(ctx context.Context, hiveModel *model.Hive) error {
 	activeHiveCount, err := hiveModel.CountActive()
 	if err != nil {
 		return err
 	}
 
-	billingPlan := getBillingPlanFromContext(ctx)
+	billingPlan, err := getBillingPlanOfOwner(ctx, hiveModel.Db, hiveModel.UserID)
+	if err != nil {
+		return err
+	}
 	hiveLimit := getHiveLimitForBillingPlan(billingPlan)
 	if activeHiveCount >= hiveLimit {
 		return fmt.Errorf("hive limit reached for %s plan (%d)", billingPlan, hiveLimit)
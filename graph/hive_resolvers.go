@@ -23,7 +23,7 @@ func (r *hiveResolver) HiveType(ctx context.Context, obj *model.Hive) (model.Hiv
 
 // Boxes is the resolver for the boxes field.
 func (r *hiveResolver) Boxes(ctx context.Context, obj *model.Hive) ([]*model.Box, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	loaders := GetLoaders(ctx)
 	if loaders != nil && loaders.BoxesByHiveLoader != nil {
 		return loaders.BoxesByHiveLoader.Load(ctx, obj.ID, uid)
@@ -36,7 +36,7 @@ func (r *hiveResolver) Boxes(ctx context.Context, obj *model.Hive) ([]*model.Box
 
 // Family is the resolver for the family field.
 func (r *hiveResolver) Family(ctx context.Context, obj *model.Hive) (*model.Family, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 
 	loaders := GetLoaders(ctx)
 	if loaders != nil && loaders.FamilyByHiveLoader != nil {
@@ -57,7 +57,7 @@ func (r *hiveResolver) Family(ctx context.Context, obj *model.Hive) (*model.Fami
 
 // Families is the resolver for the families field.
 func (r *hiveResolver) Families(ctx context.Context, obj *model.Hive) ([]*model.Family, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	return (&model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -66,7 +66,7 @@ func (r *hiveResolver) Families(ctx context.Context, obj *model.Hive) ([]*model.
 
 // BoxCount is the resolver for the boxCount field.
 func (r *hiveResolver) BoxCount(ctx context.Context, obj *model.Hive) (int, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	return (&model.Box{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -75,7 +75,7 @@ func (r *hiveResolver) BoxCount(ctx context.Context, obj *model.Hive) (int, erro
 
 // InspectionCount is the resolver for the inspectionCount field.
 func (r *hiveResolver) InspectionCount(ctx context.Context, obj *model.Hive) (int, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	return (&model.Inspection{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -100,7 +100,7 @@ func (r *hiveResolver) IsNew(ctx context.Context, obj *model.Hive) (bool, error)
 
 // LastInspection is the resolver for the lastInspection field.
 func (r *hiveResolver) LastInspection(ctx context.Context, obj *model.Hive) (*string, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	inspectionModel := &model.Inspection{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -119,7 +119,7 @@ func (r *hiveResolver) LastInspection(ctx context.Context, obj *model.Hive) (*st
 
 // ParentHive is the resolver for the parentHive field.
 func (r *hiveResolver) ParentHive(ctx context.Context, obj *model.Hive) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	return (&model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -128,7 +128,7 @@ func (r *hiveResolver) ParentHive(ctx context.Context, obj *model.Hive) (*model.
 
 // ChildHives is the resolver for the childHives field.
 func (r *hiveResolver) ChildHives(ctx context.Context, obj *model.Hive) ([]*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	return (&model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -137,7 +137,7 @@ func (r *hiveResolver) ChildHives(ctx context.Context, obj *model.Hive) ([]*mode
 
 // MergedIntoHive is the resolver for the mergedIntoHive field.
 func (r *hiveResolver) MergedIntoHive(ctx context.Context, obj *model.Hive) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	return (&model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -146,9 +146,45 @@ func (r *hiveResolver) MergedIntoHive(ctx context.Context, obj *model.Hive) (*mo
 
 // MergedFromHives is the resolver for the mergedFromHives field.
 func (r *hiveResolver) MergedFromHives(ctx context.Context, obj *model.Hive) ([]*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	return (&model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
 	}).GetMergedFromHives(obj.ID)
 }
+
+// VarroaTrend is the resolver for the varroaTrend field.
+func (r *hiveResolver) VarroaTrend(ctx context.Context, obj *model.Hive, days *int) (*model.VarroaTrend, error) {
+	uid := objectUserID(ctx, obj.UserID)
+	period := 90
+	if days != nil && *days > 0 {
+		period = *days
+	}
+
+	points, err := (&model.VarroaCount{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByHiveSince(obj.ID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewVarroaTrend(points)
+}
+
+// ApiaryHistory is the resolver for the apiaryHistory field.
+func (r *hiveResolver) ApiaryHistory(ctx context.Context, obj *model.Hive) ([]*model.HiveApiaryStay, error) {
+	uid := objectUserID(ctx, obj.UserID)
+	return (&model.Hive{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ApiaryHistory(obj)
+}
+
+// TotalYield is the resolver for the totalYield field.
+func (r *hiveResolver) TotalYield(ctx context.Context, obj *model.Hive, season *int) (float64, error) {
+	return (&model.HoneyHarvest{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).TotalYield(obj.ID, season)
+}
@@ -0,0 +1,246 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertSplitMutation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns frames and queen to the source hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		queenID := createTestQueen(t, db, userID, sourceHiveID)
+		boxID := createTestBox(t, db, userID, sourceHiveID)
+		frameIDs := createTestFrames(t, db, userID, boxID, 4)
+
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		splitHive, err := resolver.SplitHive(ctx, strconv.Itoa(sourceHiveID), nil, "take_old_queen", frameIDs[2:])
+		require.NoError(t, err)
+		require.NotNil(t, splitHive)
+		require.Equal(t, 2, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND action='split' AND active=1", userID))
+
+		// ACT
+		sourceHive, err := resolver.RevertSplit(ctx, splitHive.ID)
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, sourceHive)
+		assert.Equal(t, strconv.Itoa(sourceHiveID), sourceHive.ID)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM families WHERE id=? AND hive_id=? AND active=1", queenID, sourceHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM frames WHERE id=? AND box_id=? AND position=2", frameIDs[2], boxID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM frames WHERE id=? AND box_id=? AND position=3", frameIDs[3], boxID))
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE id=? AND active=1", splitHive.ID))
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE hive_id=? AND active=1", splitHive.ID))
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND action='split' AND active=1", userID))
+	})
+
+	t.Run("removes the queen added by the split", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		createTestQueen(t, db, userID, sourceHiveID)
+		boxID := createTestBox(t, db, userID, sourceHiveID)
+		frameIDs := createTestFrames(t, db, userID, boxID, 2)
+
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenName := "Split Queen"
+
+		splitHive, err := resolver.SplitHive(ctx, strconv.Itoa(sourceHiveID), &queenName, "new_queen", frameIDs)
+		require.NoError(t, err)
+
+		// ACT
+		_, err = resolver.RevertSplit(ctx, splitHive.ID)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM families WHERE user_id=? AND name=? AND active=1", userID, queenName))
+		assert.Equal(t, 2, countRows(t, db, "SELECT COUNT(*) FROM frames WHERE box_id=? AND active=1", boxID))
+	})
+
+	t.Run("refuses when a frame was moved after the split", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		boxID := createTestBox(t, db, userID, sourceHiveID)
+		frameIDs := createTestFrames(t, db, userID, boxID, 2)
+
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		splitHive, err := resolver.SplitHive(ctx, strconv.Itoa(sourceHiveID), nil, "no_queen", frameIDs)
+		require.NoError(t, err)
+		db.MustExec("UPDATE frames SET box_id=? WHERE id=?", boxID, frameIDs[0])
+
+		// ACT
+		sourceHive, err := resolver.RevertSplit(ctx, splitHive.ID)
+
+		// ASSERT
+		require.Error(t, err)
+		assert.Nil(t, sourceHive)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE id=? AND active=1", splitHive.ID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM frames f JOIN boxes b ON b.id=f.box_id WHERE b.hive_id=?", splitHive.ID))
+	})
+
+	t.Run("refuses while a later split of the split hive is in effect", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		boxID := createTestBox(t, db, userID, sourceHiveID)
+		frameIDs := createTestFrames(t, db, userID, boxID, 2)
+
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		splitHive, err := resolver.SplitHive(ctx, strconv.Itoa(sourceHiveID), nil, "no_queen", frameIDs)
+		require.NoError(t, err)
+		secondSplit, err := resolver.SplitHive(ctx, splitHive.ID, nil, "no_queen", frameIDs[:1])
+		require.NoError(t, err)
+
+		// ACT
+		_, refusedErr := resolver.RevertSplit(ctx, splitHive.ID)
+		_, secondRevertErr := resolver.RevertSplit(ctx, secondSplit.ID)
+		_, firstRevertErr := resolver.RevertSplit(ctx, splitHive.ID)
+
+		// ASSERT
+		require.Error(t, refusedErr)
+		require.NoError(t, secondRevertErr)
+		require.NoError(t, firstRevertErr)
+		assert.Equal(t, 2, countRows(t, db, "SELECT COUNT(*) FROM frames WHERE box_id=? AND active=1", boxID))
+	})
+}
+
+func TestRevertMergeMutation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns boxes, status and placement to the source hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		targetHiveID := createTestHive(t, db, userID, apiaryID)
+		sourceBoxID := createTestBox(t, db, userID, sourceHiveID)
+		createTestBox(t, db, userID, targetHiveID)
+		db.MustExec("INSERT INTO hive_placements (user_id, apiary_id, hive_id, x, y, rotation) VALUES (?, ?, ?, 10, 20, 90)", userID, apiaryID, sourceHiveID)
+
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		_, err := resolver.JoinHives(ctx, strconv.Itoa(sourceHiveID), strconv.Itoa(targetHiveID), "both_queens")
+		require.NoError(t, err)
+		require.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE id=? AND hive_id=?", sourceBoxID, targetHiveID))
+
+		// ACT
+		sourceHive, err := resolver.RevertMerge(ctx, strconv.Itoa(sourceHiveID))
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, sourceHive)
+		assert.Nil(t, sourceHive.MergedIntoHiveID)
+		assert.Nil(t, sourceHive.MergeType)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE id=? AND status='merged'", sourceHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE id=? AND hive_id=? AND position=0", sourceBoxID, sourceHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_placements WHERE hive_id=? AND x=10 AND y=20 AND rotation=90", sourceHiveID))
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND action='merge' AND active=1", userID))
+	})
+
+	t.Run("refuses when a merged box was removed", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		targetHiveID := createTestHive(t, db, userID, apiaryID)
+		sourceBoxID := createTestBox(t, db, userID, sourceHiveID)
+
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		_, err := resolver.JoinHives(ctx, strconv.Itoa(sourceHiveID), strconv.Itoa(targetHiveID), "both_queens")
+		require.NoError(t, err)
+		db.MustExec("UPDATE boxes SET active=0 WHERE id=?", sourceBoxID)
+
+		// ACT
+		sourceHive, err := resolver.RevertMerge(ctx, strconv.Itoa(sourceHiveID))
+
+		// ASSERT
+		require.Error(t, err)
+		assert.Nil(t, sourceHive)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE id=? AND status='merged'", sourceHiveID))
+	})
+}
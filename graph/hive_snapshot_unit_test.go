@@ -0,0 +1,72 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotPosition(position int) *int {
+	return &position
+}
+
+func TestCompareHiveSnapshots(t *testing.T) {
+	from := &model.Inspection{ID: "1", HiveSnapshot: &model.HiveSnapshot{
+		Boxes: []*model.HiveSnapshotBox{
+			{ID: "10", Position: snapshotPosition(0), Type: model.BoxTypeDeep, Frames: []*model.HiveSnapshotFrame{
+				{ID: "100", BoxID: "10", Position: snapshotPosition(0), Type: model.FrameTypeEmptyComb},
+				{ID: "101", BoxID: "10", Position: snapshotPosition(1), Type: model.FrameTypeFoundation},
+				{ID: "102", BoxID: "10", Position: snapshotPosition(2), Type: model.FrameTypeEmptyComb},
+			}},
+			{ID: "11", Position: snapshotPosition(1), Type: model.BoxTypeRoof, Frames: []*model.HiveSnapshotFrame{}},
+		},
+		Families: []*model.HiveSnapshotFamily{{ID: "7"}},
+	}}
+	to := &model.Inspection{ID: "2", HiveSnapshot: &model.HiveSnapshot{
+		Boxes: []*model.HiveSnapshotBox{
+			{ID: "10", Position: snapshotPosition(0), Type: model.BoxTypeDeep, Frames: []*model.HiveSnapshotFrame{
+				{ID: "100", BoxID: "10", Position: snapshotPosition(0), Type: model.FrameTypeEmptyComb},
+				{ID: "101", BoxID: "10", Position: snapshotPosition(1), Type: model.FrameTypeEmptyComb},
+			}},
+			{ID: "12", Position: snapshotPosition(1), Type: model.BoxTypeSuper, Frames: []*model.HiveSnapshotFrame{
+				{ID: "102", BoxID: "12", Position: snapshotPosition(0), Type: model.FrameTypeEmptyComb},
+			}},
+			{ID: "11", Position: snapshotPosition(2), Type: model.BoxTypeRoof, Frames: []*model.HiveSnapshotFrame{}},
+		},
+		Families: []*model.HiveSnapshotFamily{{ID: "8"}},
+	}}
+
+	comparison, err := model.CompareHiveSnapshots(from, to)
+	require.NoError(t, err)
+
+	require.Len(t, comparison.BoxesAdded, 1)
+	assert.Equal(t, "12", comparison.BoxesAdded[0].ID)
+	assert.Empty(t, comparison.BoxesRemoved)
+	require.Len(t, comparison.BoxesMoved, 1)
+	assert.Equal(t, "11", comparison.BoxesMoved[0].BoxID)
+
+	assert.Empty(t, comparison.FramesAdded)
+	assert.Empty(t, comparison.FramesRemoved)
+	require.Len(t, comparison.FramesMoved, 1)
+	assert.Equal(t, "102", comparison.FramesMoved[0].FrameID)
+	assert.Equal(t, "10", comparison.FramesMoved[0].FromBoxID)
+	assert.Equal(t, "12", comparison.FramesMoved[0].ToBoxID)
+	require.Len(t, comparison.FramesTypeChanged, 1)
+	assert.Equal(t, model.FrameTypeFoundation, comparison.FramesTypeChanged[0].FromType)
+
+	assert.True(t, comparison.QueenChanged)
+	require.Len(t, comparison.QueensAdded, 1)
+	assert.Equal(t, "8", comparison.QueensAdded[0].ID)
+	require.Len(t, comparison.QueensRemoved, 1)
+	assert.Equal(t, "7", comparison.QueensRemoved[0].ID)
+}
+
+func TestCompareHiveSnapshotsRequiresSnapshots(t *testing.T) {
+	_, err := model.CompareHiveSnapshots(&model.Inspection{}, &model.Inspection{HiveSnapshot: &model.HiveSnapshot{}})
+	assert.Error(t, err)
+}
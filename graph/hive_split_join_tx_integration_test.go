@@ -0,0 +1,208 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const injectedStepFailure = "injected step failure"
+
+// failWritesOf installs a trigger that rejects the given write to table for rows of userID,
+// so a multi-step transaction fails at that step
+func failWritesOf(t *testing.T, db *sqlx.DB, event string, table string, userID string) {
+	triggerName := fmt.Sprintf("test_fail_%s_%s_%s", strings.ToLower(event), table, userID)
+	_, err := db.Exec(fmt.Sprintf(
+		`CREATE TRIGGER %s BEFORE %s ON %s FOR EACH ROW
+		BEGIN
+			IF NEW.user_id = '%s' THEN
+				SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '%s';
+			END IF;
+		END`,
+		triggerName, event, table, userID, injectedStepFailure,
+	))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Exec("DROP TRIGGER IF EXISTS " + triggerName)
+	})
+}
+
+func countRows(t *testing.T, db *sqlx.DB, query string, args ...interface{}) int {
+	var count int
+	require.NoError(t, db.Get(&count, query, args...))
+	return count
+}
+
+func TestSplitAndJoinHivesAtomicity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SplitHive", func(t *testing.T) {
+		t.Parallel()
+
+		failingSteps := []struct {
+			name  string
+			event string
+			table string
+		}{
+			{name: "queen move", event: "UPDATE", table: "families"},
+			{name: "box creation", event: "INSERT", table: "boxes"},
+			{name: "frame move", event: "UPDATE", table: "frames"},
+		}
+
+		for _, failingStep := range failingSteps {
+			failingStep := failingStep
+
+			t.Run("rolls back every step when failing at "+failingStep.name, func(t *testing.T) {
+				t.Parallel()
+
+				// ARRANGE
+				db := setupTestDB(t)
+				if db == nil {
+					return
+				}
+				defer db.Close()
+
+				userID := createTestUserID()
+				defer cleanupTestData(t, db, userID)
+
+				apiaryID := createTestApiary(t, db, userID)
+				sourceHiveID := createTestHive(t, db, userID, apiaryID)
+				queenID := createTestQueen(t, db, userID, sourceHiveID)
+				boxID := createTestBox(t, db, userID, sourceHiveID)
+				frameIDs := createTestFrames(t, db, userID, boxID, 3)
+				failWritesOf(t, db, failingStep.event, failingStep.table, userID)
+
+				resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+				ctx := context.WithValue(context.Background(), "userID", userID)
+
+				// ACT
+				newHive, err := resolver.SplitHive(ctx, strconv.Itoa(sourceHiveID), nil, "take_old_queen", frameIDs)
+
+				// ASSERT
+				require.ErrorContains(t, err, injectedStepFailure)
+				assert.Nil(t, newHive)
+				assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE user_id=?", userID), "split hive must not be left behind")
+				assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE user_id=?", userID), "split boxes must not be left behind")
+				assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM families WHERE id=? AND hive_id=?", queenID, sourceHiveID), "queen must stay in source hive")
+				assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM family_moves WHERE user_id=?", userID))
+				assert.Equal(t, 3, countRows(t, db, "SELECT COUNT(*) FROM frames WHERE user_id=? AND box_id=?", userID, boxID), "frames must stay in source box")
+				assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM outbox_events WHERE user_id=?", userID), "no events for rolled back split")
+			})
+		}
+
+		t.Run("rolls back created hive when frames cannot be moved", func(t *testing.T) {
+			t.Parallel()
+
+			// ARRANGE
+			db := setupTestDB(t)
+			if db == nil {
+				return
+			}
+			defer db.Close()
+
+			userID := createTestUserID()
+			otherUserID := createTestUserID()
+			defer cleanupTestData(t, db, userID)
+			defer cleanupTestData(t, db, otherUserID)
+
+			apiaryID := createTestApiary(t, db, userID)
+			sourceHiveID := createTestHive(t, db, userID, apiaryID)
+			otherHiveID := createTestHive(t, db, otherUserID, createTestApiary(t, db, otherUserID))
+			foreignFrameIDs := createTestFrames(t, db, otherUserID, createTestBox(t, db, otherUserID, otherHiveID), 1)
+
+			resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+			ctx := context.WithValue(context.Background(), "userID", userID)
+			queenName := "Split Queen"
+
+			// ACT
+			newHive, err := resolver.SplitHive(ctx, strconv.Itoa(sourceHiveID), &queenName, "new_queen", foreignFrameIDs)
+
+			// ASSERT
+			require.Error(t, err)
+			assert.Nil(t, newHive)
+			assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE user_id=?", userID))
+			assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM families WHERE user_id=?", userID))
+			assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE user_id=?", userID))
+		})
+
+		t.Run("rejects frames of another hive of the same owner", func(t *testing.T) {
+			t.Parallel()
+
+			// ARRANGE
+			db := setupTestDB(t)
+			if db == nil {
+				return
+			}
+			defer db.Close()
+
+			userID := createTestUserID()
+			defer cleanupTestData(t, db, userID)
+
+			apiaryID := createTestApiary(t, db, userID)
+			sourceHiveID := createTestHive(t, db, userID, apiaryID)
+			otherHiveID := createTestHive(t, db, userID, apiaryID)
+			otherBoxID := createTestBox(t, db, userID, otherHiveID)
+			otherFrameIDs := createTestFrames(t, db, userID, otherBoxID, 2)
+
+			resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+			ctx := context.WithValue(context.Background(), "userID", userID)
+
+			// ACT
+			newHive, err := resolver.SplitHive(ctx, strconv.Itoa(sourceHiveID), nil, "no_queen", otherFrameIDs)
+
+			// ASSERT
+			require.EqualError(t, err, "frames to split must be in the source hive")
+			assert.Nil(t, newHive)
+			assert.Equal(t, 2, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE user_id=?", userID))
+			assert.Equal(t, 2, countRows(t, db, "SELECT COUNT(*) FROM frames WHERE user_id=? AND box_id=?", userID, otherBoxID))
+		})
+	})
+
+	t.Run("JoinHives", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("keeps boxes in source hive when merge fails after moving them", func(t *testing.T) {
+			t.Parallel()
+
+			// ARRANGE
+			db := setupTestDB(t)
+			if db == nil {
+				return
+			}
+			defer db.Close()
+
+			userID := createTestUserID()
+			defer cleanupTestData(t, db, userID)
+
+			apiaryID := createTestApiary(t, db, userID)
+			sourceHiveID := createTestHive(t, db, userID, apiaryID)
+			targetHiveID := createTestHive(t, db, userID, apiaryID)
+			sourceBoxID := createTestBox(t, db, userID, sourceHiveID)
+			// marking the source hive as merged is the last step, after the boxes were moved
+			failWritesOf(t, db, "UPDATE", "hives", userID)
+
+			resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+			ctx := context.WithValue(context.Background(), "userID", userID)
+
+			// ACT
+			mergedHive, err := resolver.JoinHives(ctx, strconv.Itoa(sourceHiveID), strconv.Itoa(targetHiveID), "both_queens")
+
+			// ASSERT
+			require.ErrorContains(t, err, injectedStepFailure)
+			assert.Nil(t, mergedHive)
+			assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE id=? AND hive_id=?", sourceBoxID, sourceHiveID))
+			assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM hives WHERE id=? AND merged_into_hive_id IS NOT NULL", sourceHiveID))
+			assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM outbox_events WHERE user_id=?", userID))
+		})
+	})
+}
@@ -0,0 +1,148 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveFramesAndBoxes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("frames moved to another hive keep their sides and are logged in both hives", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		strongHiveID := createTestHive(t, db, userID, apiaryID)
+		weakHiveID := createTestHive(t, db, userID, apiaryID)
+		strongBoxID := createTestBox(t, db, userID, strongHiveID)
+		leftSideID := createTestFrameSide(t, db, userID)
+		rightSideID := createTestFrameSide(t, db, userID)
+		broodFrameID := strconv.Itoa(createTestFrameWithSides(t, db, userID, strongBoxID, 1, leftSideID, rightSideID))
+
+		weakBoxID, err := (&model.Box{Db: db, UserID: userID}).Create(strconv.Itoa(weakHiveID), 0, nil, model.BoxTypeDeep, nil)
+		require.NoError(t, err)
+		weakBoxIDInt, _ := strconv.Atoi(*weakBoxID)
+		weakFrameIDs := createTestFrames(t, db, userID, weakBoxIDInt, 2)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		frames, err := mutation.MoveFrames(ctx, []string{broodFrameID}, *weakBoxID, nil)
+		_, takenErr := mutation.MoveFrames(ctx, []string{weakFrameIDs[0]}, *weakBoxID, []int{2})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, weakBoxIDInt, frames[0].BoxId)
+		assert.Equal(t, 2, frames[0].Position)
+		require.NotNil(t, frames[0].LeftID)
+		assert.Equal(t, leftSideID, *frames[0].LeftID)
+		require.NotNil(t, frames[0].RightID)
+		assert.Equal(t, rightSideID, *frames[0].RightID)
+		assert.NotNil(t, frames[0].FrameSpecID)
+
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND title='Frames moved to another hive'", userID, strongHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND title='Frames moved from another hive'", userID, weakHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM frame_moves WHERE user_id=? AND frame_id=? AND from_hive_id=? AND to_hive_id=?", userID, broodFrameID, strongHiveID, weakHiveID))
+
+		assert.Error(t, takenErr)
+	})
+
+	t.Run("box moved onto another hive is placed at the position and leaves its queen behind", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		targetHiveID := createTestHive(t, db, userID, apiaryID)
+		queenID := strconv.Itoa(createTestQueen(t, db, userID, sourceHiveID))
+		superIDInt := createTestBox(t, db, userID, sourceHiveID)
+		superID := strconv.Itoa(superIDInt)
+		movedFrameIDs := createTestFrames(t, db, userID, superIDInt, 2)
+		targetBoxID := strconv.Itoa(createTestBox(t, db, userID, targetHiveID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		_, err := mutation.AssignBoxFamily(ctx, superID, &queenID)
+		require.NoError(t, err)
+		position := 0
+
+		// ACT
+		box, err := mutation.MoveBox(ctx, superID, strconv.Itoa(targetHiveID), &position)
+		_, sameHiveErr := mutation.MoveBox(ctx, superID, strconv.Itoa(targetHiveID), nil)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, targetHiveID, box.HiveId)
+		require.NotNil(t, box.Position)
+		assert.Equal(t, 0, *box.Position)
+		assert.Nil(t, box.FamilyID)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE user_id=? AND id=? AND position=1", userID, targetBoxID))
+		assert.Equal(t, 2, countRows(t, db, "SELECT COUNT(*) FROM frames WHERE user_id=? AND box_id=? AND active=1", userID, superID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM frame_moves WHERE user_id=? AND frame_id=? AND from_hive_id=? AND to_hive_id=?", userID, movedFrameIDs[0], sourceHiveID, targetHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND title='Box moved to another hive'", userID, sourceHiveID))
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND title='Box moved from another hive'", userID, targetHiveID))
+
+		assert.Error(t, sameHiveErr)
+	})
+
+	t.Run("box can not be moved onto a hive of another box system", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		systemModel := &model.BoxSystem{Db: db, UserID: userID}
+		defer cleanupBoxSystemsByUser(t, systemModel)
+
+		apiaryID := createTestApiary(t, db, userID)
+		sourceHiveID := createTestHive(t, db, userID, apiaryID)
+		targetHiveID := createTestHive(t, db, userID, apiaryID)
+		boxID := strconv.Itoa(createTestBox(t, db, userID, sourceHiveID))
+		db.MustExec("UPDATE hives SET box_system_id=? WHERE id=?", insertOwnedBoxSystem(t, systemModel, "Dadant"), sourceHiveID)
+		db.MustExec("UPDATE hives SET box_system_id=? WHERE id=?", insertOwnedBoxSystem(t, systemModel, "Warre"), targetHiveID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		_, err := mutation.MoveBox(ctx, boxID, strconv.Itoa(targetHiveID), nil)
+
+		// ASSERT
+		assert.Error(t, err)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE user_id=? AND id=? AND hive_id=?", userID, boxID, sourceHiveID))
+	})
+}
@@ -0,0 +1,160 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoneyHarvest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("harvest is credited to the queen family and validated", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		familyID := createTestQueen(t, db, userID, hiveID)
+		superID := db.MustExec("INSERT INTO boxes (user_id, hive_id, position, type, active) VALUES (?, ?, 1, 'SUPER', 1)", userID, hiveID)
+		superBoxID, _ := superID.LastInsertId()
+		broodBoxID := createTestBox(t, db, userID, hiveID)
+		feederID := db.MustExec("INSERT INTO boxes (user_id, hive_id, position, type, active) VALUES (?, ?, 2, 'FEEDER', 1)", userID, hiveID)
+		feederBoxID, _ := feederID.LastInsertId()
+		otherHiveBoxID := createTestBox(t, db, userID, createTestHive(t, db, userID, apiaryID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		hive := strconv.Itoa(hiveID)
+		boxIDs := []string{strconv.FormatInt(superBoxID, 10), strconv.Itoa(broodBoxID), strconv.FormatInt(superBoxID, 10)}
+		frames := 9
+		moisture := 17.5
+		floralSource := " rapeseed "
+		date := "2026-06-12T10:00:00Z"
+		tooWet := 35.0
+
+		// ACT
+		harvest, err := mutation.RecordHarvest(ctx, hive, boxIDs, &frames, 24.5, &moisture, &floralSource, &date)
+		_, feederErr := mutation.RecordHarvest(ctx, hive, []string{strconv.FormatInt(feederBoxID, 10)}, nil, 5, nil, nil, nil)
+		_, otherHiveErr := mutation.RecordHarvest(ctx, hive, []string{strconv.Itoa(otherHiveBoxID)}, nil, 5, nil, nil, nil)
+		_, weightErr := mutation.RecordHarvest(ctx, hive, nil, nil, 0, nil, nil, nil)
+		_, moistureErr := mutation.RecordHarvest(ctx, hive, nil, nil, 5, &tooWet, nil, nil)
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, harvest)
+		require.NotNil(t, harvest.FamilyID)
+		assert.Equal(t, strconv.Itoa(familyID), *harvest.FamilyID)
+		require.NotNil(t, harvest.ApiaryID)
+		assert.Equal(t, strconv.Itoa(apiaryID), *harvest.ApiaryID)
+		assert.Equal(t, 24.5, harvest.WeightKg)
+		require.NotNil(t, harvest.FloralSource)
+		assert.Equal(t, "rapeseed", *harvest.FloralSource)
+		assert.Len(t, harvest.BoxIDs, 2)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND action='harvest' AND active=1", userID, hiveID))
+
+		assert.Error(t, feederErr)
+		assert.Error(t, otherHiveErr)
+		assert.Error(t, weightErr)
+		assert.Error(t, moistureErr)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM honey_harvests WHERE user_id=?", userID))
+	})
+
+	t.Run("yield is summed per hive, family, apiary and season", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		firstHiveID := createTestHive(t, db, userID, apiaryID)
+		firstFamilyID := createTestQueen(t, db, userID, firstHiveID)
+		secondHiveID := createTestHive(t, db, userID, apiaryID)
+		createTestQueen(t, db, userID, secondHiveID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		record := func(hiveID int, weightKg float64, moisture *float64, date string) string {
+			harvest, err := mutation.RecordHarvest(ctx, strconv.Itoa(hiveID), nil, nil, weightKg, moisture, nil, &date)
+			require.NoError(t, err)
+			return harvest.ID
+		}
+		dry, wet := 16.0, 19.0
+		record(firstHiveID, 10, &dry, "2025-07-01T10:00:00Z")
+		record(firstHiveID, 30, &wet, "2026-06-01T10:00:00Z")
+		removedID := record(firstHiveID, 100, nil, "2026-08-01T10:00:00Z")
+		record(secondHiveID, 15, nil, "2026-07-01T10:00:00Z")
+
+		season := 2026
+		apiary := strconv.Itoa(apiaryID)
+
+		// ACT
+		deleted, deleteErr := mutation.DeleteHarvest(ctx, removedID)
+		byHive, hiveErr := query.HarvestYield(ctx, model.YieldGroupingHive, nil, &season)
+		bySeason, seasonErr := query.HarvestYield(ctx, model.YieldGroupingSeason, &apiary, nil)
+		byFamily, familyErr := query.HarvestYield(ctx, model.YieldGroupingFamily, nil, nil)
+		total, totalErr := (&hiveResolver{resolver}).TotalYield(ctx, &model.Hive{ID: strconv.Itoa(firstHiveID), UserID: userID}, nil)
+		history, historyErr := (&familyResolver{resolver}).YieldHistory(ctx, &model.Family{ID: strconv.Itoa(firstFamilyID), UserID: userID})
+
+		// ASSERT
+		require.NoError(t, deleteErr)
+		assert.True(t, deleted)
+
+		require.NoError(t, hiveErr)
+		require.Len(t, byHive, 2)
+		assert.Equal(t, strconv.Itoa(firstHiveID), *byHive[0].HiveID)
+		assert.Equal(t, 30.0, byHive[0].TotalKg)
+		assert.Nil(t, byHive[0].Season)
+
+		require.NoError(t, seasonErr)
+		require.Len(t, bySeason, 2)
+		assert.Equal(t, 2025, *bySeason[0].Season)
+		assert.Equal(t, 10.0, bySeason[0].TotalKg)
+		assert.Equal(t, 2026, *bySeason[1].Season)
+		assert.Equal(t, 45.0, bySeason[1].TotalKg)
+		assert.Equal(t, 2, bySeason[1].Harvests)
+		require.NotNil(t, bySeason[1].AverageMoisture)
+		assert.InDelta(t, 19.0, *bySeason[1].AverageMoisture, 0.01)
+
+		require.NoError(t, familyErr)
+		require.Len(t, byFamily, 2)
+		assert.Equal(t, strconv.Itoa(firstFamilyID), *byFamily[0].FamilyID)
+		assert.Equal(t, 40.0, byFamily[0].TotalKg)
+		require.NotNil(t, byFamily[0].AverageMoisture)
+		assert.InDelta(t, 18.25, *byFamily[0].AverageMoisture, 0.01)
+
+		require.NoError(t, totalErr)
+		assert.Equal(t, 40.0, total)
+
+		require.NoError(t, historyErr)
+		require.Len(t, history, 2)
+		assert.Equal(t, 2025, *history[0].Season)
+		assert.Equal(t, 2026, *history[1].Season)
+	})
+}
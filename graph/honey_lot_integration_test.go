@@ -0,0 +1,254 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoneyLots(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lot with a harvest inside a withdrawal period needs ignoreWithdrawal", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		treatedHiveID := createTestHive(t, db, userID, apiaryID)
+		treatedFamilyID := createTestQueen(t, db, userID, treatedHiveID)
+		cleanHiveID := createTestHive(t, db, userID, apiaryID)
+		createTestQueen(t, db, userID, cleanHiveID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		product, err := mutation.AddTreatmentProduct(ctx, model.TreatmentProductInput{
+			Name:           "Formic strips",
+			DosageUnit:     model.TreatmentDosageUnitMl,
+			WithdrawalDays: 30,
+		})
+		require.NoError(t, err)
+		treatedOn := "2026-05-01T08:00:00Z"
+		_, err = mutation.TreatHive(ctx, model.TreatmentOfHiveInput{
+			HiveID:    strconv.Itoa(treatedHiveID),
+			Type:      "formic_acid",
+			ProductID: &product.ID,
+			StartDate: &treatedOn,
+		})
+		require.NoError(t, err)
+		treatmentID := countRows(t, db, "SELECT MAX(id) FROM treatments WHERE user_id=?", userID)
+
+		record := func(hiveID int, weightKg float64, date string) string {
+			harvest, err := mutation.RecordHarvest(ctx, strconv.Itoa(hiveID), nil, nil, weightKg, nil, nil, &date)
+			require.NoError(t, err)
+			return harvest.ID
+		}
+		treatedHarvestID := record(treatedHiveID, 12, "2026-05-20T10:00:00Z")
+		cleanHarvestID := record(cleanHiveID, 20, "2026-05-21T10:00:00Z")
+		laterHarvestID := record(cleanHiveID, 5, "2026-07-01T10:00:00Z")
+
+		ignore := true
+		lotNumber := "MARKET-1"
+		input := model.HoneyLotInput{HarvestIds: []string{treatedHarvestID, cleanHarvestID}, JarCount: 64}
+
+		// ACT
+		_, blockedErr := mutation.AddHoneyLot(ctx, input)
+		input.IgnoreWithdrawal = &ignore
+		lot, err := mutation.AddHoneyLot(ctx, input)
+		_, reusedErr := mutation.AddHoneyLot(ctx, model.HoneyLotInput{HarvestIds: []string{cleanHarvestID}, JarCount: 1})
+		cleanLot, cleanErr := mutation.AddHoneyLot(ctx, model.HoneyLotInput{LotNumber: &lotNumber, HarvestIds: []string{laterHarvestID}, JarCount: 10})
+		_, duplicateErr := mutation.AddHoneyLot(ctx, model.HoneyLotInput{LotNumber: &lotNumber, HarvestIds: []string{laterHarvestID}, JarCount: 10})
+
+		// ASSERT
+		require.Error(t, blockedErr)
+		assert.Contains(t, blockedErr.Error(), "withdrawal")
+
+		require.NoError(t, err)
+		require.NotNil(t, lot)
+		assert.Regexp(t, `^2026-\d{3}$`, lot.LotNumber)
+		assert.True(t, lot.WithdrawalOverridden)
+		assert.Equal(t, 32.0, lot.TotalKg)
+		assert.Len(t, lot.HarvestIDs, 2)
+
+		warnings, err := (&honeyLotResolver{resolver}).WithdrawalWarnings(ctx, lot)
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, treatedHarvestID, warnings[0].HarvestID)
+		require.NotNil(t, warnings[0].TreatmentID)
+		assert.Equal(t, strconv.Itoa(treatmentID), *warnings[0].TreatmentID)
+
+		assert.Error(t, reusedErr)
+
+		require.NoError(t, cleanErr)
+		assert.False(t, cleanLot.WithdrawalOverridden)
+		found, err := query.HoneyLotByNumber(ctx, " MARKET-1 ")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, cleanLot.ID, found.ID)
+		assert.Error(t, duplicateErr)
+
+		trace, err := query.HoneyLotTrace(ctx, lot.ID, nil)
+		require.NoError(t, err)
+		require.NotNil(t, trace)
+		assert.Len(t, trace.Harvests, 2)
+		require.Len(t, trace.Sources, 2)
+		assert.Equal(t, strconv.Itoa(cleanHiveID), trace.Sources[0].HiveID)
+		assert.Equal(t, strconv.Itoa(treatedHiveID), trace.Sources[1].HiveID)
+		require.NotNil(t, trace.Sources[1].FamilyID)
+		assert.Equal(t, strconv.Itoa(treatedFamilyID), *trace.Sources[1].FamilyID)
+		require.Len(t, trace.Treatments, 1)
+		assert.Equal(t, treatmentID, trace.Treatments[0].ID)
+	})
+
+	t.Run("deleted lot frees its harvests", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		harvest, err := mutation.RecordHarvest(ctx, strconv.Itoa(hiveID), nil, nil, 8, nil, nil, nil)
+		require.NoError(t, err)
+		lot, err := mutation.AddHoneyLot(ctx, model.HoneyLotInput{HarvestIds: []string{harvest.ID}, JarCount: 16})
+		require.NoError(t, err)
+
+		// ACT
+		harvestDeleted, bottledErr := mutation.DeleteHarvest(ctx, harvest.ID)
+		deleted, deleteErr := mutation.DeleteHoneyLot(ctx, lot.ID)
+		again, againErr := mutation.AddHoneyLot(ctx, model.HoneyLotInput{HarvestIds: []string{harvest.ID}, JarCount: 16})
+
+		// ASSERT
+		require.Error(t, bottledErr)
+		assert.Contains(t, bottledErr.Error(), "harvest is in lot "+lot.LotNumber)
+		assert.False(t, harvestDeleted)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM honey_harvests WHERE id=? AND active=1", harvest.ID))
+
+		require.NoError(t, deleteErr)
+		assert.True(t, deleted)
+		require.NoError(t, againErr)
+		assert.NotEqual(t, lot.LotNumber, again.LotNumber)
+		assert.Equal(t, 8.0, again.TotalKg)
+	})
+
+	t.Run("generated lot numbers continue after the highest number of the year", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		extractedAt := "2026-08-01T10:00:00Z"
+		manualNumber := "2026-005"
+		first, err := mutation.RecordHarvest(ctx, strconv.Itoa(hiveID), nil, nil, 8, nil, nil, nil)
+		require.NoError(t, err)
+		second, err := mutation.RecordHarvest(ctx, strconv.Itoa(hiveID), nil, nil, 6, nil, nil, nil)
+		require.NoError(t, err)
+		_, err = mutation.AddHoneyLot(ctx, model.HoneyLotInput{LotNumber: &manualNumber, HarvestIds: []string{first.ID}, JarCount: 16})
+		require.NoError(t, err)
+
+		// ACT
+		lot, err := mutation.AddHoneyLot(ctx, model.HoneyLotInput{HarvestIds: []string{second.ID}, JarCount: 12, ExtractedAt: &extractedAt})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, "2026-006", lot.LotNumber)
+	})
+
+	t.Run("concurrent lots get their own numbers and never share a harvest", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := strconv.Itoa(createTestHive(t, db, userID, createTestApiary(t, db, userID)))
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		harvestIDs := make([]string, 3)
+		for i := range harvestIDs {
+			harvest, err := mutation.RecordHarvest(ctx, hiveID, nil, nil, 5, nil, nil, nil)
+			require.NoError(t, err)
+			harvestIDs[i] = harvest.ID
+		}
+		extractedAt := "2027-08-01T10:00:00Z"
+
+		type created struct {
+			lot *model.HoneyLot
+			err error
+		}
+		create := func(ids ...string) chan created {
+			out := make(chan created, 1)
+			go func() {
+				lot, err := mutation.AddHoneyLot(ctx, model.HoneyLotInput{HarvestIds: ids, JarCount: 8, ExtractedAt: &extractedAt})
+				out <- created{lot, err}
+			}()
+			return out
+		}
+
+		// ACT
+		firstOwn, secondOwn := create(harvestIDs[0]), create(harvestIDs[1])
+		firstShared, secondShared := create(harvestIDs[2]), create(harvestIDs[2])
+		own := []created{<-firstOwn, <-secondOwn}
+		shared := []created{<-firstShared, <-secondShared}
+
+		// ASSERT
+		require.NoError(t, own[0].err)
+		require.NoError(t, own[1].err)
+		assert.NotEqual(t, own[0].lot.LotNumber, own[1].lot.LotNumber)
+		assert.Regexp(t, `^2027-\d{3}$`, own[0].lot.LotNumber)
+
+		sharedErrors := 0
+		for _, result := range shared {
+			if result.err != nil {
+				sharedErrors++
+			}
+		}
+		assert.Equal(t, 1, sharedErrors, "a harvest must end up in one lot only")
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM honey_lot_harvests WHERE harvest_id=?", harvestIDs[2]))
+	})
+}
@@ -0,0 +1,167 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectionLifecycle(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inspectionsConnection pages through inspections added in the same second", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		created := []string{}
+		for i := 0; i < 5; i++ {
+			inspection, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: hiveID, Data: "{}"})
+			require.NoError(t, err)
+			created = append(created, inspection.ID)
+		}
+		db.MustExec("UPDATE inspections SET added='2026-05-01 10:00:00' WHERE user_id=?", userID)
+
+		first := 2
+		seen := []string{}
+		var after *string
+
+		// ACT
+		for page := 0; page < 3; page++ {
+			connection, err := query.InspectionsConnection(ctx, strconv.Itoa(hiveID), &first, after)
+			require.NoError(t, err)
+			assert.Equal(t, 5, connection.TotalCount)
+			for _, edge := range connection.Edges {
+				seen = append(seen, edge.Node.ID)
+			}
+			if !connection.PageInfo.HasNextPage {
+				break
+			}
+			after = connection.PageInfo.EndCursor
+		}
+
+		// ASSERT
+		assert.Equal(t, []string{created[4], created[3], created[2], created[1], created[0]}, seen)
+
+		latest, err := (&model.Inspection{Db: db, UserID: userID}).GetLatestByHiveId(strconv.Itoa(hiveID))
+		require.NoError(t, err)
+		assert.Equal(t, created[4], latest.ID)
+	})
+
+	t.Run("updateInspection changes observations and keeps the hive snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		createTestBox(t, db, userID, hiveID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		inspection, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: hiveID, Data: `{"frames":[1]}`})
+		require.NoError(t, err)
+		createTestBox(t, db, userID, hiveID)
+
+		queenSeen := true
+		temperament := model.InspectionTemperamentNervous
+
+		// ACT
+		updated, err := mutation.UpdateInspection(ctx, inspection.ID, model.InspectionUpdateInput{
+			Observations: &model.InspectionObservationsInput{QueenSeen: &queenSeen, Temperament: &temperament},
+		})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, updated)
+		assert.True(t, *updated.Observations.QueenSeen)
+		assert.Equal(t, temperament, *updated.Observations.Temperament)
+		assert.Contains(t, updated.Data, `"frames":[1]`)
+		require.NotNil(t, updated.HiveSnapshot)
+		assert.Len(t, updated.HiveSnapshot.Boxes, 1)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM inspections WHERE id=? AND queen_seen=1 AND temperament='NERVOUS'", inspection.ID))
+
+		invalid := "[1]"
+		_, err = mutation.UpdateInspection(ctx, inspection.ID, model.InspectionUpdateInput{Data: &invalid})
+		assert.Error(t, err)
+	})
+
+	t.Run("deleteInspection hides the inspection and falls back to the previous latest one", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		older, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: hiveID, Data: "{}"})
+		require.NoError(t, err)
+		newer, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: hiveID, Data: "{}"})
+		require.NoError(t, err)
+
+		// ACT
+		deleted, err := mutation.DeleteInspection(ctx, newer.ID)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		found, err := query.Inspection(ctx, newer.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		list, err := query.Inspections(ctx, strconv.Itoa(hiveID), nil)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, older.ID, list[0].ID)
+
+		latest, err := (&model.Inspection{Db: db, UserID: userID}).GetLatestByHiveId(strconv.Itoa(hiveID))
+		require.NoError(t, err)
+		assert.Equal(t, older.ID, latest.ID)
+
+		deletedAgain, err := mutation.DeleteInspection(ctx, newer.ID)
+		require.NoError(t, err)
+		assert.False(t, deletedAgain)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM inspections WHERE id=? AND active=0", newer.ID))
+	})
+}
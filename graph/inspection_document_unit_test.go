@@ -0,0 +1,72 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInspectionDocumentUpgradesLegacyData(t *testing.T) {
+	doc, err := model.ParseInspectionDocument(`{"queenSeen":false,"temperament":"calm","broodPattern":"unknown","hive":{"boxes":[]}}`)
+	require.NoError(t, err)
+
+	require.NotNil(t, doc.Observations.QueenSeen)
+	assert.False(t, *doc.Observations.QueenSeen)
+	require.NotNil(t, doc.Observations.Temperament)
+	assert.Equal(t, model.InspectionTemperamentCalm, *doc.Observations.Temperament)
+	assert.Nil(t, doc.Observations.BroodPattern, "invalid legacy values are dropped")
+
+	data, err := doc.JSON()
+	require.NoError(t, err)
+
+	var stored map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &stored))
+	assert.Equal(t, float64(model.InspectionSchemaVersion), stored["schemaVersion"])
+	assert.Contains(t, stored, "hive", "unknown keys are kept verbatim")
+	assert.Equal(t, false, stored["observations"].(map[string]interface{})["queenSeen"])
+}
+
+func TestParseInspectionDocumentValidatesVersionedData(t *testing.T) {
+	_, err := model.ParseInspectionDocument(`{"schemaVersion":1,"observations":{"temperament":"GRUMPY"}}`)
+	assert.Error(t, err)
+
+	_, err = model.ParseInspectionDocument(`{"schemaVersion":1,"observations":{"queenSeen":"yes"}}`)
+	assert.Error(t, err)
+
+	_, err = model.ParseInspectionDocument(`{"schemaVersion":1,"observations":{"mood":"ok"}}`)
+	assert.Error(t, err)
+
+	_, err = model.ParseInspectionDocument(`{"schemaVersion":2}`)
+	assert.Error(t, err)
+
+	_, err = model.ParseInspectionDocument(`[1,2]`)
+	assert.Error(t, err)
+
+	doc, err := model.ParseInspectionDocument(`{"schemaVersion":1,"observations":{"queenSeen":true,"population":"STRONG"}}`)
+	require.NoError(t, err)
+	assert.True(t, *doc.Observations.QueenSeen)
+	assert.Equal(t, model.InspectionPopulationStrong, *doc.Observations.Population)
+}
+
+func TestInspectionDocumentApplyInput(t *testing.T) {
+	doc, err := model.ParseInspectionDocument(`{}`)
+	require.NoError(t, err)
+
+	queenSeen := true
+	broodPattern := model.InspectionBroodPatternSpotty
+	require.NoError(t, doc.ApplyInput(&model.InspectionObservationsInput{
+		QueenSeen:    &queenSeen,
+		BroodPattern: &broodPattern,
+	}))
+	assert.True(t, *doc.Observations.QueenSeen)
+	assert.Equal(t, broodPattern, *doc.Observations.BroodPattern)
+
+	invalid := model.InspectionPopulation("HUGE")
+	assert.Error(t, doc.ApplyInput(&model.InspectionObservationsInput{Population: &invalid}))
+}
@@ -0,0 +1,226 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectionObservations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("addInspection rejects invalid observations", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		inspection, err := resolver.AddInspection(ctx, model.InspectionInput{
+			HiveID: hiveID,
+			Data:   `{"schemaVersion":1,"observations":{"temperament":"GRUMPY"}}`,
+		})
+
+		// ASSERT
+		require.Error(t, err)
+		assert.Nil(t, inspection)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM inspections WHERE user_id=?", userID))
+	})
+
+	t.Run("addInspection stores typed observations from input", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		resolver := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenSeen := true
+		temperament := model.InspectionTemperamentNervous
+
+		// ACT
+		inspection, err := resolver.AddInspection(ctx, model.InspectionInput{
+			HiveID:       hiveID,
+			Data:         `{"hive":{"boxes":[]}}`,
+			Observations: &model.InspectionObservationsInput{QueenSeen: &queenSeen, Temperament: &temperament},
+		})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, inspection)
+		assert.Equal(t, model.InspectionSchemaVersion, inspection.SchemaVersion)
+		require.NotNil(t, inspection.Observations.QueenSeen)
+		assert.True(t, *inspection.Observations.QueenSeen)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM inspections WHERE id=? AND queen_seen=1 AND temperament='NERVOUS'", inspection.ID))
+	})
+
+	t.Run("legacy rows are read without writes and upgraded by the background job", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		result := db.MustExec(
+			"INSERT INTO inspections (user_id, hive_id, data, added) VALUES (?, ?, ?, NOW())",
+			userID, hiveID, `{"queenSeen":true,"broodPattern":"solid"}`,
+		)
+		legacyID, _ := result.LastInsertId()
+
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenSeen := true
+		upgradedRow := "SELECT COUNT(*) FROM inspections WHERE id=? AND schema_version=? AND queen_seen=1 AND brood_pattern='SOLID'"
+
+		// ACT
+		inspection, err := query.Inspection(ctx, strconv.FormatInt(legacyID, 10))
+		searchedBefore, searchBeforeErr := query.InspectionsSearch(ctx, model.InspectionSearchFilter{QueenSeen: &queenSeen})
+		upgradedAfterRead := countRows(t, db, upgradedRow, legacyID, model.InspectionSchemaVersion)
+		upgrader := NewInspectionUpgrader(db)
+		for {
+			upgraded, err := upgrader.UpgradePending()
+			require.NoError(t, err)
+			if upgraded < inspectionUpgradeBatchSize {
+				break
+			}
+		}
+		searchedAfter, searchAfterErr := query.InspectionsSearch(ctx, model.InspectionSearchFilter{QueenSeen: &queenSeen})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, inspection)
+		require.NotNil(t, inspection.Observations.BroodPattern)
+		assert.Equal(t, model.InspectionBroodPatternSolid, *inspection.Observations.BroodPattern)
+
+		require.NoError(t, searchBeforeErr)
+		assert.Empty(t, searchedBefore)
+		assert.Equal(t, 0, upgradedAfterRead)
+
+		assert.Equal(t, 1, countRows(t, db, upgradedRow, legacyID, model.InspectionSchemaVersion))
+		require.NoError(t, searchAfterErr)
+		require.Len(t, searchedAfter, 1)
+		assert.Equal(t, strconv.FormatInt(legacyID, 10), searchedAfter[0].ID)
+	})
+}
+
+func TestInspectionsSearchQuery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finds hives where the queen was not seen in the last inspections", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		queenlessHiveID := createTestHive(t, db, userID, apiaryID)
+		healthyHiveID := createTestHive(t, db, userID, apiaryID)
+
+		insertInspection := func(hiveID int, daysAgo int, data string) {
+			db.MustExec(
+				"INSERT INTO inspections (user_id, hive_id, data, added) VALUES (?, ?, ?, NOW() - INTERVAL ? DAY)",
+				userID, hiveID, data, daysAgo,
+			)
+		}
+		insertInspection(queenlessHiveID, 30, `{"queenSeen":true}`)
+		insertInspection(queenlessHiveID, 14, `{"queenSeen":false}`)
+		insertInspection(queenlessHiveID, 1, `{"schemaVersion":1,"observations":{"queenSeen":false}}`)
+		insertInspection(healthyHiveID, 14, `{"queenSeen":false}`)
+		insertInspection(healthyHiveID, 1, `{"queenSeen":true}`)
+
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		lastN := 2
+
+		// ACT
+		inspections, err := query.InspectionsSearch(ctx, model.InspectionSearchFilter{QueenNotSeenInLast: &lastN})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, inspections, 2)
+		for _, inspection := range inspections {
+			assert.Equal(t, strconv.Itoa(queenlessHiveID), inspection.HiveID)
+			assert.False(t, *inspection.Observations.QueenSeen)
+		}
+	})
+
+	t.Run("filters by temperament and hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		otherHiveID := createTestHive(t, db, userID, apiaryID)
+
+		inspectionModel := &model.Inspection{Db: db, UserID: userID}
+		_, err := inspectionModel.Create(`{"schemaVersion":1,"observations":{"temperament":"AGGRESSIVE"}}`, hiveID)
+		require.NoError(t, err)
+		_, err = inspectionModel.Create(`{"schemaVersion":1,"observations":{"temperament":"CALM"}}`, hiveID)
+		require.NoError(t, err)
+		_, err = inspectionModel.Create(`{"schemaVersion":1,"observations":{"temperament":"AGGRESSIVE"}}`, otherHiveID)
+		require.NoError(t, err)
+
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		inspections, err := query.InspectionsSearch(ctx, model.InspectionSearchFilter{
+			HiveIds:     []string{strconv.Itoa(hiveID)},
+			Temperament: []model.InspectionTemperament{model.InspectionTemperamentAggressive, model.InspectionTemperamentNervous},
+		})
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, inspections, 1)
+		assert.Equal(t, model.InspectionTemperamentAggressive, *inspections[0].Observations.Temperament)
+	})
+}
@@ -0,0 +1,101 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectionHiveSnapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("compareInspections reports structure changes between inspections", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		oldQueenID := createTestQueen(t, db, userID, hiveID)
+		boxID := createTestBox(t, db, userID, hiveID)
+		frameIDs := createTestFrames(t, db, userID, boxID, 2)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		first, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: hiveID, Data: "{}"})
+		require.NoError(t, err)
+
+		superID := createTestBox(t, db, userID, hiveID)
+		db.MustExec("UPDATE boxes SET position=1 WHERE id=?", superID)
+		db.MustExec("UPDATE frames SET box_id=?, position=0 WHERE id=?", superID, frameIDs[1])
+		db.MustExec("UPDATE families SET active=0 WHERE id=?", oldQueenID)
+		createTestQueen(t, db, userID, hiveID)
+
+		second, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: hiveID, Data: "{}"})
+		require.NoError(t, err)
+
+		// ACT
+		comparison, err := query.CompareInspections(ctx, first.ID, second.ID)
+
+		// ASSERT
+		require.NoError(t, err)
+		require.NotNil(t, first.HiveSnapshot)
+		require.Len(t, first.HiveSnapshot.Boxes, 1)
+		assert.Len(t, first.HiveSnapshot.Boxes[0].Frames, 2)
+
+		require.Len(t, comparison.BoxesAdded, 1)
+		assert.Equal(t, strconv.Itoa(superID), comparison.BoxesAdded[0].ID)
+		require.Len(t, comparison.FramesMoved, 1)
+		assert.Equal(t, frameIDs[1], comparison.FramesMoved[0].FrameID)
+		assert.True(t, comparison.QueenChanged)
+		require.Len(t, comparison.QueensRemoved, 1)
+		assert.Equal(t, strconv.Itoa(oldQueenID), comparison.QueensRemoved[0].ID)
+	})
+
+	t.Run("compareInspections refuses inspections of different hives", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		inspectionModel := &model.Inspection{Db: db, UserID: userID}
+		firstID, err := inspectionModel.Create("{}", createTestHive(t, db, userID, apiaryID))
+		require.NoError(t, err)
+		secondID, err := inspectionModel.Create("{}", createTestHive(t, db, userID, apiaryID))
+		require.NoError(t, err)
+
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		comparison, err := query.CompareInspections(ctx, *firstID, *secondID)
+
+		// ASSERT
+		require.Error(t, err)
+		assert.Nil(t, comparison)
+	})
+}
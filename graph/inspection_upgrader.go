@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	inspectionUpgradeLockName     = "swarm_api_inspection_upgrade"
+	inspectionUpgradePollInterval = time.Minute
+	inspectionUpgradeBatchSize    = 500
+	// pause between batches so the upgrade does not starve regular queries
+	inspectionUpgradeBatchPause = 200 * time.Millisecond
+)
+
+// InspectionUpgrader rewrites inspections stored in an older schema version in batches,
+// so reads stay read-only and search filters see the typed observations.
+type InspectionUpgrader struct {
+	Db *sqlx.DB
+}
+
+func NewInspectionUpgrader(db *sqlx.DB) *InspectionUpgrader {
+	return &InspectionUpgrader{Db: db}
+}
+
+// Run upgrades legacy rows until ctx is cancelled, rows written by older clients are picked up on the next poll
+func (u *InspectionUpgrader) Run(ctx context.Context) {
+	ticker := time.NewTicker(inspectionUpgradePollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := u.upgradeWithLock(ctx); err != nil {
+			logger.Error(fmt.Sprintf("Inspection upgrade failed: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *InspectionUpgrader) upgradeWithLock(ctx context.Context) error {
+	conn, err := u.Db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// GET_LOCK is bound to the connection, so the same connection has to release it
+	var locked sql.NullInt64
+	err = conn.GetContext(ctx, &locked, `SELECT GET_LOCK(?, 0)`, inspectionUpgradeLockName)
+	if err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return nil
+	}
+	defer conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, inspectionUpgradeLockName)
+
+	for {
+		upgraded, err := u.UpgradePending()
+		if err != nil || upgraded < inspectionUpgradeBatchSize {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(inspectionUpgradeBatchPause):
+		}
+	}
+}
+
+// UpgradePending rewrites one batch of legacy inspections and returns how many were upgraded
+func (u *InspectionUpgrader) UpgradePending() (int, error) {
+	return (&model.Inspection{Db: u.Db}).UpgradeLegacyBatch(inspectionUpgradeBatchSize)
+}
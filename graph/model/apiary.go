@@ -2,25 +2,33 @@ package model
 
 import (
 	"database/sql"
+	"strconv"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
 type Apiary struct {
-	Db       *sqlx.DB
-	ID       int        `json:"id"`
-	UserID   string     `json:"user_id" db:"user_id"`
-	Name     *string    `json:"name"`
-	Type     ApiaryType `json:"type" db:"type"`
-	Location *string    `json:"location"`
-	Active   *bool      `json:"active" db:"active"`
-	Lat      *string    `json:"lat" db:"lat"`
-	Lng      *string    `json:"lng" db:"lng"`
+	Db     *sqlx.DB
+	ID     int        `json:"id"`
+	UserID string     `json:"user_id" db:"user_id"`
+	Name   *string    `json:"name"`
+	Type   ApiaryType `json:"type" db:"type"`
+	Active *bool      `json:"active" db:"active"`
+	Lat    *string    `json:"lat" db:"lat"`
+	Lng    *string    `json:"lng" db:"lng"`
 }
 
+// apiaryColumns lists the columns read into Apiary, geo_point is only used in queries
+const apiaryColumns = "id, user_id, name, type, active, lat, lng"
+
 func (Apiary) IsEntity() {}
 
+// Location names the place of the apiary from the bundled offline place list
+func (r *Apiary) Location() *string {
+	return describeLocation(r.Lat, r.Lng)
+}
+
 func ensureApiaryType(apiary *Apiary) {
 	if apiary != nil && apiary.Type == "" {
 		apiary.Type = ApiaryTypeStatic
@@ -30,7 +38,7 @@ func ensureApiaryType(apiary *Apiary) {
 func (r *Apiary) Get(id string) (*Apiary, error) {
 	apiary := Apiary{}
 	err2 := r.Db.Get(&apiary,
-		`SELECT * 
+		`SELECT `+apiaryColumns+`
 		FROM apiaries 
 		WHERE id=? AND user_id=? AND active=1
 		LIMIT 1`, id, r.UserID)
@@ -46,7 +54,7 @@ func (r *Apiary) Get(id string) (*Apiary, error) {
 func (r *Apiary) List() ([]*Apiary, error) {
 	apiaries := []*Apiary{}
 	err2 := r.Db.Select(&apiaries,
-		`SELECT * 
+		`SELECT `+apiaryColumns+`
 		FROM apiaries
 		WHERE user_id=? AND active=1`, r.UserID)
 	for _, apiary := range apiaries {
@@ -57,6 +65,11 @@ func (r *Apiary) List() ([]*Apiary, error) {
 }
 
 func (r *Apiary) Create(input ApiaryInput) (*Apiary, error) {
+	lat, lng, err := normalizeCoordinates(input.Lat, input.Lng)
+	if err != nil {
+		return nil, err
+	}
+
 	tx := r.Db.MustBegin()
 	var apiaryType *string
 	if input.Type != nil {
@@ -70,29 +83,54 @@ func (r *Apiary) Create(input ApiaryInput) (*Apiary, error) {
 			"userID": r.UserID,
 			"name":   input.Name,
 			"type":   apiaryType,
-			"lat":    input.Lat,
-			"lng":    input.Lng,
+			"lat":    lat,
+			"lng":    lng,
 		})
 
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
 	id, err := result.LastInsertId()
-	tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = r.logLocationTx(tx, strconv.FormatInt(id, 10), input.ArrivedAt, input.Forage)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
+	apiary, err := r.recordChangeTx(tx, strconv.FormatInt(id, 10), "created")
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
+	return apiary, tx.Commit()
+}
+
+// recordChangeTx records an event with the apiary state after the change and returns that state
+func (r *Apiary) recordChangeTx(tx *sqlx.Tx, id string, action string) (*Apiary, error) {
 	apiary := Apiary{}
-	err = r.Db.Get(&apiary, "SELECT * FROM `apiaries` WHERE id=? AND user_id=? LIMIT 1", id, r.UserID)
+	err := tx.Get(&apiary, "SELECT "+apiaryColumns+" FROM `apiaries` WHERE id=? AND user_id=? LIMIT 1", id, r.UserID)
+	if err != nil {
+		return nil, err
+	}
 	ensureApiaryType(&apiary)
 
-	return &apiary, err
+	return &apiary, recordApiaryEventTx(tx, r.UserID, id, action, apiary)
 }
 
 func (r *Apiary) Update(id string, input ApiaryInput) (*Apiary, error) {
+	lat, lng, err := normalizeCoordinates(input.Lat, input.Lng)
+	if err != nil {
+		return nil, err
+	}
+
 	tx := r.Db.MustBegin()
 	var apiaryType *string
 	if input.Type != nil {
@@ -107,25 +145,29 @@ func (r *Apiary) Update(id string, input ApiaryInput) (*Apiary, error) {
 			"userID": r.UserID,
 			"name":   input.Name,
 			"type":   apiaryType,
-			"lat":    input.Lat,
-			"lng":    input.Lng,
+			"lat":    lat,
+			"lng":    lng,
 		})
 
-	tx.Commit()
+	if err2 != nil {
+		tx.Rollback()
+		return nil, err2
+	}
 
+	// mobile apiaries keep their previous locations instead of overwriting them
+	err2 = r.logLocationTx(tx, id, input.ArrivedAt, input.Forage)
 	if err2 != nil {
+		tx.Rollback()
 		return nil, err2
 	}
 
-	apiary := Apiary{}
-	err3 := r.Db.Get(&apiary,
-		`SELECT * 
-		FROM apiaries 
-		WHERE id=? AND user_id=?
-		LIMIT 1`, id, r.UserID)
-	ensureApiaryType(&apiary)
+	apiary, err3 := r.recordChangeTx(tx, id, "updated")
+	if err3 != nil {
+		tx.Rollback()
+		return nil, err3
+	}
 
-	return &apiary, err3
+	return apiary, tx.Commit()
 }
 
 func (r *Apiary) Deactivate(id string) (*bool, error) {
@@ -138,6 +180,18 @@ func (r *Apiary) Deactivate(id string) (*bool, error) {
 			"userID": r.UserID,
 		},
 	)
+	if err == nil {
+		_, err = r.recordChangeTx(tx, id, "deleted")
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		success = false
+		return &success, err
+	}
+
 	err = tx.Commit()
 
 	if err != nil {
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AccessEntity names a kind of record whose access is resolved through the apiary it belongs to
+type AccessEntity string
+
+const (
+	AccessApiary          AccessEntity = "apiary"
+	AccessApiaryObstacle  AccessEntity = "apiary_obstacle"
+	AccessHive            AccessEntity = "hive"
+	AccessBox             AccessEntity = "box"
+	AccessFrame           AccessEntity = "frame"
+	AccessFrameSide       AccessEntity = "frame_side"
+	AccessFamily          AccessEntity = "family"
+	AccessInspection      AccessEntity = "inspection"
+	AccessHiveLog         AccessEntity = "hive_log"
+	AccessTreatmentCourse AccessEntity = "treatment_course"
+	AccessVarroaCount     AccessEntity = "varroa_count"
+	AccessPollination     AccessEntity = "pollination_contract"
+	AccessHarvest         AccessEntity = "honey_harvest"
+	AccessHoneyLot        AccessEntity = "honey_lot"
+	AccessFeeding         AccessEntity = "feeding"
+	AccessQueenEvent      AccessEntity = "queen_event"
+	AccessQueenRating     AccessEntity = "queen_rating"
+)
+
+// accessLookups read the owner and apiary of a record. Records stay stored under the apiary owner,
+// so collaborators act with the owner user id once their membership is confirmed.
+var accessLookups = map[AccessEntity]string{
+	AccessApiary: `SELECT a.user_id, a.id AS apiary_id
+		FROM apiaries a WHERE a.id=? AND a.active=1`,
+	AccessApiaryObstacle: `SELECT o.user_id, o.apiary_id
+		FROM apiary_obstacles o WHERE o.id=?`,
+	AccessHive: `SELECT h.user_id, h.apiary_id
+		FROM hives h WHERE h.id=?`,
+	AccessBox: `SELECT b.user_id, h.apiary_id
+		FROM boxes b LEFT JOIN hives h ON h.id = b.hive_id WHERE b.id=?`,
+	AccessFrame: `SELECT f.user_id, h.apiary_id
+		FROM frames f LEFT JOIN boxes b ON b.id = f.box_id LEFT JOIN hives h ON h.id = b.hive_id WHERE f.id=?`,
+	AccessFrameSide: `SELECT fs.user_id, h.apiary_id
+		FROM frames_sides fs
+		LEFT JOIN frames f ON (f.left_id = fs.id OR f.right_id = fs.id) AND f.user_id = fs.user_id
+		LEFT JOIN boxes b ON b.id = f.box_id
+		LEFT JOIN hives h ON h.id = b.hive_id
+		WHERE fs.id=?
+		ORDER BY f.active DESC`,
+	AccessFamily: `SELECT fam.user_id, h.apiary_id
+		FROM families fam LEFT JOIN hives h ON h.id = fam.hive_id WHERE fam.id=?`,
+	AccessInspection: `SELECT i.user_id, h.apiary_id
+		FROM inspections i LEFT JOIN hives h ON h.id = i.hive_id WHERE i.id=?`,
+	AccessHiveLog: `SELECT l.user_id, h.apiary_id
+		FROM hive_logs l LEFT JOIN hives h ON h.id = l.hive_id WHERE l.id=?`,
+	AccessTreatmentCourse: `SELECT c.user_id, h.apiary_id
+		FROM treatment_courses c LEFT JOIN hives h ON h.id = c.hive_id WHERE c.id=?`,
+	AccessVarroaCount: `SELECT v.user_id, h.apiary_id
+		FROM varroa_counts v LEFT JOIN hives h ON h.id = v.hive_id WHERE v.id=?`,
+	AccessPollination: `SELECT p.user_id, p.apiary_id
+		FROM pollination_contracts p WHERE p.id=?`,
+	AccessHarvest: `SELECT hh.user_id, h.apiary_id
+		FROM honey_harvests hh LEFT JOIN hives h ON h.id = hh.hive_id WHERE hh.id=?`,
+	AccessFeeding: `SELECT f.user_id, h.apiary_id
+		FROM feedings f LEFT JOIN hives h ON h.id = f.hive_id WHERE f.id=?`,
+	AccessQueenEvent: `SELECT e.user_id, h.apiary_id
+		FROM queen_events e LEFT JOIN families fam ON fam.id = e.family_id LEFT JOIN hives h ON h.id = fam.hive_id WHERE e.id=?`,
+	AccessQueenRating: `SELECT qr.user_id, h.apiary_id
+		FROM queen_ratings qr LEFT JOIN families fam ON fam.id = qr.family_id LEFT JOIN hives h ON h.id = fam.hive_id WHERE qr.id=?`,
+	// lots can mix honey of several apiaries, so they are never shared
+	AccessHoneyLot: `SELECT l.user_id, NULL AS apiary_id
+		FROM honey_lots l WHERE l.id=?`,
+}
+
+// Access is what a user may do with a record and whose data it is
+type Access struct {
+	OwnerID  string
+	ApiaryID *string
+	Role     ApiaryRole
+}
+
+func (a *Access) CanEdit() bool {
+	return a.Role == ApiaryRoleOwner || a.Role == ApiaryRoleEditor
+}
+
+// ApiaryAccess resolves access of UserID to records, either as their owner or as an apiary member
+type ApiaryAccess struct {
+	Db     *sqlx.DB
+	UserID string
+}
+
+// For returns nil when the record does not exist or the user has no access to it
+func (r *ApiaryAccess) For(entity AccessEntity, id string) (*Access, error) {
+	row := struct {
+		OwnerID  string  `db:"user_id"`
+		ApiaryID *string `db:"apiary_id"`
+	}{}
+	err := r.Db.Get(&row, accessLookups[entity]+` LIMIT 1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if row.OwnerID == r.UserID {
+		return &Access{OwnerID: row.OwnerID, ApiaryID: row.ApiaryID, Role: ApiaryRoleOwner}, nil
+	}
+	// warehouse queens and other records outside of apiaries are never shared
+	if row.ApiaryID == nil {
+		return nil, nil
+	}
+
+	role, err := r.memberRole(*row.ApiaryID, row.OwnerID)
+	if err != nil || role == nil {
+		return nil, err
+	}
+
+	return &Access{OwnerID: row.OwnerID, ApiaryID: row.ApiaryID, Role: *role}, nil
+}
+
+// memberRole is the role of an accepted membership in an active apiary of the owner
+func (r *ApiaryAccess) memberRole(apiaryID string, ownerID string) (*ApiaryRole, error) {
+	var role ApiaryRole
+	err := r.Db.Get(&role,
+		`SELECT m.role
+		FROM apiary_members m
+		JOIN apiaries a ON a.id = m.apiary_id AND a.user_id=? AND a.active=1
+		WHERE m.apiary_id=? AND m.user_id=? AND m.status='ACCEPTED'
+		LIMIT 1`, ownerID, apiaryID, r.UserID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
@@ -0,0 +1,257 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Gratheon/swarm-api/geocode"
+	"github.com/jmoiron/sqlx"
+)
+
+// ForageRadiusKm is how far bees usually fly for forage
+const ForageRadiusKm = 3.0
+
+// MaxNearRadiusKm limits apiariesNear, larger areas are better served by the apiary list
+const MaxNearRadiusKm = 500.0
+
+// ApiaryDistance is an apiary with its distance from the searched point
+type ApiaryDistance struct {
+	Apiary     *Apiary `json:"apiary"`
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// ForageOverlap is a pair of apiaries whose forage areas intersect
+type ForageOverlap struct {
+	Apiary      *Apiary `json:"apiary"`
+	OtherApiary *Apiary `json:"otherApiary"`
+	DistanceKm  float64 `json:"distanceKm"`
+	// Overlap is the share of one forage area covered by the other one, from 0 to 1
+	Overlap float64 `json:"overlap"`
+}
+
+type apiaryDistanceRow struct {
+	Apiary
+	DistanceKm float64 `db:"distance_km"`
+}
+
+type forageOverlapRow struct {
+	ApiaryID      int     `db:"apiary_id"`
+	OtherApiaryID int     `db:"other_apiary_id"`
+	DistanceKm    float64 `db:"distance_km"`
+}
+
+// kmPerLatDegree is rounded down, so bounding boxes are a bit larger than the searched circle
+const kmPerLatDegree = 110.5
+
+// boundingBoxCondition limits alias to the lat/lng box around the point that holds the circle of radiusKm,
+// so the index on lat and lng narrows rows before the distance is computed.
+// Longitude is not limited when the box reaches a pole or crosses the antimeridian
+func boundingBoxCondition(alias string, lat float64, lng float64, radiusKm float64) (string, []interface{}) {
+	latDelta := radiusKm / kmPerLatDegree
+	condition := fmt.Sprintf(`%[1]s.lat BETWEEN ? AND ?`, alias)
+	args := []interface{}{lat - latDelta, lat + latDelta}
+
+	farthestLat := math.Abs(lat) + latDelta
+	if farthestLat >= 89 {
+		return condition, args
+	}
+	lngDelta := latDelta / math.Cos(farthestLat*math.Pi/180)
+	if lng-lngDelta < -180 || lng+lngDelta > 180 {
+		return condition, args
+	}
+
+	condition += fmt.Sprintf(` AND %[1]s.lng BETWEEN ? AND ?`, alias)
+	return condition, append(args, lng-lngDelta, lng+lngDelta)
+}
+
+// accessibleApiaryCondition limits alias to apiaries of the user and apiaries shared with them, it takes the user id twice
+func accessibleApiaryCondition(alias string) string {
+	return fmt.Sprintf(
+		`(%[1]s.user_id=? OR %[1]s.id IN (SELECT apiary_id FROM apiary_members WHERE user_id=? AND status='ACCEPTED'))`,
+		alias)
+}
+
+// ValidateCoordinates checks that lat and lng are degrees within range
+func ValidateCoordinates(lat float64, lng float64) error {
+	if math.IsNaN(lat) || math.IsNaN(lng) {
+		return errors.New("coordinates must be numbers")
+	}
+	if lat < -90 || lat > 90 {
+		return errors.New("lat must be between -90 and 90")
+	}
+	if lng < -180 || lng > 180 {
+		return errors.New("lng must be between -180 and 180")
+	}
+
+	return nil
+}
+
+// normalizeCoordinates validates coordinates given as text and formats them the way the DECIMAL(9,6) columns return them.
+// Both are nil when none were given, 0,0 was the old column default so it also means no coordinates
+func normalizeCoordinates(lat *string, lng *string) (*string, *string, error) {
+	latText := ""
+	if lat != nil {
+		latText = strings.TrimSpace(*lat)
+	}
+	lngText := ""
+	if lng != nil {
+		lngText = strings.TrimSpace(*lng)
+	}
+	if latText == "" && lngText == "" {
+		return nil, nil, nil
+	}
+	if latText == "" || lngText == "" {
+		return nil, nil, errors.New("lat and lng must be set together")
+	}
+
+	latValue, err := strconv.ParseFloat(strings.Replace(latText, ",", ".", 1), 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid lat %q", latText)
+	}
+	lngValue, err := strconv.ParseFloat(strings.Replace(lngText, ",", ".", 1), 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid lng %q", lngText)
+	}
+	err = ValidateCoordinates(latValue, lngValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	if latValue == 0 && lngValue == 0 {
+		return nil, nil, nil
+	}
+
+	normalizedLat := strconv.FormatFloat(latValue, 'f', 6, 64)
+	normalizedLng := strconv.FormatFloat(lngValue, 'f', 6, 64)
+	return &normalizedLat, &normalizedLng, nil
+}
+
+func describeLocation(lat *string, lng *string) *string {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	latValue, err := strconv.ParseFloat(*lat, 64)
+	if err != nil {
+		return nil
+	}
+	lngValue, err := strconv.ParseFloat(*lng, 64)
+	if err != nil || ValidateCoordinates(latValue, lngValue) != nil {
+		return nil
+	}
+
+	location := geocode.Describe(latValue, lngValue)
+	if location == "" {
+		return nil
+	}
+	return &location
+}
+
+// ForageOverlapShare is the share of a forage circle of radiusKm covered by another one distanceKm away
+func ForageOverlapShare(distanceKm float64, radiusKm float64) float64 {
+	if radiusKm <= 0 || distanceKm >= 2*radiusKm {
+		return 0
+	}
+	if distanceKm <= 0 {
+		return 1
+	}
+
+	lens := 2*radiusKm*radiusKm*math.Acos(distanceKm/(2*radiusKm)) -
+		distanceKm/2*math.Sqrt(4*radiusKm*radiusKm-distanceKm*distanceKm)
+	return lens / (math.Pi * radiusKm * radiusKm)
+}
+
+// ListNear returns active apiaries of UserID and apiaries shared with them within radiusKm of the point, nearest first
+func (r *Apiary) ListNear(lat float64, lng float64, radiusKm float64) ([]*ApiaryDistance, error) {
+	err := ValidateCoordinates(lat, lng)
+	if err != nil {
+		return nil, err
+	}
+	if radiusKm <= 0 || radiusKm > MaxNearRadiusKm {
+		return nil, fmt.Errorf("radiusKm must be between 0 and %.0f", MaxNearRadiusKm)
+	}
+
+	boxCondition, boxArgs := boundingBoxCondition("a", lat, lng, radiusKm)
+	args := []interface{}{lat, lng}
+	args = append(args, boxArgs...)
+	args = append(args, r.UserID, r.UserID, radiusKm)
+
+	rows := []*apiaryDistanceRow{}
+	err = r.Db.Select(&rows,
+		`SELECT `+apiaryColumns+`, ST_Distance_Sphere(a.geo_point, ST_SRID(POINT(?, ?), 4326)) / 1000 AS distance_km
+		FROM apiaries a
+		WHERE a.active=1 AND a.geo_point IS NOT NULL AND `+boxCondition+` AND `+accessibleApiaryCondition("a")+`
+		HAVING distance_km <= ?
+		ORDER BY distance_km ASC, id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*ApiaryDistance, 0, len(rows))
+	for _, row := range rows {
+		apiary := row.Apiary
+		ensureApiaryType(&apiary)
+		list = append(list, &ApiaryDistance{Apiary: &apiary, DistanceKm: row.DistanceKm})
+	}
+
+	return list, nil
+}
+
+// ListForageOverlaps returns pairs of active apiaries of UserID and apiaries shared with them
+// whose forage circles of radiusKm intersect, closest pairs first
+func (r *Apiary) ListForageOverlaps(radiusKm float64) ([]*ForageOverlap, error) {
+	if radiusKm <= 0 || radiusKm > 20 {
+		return nil, errors.New("forageRadiusKm must be between 0 and 20")
+	}
+
+	// the lat band lets the join use the lat/lng index, longitude is left to the distance check
+	latDelta := 2 * radiusKm / kmPerLatDegree
+
+	rows := []*forageOverlapRow{}
+	err := r.Db.Select(&rows,
+		`SELECT a.id AS apiary_id, b.id AS other_apiary_id, ST_Distance_Sphere(a.geo_point, b.geo_point) / 1000 AS distance_km
+		FROM apiaries a
+		JOIN apiaries b ON b.lat BETWEEN a.lat - ? AND a.lat + ?
+			AND b.id > a.id AND b.active=1 AND b.geo_point IS NOT NULL
+		WHERE a.active=1 AND a.geo_point IS NOT NULL
+		  AND `+accessibleApiaryCondition("a")+`
+		  AND `+accessibleApiaryCondition("b")+`
+		HAVING distance_km < ?
+		ORDER BY distance_km ASC, apiary_id ASC, other_apiary_id ASC`,
+		latDelta, latDelta, r.UserID, r.UserID, r.UserID, r.UserID, 2*radiusKm)
+	if err != nil || len(rows) == 0 {
+		return []*ForageOverlap{}, err
+	}
+
+	ids := []int{}
+	for _, row := range rows {
+		ids = append(ids, row.ApiaryID, row.OtherApiaryID)
+	}
+	query, args, err := sqlx.In(`SELECT `+apiaryColumns+` FROM apiaries WHERE id IN (?)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	apiaries := []*Apiary{}
+	err = r.Db.Select(&apiaries, r.Db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[int]*Apiary{}
+	for _, apiary := range apiaries {
+		ensureApiaryType(apiary)
+		byID[apiary.ID] = apiary
+	}
+
+	list := make([]*ForageOverlap, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, &ForageOverlap{
+			Apiary:      byID[row.ApiaryID],
+			OtherApiary: byID[row.OtherApiaryID],
+			DistanceKm:  row.DistanceKm,
+			Overlap:     ForageOverlapShare(row.DistanceKm, radiusKm),
+		})
+	}
+
+	return list, nil
+}
@@ -0,0 +1,258 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// apiaryInviteDays is how long an invite can be accepted
+const apiaryInviteDays = 14
+
+// ApiaryMember is a collaborator of an apiary, or a pending invite until UserID is set
+type ApiaryMember struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"-"`
+
+	ID         string             `json:"id" db:"id"`
+	ApiaryID   string             `json:"apiaryId" db:"apiary_id"`
+	MemberID   *string            `json:"userId" db:"user_id"`
+	Role       ApiaryRole         `json:"role" db:"role"`
+	Status     ApiaryMemberStatus `json:"status" db:"status"`
+	InvitedBy  string             `json:"-" db:"invited_by"`
+	InvitedAt  string             `json:"invitedAt" db:"invited_at"`
+	ExpiresAt  string             `json:"expiresAt" db:"expires_at"`
+	AcceptedAt *string            `json:"acceptedAt" db:"accepted_at"`
+	RevokedAt  *string            `json:"revokedAt" db:"revoked_at"`
+}
+
+const apiaryMemberColumns = `id, apiary_id, user_id, role, status, invited_by, invited_at, expires_at, accepted_at, revoked_at`
+
+func hashApiaryInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *ApiaryMember) get(q sqlx.Queryer, id string) (*ApiaryMember, error) {
+	member := ApiaryMember{}
+	err := sqlx.Get(q, &member, `SELECT `+apiaryMemberColumns+` FROM apiary_members WHERE id=? LIMIT 1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ListByApiary returns all members and invites for the owner, other members see accepted members only
+func (r *ApiaryMember) ListByApiary(apiaryID string, role ApiaryRole) ([]*ApiaryMember, error) {
+	condition := ` AND status='ACCEPTED'`
+	if role == ApiaryRoleOwner {
+		condition = ``
+	}
+
+	list := []*ApiaryMember{}
+	err := r.Db.Select(&list,
+		`SELECT `+apiaryMemberColumns+`
+		FROM apiary_members
+		WHERE apiary_id=?`+condition+`
+		ORDER BY invited_at ASC, id ASC`, apiaryID)
+
+	return list, err
+}
+
+// ListSharedApiaries returns active apiaries of other users where UserID is an accepted member
+func (r *ApiaryMember) ListSharedApiaries() ([]*Apiary, error) {
+	apiaries := []*Apiary{}
+	err := r.Db.Select(&apiaries,
+		`SELECT `+apiaryColumns+`
+		FROM apiaries
+		WHERE active=1 AND user_id<>?
+		  AND id IN (SELECT apiary_id FROM apiary_members WHERE user_id=? AND status='ACCEPTED')
+		ORDER BY id ASC`, r.UserID, r.UserID)
+	for _, apiary := range apiaries {
+		ensureApiaryType(apiary)
+	}
+
+	return apiaries, err
+}
+
+// Invite creates a pending membership of an apiary of UserID. The token is returned once, only its hash is stored
+func (r *ApiaryMember) Invite(apiaryID string, role ApiaryRole) (*ApiaryMember, string, error) {
+	if role != ApiaryRoleEditor && role != ApiaryRoleViewer {
+		return nil, "", errors.New("invited members can be editors or viewers")
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, "", err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	tx := r.Db.MustBegin()
+
+	var owned int
+	err := tx.Get(&owned, `SELECT COUNT(*) FROM apiaries WHERE id=? AND user_id=? AND active=1`, apiaryID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+	if owned == 0 {
+		tx.Rollback()
+		return nil, "", errors.New("only the apiary owner can invite members")
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO apiary_members (apiary_id, role, status, invite_token_hash, invited_by, expires_at)
+		VALUES (?, ?, 'PENDING', ?, ?, DATE_ADD(NOW(), INTERVAL ? DAY))`,
+		apiaryID, role, hashApiaryInviteToken(token), r.UserID, apiaryInviteDays)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	member, err := r.get(tx, strconv.FormatInt(id, 10))
+	if err == nil {
+		err = recordApiaryEventTx(tx, r.UserID, apiaryID, "member_invited", member)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	return member, token, tx.Commit()
+}
+
+// Accept turns a pending invite into a membership of UserID and returns the apiary id
+func (r *ApiaryMember) Accept(token string) (string, error) {
+	tx := r.Db.MustBegin()
+
+	invite := struct {
+		ID       string `db:"id"`
+		ApiaryID string `db:"apiary_id"`
+		OwnerID  string `db:"owner_id"`
+		Expired  bool   `db:"expired"`
+	}{}
+	err := tx.Get(&invite,
+		`SELECT m.id, m.apiary_id, a.user_id AS owner_id, m.expires_at < NOW() AS expired
+		FROM apiary_members m
+		JOIN apiaries a ON a.id = m.apiary_id AND a.active=1
+		WHERE m.invite_token_hash=? AND m.status='PENDING'
+		LIMIT 1
+		FOR UPDATE`, hashApiaryInviteToken(token))
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return "", errors.New("invite not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if invite.Expired {
+		tx.Rollback()
+		return "", errors.New("invite expired")
+	}
+	if invite.OwnerID == r.UserID {
+		tx.Rollback()
+		return "", errors.New("apiary owner can not accept an invite to own apiary")
+	}
+
+	var existing int
+	err = tx.Get(&existing,
+		`SELECT COUNT(*) FROM apiary_members WHERE apiary_id=? AND user_id=? AND status='ACCEPTED'`,
+		invite.ApiaryID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if existing > 0 {
+		tx.Rollback()
+		return "", errors.New("already a member of the apiary")
+	}
+
+	// the token is single use, clearing it keeps the unique index free of accepted invites
+	_, err = tx.Exec(
+		`UPDATE apiary_members
+		SET user_id=?, status='ACCEPTED', accepted_at=NOW(), invite_token_hash=NULL
+		WHERE id=?`, r.UserID, invite.ID)
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	member, err := r.get(tx, invite.ID)
+	if err == nil {
+		err = recordApiaryEventTx(tx, invite.OwnerID, invite.ApiaryID, "member_joined", member)
+	}
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return invite.ApiaryID, tx.Commit()
+}
+
+// Revoke ends a membership or cancels an invite. The owner can revoke anyone, members can only leave themselves
+func (r *ApiaryMember) Revoke(apiaryID string, memberID string) (bool, error) {
+	tx := r.Db.MustBegin()
+
+	member, err := r.get(tx, memberID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if member == nil || member.ApiaryID != apiaryID || member.Status == ApiaryMemberStatusRevoked {
+		tx.Rollback()
+		return false, nil
+	}
+
+	var ownerID string
+	err = tx.Get(&ownerID, `SELECT user_id FROM apiaries WHERE id=? LIMIT 1`, apiaryID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return false, nil
+	}
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	leaving := member.MemberID != nil && *member.MemberID == r.UserID
+	if ownerID != r.UserID && !leaving {
+		tx.Rollback()
+		return false, errors.New("only the apiary owner can revoke other members")
+	}
+
+	_, err = tx.Exec(
+		`UPDATE apiary_members
+		SET status='REVOKED', revoked_at=NOW(), invite_token_hash=NULL
+		WHERE id=?`, memberID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	member, err = r.get(tx, memberID)
+	if err == nil {
+		err = recordApiaryEventTx(tx, ownerID, apiaryID, "member_revoked", member)
+	}
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ApiaryRelocation is a location a mobile apiary was placed at, usually for one honey flow
+type ApiaryRelocation struct {
+	ID         string  `json:"id" db:"id"`
+	ApiaryID   string  `json:"apiaryId" db:"apiary_id"`
+	Lat        string  `json:"lat" db:"lat"`
+	Lng        string  `json:"lng" db:"lng"`
+	ArrivedAt  *string `json:"arrivedAt" db:"arrived_at"`
+	DepartedAt *string `json:"departedAt" db:"departed_at"`
+	Forage     *string `json:"forage" db:"forage"`
+}
+
+// Location names the place from the bundled offline place list
+func (r *ApiaryRelocation) Location() *string {
+	return describeLocation(&r.Lat, &r.Lng)
+}
+
+// LocationHistory lists the locations of an apiary, oldest first.
+// Legacy relocations whose coordinates did not parse are skipped
+func (r *Apiary) LocationHistory(id string) ([]*ApiaryRelocation, error) {
+	list := []*ApiaryRelocation{}
+	err := r.Db.Select(&list,
+		`SELECT id, apiary_id, lat, lng, arrived_at, departed_at, forage
+		FROM apiary_relocations
+		WHERE apiary_id=? AND user_id=? AND lat IS NOT NULL AND lng IS NOT NULL
+		ORDER BY arrived_at IS NOT NULL, arrived_at ASC, id ASC`, id, r.UserID)
+
+	return list, err
+}
+
+// Relocate moves a mobile apiary to new coordinates and logs the relocation
+func (r *Apiary) Relocate(id string, input ApiaryRelocationInput) (*Apiary, error) {
+	lat, lng, err := normalizeCoordinates(&input.Lat, &input.Lng)
+	if err != nil {
+		return nil, err
+	}
+	if lat == nil {
+		return nil, errors.New("lat and lng are required")
+	}
+
+	tx := r.Db.MustBegin()
+
+	var apiaryType ApiaryType
+	err = tx.Get(&apiaryType, `SELECT type FROM apiaries WHERE id=? AND user_id=? AND active=1 LIMIT 1 FOR UPDATE`, id, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("apiary not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if apiaryType != ApiaryTypeMobile {
+		tx.Rollback()
+		return nil, errors.New("only mobile apiaries can be relocated")
+	}
+
+	_, err = tx.Exec(`UPDATE apiaries SET lat=?, lng=? WHERE id=? AND user_id=?`, *lat, *lng, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = r.relocateTx(tx, id, *lat, *lng, input.ArrivedAt, input.Forage)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	apiary, err := r.recordChangeTx(tx, id, "relocated")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return apiary, tx.Commit()
+}
+
+// logLocationTx appends a relocation when a mobile apiary was saved with new coordinates
+func (r *Apiary) logLocationTx(tx *sqlx.Tx, id string, arrivedAt *string, forage *string) error {
+	apiary := Apiary{}
+	err := tx.Get(&apiary, "SELECT "+apiaryColumns+" FROM `apiaries` WHERE id=? AND user_id=? LIMIT 1", id, r.UserID)
+	if err != nil {
+		return err
+	}
+	if apiary.Type != ApiaryTypeMobile || apiary.Lat == nil || apiary.Lng == nil {
+		return nil
+	}
+
+	return r.relocateTx(tx, id, *apiary.Lat, *apiary.Lng, arrivedAt, forage)
+}
+
+// relocateTx closes the current location of the apiary and opens the new one.
+// Saving the current coordinates again only updates the forage label
+func (r *Apiary) relocateTx(tx *sqlx.Tx, id string, lat string, lng string, arrivedAt *string, forage *string) error {
+	when, err := parseOptionalDateTimeInput("arrivedAt", arrivedAt)
+	if err != nil {
+		return err
+	}
+	if when == nil {
+		now := time.Now().UTC().Format(mysqlDateTimeFormat)
+		when = &now
+	}
+
+	// a legacy location without coordinates never matches, so it gets closed
+	current := ApiaryRelocation{}
+	err = tx.Get(&current,
+		`SELECT id, apiary_id, COALESCE(lat, '') AS lat, COALESCE(lng, '') AS lng, arrived_at, departed_at, forage
+		FROM apiary_relocations
+		WHERE apiary_id=? AND user_id=? AND departed_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1
+		FOR UPDATE`, id, r.UserID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	hasCurrent := err == nil
+
+	if hasCurrent && current.Lat == lat && current.Lng == lng {
+		if forage == nil {
+			return nil
+		}
+		_, err = tx.Exec(`UPDATE apiary_relocations SET forage=? WHERE id=?`, *forage, current.ID)
+		return err
+	}
+
+	if hasCurrent {
+		if current.ArrivedAt != nil {
+			arrived, err := parseDBDateTime(*current.ArrivedAt)
+			if err != nil {
+				return err
+			}
+			departed, err := parseDBDateTime(*when)
+			if err != nil {
+				return err
+			}
+			if departed.Before(arrived) {
+				return errors.New("relocation can not be dated before the arrival at the current location")
+			}
+		}
+
+		_, err = tx.Exec(`UPDATE apiary_relocations SET departed_at=? WHERE id=?`, *when, current.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.NamedExec(
+		`INSERT INTO apiary_relocations (user_id, apiary_id, lat, lng, arrived_at, forage)
+		VALUES (:userID, :apiaryID, :lat, :lng, :arrivedAt, :forage)`,
+		map[string]interface{}{
+			"userID":    r.UserID,
+			"apiaryID":  id,
+			"lat":       lat,
+			"lng":       lng,
+			"arrivedAt": *when,
+			"forage":    forage,
+		},
+	)
+
+	return err
+}
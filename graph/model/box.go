@@ -51,6 +51,7 @@ type Box struct {
 	BoxSystemID *int       `json:"box_system_id" db:"box_system_id"`
 	BoxSpecID   *int       `json:"box_spec_id" db:"box_spec_id"`
 	Active      int        `db:"active"`
+	FamilyID    *string    `json:"familyId" db:"family_id"`
 }
 
 const (
@@ -118,6 +119,31 @@ func (r *Box) Get(id string) (*Box, error) {
 	return &box, err
 }
 
+// getTx reads a box within tx, so changes of the transaction are visible
+func (r *Box) getTx(tx *sqlx.Tx, id string) (*Box, error) {
+	box := Box{}
+	err := tx.Get(&box,
+		`SELECT *
+		FROM boxes
+		WHERE id=? AND user_id=?
+		LIMIT 1`, id, r.UserID)
+
+	return &box, err
+}
+
+// recordChangeTx records an event with the box state after the change, boxes of other users are skipped
+func (r *Box) recordChangeTx(tx *sqlx.Tx, id string, action string) error {
+	box, err := r.getTx(tx, id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return recordHiveEventTx(tx, r.UserID, strconv.Itoa(box.HiveId), "box", id, action, box)
+}
+
 func (r *Box) CreateByHiveId(hiveId string, boxCount int, colors []*string, boxType BoxType) error {
 	tx := r.Db.MustBegin()
 	spec, err := r.resolveSpecForHive(tx, hiveId, boxType)
@@ -145,7 +171,7 @@ func (r *Box) CreateByHiveId(hiveId string, boxCount int, colors []*string, boxT
 			color = *colors[position]
 		}
 
-		_, err2 := tx.NamedExec(
+		result, err := tx.NamedExec(
 			`INSERT INTO boxes (hive_id, position, color, user_id, type, box_system_id, box_spec_id)
 			 VALUES (:hiveId, :position, :color, :userID, :type, :boxSystemID, :boxSpecID)`,
 			map[string]interface{}{
@@ -158,9 +184,21 @@ func (r *Box) CreateByHiveId(hiveId string, boxCount int, colors []*string, boxT
 				"boxSpecID":   spec.BoxSpecID,
 			},
 		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 
-		if err2 != nil {
-			return err2
+		id, err := result.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		err = r.recordChangeTx(tx, strconv.FormatInt(id, 10), "created")
+		if err != nil {
+			tx.Rollback()
+			return err
 		}
 	}
 
@@ -211,6 +249,7 @@ func (r *Box) Create(hiveId string, position int, color *string, boxType BoxType
 	)
 
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
@@ -219,6 +258,13 @@ func (r *Box) Create(hiveId string, position int, color *string, boxType BoxType
 	strId := strconv.Itoa(int(id))
 
 	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = r.recordChangeTx(tx, strId, "created")
+	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
@@ -227,18 +273,27 @@ func (r *Box) Create(hiveId string, position int, color *string, boxType BoxType
 
 func (r *Box) CreateSingleBox(hiveId string, position int, color string, boxType BoxType) (string, error) {
 	tx := r.Db.MustBegin()
-	spec, err := r.resolveSpecForHive(tx, hiveId, boxType)
+
+	id, err := r.CreateSingleBoxTx(tx, hiveId, position, color, boxType)
 	if err != nil {
 		tx.Rollback()
 		return "", err
 	}
+
+	return id, tx.Commit()
+}
+
+// CreateSingleBoxTx adds one box to a hive within a transaction owned by the caller
+func (r *Box) CreateSingleBoxTx(tx *sqlx.Tx, hiveId string, position int, color string, boxType BoxType) (string, error) {
+	spec, err := r.resolveSpecForHive(tx, hiveId, boxType)
+	if err != nil {
+		return "", err
+	}
 	if spec == nil {
-		tx.Rollback()
 		return "", errors.New("no box specification found for box type")
 	}
 	hiveBoxSystemID, err := r.getHiveBoxSystemID(tx, hiveId)
 	if err != nil {
-		tx.Rollback()
 		return "", err
 	}
 	var hiveBoxSystemValue interface{} = nil
@@ -272,12 +327,13 @@ func (r *Box) CreateSingleBox(hiveId string, position int, color string, boxType
 		return "", err
 	}
 
-	err = tx.Commit()
+	strId := strconv.Itoa(int(id))
+	err = r.recordChangeTx(tx, strId, "created")
 	if err != nil {
 		return "", err
 	}
 
-	return strconv.Itoa(int(id)), nil
+	return strId, nil
 }
 
 func (r *Box) ListByHive(hiveId string) ([]*Box, error) {
@@ -323,6 +379,10 @@ func (r *Box) SwapBoxPositions(box1ID string, box2ID string) (*bool, error) {
 		ok = false
 		return &ok, nil
 	}
+	if box1.HiveId != box2.HiveId {
+		ok = false
+		return &ok, errors.New("boxes to swap must be in the same hive")
+	}
 
 	tmpPosition := *box1.Position
 	box1.Position = box2.Position
@@ -357,7 +417,12 @@ func (r *Box) Update(id *string, position int, color *string) (bool, error) {
 		},
 	)
 
+	if err == nil {
+		err = r.recordChangeTx(tx, *id, "updated")
+	}
+
 	if err != nil {
+		tx.Rollback()
 		return false, err
 	}
 
@@ -384,7 +449,12 @@ func (r *Box) UpdateHoleCount(id string, holeCount int) (bool, error) {
 		},
 	)
 
+	if err == nil {
+		err = r.recordChangeTx(tx, id, "updated")
+	}
+
 	if err != nil {
+		tx.Rollback()
 		return false, err
 	}
 
@@ -408,7 +478,11 @@ func (r *Box) UpdateRoofStyle(id string, roofStyle RoofStyle) (bool, error) {
 			"userID":    r.UserID,
 		},
 	)
+	if err == nil {
+		err = r.recordChangeTx(tx, id, "updated")
+	}
 	if err != nil {
+		tx.Rollback()
 		return false, err
 	}
 
@@ -430,6 +504,15 @@ func (r *Box) Deactivate(id string) (*bool, error) {
 			"userID": r.UserID,
 		},
 	)
+	if err == nil {
+		err = r.recordChangeTx(tx, id, "deleted")
+	}
+	if err != nil {
+		tx.Rollback()
+		success = false
+		return &success, err
+	}
+
 	err = tx.Commit()
 
 	if err != nil {
@@ -442,8 +525,26 @@ func (r *Box) Deactivate(id string) (*bool, error) {
 func (r *Box) MoveBoxesToHive(boxIDs []string, targetHiveID string, startPosition int) error {
 	tx := r.Db.MustBegin()
 
+	err := r.MoveBoxesToHiveTx(tx, boxIDs, targetHiveID, startPosition)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MoveBoxesToHiveTx moves boxes to targetHiveID within a transaction owned by the caller
+func (r *Box) MoveBoxesToHiveTx(tx *sqlx.Tx, boxIDs []string, targetHiveID string, startPosition int) error {
+	frameModel := &Frame{UserID: r.UserID}
 	for i, boxID := range boxIDs {
-		_, err := tx.NamedExec(
+		var fromHiveID int
+		err := tx.Get(&fromHiveID, `SELECT hive_id FROM boxes WHERE id=? AND user_id=? AND active=1 LIMIT 1`, boxID, r.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NamedExec(
 			`UPDATE boxes 
 			SET hive_id=:hiveID, position=:position
 			WHERE id=:id AND user_id=:userID AND active=1`,
@@ -454,13 +555,18 @@ func (r *Box) MoveBoxesToHive(boxIDs []string, targetHiveID string, startPositio
 				"userID":   r.UserID,
 			},
 		)
+		if err == nil {
+			err = r.recordChangeTx(tx, boxID, "moved")
+		}
+		if err == nil {
+			err = frameModel.recordBoxMoveTx(tx, boxID, fromHiveID, targetHiveID)
+		}
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (r *Box) GetMaxPosition(hiveID string) (int, error) {
@@ -481,6 +587,24 @@ func (r *Box) GetMaxPosition(hiveID string) (int, error) {
 	return int(maxPosition.Int64), nil
 }
 
+// GetMaxPositionTx reads the top box position within tx, so boxes stacked in the same transaction are counted
+func (r *Box) GetMaxPositionTx(tx *sqlx.Tx, hiveID string) (int, error) {
+	var maxPosition sql.NullInt64
+	err := tx.Get(&maxPosition,
+		`SELECT MAX(position) FROM boxes WHERE hive_id=? AND user_id=? AND active=1`,
+		hiveID, r.UserID,
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	if !maxPosition.Valid {
+		return -1, nil
+	}
+
+	return int(maxPosition.Int64), nil
+}
+
 func (r *Box) GetBoxesByTypeForHive(hiveID string, boxTypes []BoxType) ([]*Box, error) {
 	if len(boxTypes) == 0 {
 		return []*Box{}, nil
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// mysqlDateTimeFormat is how DATETIME values are written to and compared in MySQL
+const mysqlDateTimeFormat = "2006-01-02 15:04:05"
+
+// ParseDateTimeInput reads a DateTime argument given either as RFC3339 or as a plain YYYY-MM-DD date
+func ParseDateTimeInput(field string, value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return parsed, nil
+	}
+
+	parsed, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid %s format, must be RFC3339 or YYYY-MM-DD", field)
+	}
+
+	return parsed, nil
+}
+
+// parseOptionalDateTimeInput converts an optional DateTime argument to the MySQL DATETIME format
+func parseOptionalDateTimeInput(field string, value *string) (*string, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	parsed, err := ParseDateTimeInput(field, *value)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted := parsed.UTC().Format(mysqlDateTimeFormat)
+	return &formatted, nil
+}
+
+// parseDBDateTime reads a DATETIME scanned into a string, with or without parseTime on the connection
+func parseDBDateTime(value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err == nil {
+		return parsed, nil
+	}
+
+	return time.Parse(mysqlDateTimeFormat, value)
+}
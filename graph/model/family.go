@@ -20,6 +20,15 @@ type Family struct {
 	Added       *string       `json:"added" db:"added"`
 	Color       *string       `json:"color" db:"color"`
 	Inspections []*Inspection `json:"inspections"`
+
+	MotherID      *string     `json:"motherId" db:"mother_id"`
+	MatingType    *MatingType `json:"matingType" db:"mating_type"`
+	MatingStation *string     `json:"matingStation" db:"mating_station"`
+	DroneSourceID *string     `json:"droneSourceId" db:"drone_source_id"`
+	DroneOrigin   *string     `json:"droneOrigin" db:"drone_origin"`
+
+	RearingBatchID *string `json:"rearingBatchId" db:"rearing_batch_id"`
+	IsPrimary      bool    `json:"isPrimary" db:"is_primary"`
 }
 
 const (
@@ -29,6 +38,8 @@ const (
 	familyMoveTypeDeleted     = "DELETED"
 )
 
+// createMoveTx records the move of a family already updated within tx and keeps one primary queen
+// in the hives the family left and entered
 func (r *Family) createMoveTx(tx *sqlx.Tx, familyID int, fromHiveID *int, toHiveID *int, moveType string) error {
 	_, err := tx.NamedExec(
 		`INSERT INTO family_moves (user_id, family_id, from_hive_id, to_hive_id, move_type)
@@ -41,7 +52,34 @@ func (r *Family) createMoveTx(tx *sqlx.Tx, familyID int, fromHiveID *int, toHive
 			"moveType":   moveType,
 		},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return r.updatePrimaryTx(tx, familyID, fromHiveID, toHiveID)
+}
+
+// recordChangeTx records an event with the family state after the change, for hiveID or else the hive
+// the family is in. Families kept in the warehouse are reported to inventory subscribers.
+func (r *Family) recordChangeTx(tx *sqlx.Tx, familyID int, hiveID *int, action string, inventory bool) error {
+	family := Family{}
+	err := tx.Get(&family,
+		`SELECT *
+		FROM families
+		WHERE id=? AND user_id=?
+		LIMIT 1`, familyID, r.UserID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if hiveID == nil {
+		hiveID = family.HiveID
+	}
+
+	return recordFamilyEventTx(tx, r.UserID, hiveID, familyID, action, inventory, family)
 }
 
 func (r *Family) GetById(id *int) (*Family, error) {
@@ -71,7 +109,9 @@ func (r *Family) GetById(id *int) (*Family, error) {
 }
 
 func (r *Family) Create(name *string, race *string, added *string, color *string) (*int, error) {
-	result, err := r.Db.NamedExec(
+	tx := r.Db.MustBegin()
+
+	result, err := tx.NamedExec(
 		`INSERT INTO families (user_id, name, race, added, color) 
 		VALUES (:userID, :name, :race, :added, :color)`,
 		map[string]interface{}{
@@ -84,18 +124,30 @@ func (r *Family) Create(name *string, race *string, added *string, color *string
 	)
 
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
 	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	id2 := int(id)
 
-	return &id2, err
+	if err = r.recordChangeTx(tx, id2, nil, "created", true); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &id2, tx.Commit()
 }
 
 func (r *Family) Update(id *string, name *string, race *string, added *string, color *string) (*int64, error) {
-	_, err := r.Db.NamedExec(
+	tx := r.Db.MustBegin()
+
+	_, err := tx.NamedExec(
 		`UPDATE families 
 		SET name=:name, race=:race, added = :added, color = :color
 		WHERE id=:id AND user_id=:userID AND active=1`,
@@ -110,10 +162,23 @@ func (r *Family) Update(id *string, name *string, race *string, added *string, c
 	)
 
 	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if id != nil {
+		familyIDInt, convErr := strconv.Atoi(*id)
+		if convErr == nil {
+			err = r.recordChangeTx(tx, familyIDInt, nil, "updated", false)
+		}
+	}
+
+	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	return nil, err
+	return nil, tx.Commit()
 }
 
 func (r *Family) Upsert(uid string, hive HiveUpdateInput) (*string, error) {
@@ -144,7 +209,8 @@ func (r *Family) ListByHive(hiveID string) ([]*Family, error) {
 	err := r.Db.Select(&families,
 		`SELECT * 
 		FROM families
-		WHERE hive_id=? AND user_id=? AND active=1`,
+		WHERE hive_id=? AND user_id=? AND active=1
+		ORDER BY is_primary DESC, id ASC`,
 		hiveID, r.UserID)
 
 	if err != nil {
@@ -166,12 +232,23 @@ func (r *Family) ListByHive(hiveID string) ([]*Family, error) {
 }
 
 func (r *Family) CreateForHive(hiveID string, name *string, race *string, added *string, color *string) (*int, error) {
-	hiveIDInt, err := strconv.Atoi(hiveID)
+	tx := r.Db.MustBegin()
+
+	id, err := r.CreateForHiveTx(tx, hiveID, name, race, added, color)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	tx := r.Db.MustBegin()
+	return id, tx.Commit()
+}
+
+// CreateForHiveTx adds a queen to a hive within a transaction owned by the caller
+func (r *Family) CreateForHiveTx(tx *sqlx.Tx, hiveID string, name *string, race *string, added *string, color *string) (*int, error) {
+	hiveIDInt, err := strconv.Atoi(hiveID)
+	if err != nil {
+		return nil, err
+	}
 
 	result, err := tx.NamedExec(
 		`INSERT INTO families (user_id, hive_id, name, race, added, color) 
@@ -187,27 +264,28 @@ func (r *Family) CreateForHive(hiveID string, name *string, race *string, added
 	)
 
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	id2 := int(id)
 
 	if err = r.createMoveTx(tx, id2, nil, &hiveIDInt, familyMoveTypeAssigned); err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	if err = tx.Commit(); err != nil {
+	if err = r.inheritSplitMotherTx(tx, id2, hiveIDInt); err != nil {
+		return nil, err
+	}
+
+	if err = r.recordChangeTx(tx, id2, &hiveIDInt, "created", false); err != nil {
 		return nil, err
 	}
 
-	return &id2, err
+	return &id2, nil
 }
 
 func (r *Family) MoveToWarehouse(hiveID string, familyID string) (*Family, error) {
@@ -248,6 +326,11 @@ func (r *Family) MoveToWarehouse(hiveID string, familyID string) (*Family, error
 		return nil, err
 	}
 
+	if err = r.recordChangeTx(tx, familyIDInt, &hiveIDInt, "moved", true); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -256,6 +339,19 @@ func (r *Family) MoveToWarehouse(hiveID string, familyID string) (*Family, error
 }
 
 func (r *Family) MoveBetweenHives(familyID string, fromHiveID string, toHiveID string) error {
+	tx := r.Db.MustBegin()
+
+	err := r.MoveBetweenHivesTx(tx, familyID, fromHiveID, toHiveID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MoveBetweenHivesTx transfers a queen within a transaction owned by the caller
+func (r *Family) MoveBetweenHivesTx(tx *sqlx.Tx, familyID string, fromHiveID string, toHiveID string) error {
 	familyIDInt, err := strconv.Atoi(familyID)
 	if err != nil {
 		return err
@@ -269,8 +365,6 @@ func (r *Family) MoveBetweenHives(familyID string, fromHiveID string, toHiveID s
 		return err
 	}
 
-	tx := r.Db.MustBegin()
-
 	result, err := tx.Exec(
 		`UPDATE families
 		SET hive_id=?
@@ -278,26 +372,27 @@ func (r *Family) MoveBetweenHives(familyID string, fromHiveID string, toHiveID s
 		toHiveIDInt, familyIDInt, fromHiveIDInt, r.UserID,
 	)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 	if rowsAffected == 0 {
-		tx.Rollback()
 		return sql.ErrNoRows
 	}
 
 	if err = r.createMoveTx(tx, familyIDInt, &fromHiveIDInt, &toHiveIDInt, familyMoveTypeTransferred); err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	return tx.Commit()
+	err = r.recordChangeTx(tx, familyIDInt, &fromHiveIDInt, "moved", false)
+	if err != nil {
+		return err
+	}
+
+	return r.recordChangeTx(tx, familyIDInt, &toHiveIDInt, "moved", false)
 }
 
 func (r *Family) AssignFromWarehouse(hiveID string, familyID string) (*Family, error) {
@@ -338,6 +433,11 @@ func (r *Family) AssignFromWarehouse(hiveID string, familyID string) (*Family, e
 		return nil, err
 	}
 
+	if err = r.recordChangeTx(tx, familyIDInt, &hiveIDInt, "moved", true); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -346,6 +446,23 @@ func (r *Family) AssignFromWarehouse(hiveID string, familyID string) (*Family, e
 }
 
 func (r *Family) DeleteFromHive(hiveID string, familyID string) (bool, error) {
+	tx := r.Db.MustBegin()
+
+	deleted, err := r.DeleteFromHiveTx(tx, hiveID, familyID)
+	if err != nil || !deleted {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteFromHiveTx deactivates a family of the hive within tx, returns false if the family is not in the hive
+func (r *Family) DeleteFromHiveTx(tx *sqlx.Tx, hiveID string, familyID string) (bool, error) {
 	hiveIDInt, err := strconv.Atoi(hiveID)
 	if err != nil {
 		return false, err
@@ -355,8 +472,6 @@ func (r *Family) DeleteFromHive(hiveID string, familyID string) (bool, error) {
 		return false, err
 	}
 
-	tx := r.Db.MustBegin()
-
 	result, err := tx.Exec(
 		`UPDATE families
 		SET active=0
@@ -364,26 +479,22 @@ func (r *Family) DeleteFromHive(hiveID string, familyID string) (bool, error) {
 		familyIDInt, hiveIDInt, r.UserID,
 	)
 	if err != nil {
-		tx.Rollback()
 		return false, err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		tx.Rollback()
 		return false, err
 	}
 	if rowsAffected == 0 {
-		tx.Rollback()
 		return false, nil
 	}
 
 	if err = r.createMoveTx(tx, familyIDInt, &hiveIDInt, nil, familyMoveTypeDeleted); err != nil {
-		tx.Rollback()
 		return false, err
 	}
 
-	if err = tx.Commit(); err != nil {
+	if err = r.recordChangeTx(tx, familyIDInt, &hiveIDInt, "deleted", false); err != nil {
 		return false, err
 	}
 
@@ -464,6 +575,11 @@ func (r *Family) DeleteFromWarehouse(familyID string) (bool, error) {
 		return false, err
 	}
 
+	if err = r.recordChangeTx(tx, familyIDInt, nil, "deleted", true); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return false, err
 	}
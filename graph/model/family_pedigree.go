@@ -0,0 +1,271 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultPedigreeDepth is how many generations of ancestors are listed by default
+const DefaultPedigreeDepth = 3
+
+// MaxPedigreeDepth limits ancestry trees, each generation doubles the possible ancestors
+const MaxPedigreeDepth = 10
+
+// maxMotherLine limits how far the mother line is followed to detect a queen being her own ancestor
+const maxMotherLine = 100
+
+func setFamilyAges(families ...*Family) {
+	currentYear := time.Now().Year()
+	for _, family := range families {
+		if family.Added == nil {
+			continue
+		}
+		birthYear, err := strconv.Atoi(*family.Added)
+		if err == nil {
+			age := currentYear - birthYear
+			family.Age = &age
+		}
+	}
+}
+
+// listAnyByIDs returns families by id, removed ones included so lineage stays complete
+func (r *Family) listAnyByIDs(ids []string) (map[string]*Family, error) {
+	byID := map[string]*Family{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT * FROM families WHERE user_id=? AND id IN (?)`, r.UserID, ids)
+	if err != nil {
+		return nil, err
+	}
+	families := []*Family{}
+	err = r.Db.Select(&families, r.Db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	setFamilyAges(families...)
+	for _, family := range families {
+		byID[family.ID] = family
+	}
+
+	return byID, nil
+}
+
+// GetAny returns a family by id even when it was removed, for parents of living queens
+func (r *Family) GetAny(id string) (*Family, error) {
+	byID, err := r.listAnyByIDs([]string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	return byID[id], nil
+}
+
+// Pedigree builds the ancestry tree of the family one generation at a time, depth generations up.
+// Ancestors can appear more than once in inbred lines
+func (r *Family) Pedigree(family *Family, depth int) (*PedigreeNode, error) {
+	if depth < 0 || depth > MaxPedigreeDepth {
+		return nil, errors.New("depth must be between 0 and 10")
+	}
+
+	root := &PedigreeNode{Family: family}
+	level := []*PedigreeNode{root}
+	for generation := 1; generation <= depth && len(level) > 0; generation++ {
+		ids := []string{}
+		for _, node := range level {
+			if node.Family.MotherID != nil {
+				ids = append(ids, *node.Family.MotherID)
+			}
+			if node.Family.DroneSourceID != nil {
+				ids = append(ids, *node.Family.DroneSourceID)
+			}
+		}
+
+		parents, err := r.listAnyByIDs(uniqueStrings(ids))
+		if err != nil {
+			return nil, err
+		}
+
+		next := []*PedigreeNode{}
+		for _, node := range level {
+			if node.Family.MotherID != nil && parents[*node.Family.MotherID] != nil {
+				node.Mother = &PedigreeNode{Family: parents[*node.Family.MotherID], Generation: generation}
+				next = append(next, node.Mother)
+			}
+			if node.Family.DroneSourceID != nil && parents[*node.Family.DroneSourceID] != nil {
+				node.DroneSource = &PedigreeNode{Family: parents[*node.Family.DroneSourceID], Generation: generation}
+				next = append(next, node.DroneSource)
+			}
+		}
+		level = next
+	}
+
+	return root, nil
+}
+
+// ListDaughters returns active queens whose mother is the family, oldest first
+func (r *Family) ListDaughters(familyID string) ([]*Family, error) {
+	families := []*Family{}
+	err := r.Db.Select(&families,
+		`SELECT *
+		FROM families
+		WHERE mother_id=? AND user_id=? AND active=1
+		ORDER BY id ASC`, familyID, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	setFamilyAges(families...)
+	return families, nil
+}
+
+func trimmedOptional(value *string, field string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if len(trimmed) > 255 {
+		return nil, errors.New(field + " must be at most 255 characters")
+	}
+
+	return &trimmed, nil
+}
+
+// SetPedigree replaces the parentage of the family. A queen can not be her own mother or ancestor
+func (r *Family) SetPedigree(familyID string, input QueenPedigreeInput) (*Family, error) {
+	matingStation, err := trimmedOptional(input.MatingStation, "matingStation")
+	if err != nil {
+		return nil, err
+	}
+	droneOrigin, err := trimmedOptional(input.DroneOrigin, "droneOrigin")
+	if err != nil {
+		return nil, err
+	}
+	if matingStation != nil && (input.MatingType == nil || *input.MatingType != MatingTypeMatingStation) {
+		return nil, errors.New("matingStation needs matingType MATING_STATION")
+	}
+	if input.MotherID != nil && *input.MotherID == familyID {
+		return nil, errors.New("a queen can not be her own mother")
+	}
+	if input.DroneSourceID != nil && *input.DroneSourceID == familyID {
+		return nil, errors.New("a queen can not be her own drone source")
+	}
+
+	familyIDInt, err := strconv.Atoi(familyID)
+	if err != nil {
+		return nil, err
+	}
+	family, err := r.GetById(&familyIDInt)
+	if err != nil {
+		return nil, err
+	}
+	if family == nil {
+		return nil, errors.New("queen not found")
+	}
+
+	parentIDs := []string{}
+	if input.MotherID != nil {
+		parentIDs = append(parentIDs, *input.MotherID)
+	}
+	if input.DroneSourceID != nil {
+		parentIDs = append(parentIDs, *input.DroneSourceID)
+	}
+	parents, err := r.listAnyByIDs(parentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if input.MotherID != nil && parents[*input.MotherID] == nil {
+		return nil, errors.New("mother queen not found")
+	}
+	if input.DroneSourceID != nil && parents[*input.DroneSourceID] == nil {
+		return nil, errors.New("drone source queen not found")
+	}
+
+	if input.MotherID != nil {
+		ancestor := parents[*input.MotherID]
+		for i := 0; i < maxMotherLine && ancestor != nil && ancestor.MotherID != nil; i++ {
+			if *ancestor.MotherID == familyID {
+				return nil, errors.New("mother can not be a daughter of the queen")
+			}
+			ancestor, err = r.GetAny(*ancestor.MotherID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.NamedExec(
+		`UPDATE families
+		SET mother_id=:motherID, mating_type=:matingType, mating_station=:matingStation,
+			drone_source_id=:droneSourceID, drone_origin=:droneOrigin
+		WHERE id=:id AND user_id=:userID AND active=1`,
+		map[string]interface{}{
+			"id":            familyID,
+			"userID":        r.UserID,
+			"motherID":      input.MotherID,
+			"matingType":    input.MatingType,
+			"matingStation": matingStation,
+			"droneSourceID": input.DroneSourceID,
+			"droneOrigin":   droneOrigin,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = r.recordChangeTx(tx, familyIDInt, nil, "updated", family.HiveID == nil); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetById(&familyIDInt)
+}
+
+// RememberSplitMotherTx keeps the primary queen of the source colony on a queenless split,
+// the queen the split raises is her daughter
+func (r *Family) RememberSplitMotherTx(tx *sqlx.Tx, splitHiveID string, sourceHiveID string) error {
+	_, err := tx.Exec(
+		`UPDATE hives
+		SET split_mother_id=(
+			SELECT id FROM families
+			WHERE hive_id=? AND user_id=? AND active=1
+			ORDER BY is_primary DESC, id ASC
+			LIMIT 1
+		)
+		WHERE id=? AND user_id=?`, sourceHiveID, r.UserID, splitHiveID, r.UserID)
+	return err
+}
+
+// inheritSplitMotherTx records the source colony queen as mother of the first queen added to a queenless split
+func (r *Family) inheritSplitMotherTx(tx *sqlx.Tx, familyID int, hiveID int) error {
+	result, err := tx.Exec(
+		`UPDATE families f
+		JOIN hives h ON h.id = f.hive_id AND h.user_id = f.user_id
+		SET f.mother_id = h.split_mother_id
+		WHERE f.id=? AND f.user_id=? AND f.mother_id IS NULL AND h.split_mother_id IS NOT NULL`,
+		familyID, r.UserID)
+	if err != nil {
+		return err
+	}
+	inherited, err := result.RowsAffected()
+	if err != nil || inherited == 0 {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE hives SET split_mother_id=NULL WHERE id=? AND user_id=?`, hiveID, r.UserID)
+	return err
+}
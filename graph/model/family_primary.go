@@ -0,0 +1,192 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ensurePrimaryTx makes the oldest queen of the hive primary when none of its queens is
+func (r *Family) ensurePrimaryTx(tx *sqlx.Tx, hiveID int) error {
+	var primaries int
+	err := tx.Get(&primaries,
+		`SELECT COUNT(*) FROM families WHERE hive_id=? AND user_id=? AND active=1 AND is_primary=1`,
+		hiveID, r.UserID)
+	if err != nil || primaries > 0 {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE families
+		SET is_primary=1
+		WHERE hive_id=? AND user_id=? AND active=1
+		ORDER BY id ASC
+		LIMIT 1`, hiveID, r.UserID)
+	return err
+}
+
+// updatePrimaryTx gives up the primary flag and the boxes of a family leaving fromHiveID
+// and picks primary queens for both hives when they have none
+func (r *Family) updatePrimaryTx(tx *sqlx.Tx, familyID int, fromHiveID *int, toHiveID *int) error {
+	if fromHiveID != nil {
+		_, err := tx.Exec(`UPDATE families SET is_primary=0 WHERE id=? AND user_id=?`, familyID, r.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`UPDATE boxes SET family_id=NULL WHERE family_id=? AND hive_id=? AND user_id=?`,
+			familyID, *fromHiveID, r.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err = r.ensurePrimaryTx(tx, *fromHiveID); err != nil {
+			return err
+		}
+	}
+
+	if toHiveID != nil {
+		return r.ensurePrimaryTx(tx, *toHiveID)
+	}
+
+	return nil
+}
+
+// SetPrimary makes the family the primary queen of the hive she is in
+func (r *Family) SetPrimary(hiveID string, familyID string) (*Family, error) {
+	hiveIDInt, err := strconv.Atoi(hiveID)
+	if err != nil {
+		return nil, err
+	}
+	familyIDInt, err := strconv.Atoi(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	var previousID *int
+	err = tx.Get(&previousID,
+		`SELECT id FROM families WHERE hive_id=? AND user_id=? AND active=1 AND is_primary=1 LIMIT 1`,
+		hiveIDInt, r.UserID)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result, err := tx.Exec(
+		`UPDATE families
+		SET is_primary=1
+		WHERE id=? AND hive_id=? AND user_id=? AND active=1`,
+		familyIDInt, hiveIDInt, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if rowsAffected == 0 && (previousID == nil || *previousID != familyIDInt) {
+		tx.Rollback()
+		return nil, errors.New("queen is not in the hive")
+	}
+
+	if previousID != nil && *previousID != familyIDInt {
+		_, err = tx.Exec(`UPDATE families SET is_primary=0 WHERE id=? AND user_id=?`, *previousID, r.UserID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err = r.recordChangeTx(tx, *previousID, &hiveIDInt, "updated", false); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if rowsAffected > 0 {
+		if err = r.recordChangeTx(tx, familyIDInt, &hiveIDInt, "updated", false); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetById(&familyIDInt)
+}
+
+// AssignBox sets the queen occupying the box, nil gives the box back to the primary queen
+func (r *Family) AssignBox(boxID string, familyID *string) error {
+	tx := r.Db.MustBegin()
+
+	var hiveID int
+	err := tx.Get(&hiveID, `SELECT hive_id FROM boxes WHERE id=? AND user_id=? AND active=1 LIMIT 1`, boxID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return errors.New("box not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if familyID != nil {
+		var inHive int
+		err = tx.Get(&inHive,
+			`SELECT COUNT(*) FROM families WHERE id=? AND hive_id=? AND user_id=? AND active=1`,
+			*familyID, hiveID, r.UserID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if inHive == 0 {
+			tx.Rollback()
+			return errors.New("queen is not in the hive of the box")
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE boxes SET family_id=? WHERE id=? AND user_id=?`, familyID, boxID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, strconv.Itoa(hiveID), "box", boxID, "updated", map[string]interface{}{
+		"id":        boxID,
+		"hive_id":   hiveID,
+		"family_id": familyID,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// BoxOccupantID returns the queen assigned to the box or else the primary queen of its hive,
+// nil when the hive has no queen
+func (r *Family) BoxOccupantID(boxID string) (*int, error) {
+	var familyID int
+	err := r.Db.Get(&familyID,
+		`SELECT f.id
+		FROM boxes b
+		JOIN families f ON f.hive_id = b.hive_id AND f.user_id = b.user_id AND f.active=1
+		WHERE b.id=? AND b.user_id=?
+		ORDER BY f.id = b.family_id DESC, f.is_primary DESC, f.id ASC
+		LIMIT 1`, boxID, r.UserID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &familyID, nil
+}
@@ -0,0 +1,481 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Feeding is feed given to a hive, with its amount converted to kg of feed and kg of sugar
+type Feeding struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID              string   `json:"id" db:"id"`
+	HiveID          string   `json:"hiveId" db:"hive_id"`
+	BoxID           *string  `json:"boxId" db:"box_id"`
+	ApiaryID        *string  `json:"apiaryId" db:"apiary_id"`
+	FeedType        FeedType `json:"feedType" db:"feed_type"`
+	Amount          float64  `json:"amount" db:"amount"`
+	Unit            FeedUnit `json:"unit" db:"unit"`
+	FeedKg          float64  `json:"feedKg" db:"feed_kg"`
+	SugarKg         float64  `json:"sugarKg" db:"sugar_kg"`
+	StockDeductedKg float64  `json:"-" db:"stock_deducted_kg"`
+	FedAt           string   `json:"fedAt" db:"fed_at"`
+	Notes           *string  `json:"notes" db:"notes"`
+}
+
+// FeedingInput is feed to record for a hive
+type FeedingInput struct {
+	HiveID   string
+	FeedType FeedType
+	Amount   float64
+	Unit     FeedUnit
+	BoxID    *string
+	Date     *string
+	Notes    *string
+}
+
+type FeedTypeTotal struct {
+	FeedType FeedType `json:"feedType" db:"feed_type"`
+	Feedings int      `json:"feedings" db:"feedings"`
+	FeedKg   float64  `json:"feedKg" db:"feed_kg"`
+	SugarKg  float64  `json:"sugarKg" db:"sugar_kg"`
+}
+
+type HiveFeedTotal struct {
+	HiveID   string  `json:"hiveId" db:"hive_id"`
+	Feedings int     `json:"feedings" db:"feedings"`
+	FeedKg   float64 `json:"feedKg" db:"feed_kg"`
+	SugarKg  float64 `json:"sugarKg" db:"sugar_kg"`
+}
+
+// FeedingReport sums feed given in one season, seasons are calendar years
+type FeedingReport struct {
+	Season     int              `json:"season"`
+	ApiaryID   *string          `json:"apiaryId"`
+	Feedings   int              `json:"feedings"`
+	SugarKg    float64          `json:"sugarKg"`
+	ByFeedType []*FeedTypeTotal `json:"byFeedType"`
+	ByHive     []*HiveFeedTotal `json:"byHive"`
+}
+
+type FeedStock struct {
+	FeedType FeedType `json:"feedType" db:"feed_type"`
+	AmountKg float64  `json:"amountKg" db:"amount_kg"`
+}
+
+// feedProfile describes how an amount of feed converts to kg of feed and kg of sugar
+type feedProfile struct {
+	// DensityKgPerL is zero for feeds that can not be measured in liters
+	DensityKgPerL float64
+	SugarShare    float64
+}
+
+// feedProfiles use typical values, syrup ratios are by weight. Protein feeds count as no sugar,
+// their sugar content depends too much on the recipe
+var feedProfiles = map[FeedType]feedProfile{
+	FeedTypeSyrup1_1:         {DensityKgPerL: 1.23, SugarShare: 0.5},
+	FeedTypeSyrup2_1:         {DensityKgPerL: 1.33, SugarShare: 2.0 / 3.0},
+	FeedTypeInvertSyrup:      {DensityKgPerL: 1.38, SugarShare: 0.72},
+	FeedTypeFondant:          {SugarShare: 0.9},
+	FeedTypeDrySugar:         {SugarShare: 1},
+	FeedTypePollenPatty:      {},
+	FeedTypePollenSubstitute: {},
+}
+
+// feedBoxTypes are boxes feed can be put into, either a feeder box or a box with feeder frames
+var feedBoxTypes = map[BoxType]bool{
+	BoxTypeHorizontalFeeder:       true,
+	BoxTypeDeep:                   true,
+	BoxTypeSuper:                  true,
+	BoxTypeLargeHorizontalSection: true,
+}
+
+const feedingColumns = `id, user_id, hive_id, box_id, apiary_id, feed_type, amount, unit, feed_kg, sugar_kg, stock_deducted_kg, fed_at, notes`
+
+// FeedAmountKg converts an amount of feed to kg of feed and kg of sugar in it
+func FeedAmountKg(feedType FeedType, amount float64, unit FeedUnit) (float64, float64, error) {
+	profile, ok := feedProfiles[feedType]
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid feed type %s", feedType)
+	}
+
+	var feedKg float64
+	switch unit {
+	case FeedUnitKg:
+		feedKg = amount
+	case FeedUnitG:
+		feedKg = amount / 1000
+	case FeedUnitL, FeedUnitMl:
+		if profile.DensityKgPerL == 0 {
+			return 0, 0, fmt.Errorf("%s can not be measured in liters", strings.ToLower(string(feedType)))
+		}
+		feedKg = amount * profile.DensityKgPerL
+		if unit == FeedUnitMl {
+			feedKg = feedKg / 1000
+		}
+	default:
+		return 0, 0, fmt.Errorf("invalid feed unit %s", unit)
+	}
+
+	return roundFeedKg(feedKg), roundFeedKg(feedKg * profile.SugarShare), nil
+}
+
+// roundFeedKg rounds to grams, the precision of the DECIMAL(8,3) columns
+func roundFeedKg(kg float64) float64 {
+	return math.Round(kg*1000) / 1000
+}
+
+func (r *Feeding) Get(id string) (*Feeding, error) {
+	feeding := Feeding{}
+	err := r.Db.Get(&feeding,
+		`SELECT `+feedingColumns+`
+		FROM feedings
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &feeding, nil
+}
+
+// ListByHive returns feedings of the hive, newest first, of one season when set
+func (r *Feeding) ListByHive(hiveID string, season *int) ([]*Feeding, error) {
+	return r.list(`hive_id=?`, hiveID, season)
+}
+
+// ListByApiary returns feedings of hives while they were in the apiary, newest first, of one season when set
+func (r *Feeding) ListByApiary(apiaryID string, season *int) ([]*Feeding, error) {
+	return r.list(`apiary_id=?`, apiaryID, season)
+}
+
+func (r *Feeding) list(condition string, value string, season *int) ([]*Feeding, error) {
+	args := []interface{}{r.UserID, value}
+	if season != nil {
+		condition += ` AND YEAR(fed_at)=?`
+		args = append(args, *season)
+	}
+
+	list := []*Feeding{}
+	err := r.Db.Select(&list,
+		`SELECT `+feedingColumns+`
+		FROM feedings
+		WHERE user_id=? AND active=1 AND `+condition+`
+		ORDER BY fed_at DESC, id DESC`, args...)
+
+	return list, err
+}
+
+func validateFeedingInput(input FeedingInput) error {
+	if input.Amount <= 0 || input.Amount > 100000 {
+		return errors.New("amount must be more than 0 and at most 100000")
+	}
+	if input.Notes != nil && len(*input.Notes) > 2000 {
+		return errors.New("notes must be at most 2000 characters")
+	}
+
+	return nil
+}
+
+// Record saves feed given to the hive and takes it from warehouse feed stock when deductStock is set.
+// Stock never goes below zero, only what was taken is given back when the feeding is removed
+func (r *Feeding) Record(input FeedingInput, deductStock bool) (*Feeding, error) {
+	if err := validateFeedingInput(input); err != nil {
+		return nil, err
+	}
+	feedKg, sugarKg, err := FeedAmountKg(input.FeedType, input.Amount, input.Unit)
+	if err != nil {
+		return nil, err
+	}
+	fedAt, err := parseOptionalDateTimeInput("date", input.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	var apiaryID *int
+	err = tx.Get(&apiaryID, `SELECT apiary_id FROM hives WHERE id=? AND user_id=? AND active=1 LIMIT 1`, input.HiveID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("hive not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if input.BoxID != nil {
+		box := struct {
+			HiveID int     `db:"hive_id"`
+			Type   BoxType `db:"type"`
+		}{}
+		err = tx.Get(&box, `SELECT hive_id, type FROM boxes WHERE id=? AND user_id=? AND active=1 LIMIT 1`, *input.BoxID, r.UserID)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, errors.New("box not found")
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if strconv.Itoa(box.HiveID) != input.HiveID {
+			tx.Rollback()
+			return nil, fmt.Errorf("box %s belongs to another hive", *input.BoxID)
+		}
+		if !feedBoxTypes[box.Type] {
+			tx.Rollback()
+			return nil, fmt.Errorf("feed can not be put into a %s box", strings.ToLower(string(box.Type)))
+		}
+	}
+
+	deductedKg := 0.0
+	if deductStock {
+		var stockKg float64
+		err = tx.Get(&stockKg,
+			`SELECT amount_kg FROM warehouse_feed_stock WHERE user_id=? AND feed_type=? FOR UPDATE`,
+			r.UserID, input.FeedType)
+		if err != nil && err != sql.ErrNoRows {
+			tx.Rollback()
+			return nil, err
+		}
+		deductedKg = math.Min(stockKg, feedKg)
+		if deductedKg > 0 {
+			_, err = tx.Exec(
+				`UPDATE warehouse_feed_stock SET amount_kg=amount_kg-? WHERE user_id=? AND feed_type=?`,
+				deductedKg, r.UserID, input.FeedType)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO feedings (user_id, hive_id, box_id, apiary_id, feed_type, amount, unit, feed_kg, sugar_kg, stock_deducted_kg, fed_at, notes)
+		VALUES (:userID, :hiveID, :boxID, :apiaryID, :feedType, :amount, :unit, :feedKg, :sugarKg, :stockDeductedKg, COALESCE(:fedAt, NOW()), :notes)`,
+		map[string]interface{}{
+			"userID":          r.UserID,
+			"hiveID":          input.HiveID,
+			"boxID":           input.BoxID,
+			"apiaryID":        apiaryID,
+			"feedType":        input.FeedType,
+			"amount":          input.Amount,
+			"unit":            input.Unit,
+			"feedKg":          feedKg,
+			"sugarKg":         sugarKg,
+			"stockDeductedKg": deductedKg,
+			"fedAt":           fedAt,
+			"notes":           input.Notes,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	feedingID := strconv.FormatInt(id, 10)
+
+	feeding := Feeding{}
+	err = tx.Get(&feeding, `SELECT `+feedingColumns+` FROM feedings WHERE id=? LIMIT 1`, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	source := "system"
+	details := fmt.Sprintf("%s %s of %s",
+		strconv.FormatFloat(input.Amount, 'f', -1, 64), strings.ToLower(string(input.Unit)),
+		strings.ReplaceAll(strings.ToLower(string(input.FeedType)), "_", " "))
+	dedupeKey := "feeding:" + feedingID
+	err = (&HiveLog{UserID: r.UserID}).CreateTx(tx, HiveLogInput{
+		HiveID:    input.HiveID,
+		Action:    "feeding",
+		Title:     "Hive fed",
+		Details:   &details,
+		Source:    &source,
+		DedupeKey: &dedupeKey,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, input.HiveID, "feeding", feedingID, "created", feeding)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &feeding, nil
+}
+
+// Delete removes the feeding and gives feed taken from the warehouse back to stock
+func (r *Feeding) Delete(id string) (bool, error) {
+	feeding, err := r.Get(id)
+	if err != nil || feeding == nil {
+		return false, err
+	}
+
+	tx := r.Db.MustBegin()
+	result, err := tx.Exec(`UPDATE feedings SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	// a concurrent or repeated delete already gave the feed back
+	deactivated, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if deactivated != 1 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if feeding.StockDeductedKg > 0 {
+		_, err = tx.Exec(
+			`INSERT INTO warehouse_feed_stock (user_id, feed_type, amount_kg) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE amount_kg=amount_kg+VALUES(amount_kg)`,
+			r.UserID, feeding.FeedType, feeding.StockDeductedKg)
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	err = (&HiveLog{UserID: r.UserID}).DeleteByDedupePrefixTx(tx, "feeding:"+id)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, feeding.HiveID, "feeding", id, "deleted", feeding)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Report sums feed given in the season, the current year when not set, of one apiary when set
+func (r *Feeding) Report(season *int, apiaryID *string) (*FeedingReport, error) {
+	report := &FeedingReport{
+		Season:     time.Now().UTC().Year(),
+		ApiaryID:   apiaryID,
+		ByFeedType: []*FeedTypeTotal{},
+		ByHive:     []*HiveFeedTotal{},
+	}
+	if season != nil {
+		report.Season = *season
+	}
+
+	condition := ``
+	args := []interface{}{r.UserID, report.Season}
+	if apiaryID != nil {
+		condition = ` AND apiary_id=?`
+		args = append(args, *apiaryID)
+	}
+
+	err := r.Db.Select(&report.ByFeedType,
+		`SELECT feed_type, COUNT(*) AS feedings, SUM(feed_kg) AS feed_kg, SUM(sugar_kg) AS sugar_kg
+		FROM feedings
+		WHERE user_id=? AND active=1 AND YEAR(fed_at)=?`+condition+`
+		GROUP BY feed_type
+		ORDER BY sugar_kg DESC, feed_kg DESC, feed_type ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Db.Select(&report.ByHive,
+		`SELECT hive_id, COUNT(*) AS feedings, SUM(feed_kg) AS feed_kg, SUM(sugar_kg) AS sugar_kg
+		FROM feedings
+		WHERE user_id=? AND active=1 AND YEAR(fed_at)=?`+condition+`
+		GROUP BY hive_id
+		ORDER BY sugar_kg DESC, hive_id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, total := range report.ByFeedType {
+		report.Feedings += total.Feedings
+		report.SugarKg += total.SugarKg
+	}
+	report.SugarKg = roundFeedKg(report.SugarKg)
+
+	return report, nil
+}
+
+type WarehouseFeedStock struct {
+	Db     *sqlx.DB
+	UserID string
+}
+
+// List returns feed kept in the warehouse, every feed type is listed
+func (r *WarehouseFeedStock) List() ([]*FeedStock, error) {
+	rows := []*FeedStock{}
+	err := r.Db.Select(&rows, `SELECT feed_type, amount_kg FROM warehouse_feed_stock WHERE user_id=?`, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := map[FeedType]float64{}
+	for _, row := range rows {
+		byType[row.FeedType] = row.AmountKg
+	}
+
+	list := make([]*FeedStock, 0, len(AllFeedType))
+	for _, feedType := range AllFeedType {
+		list = append(list, &FeedStock{FeedType: feedType, AmountKg: byType[feedType]})
+	}
+
+	return list, nil
+}
+
+func (r *WarehouseFeedStock) Set(feedType FeedType, amountKg float64) (*FeedStock, error) {
+	if !feedType.IsValid() {
+		return nil, fmt.Errorf("invalid feed type %s", feedType)
+	}
+	if amountKg < 0 || amountKg > 1000000 {
+		return nil, errors.New("amountKg must be between 0 and 1000000")
+	}
+	amountKg = roundFeedKg(amountKg)
+
+	_, err := r.Db.NamedExec(
+		`INSERT INTO warehouse_feed_stock (user_id, feed_type, amount_kg)
+		VALUES (:userID, :feedType, :amountKg)
+		ON DUPLICATE KEY UPDATE amount_kg=:amountKg`,
+		map[string]interface{}{
+			"userID":   r.UserID,
+			"feedType": feedType,
+			"amountKg": amountKg,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeedStock{FeedType: feedType, AmountKg: amountKg}, nil
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"database/sql"
+	"strconv"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -14,10 +15,11 @@ type Frame struct {
 	Position    int        `json:"position"`
 	Type        FrameType  `json:"type" db:"type"`
 	FrameSpecID *int       `json:"frame_spec_id" db:"frame_spec_id"`
+	CombBuiltAt *string    `json:"combBuiltAt" db:"comb_built_at"`
 	LeftID      *int       `json:"left" db:"left_id"`
 	RightID     *int       `json:"right" db:"right_id"`
-	LeftSide    *FrameSide `json:"left" `
-	RightSide   *FrameSide `json:"right"`
+	LeftSide    *FrameSide `json:"leftSide"`
+	RightSide   *FrameSide `json:"rightSide"`
 	Active      int        `db:"active"`
 }
 
@@ -36,6 +38,24 @@ func (r *Frame) Get(id int64) (*Frame, error) {
 	return &frame, err
 }
 
+// recordChangeTx records an event with the frame state after the change, frames of other users are skipped
+func (r *Frame) recordChangeTx(tx *sqlx.Tx, id string, action string) error {
+	frame := Frame{}
+	err := tx.Get(&frame,
+		`SELECT *
+		FROM frames
+		WHERE id=? AND user_id=?
+		LIMIT 1`, id, r.UserID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return recordBoxEventTx(tx, r.UserID, strconv.Itoa(frame.BoxId), "frame", id, action, frame)
+}
+
 func (r *Frame) CreateFramesForBox(boxID *string, frameCount int) error {
 	for frameNr := 0; frameNr < frameCount; frameNr++ {
 		leftSide := &FrameSide{
@@ -88,8 +108,8 @@ func (r *Frame) Create(boxID *string, position int, frameType FrameType, leftID
 	}
 
 	result, err := tx.NamedExec(
-		`INSERT INTO frames (box_id, position, left_id, right_id, user_id, type, frame_spec_id) 
-		VALUES (:boxID, :position, :left_id, :right_id, :userID, :frameType, :frameSpecID)`,
+		`INSERT INTO frames (box_id, position, left_id, right_id, user_id, type, frame_spec_id, comb_built_at) 
+		VALUES (:boxID, :position, :left_id, :right_id, :userID, :frameType, :frameSpecID, IF(:frameType = 'EMPTY_COMB', NOW(), NULL))`,
 		map[string]interface{}{
 			"boxID":       boxID,
 			"position":    position,
@@ -111,6 +131,22 @@ func (r *Frame) Create(boxID *string, position int, frameType FrameType, leftID
 		tx.Rollback()
 		return nil, err
 	}
+
+	err = r.recordChangeTx(tx, strconv.FormatInt(id, 10), "created")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	boxIDInt, err := strconv.Atoi(*boxID)
+	if err == nil {
+		err = r.recordMoveTx(tx, strconv.FormatInt(id, 10), nil, &boxIDInt, frameMoveTypePlaced)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -122,25 +158,34 @@ func (r *Frame) IsFrameWithSides(frameType FrameType) bool {
 }
 
 func (r *Frame) Update(frameID string, boxID string, position int) (*int64, error) {
-	var frameType FrameType
-	err := r.Db.Get(
-		&frameType,
-		`SELECT type
+	tx := r.Db.MustBegin()
+
+	frame := Frame{}
+	err := tx.Get(
+		&frame,
+		`SELECT type, box_id
 		FROM frames
 		WHERE id=? AND user_id=? AND active=1
 		LIMIT 1`,
 		frameID, r.UserID,
 	)
 	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	toBoxID, err := strconv.Atoi(boxID)
+	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	frameSpecID, err := resolveFrameSpecForTargetBox(r.Db, r.UserID, boxID, frameType)
+	frameSpecID, err := resolveFrameSpecForTargetBox(tx, r.UserID, boxID, frame.Type)
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	_, err = r.Db.NamedExec(
+	_, err = tx.NamedExec(
 		`UPDATE frames 
 		SET box_id=:boxID, position=:position, frame_spec_id=:frameSpecID
 		WHERE id=:id AND user_id=:userID`,
@@ -153,15 +198,42 @@ func (r *Frame) Update(frameID string, boxID string, position int) (*int64, erro
 		},
 	)
 
+	if err == nil {
+		err = r.recordChangeTx(tx, frameID, "updated")
+	}
+	if err == nil {
+		err = r.recordMoveTx(tx, frameID, &frame.BoxId, &toBoxID, frameMoveTypeMoved)
+	}
+
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
-	return nil, err
+	return nil, tx.Commit()
 }
 
 func (r *Frame) DeactivateFrames(boxId *string) error {
-	_, err := r.Db.NamedExec(
+	tx := r.Db.MustBegin()
+
+	_, err := tx.NamedExec(
+		`INSERT INTO frame_moves (user_id, frame_id, from_box_id, from_hive_id, move_type)
+		SELECT f.user_id, f.id, f.box_id, b.hive_id, :moveType
+		FROM frames f
+		LEFT JOIN boxes b ON b.id = f.box_id
+		WHERE f.box_id=:boxID AND f.user_id=:userID AND f.active=1`,
+		map[string]interface{}{
+			"boxID":    boxId,
+			"userID":   r.UserID,
+			"moveType": frameMoveTypeRemoved,
+		},
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.NamedExec(
 		`UPDATE frames 
 		SET active = 0
 		WHERE box_id=:boxID AND user_id=:userID`,
@@ -170,14 +242,18 @@ func (r *Frame) DeactivateFrames(boxId *string) error {
 			"userID": r.UserID,
 		},
 	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
 func (r *Frame) ListByBox(boxId *string) ([]*Frame, error) {
 	frames := []*Frame{}
 	err := r.Db.Select(&frames,
-		`SELECT id, position, left_id, right_id, type
+		`SELECT id, user_id, position, left_id, right_id, type, comb_built_at
 	FROM frames
 	WHERE frames.box_id =? AND user_id=? AND active=1
 	ORDER BY position`, boxId, r.UserID)
@@ -185,11 +261,40 @@ func (r *Frame) ListByBox(boxId *string) ([]*Frame, error) {
 	return frames, err
 }
 
+// CountInHive counts how many of frameIDs are active frames in active boxes of hiveID
+func (r *Frame) CountInHive(frameIDs []string, hiveID string) (int, error) {
+	if len(frameIDs) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT COUNT(DISTINCT f.id)
+		FROM frames f
+		INNER JOIN boxes b ON b.id = f.box_id AND b.user_id = f.user_id AND b.active = 1
+		WHERE f.id IN (?) AND f.user_id=? AND f.active=1 AND b.hive_id=?`,
+		frameIDs, r.UserID, hiveID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = r.Db.Get(&count, r.Db.Rebind(query), args...)
+	return count, err
+}
+
 func (r *Frame) Deactivate(id string) (*bool, error) {
 	success := true
 	tx := r.Db.MustBegin()
 
-	_, err := tx.NamedExec(
+	fromBoxID, err := r.boxIDTx(tx, id)
+	if err != nil {
+		tx.Rollback()
+		success = false
+		return &success, err
+	}
+
+	_, err = tx.NamedExec(
 		`UPDATE frames 
 		SET active = 0
 		WHERE id=:id AND user_id=:userID`,
@@ -198,6 +303,17 @@ func (r *Frame) Deactivate(id string) (*bool, error) {
 			"userID": r.UserID,
 		},
 	)
+	if err == nil {
+		err = r.recordChangeTx(tx, id, "deleted")
+	}
+	if err == nil {
+		err = r.recordMoveTx(tx, id, fromBoxID, nil, frameMoveTypeRemoved)
+	}
+	if err != nil {
+		tx.Rollback()
+		success = false
+		return &success, err
+	}
 
 	err = tx.Commit()
 
@@ -231,24 +347,48 @@ func (r *Frame) GetFrameBySideID(sideID int) (*int, error) {
 func (r *Frame) MoveFramesToBox(frameIDs []string, targetBoxID string) error {
 	tx := r.Db.MustBegin()
 
+	err := r.MoveFramesToBoxTx(tx, frameIDs, targetBoxID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MoveFramesToBoxTx moves frames to targetBoxID within a transaction owned by the caller
+func (r *Frame) MoveFramesToBoxTx(tx *sqlx.Tx, frameIDs []string, targetBoxID string) error {
+	positions := make([]int, len(frameIDs))
+	for i := range frameIDs {
+		positions[i] = i + 1
+	}
+
+	return r.moveFramesTx(tx, frameIDs, targetBoxID, positions)
+}
+
+// moveFramesTx moves each frame to targetBoxID at the position with the same index
+func (r *Frame) moveFramesTx(tx *sqlx.Tx, frameIDs []string, targetBoxID string, positions []int) error {
+	toBoxID, err := strconv.Atoi(targetBoxID)
+	if err != nil {
+		return err
+	}
+
 	for i, frameID := range frameIDs {
-		var frameType FrameType
+		frame := Frame{}
 		err := tx.Get(
-			&frameType,
-			`SELECT type
+			&frame,
+			`SELECT type, box_id
 			FROM frames
 			WHERE id=? AND user_id=? AND active=1
 			LIMIT 1`,
 			frameID, r.UserID,
 		)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 
-		frameSpecID, err := resolveFrameSpecForTargetBox(tx, r.UserID, targetBoxID, frameType)
+		frameSpecID, err := resolveFrameSpecForTargetBox(tx, r.UserID, targetBoxID, frame.Type)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 
@@ -259,16 +399,21 @@ func (r *Frame) MoveFramesToBox(frameIDs []string, targetBoxID string) error {
 			map[string]interface{}{
 				"id":          frameID,
 				"boxID":       targetBoxID,
-				"position":    i + 1,
+				"position":    positions[i],
 				"frameSpecID": frameSpecID,
 				"userID":      r.UserID,
 			},
 		)
+		if err == nil {
+			err = r.recordChangeTx(tx, frameID, "moved")
+		}
+		if err == nil {
+			err = r.recordMoveTx(tx, frameID, &frame.BoxId, &toBoxID, frameMoveTypeMoved)
+		}
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
@@ -0,0 +1,231 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	frameMoveTypePlaced  = "PLACED"
+	frameMoveTypeMoved   = "MOVED"
+	frameMoveTypeRemoved = "REMOVED"
+)
+
+// DefaultCombRotationDays is the comb age old dark combs are replaced at for disease control
+const DefaultCombRotationDays = 3 * 365
+
+// FrameMove is a frame placed into, moved between or removed from boxes
+type FrameMove struct {
+	ID         string  `json:"id" db:"id"`
+	MoveType   string  `json:"moveType" db:"move_type"`
+	FromBoxID  *string `json:"fromBoxId" db:"from_box_id"`
+	FromHiveID *string `json:"fromHiveId" db:"from_hive_id"`
+	ToBoxID    *string `json:"toBoxId" db:"to_box_id"`
+	ToHiveID   *string `json:"toHiveId" db:"to_hive_id"`
+	MovedAt    string  `json:"movedAt" db:"moved_at"`
+}
+
+// CombDueForRotation is a frame with a comb older than the rotation age
+type CombDueForRotation struct {
+	Frame       *Frame `json:"frame"`
+	HiveID      string `json:"hiveId"`
+	BoxID       string `json:"boxId"`
+	CombAgeDays int    `json:"combAgeDays"`
+}
+
+// CombAgeDays returns full days between the comb build date and now, nil when the date is unknown
+func CombAgeDays(combBuiltAt *string, now time.Time) *int {
+	if combBuiltAt == nil {
+		return nil
+	}
+	builtAt, err := parseDBDateTime(*combBuiltAt)
+	if err != nil {
+		return nil
+	}
+
+	days := int(now.Sub(builtAt).Hours() / 24)
+	if days < 0 {
+		days = 0
+	}
+	return &days
+}
+
+// boxIDTx returns the box the frame is in, nil for frames without a box
+func (r *Frame) boxIDTx(tx *sqlx.Tx, frameID string) (*int, error) {
+	var boxID *int
+	err := tx.Get(&boxID, `SELECT box_id FROM frames WHERE id=? AND user_id=? LIMIT 1`, frameID, r.UserID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return boxID, err
+}
+
+// recordMoveTx records the frame leaving fromBoxID for toBoxID with the hives of both boxes,
+// reordering within the same box is not a move
+func (r *Frame) recordMoveTx(tx *sqlx.Tx, frameID string, fromBoxID *int, toBoxID *int, moveType string) error {
+	if fromBoxID != nil && toBoxID != nil && *fromBoxID == *toBoxID {
+		return nil
+	}
+
+	_, err := tx.NamedExec(
+		`INSERT INTO frame_moves (user_id, frame_id, from_box_id, from_hive_id, to_box_id, to_hive_id, move_type)
+		VALUES (
+			:userID, :frameID,
+			:fromBoxID, (SELECT hive_id FROM boxes WHERE id=:fromBoxID AND user_id=:userID),
+			:toBoxID, (SELECT hive_id FROM boxes WHERE id=:toBoxID AND user_id=:userID),
+			:moveType
+		)`,
+		map[string]interface{}{
+			"userID":    r.UserID,
+			"frameID":   frameID,
+			"fromBoxID": fromBoxID,
+			"toBoxID":   toBoxID,
+			"moveType":  moveType,
+		},
+	)
+	return err
+}
+
+// recordBoxMoveTx records the frames of a box moved from one hive to another, the frames stay in the box
+func (r *Frame) recordBoxMoveTx(tx *sqlx.Tx, boxID string, fromHiveID int, toHiveID string) error {
+	if strconv.Itoa(fromHiveID) == toHiveID {
+		return nil
+	}
+
+	_, err := tx.NamedExec(
+		`INSERT INTO frame_moves (user_id, frame_id, from_box_id, from_hive_id, to_box_id, to_hive_id, move_type)
+		SELECT user_id, id, box_id, :fromHiveID, box_id, :toHiveID, :moveType
+		FROM frames
+		WHERE box_id=:boxID AND user_id=:userID AND active=1`,
+		map[string]interface{}{
+			"userID":     r.UserID,
+			"boxID":      boxID,
+			"fromHiveID": fromHiveID,
+			"toHiveID":   toHiveID,
+			"moveType":   frameMoveTypeMoved,
+		},
+	)
+	return err
+}
+
+// History returns the moves of the frame, oldest first
+func (r *Frame) History(frameID string) ([]*FrameMove, error) {
+	moves := []*FrameMove{}
+	err := r.Db.Select(&moves,
+		`SELECT id, move_type, from_box_id, from_hive_id, to_box_id, to_hive_id, moved_at
+		FROM frame_moves
+		WHERE frame_id=? AND user_id=?
+		ORDER BY moved_at ASC, id ASC`, frameID, r.UserID)
+
+	return moves, err
+}
+
+// SetCombBuiltAt sets when the comb of the frame was drawn, nil date clears it
+func (r *Frame) SetCombBuiltAt(frameID string, date *string) (*Frame, error) {
+	combBuiltAt, err := parseOptionalDateTimeInput("date", date)
+	if err != nil {
+		return nil, err
+	}
+	if combBuiltAt != nil && *combBuiltAt > time.Now().UTC().Format(mysqlDateTimeFormat) {
+		return nil, errors.New("comb build date can not be in the future")
+	}
+
+	tx := r.Db.MustBegin()
+	result, err := tx.Exec(
+		`UPDATE frames SET comb_built_at=? WHERE id=? AND user_id=? AND active=1`,
+		combBuiltAt, frameID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return nil, errors.New("frame not found")
+	}
+
+	if err = r.recordChangeTx(tx, frameID, "updated"); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseInt(frameID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(id)
+}
+
+// ListDueForRotation returns frames in hives of the apiary with combs drawn at least maxAgeDays ago, oldest comb first
+func (r *Frame) ListDueForRotation(apiaryID string, maxAgeDays *int) ([]*CombDueForRotation, error) {
+	ageDays := DefaultCombRotationDays
+	if maxAgeDays != nil {
+		ageDays = *maxAgeDays
+	}
+	if ageDays < 1 {
+		return nil, errors.New("maxAgeDays must be positive")
+	}
+
+	now := time.Now().UTC()
+	cutoff := now.AddDate(0, 0, -ageDays).Format(mysqlDateTimeFormat)
+
+	frames := []*Frame{}
+	err := r.Db.Select(&frames,
+		`SELECT f.*
+		FROM frames f
+		JOIN boxes b ON b.id = f.box_id AND b.user_id = f.user_id AND b.active=1
+		JOIN hives h ON h.id = b.hive_id AND h.user_id = b.user_id AND h.active=1
+		WHERE h.apiary_id=? AND f.user_id=? AND f.active=1 AND f.comb_built_at <= ?
+		ORDER BY f.comb_built_at ASC, f.id ASC`, apiaryID, r.UserID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return []*CombDueForRotation{}, nil
+	}
+
+	boxes := []struct {
+		ID     int `db:"id"`
+		HiveID int `db:"hive_id"`
+	}{}
+	err = r.Db.Select(&boxes,
+		`SELECT b.id, b.hive_id
+		FROM boxes b
+		JOIN hives h ON h.id = b.hive_id AND h.user_id = b.user_id
+		WHERE h.apiary_id=? AND b.user_id=? AND b.active=1`, apiaryID, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+	hiveByBox := map[int]int{}
+	for _, box := range boxes {
+		hiveByBox[box.ID] = box.HiveID
+	}
+
+	due := make([]*CombDueForRotation, 0, len(frames))
+	for _, frame := range frames {
+		ageDays := CombAgeDays(frame.CombBuiltAt, now)
+		if ageDays == nil {
+			continue
+		}
+		due = append(due, &CombDueForRotation{
+			Frame:       frame,
+			HiveID:      strconv.Itoa(hiveByBox[frame.BoxId]),
+			BoxID:       strconv.Itoa(frame.BoxId),
+			CombAgeDays: *ageDays,
+		})
+	}
+
+	return due, nil
+}
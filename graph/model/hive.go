@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -120,7 +121,7 @@ func (r *Hive) ListByApiarySorted(apiaryID int, sortBy HiveSortBy, sortOrder Sor
 		LEFT JOIN (
 			SELECT i.hive_id, MAX(i.added) AS last_inspection
 			FROM inspections i
-			WHERE i.user_id=?
+			WHERE i.user_id=? AND i.active=1
 			GROUP BY i.hive_id
 		) li ON li.hive_id = h.id
 		LEFT JOIN (
@@ -213,16 +214,46 @@ func (r *Hive) Create(input HiveInput) (*Hive, error) {
 		return nil, err
 	}
 
+	hive, err := r.getTx(tx, strconv.FormatInt(id, 10))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, hive.ID, "hive", hive.ID, "created", hive)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	err = tx.Commit()
 
 	if err != nil {
 		return nil, err
 	}
 
+	return hive, nil
+}
+
+// recordChangeTx records an event with the hive state after the change, hives of other users are skipped
+func (r *Hive) recordChangeTx(tx *sqlx.Tx, id string, action string) error {
+	hive, err := r.getTx(tx, id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return recordHiveEventTx(tx, r.UserID, id, "hive", id, action, hive)
+}
+
+// getTx reads a hive within tx, so changes of the transaction are visible
+func (r *Hive) getTx(tx *sqlx.Tx, id string) (*Hive, error) {
 	hive := Hive{}
-	err = r.Db.Get(&hive, `SELECT id, user_id, apiary_id, box_system_id, hive_type, active, hive_number, notes, color, status, added,
+	err := tx.Get(&hive, `SELECT id, user_id, apiary_id, box_system_id, hive_type, active, hive_number, notes, color, status, added,
 	        collapse_date, collapse_cause, parent_hive_id, split_date, merged_into_hive_id, merge_date, merge_type
-	        FROM hives WHERE id=? LIMIT 1`, id)
+	        FROM hives WHERE id=? AND user_id=? LIMIT 1`, id, r.UserID)
 
 	return &hive, err
 }
@@ -256,8 +287,18 @@ func (r *Hive) Update(id string, notes *string, hiveNumber *int, FamilyID *strin
 			"userID":     r.UserID,
 		},
 	)
-	tx.Commit()
-	return err
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = r.recordChangeTx(tx, id, "updated")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *Hive) Deactivate(id string) (*bool, error) {
@@ -271,7 +312,12 @@ func (r *Hive) Deactivate(id string) (*bool, error) {
 		},
 	)
 
+	if err == nil {
+		err = r.recordChangeTx(tx, id, "deleted")
+	}
+
 	if err != nil {
+		tx.Rollback()
 		success = false
 		return &success, err
 	}
@@ -315,6 +361,12 @@ func (r *Hive) MarkAsCollapsed(id string, collapseDate time.Time, collapseCause
 		return err
 	}
 
+	err = r.recordChangeTx(tx, id, "collapsed")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	err = tx.Commit()
 
 	return err
@@ -352,16 +404,25 @@ func (r *Hive) GetChildHives(hiveID string) ([]*Hive, error) {
 func (r *Hive) Split(sourceHiveID string, apiaryID int) (*Hive, error) {
 	tx := r.Db.MustBegin()
 
+	hive, err := r.SplitTx(tx, sourceHiveID, apiaryID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return hive, tx.Commit()
+}
+
+// SplitTx creates a child hive of sourceHiveID within tx, so it can be combined with other split steps
+func (r *Hive) SplitTx(tx *sqlx.Tx, sourceHiveID string, apiaryID int) (*Hive, error) {
 	var sourceBoxSystemID sql.NullInt64
 	var sourceHiveType string
 	err := tx.Get(&sourceBoxSystemID, `SELECT box_system_id FROM hives WHERE id=? AND user_id=? LIMIT 1`, sourceHiveID, r.UserID)
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	err = tx.Get(&sourceHiveType, `SELECT hive_type FROM hives WHERE id=? AND user_id=? LIMIT 1`, sourceHiveID, r.UserID)
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
@@ -389,22 +450,36 @@ func (r *Hive) Split(sourceHiveID string, apiaryID int) (*Hive, error) {
 		return nil, err
 	}
 
-	err = tx.Commit()
+	hive, err := r.getTx(tx, strconv.FormatInt(id, 10))
 	if err != nil {
 		return nil, err
 	}
 
-	hive := Hive{}
-	err = r.Db.Get(&hive, `SELECT id, user_id, apiary_id, box_system_id, hive_type, active, hive_number, notes, color, status, added,
-	        collapse_date, collapse_cause, parent_hive_id, split_date, merged_into_hive_id, merge_date, merge_type
-	        FROM hives WHERE id=? LIMIT 1`, id)
+	err = recordHiveEventTx(tx, r.UserID, hive.ID, "hive", hive.ID, "created", hive)
+	if err == nil {
+		err = recordHiveEventTx(tx, r.UserID, sourceHiveID, "hive", sourceHiveID, "split", hive)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return &hive, err
+	return hive, nil
 }
 
 func (r *Hive) MarkAsMerged(sourceHiveID string, targetHiveID string, mergeDate time.Time, mergeType string) error {
 	tx := r.Db.MustBegin()
 
+	err := r.MarkAsMergedTx(tx, sourceHiveID, targetHiveID, mergeDate, mergeType)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkAsMergedTx marks sourceHiveID as merged into targetHiveID within tx
+func (r *Hive) MarkAsMergedTx(tx *sqlx.Tx, sourceHiveID string, targetHiveID string, mergeDate time.Time, mergeType string) error {
 	_, err := tx.NamedExec(
 		`UPDATE hives SET status='merged',
 			merged_into_hive_id = :targetHiveID, 
@@ -421,7 +496,6 @@ func (r *Hive) MarkAsMerged(sourceHiveID string, targetHiveID string, mergeDate
 	)
 
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
@@ -430,13 +504,15 @@ func (r *Hive) MarkAsMerged(sourceHiveID string, targetHiveID string, mergeDate
 		sourceHiveID, r.UserID)
 
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	err = tx.Commit()
+	err = r.recordChangeTx(tx, targetHiveID, "join")
+	if err != nil {
+		return err
+	}
 
-	return err
+	return r.recordChangeTx(tx, sourceHiveID, "merged")
 }
 
 func (r *Hive) GetMergedIntoHive(mergedIntoHiveID *int) (*Hive, error) {
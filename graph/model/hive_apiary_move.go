@@ -0,0 +1,220 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HiveApiaryStay is a period a hive lived in one apiary
+type HiveApiaryStay struct {
+	ApiaryID string  `json:"apiaryId"`
+	Apiary   *Apiary `json:"apiary"`
+	From     *string `json:"from"`
+	Until    *string `json:"until"`
+}
+
+type hiveApiaryMoveRow struct {
+	FromApiaryID *string `db:"from_apiary_id"`
+	ToApiaryID   string  `db:"to_apiary_id"`
+	MovedAt      string  `db:"moved_at"`
+}
+
+// MoveToApiary relocates a hive to another apiary of UserID at movedAt, now by default.
+// The placement in the old apiary is removed, the new apiary places the hive like a newly added one
+func (r *Hive) MoveToApiary(id string, targetApiaryID string, movedAt *string) error {
+	when, err := parseOptionalDateTimeInput("date", movedAt)
+	if err != nil {
+		return err
+	}
+	if when == nil {
+		now := time.Now().UTC().Format(mysqlDateTimeFormat)
+		when = &now
+	}
+
+	tx := r.Db.MustBegin()
+
+	hive, err := r.getTx(tx, id)
+	if err == sql.ErrNoRows || (err == nil && (hive.Active == nil || !*hive.Active)) {
+		tx.Rollback()
+		return errors.New("hive not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	sourceApiaryID := strconv.Itoa(hive.ApiaryID)
+	if sourceApiaryID == targetApiaryID {
+		tx.Rollback()
+		return errors.New("hive is already in this apiary")
+	}
+
+	var targetName *string
+	err = tx.Get(&targetName, `SELECT name FROM apiaries WHERE id=? AND user_id=? AND active=1 LIMIT 1`, targetApiaryID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return errors.New("target apiary not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// history is read in moved_at order, so moves can not be backdated before the previous one
+	var laterMoves int
+	err = tx.Get(&laterMoves,
+		`SELECT COUNT(*) FROM hive_apiary_moves WHERE hive_id=? AND user_id=? AND moved_at > ?`,
+		id, r.UserID, *when)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if laterMoves > 0 {
+		tx.Rollback()
+		return errors.New("hive was already moved after this date")
+	}
+
+	var sourceName *string
+	err = tx.Get(&sourceName, `SELECT name FROM apiaries WHERE id=? AND user_id=? LIMIT 1`, sourceApiaryID, r.UserID)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE hives SET apiary_id=? WHERE id=? AND user_id=?`, targetApiaryID, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM hive_placements WHERE hive_id=? AND user_id=?`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO hive_apiary_moves (user_id, hive_id, from_apiary_id, to_apiary_id, moved_at)
+		VALUES (:userID, :hiveID, :fromApiaryID, :toApiaryID, :movedAt)`,
+		map[string]interface{}{
+			"userID":       r.UserID,
+			"hiveID":       id,
+			"fromApiaryID": sourceApiaryID,
+			"toApiaryID":   targetApiaryID,
+			"movedAt":      *when,
+		},
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	moveID, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	source := "system"
+	details := fmt.Sprintf("Moved from %s to %s on %s",
+		apiaryLogName(sourceApiaryID, sourceName), apiaryLogName(targetApiaryID, targetName), (*when)[:10])
+	dedupeKey := fmt.Sprintf("hive-apiary-move:%d", moveID)
+	err = (&HiveLog{UserID: r.UserID}).CreateTx(tx, HiveLogInput{
+		HiveID:    id,
+		Action:    "move",
+		Title:     "Hive moved to another apiary",
+		Details:   &details,
+		Source:    &source,
+		DedupeKey: &dedupeKey,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// the hive event goes to the new apiary, subscribers of the old one learn the hive left it
+	err = recordApiaryEventTx(tx, r.UserID, sourceApiaryID, "hive_moved_out", map[string]interface{}{
+		"hiveId":         id,
+		"targetApiaryId": targetApiaryID,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = r.recordChangeTx(tx, id, "moved")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func apiaryLogName(id string, name *string) string {
+	if name == nil || *name == "" {
+		return "apiary #" + id
+	}
+	return *name
+}
+
+// ApiaryHistory lists the apiaries hive lived in, oldest first. The last stay is the current apiary
+func (r *Hive) ApiaryHistory(hive *Hive) ([]*HiveApiaryStay, error) {
+	moves := []*hiveApiaryMoveRow{}
+	err := r.Db.Select(&moves,
+		`SELECT from_apiary_id, to_apiary_id, moved_at
+		FROM hive_apiary_moves
+		WHERE hive_id=? AND user_id=?
+		ORDER BY moved_at ASC, id ASC`, hive.ID, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := &HiveApiaryStay{ApiaryID: strconv.Itoa(hive.ApiaryID), From: hive.Added}
+	if len(moves) > 0 && moves[0].FromApiaryID != nil {
+		current.ApiaryID = *moves[0].FromApiaryID
+	}
+
+	stays := []*HiveApiaryStay{}
+	for _, move := range moves {
+		movedAt := move.MovedAt
+		current.Until = &movedAt
+		stays = append(stays, current)
+		current = &HiveApiaryStay{ApiaryID: move.ToApiaryID, From: &movedAt}
+	}
+	stays = append(stays, current)
+
+	return stays, r.loadStayApiaries(stays)
+}
+
+// loadStayApiaries sets apiaries of stays, deactivated ones included as the history is kept for disease tracing
+func (r *Hive) loadStayApiaries(stays []*HiveApiaryStay) error {
+	ids := make([]string, 0, len(stays))
+	for _, stay := range stays {
+		ids = append(ids, stay.ApiaryID)
+	}
+
+	query, args, err := sqlx.In(`SELECT `+apiaryColumns+` FROM apiaries WHERE id IN (?) AND user_id=?`, ids, r.UserID)
+	if err != nil {
+		return err
+	}
+	apiaries := []*Apiary{}
+	err = r.Db.Select(&apiaries, r.Db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+
+	byID := map[string]*Apiary{}
+	for _, apiary := range apiaries {
+		ensureApiaryType(apiary)
+		byID[strconv.Itoa(apiary.ID)] = apiary
+	}
+	for _, stay := range stays {
+		stay.Apiary = byID[stay.ApiaryID]
+	}
+
+	return nil
+}
@@ -260,3 +260,37 @@ func (r *HiveLog) Delete(id string) (bool, error) {
 	}
 	return rows > 0, nil
 }
+
+// CreateTx writes a log entry as part of a larger change, skipping the ownership check the caller already did
+func (r *HiveLog) CreateTx(tx *sqlx.Tx, input HiveLogInput) error {
+	relatedBytes, _ := json.Marshal(input.RelatedHives)
+	relatedJSON := string(relatedBytes)
+
+	_, err := tx.NamedExec(
+		`INSERT INTO hive_logs (user_id, hive_id, action, title, details, source, related_hives, dedupe_key)
+		 VALUES (:user_id, :hive_id, :action, :title, :details, :source, :related_hives, :dedupe_key)
+		 ON DUPLICATE KEY UPDATE active = 1, updated_at = CURRENT_TIMESTAMP`,
+		map[string]interface{}{
+			"user_id":       r.UserID,
+			"hive_id":       input.HiveID,
+			"action":        input.Action,
+			"title":         input.Title,
+			"details":       input.Details,
+			"source":        input.Source,
+			"related_hives": relatedJSON,
+			"dedupe_key":    input.DedupeKey,
+		},
+	)
+	return err
+}
+
+// DeleteByDedupePrefixTx deactivates the entries a change wrote under a common dedupe key prefix
+func (r *HiveLog) DeleteByDedupePrefixTx(tx *sqlx.Tx, prefix string) error {
+	_, err := tx.Exec(
+		`UPDATE hive_logs SET active=0, updated_at=CURRENT_TIMESTAMP
+		 WHERE user_id=? AND dedupe_key LIKE CONCAT(?, '%') AND active=1`,
+		r.UserID,
+		prefix,
+	)
+	return err
+}
@@ -0,0 +1,218 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HiveSnapshot is the box, frame and queen structure of a hive, stored with an inspection
+type HiveSnapshot struct {
+	Boxes    []*HiveSnapshotBox    `json:"boxes"`
+	Families []*HiveSnapshotFamily `json:"families"`
+}
+
+type HiveSnapshotBox struct {
+	ID       string               `json:"id" db:"id"`
+	Position *int                 `json:"position" db:"position"`
+	Type     BoxType              `json:"type" db:"type"`
+	Color    *string              `json:"color" db:"color"`
+	Frames   []*HiveSnapshotFrame `json:"frames" db:"-"`
+}
+
+type HiveSnapshotFrame struct {
+	ID       string    `json:"id" db:"id"`
+	BoxID    string    `json:"boxId" db:"box_id"`
+	Position *int      `json:"position" db:"position"`
+	Type     FrameType `json:"type" db:"type"`
+}
+
+type HiveSnapshotFamily struct {
+	ID    string  `json:"id" db:"id"`
+	Name  *string `json:"name" db:"name"`
+	Race  *string `json:"race" db:"race"`
+	Added *string `json:"added" db:"added"`
+	Color *string `json:"color" db:"color"`
+}
+
+// captureHiveSnapshotTx reads the active boxes, frames and queens of the hive within tx
+func captureHiveSnapshotTx(tx *sqlx.Tx, userID string, hiveID int) (*HiveSnapshot, error) {
+	snapshot := &HiveSnapshot{
+		Boxes:    []*HiveSnapshotBox{},
+		Families: []*HiveSnapshotFamily{},
+	}
+
+	err := tx.Select(&snapshot.Boxes,
+		`SELECT id, position, type, color
+		FROM boxes
+		WHERE hive_id=? AND user_id=? AND active=1
+		ORDER BY position ASC, id ASC`, hiveID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := []*HiveSnapshotFrame{}
+	err = tx.Select(&frames,
+		`SELECT f.id, f.box_id, f.position, f.type
+		FROM frames f
+		JOIN boxes b ON b.id = f.box_id
+		WHERE b.hive_id=? AND b.user_id=? AND b.active=1 AND f.active=1
+		ORDER BY f.position ASC, f.id ASC`, hiveID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	boxesByID := map[string]*HiveSnapshotBox{}
+	for _, box := range snapshot.Boxes {
+		box.Frames = []*HiveSnapshotFrame{}
+		boxesByID[box.ID] = box
+	}
+	for _, frame := range frames {
+		if box, ok := boxesByID[frame.BoxID]; ok {
+			box.Frames = append(box.Frames, frame)
+		}
+	}
+
+	err = tx.Select(&snapshot.Families,
+		`SELECT id, name, race, added, color
+		FROM families
+		WHERE hive_id=? AND user_id=? AND active=1
+		ORDER BY id ASC`, hiveID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func parseHiveSnapshot(raw *string) *HiveSnapshot {
+	if raw == nil || *raw == "" {
+		return nil
+	}
+
+	snapshot := &HiveSnapshot{}
+	if err := json.Unmarshal([]byte(*raw), snapshot); err != nil {
+		return nil
+	}
+
+	return snapshot
+}
+
+// CompareHiveSnapshots lists what changed in the hive structure from one inspection to another
+func CompareHiveSnapshots(from *Inspection, to *Inspection) (*InspectionComparison, error) {
+	if from.HiveSnapshot == nil || to.HiveSnapshot == nil {
+		return nil, errors.New("inspection was recorded without a hive snapshot")
+	}
+
+	comparison := &InspectionComparison{
+		From:              from,
+		To:                to,
+		BoxesAdded:        []*HiveSnapshotBox{},
+		BoxesRemoved:      []*HiveSnapshotBox{},
+		BoxesMoved:        []*HiveSnapshotBoxMove{},
+		FramesAdded:       []*HiveSnapshotFrame{},
+		FramesRemoved:     []*HiveSnapshotFrame{},
+		FramesMoved:       []*HiveSnapshotFrameMove{},
+		FramesTypeChanged: []*HiveSnapshotFrameTypeChange{},
+		QueensAdded:       []*HiveSnapshotFamily{},
+		QueensRemoved:     []*HiveSnapshotFamily{},
+	}
+
+	fromBoxes, fromFrames := indexSnapshotBoxes(from.HiveSnapshot)
+	toBoxes, toFrames := indexSnapshotBoxes(to.HiveSnapshot)
+
+	for _, box := range to.HiveSnapshot.Boxes {
+		previous, ok := fromBoxes[box.ID]
+		if !ok {
+			comparison.BoxesAdded = append(comparison.BoxesAdded, box)
+			continue
+		}
+		if !sameIntPtr(previous.Position, box.Position) {
+			comparison.BoxesMoved = append(comparison.BoxesMoved, &HiveSnapshotBoxMove{
+				BoxID:        box.ID,
+				FromPosition: previous.Position,
+				ToPosition:   box.Position,
+			})
+		}
+	}
+	for _, box := range from.HiveSnapshot.Boxes {
+		if _, ok := toBoxes[box.ID]; !ok {
+			comparison.BoxesRemoved = append(comparison.BoxesRemoved, box)
+		}
+	}
+
+	for _, box := range to.HiveSnapshot.Boxes {
+		for _, frame := range box.Frames {
+			previous, ok := fromFrames[frame.ID]
+			if !ok {
+				comparison.FramesAdded = append(comparison.FramesAdded, frame)
+				continue
+			}
+			if previous.BoxID != frame.BoxID || !sameIntPtr(previous.Position, frame.Position) {
+				comparison.FramesMoved = append(comparison.FramesMoved, &HiveSnapshotFrameMove{
+					FrameID:      frame.ID,
+					FromBoxID:    previous.BoxID,
+					FromPosition: previous.Position,
+					ToBoxID:      frame.BoxID,
+					ToPosition:   frame.Position,
+				})
+			}
+			if previous.Type != frame.Type {
+				comparison.FramesTypeChanged = append(comparison.FramesTypeChanged, &HiveSnapshotFrameTypeChange{
+					FrameID:  frame.ID,
+					FromType: previous.Type,
+					ToType:   frame.Type,
+				})
+			}
+		}
+	}
+	for _, box := range from.HiveSnapshot.Boxes {
+		for _, frame := range box.Frames {
+			if _, ok := toFrames[frame.ID]; !ok {
+				comparison.FramesRemoved = append(comparison.FramesRemoved, frame)
+			}
+		}
+	}
+
+	fromFamilies := map[string]bool{}
+	for _, family := range from.HiveSnapshot.Families {
+		fromFamilies[family.ID] = true
+	}
+	toFamilies := map[string]bool{}
+	for _, family := range to.HiveSnapshot.Families {
+		toFamilies[family.ID] = true
+		if !fromFamilies[family.ID] {
+			comparison.QueensAdded = append(comparison.QueensAdded, family)
+		}
+	}
+	for _, family := range from.HiveSnapshot.Families {
+		if !toFamilies[family.ID] {
+			comparison.QueensRemoved = append(comparison.QueensRemoved, family)
+		}
+	}
+	comparison.QueenChanged = len(comparison.QueensAdded) > 0 || len(comparison.QueensRemoved) > 0
+
+	return comparison, nil
+}
+
+func indexSnapshotBoxes(snapshot *HiveSnapshot) (map[string]*HiveSnapshotBox, map[string]*HiveSnapshotFrame) {
+	boxes := map[string]*HiveSnapshotBox{}
+	frames := map[string]*HiveSnapshotFrame{}
+	for _, box := range snapshot.Boxes {
+		boxes[box.ID] = box
+		for _, frame := range box.Frames {
+			frames[frame.ID] = frame
+		}
+	}
+
+	return boxes, frames
+}
+
+func sameIntPtr(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
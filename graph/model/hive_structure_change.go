@@ -0,0 +1,630 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	HiveStructureChangeSplit = "SPLIT"
+	HiveStructureChangeMerge = "MERGE"
+)
+
+const (
+	hiveStructureItemFrame      = "FRAME"
+	hiveStructureItemBox        = "BOX"
+	hiveStructureItemCreatedBox = "CREATED_BOX"
+)
+
+// HiveStructureChange records a split or merge together with every box and frame it moved, so it can be reverted.
+// For a split the target is the hive created by the split, for a merge the hive the source was merged into.
+// Queen moves of a split are not duplicated here, they are read back from family_moves.
+type HiveStructureChange struct {
+	Db     *sqlx.DB
+	UserID string `db:"user_id"`
+
+	ID                int64   `db:"id"`
+	ChangeType        string  `db:"change_type"`
+	SourceHiveID      int     `db:"source_hive_id"`
+	TargetHiveID      int     `db:"target_hive_id"`
+	PreviousStatus    *string `db:"previous_status"`
+	PreviousPlacement *string `db:"previous_placement"`
+}
+
+type hiveStructureChangeItem struct {
+	ItemType        string `db:"item_type"`
+	ItemID          int    `db:"item_id"`
+	FromParentID    *int   `db:"from_parent_id"`
+	FromPosition    *int   `db:"from_position"`
+	FromFrameSpecID *int   `db:"from_frame_spec_id"`
+	ToParentID      int    `db:"to_parent_id"`
+	ToPosition      *int   `db:"to_position"`
+}
+
+type hivePlacementSnapshot struct {
+	ApiaryID int     `json:"apiary_id"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Rotation float64 `json:"rotation"`
+}
+
+// CreateTx starts the record of a change, keeping the source hive status and placement the change may overwrite,
+// and writes the hive log entries of both hives
+func (r *HiveStructureChange) CreateTx(tx *sqlx.Tx, changeType string, sourceHiveID string, targetHiveID string) (int64, error) {
+	var previousStatus sql.NullString
+	err := tx.Get(&previousStatus, `SELECT status FROM hives WHERE id=? AND user_id=? LIMIT 1`, sourceHiveID, r.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	var previousPlacement sql.NullString
+	err = tx.Get(&previousPlacement,
+		`SELECT JSON_OBJECT('apiary_id', apiary_id, 'x', x, 'y', y, 'rotation', rotation)
+		FROM hive_placements
+		WHERE hive_id=? AND user_id=?
+		LIMIT 1`, sourceHiveID, r.UserID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO hive_structure_changes (user_id, change_type, source_hive_id, target_hive_id, previous_status, previous_placement)
+		VALUES (:userID, :changeType, :sourceHiveID, :targetHiveID, :previousStatus, :previousPlacement)`,
+		map[string]interface{}{
+			"userID":            r.UserID,
+			"changeType":        changeType,
+			"sourceHiveID":      sourceHiveID,
+			"targetHiveID":      targetHiveID,
+			"previousStatus":    previousStatus,
+			"previousPlacement": previousPlacement,
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err = r.createLogsTx(tx, id, changeType, sourceHiveID, targetHiveID); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func hiveStructureLogPrefix(changeID int64) string {
+	return fmt.Sprintf("hive-structure-change:%d:", changeID)
+}
+
+func (r *HiveStructureChange) createLogsTx(tx *sqlx.Tx, changeID int64, changeType string, sourceHiveID string, targetHiveID string) error {
+	hiveModel := &Hive{UserID: r.UserID}
+	sourceHive, err := hiveModel.getTx(tx, sourceHiveID)
+	if err != nil {
+		return err
+	}
+	targetHive, err := hiveModel.getTx(tx, targetHiveID)
+	if err != nil {
+		return err
+	}
+
+	action, sourceTitle, targetTitle := "split", "Hive split", "Hive created by split"
+	if changeType == HiveStructureChangeMerge {
+		action, sourceTitle, targetTitle = "merge", "Hive merged into another hive", "Hive merged"
+	}
+
+	source := "system"
+	logModel := &HiveLog{UserID: r.UserID}
+	prefix := hiveStructureLogPrefix(changeID)
+
+	sourceKey := prefix + sourceHiveID
+	err = logModel.CreateTx(tx, HiveLogInput{
+		HiveID:       sourceHiveID,
+		Action:       action,
+		Title:        sourceTitle,
+		Source:       &source,
+		DedupeKey:    &sourceKey,
+		RelatedHives: []*HiveLogRelatedHiveInput{{ID: targetHiveID, HiveNumber: targetHive.HiveNumber}},
+	})
+	if err != nil {
+		return err
+	}
+
+	targetKey := prefix + targetHiveID
+	return logModel.CreateTx(tx, HiveLogInput{
+		HiveID:       targetHiveID,
+		Action:       action,
+		Title:        targetTitle,
+		Source:       &source,
+		DedupeKey:    &targetKey,
+		RelatedHives: []*HiveLogRelatedHiveInput{{ID: sourceHiveID, HiveNumber: sourceHive.HiveNumber}},
+	})
+}
+
+func (r *HiveStructureChange) addItemTx(tx *sqlx.Tx, changeID int64, item hiveStructureChangeItem) error {
+	_, err := tx.NamedExec(
+		`INSERT INTO hive_structure_change_items (change_id, item_type, item_id, from_parent_id, from_position, from_frame_spec_id, to_parent_id, to_position)
+		VALUES (:changeID, :itemType, :itemID, :fromParentID, :fromPosition, :fromFrameSpecID, :toParentID, :toPosition)`,
+		map[string]interface{}{
+			"changeID":        changeID,
+			"itemType":        item.ItemType,
+			"itemID":          item.ItemID,
+			"fromParentID":    item.FromParentID,
+			"fromPosition":    item.FromPosition,
+			"fromFrameSpecID": item.FromFrameSpecID,
+			"toParentID":      item.ToParentID,
+			"toPosition":      item.ToPosition,
+		},
+	)
+	return err
+}
+
+// RecordFrameMovesTx keeps where frames were before MoveFramesToBoxTx moves them to targetBoxID
+func (r *HiveStructureChange) RecordFrameMovesTx(tx *sqlx.Tx, changeID int64, frameIDs []string, targetBoxID string) error {
+	targetBoxIDInt, err := strconv.Atoi(targetBoxID)
+	if err != nil {
+		return err
+	}
+
+	for i, frameID := range frameIDs {
+		var frame struct {
+			ID          int  `db:"id"`
+			BoxID       int  `db:"box_id"`
+			Position    *int `db:"position"`
+			FrameSpecID *int `db:"frame_spec_id"`
+		}
+		err := tx.Get(&frame,
+			`SELECT id, box_id, position, frame_spec_id
+			FROM frames
+			WHERE id=? AND user_id=? AND active=1
+			LIMIT 1`, frameID, r.UserID)
+		if err != nil {
+			return err
+		}
+
+		toPosition := i + 1
+		err = r.addItemTx(tx, changeID, hiveStructureChangeItem{
+			ItemType:        hiveStructureItemFrame,
+			ItemID:          frame.ID,
+			FromParentID:    &frame.BoxID,
+			FromPosition:    frame.Position,
+			FromFrameSpecID: frame.FrameSpecID,
+			ToParentID:      targetBoxIDInt,
+			ToPosition:      &toPosition,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordBoxMovesTx keeps where boxes were before MoveBoxesToHiveTx moves them to targetHiveID
+func (r *HiveStructureChange) RecordBoxMovesTx(tx *sqlx.Tx, changeID int64, boxIDs []string, targetHiveID string, startPosition int) error {
+	targetHiveIDInt, err := strconv.Atoi(targetHiveID)
+	if err != nil {
+		return err
+	}
+
+	for i, boxID := range boxIDs {
+		var box struct {
+			ID       int  `db:"id"`
+			HiveID   int  `db:"hive_id"`
+			Position *int `db:"position"`
+		}
+		err := tx.Get(&box,
+			`SELECT id, hive_id, position
+			FROM boxes
+			WHERE id=? AND user_id=? AND active=1
+			LIMIT 1`, boxID, r.UserID)
+		if err != nil {
+			return err
+		}
+
+		toPosition := startPosition + i
+		err = r.addItemTx(tx, changeID, hiveStructureChangeItem{
+			ItemType:     hiveStructureItemBox,
+			ItemID:       box.ID,
+			FromParentID: &box.HiveID,
+			FromPosition: box.Position,
+			ToParentID:   targetHiveIDInt,
+			ToPosition:   &toPosition,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordCreatedBoxTx keeps a box the change added to hiveID, it is removed again on revert
+func (r *HiveStructureChange) RecordCreatedBoxTx(tx *sqlx.Tx, changeID int64, boxID string, hiveID string) error {
+	boxIDInt, err := strconv.Atoi(boxID)
+	if err != nil {
+		return err
+	}
+	hiveIDInt, err := strconv.Atoi(hiveID)
+	if err != nil {
+		return err
+	}
+
+	return r.addItemTx(tx, changeID, hiveStructureChangeItem{
+		ItemType:   hiveStructureItemCreatedBox,
+		ItemID:     boxIDInt,
+		ToParentID: hiveIDInt,
+	})
+}
+
+// RevertSplitTx undoes the latest split that created hiveID and returns the source hive id
+func (r *HiveStructureChange) RevertSplitTx(tx *sqlx.Tx, hiveID string) (string, error) {
+	change, err := r.getLatestTx(tx, HiveStructureChangeSplit, "target_hive_id", hiveID)
+	if err != nil {
+		return "", err
+	}
+	if change == nil {
+		return "", errors.New("hive was not created by a split that can be reverted")
+	}
+	if err = r.ensureNoLaterChangesTx(tx, change); err != nil {
+		return "", err
+	}
+
+	sourceHiveID := strconv.Itoa(change.SourceHiveID)
+	targetHiveID := strconv.Itoa(change.TargetHiveID)
+
+	var restorableHives int
+	err = tx.Get(&restorableHives,
+		`SELECT COUNT(*) FROM hives
+		WHERE id IN (?, ?) AND user_id=? AND active=1 AND COALESCE(status, 'active') NOT IN ('merged', 'collapsed')`,
+		change.SourceHiveID, change.TargetHiveID, r.UserID)
+	if err != nil {
+		return "", err
+	}
+	if restorableHives != 2 {
+		return "", errors.New("cannot revert split: one of the hives was removed, merged or collapsed")
+	}
+
+	items, err := r.listItemsTx(tx, change.ID)
+	if err != nil {
+		return "", err
+	}
+
+	frames, createdBoxes := []hiveStructureChangeItem{}, []hiveStructureChangeItem{}
+	for _, item := range items {
+		switch item.ItemType {
+		case hiveStructureItemFrame:
+			frames = append(frames, item)
+		case hiveStructureItemCreatedBox:
+			createdBoxes = append(createdBoxes, item)
+		}
+	}
+
+	// the split hive must hold exactly what the split put there
+	var boxesInTarget, framesInTarget int
+	err = tx.Get(&boxesInTarget, `SELECT COUNT(*) FROM boxes WHERE hive_id=? AND user_id=? AND active=1`, change.TargetHiveID, r.UserID)
+	if err != nil {
+		return "", err
+	}
+	err = tx.Get(&framesInTarget,
+		`SELECT COUNT(*) FROM frames f
+		JOIN boxes b ON b.id = f.box_id
+		WHERE b.hive_id=? AND b.user_id=? AND b.active=1 AND f.active=1`, change.TargetHiveID, r.UserID)
+	if err != nil {
+		return "", err
+	}
+	if boxesInTarget != len(createdBoxes) || framesInTarget != len(frames) {
+		return "", errors.New("cannot revert split: boxes or frames of the split hive changed since the split")
+	}
+
+	for _, frame := range frames {
+		if err = r.ensureItemInPlaceTx(tx, frame); err != nil {
+			return "", err
+		}
+		var sourceBoxActive int
+		err = tx.Get(&sourceBoxActive,
+			`SELECT COUNT(*) FROM boxes WHERE id=? AND hive_id=? AND user_id=? AND active=1`,
+			frame.FromParentID, change.SourceHiveID, r.UserID)
+		if err != nil {
+			return "", err
+		}
+		if sourceBoxActive == 0 {
+			return "", errors.New("cannot revert split: original box of a frame is no longer in the source hive")
+		}
+	}
+
+	queenMoves, err := r.listSplitQueenMovesTx(tx, change.TargetHiveID)
+	if err != nil {
+		return "", err
+	}
+
+	frameModel := &Frame{UserID: r.UserID}
+	for _, frame := range frames {
+		frameID := strconv.Itoa(frame.ItemID)
+		fromBoxID, err := frameModel.boxIDTx(tx, frameID)
+		if err != nil {
+			return "", err
+		}
+		_, err = tx.Exec(
+			`UPDATE frames SET box_id=?, position=?, frame_spec_id=? WHERE id=? AND user_id=?`,
+			frame.FromParentID, frame.FromPosition, frame.FromFrameSpecID, frame.ItemID, r.UserID)
+		if err == nil {
+			err = frameModel.recordChangeTx(tx, frameID, "moved")
+		}
+		if err == nil {
+			err = frameModel.recordMoveTx(tx, frameID, fromBoxID, frame.FromParentID, frameMoveTypeMoved)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	familyModel := &Family{UserID: r.UserID}
+	for _, move := range queenMoves {
+		familyID := strconv.Itoa(move.FamilyID)
+		if move.MoveType == familyMoveTypeTransferred && move.FromHiveID != nil {
+			err = familyModel.MoveBetweenHivesTx(tx, familyID, targetHiveID, strconv.Itoa(*move.FromHiveID))
+		} else {
+			_, err = familyModel.DeleteFromHiveTx(tx, targetHiveID, familyID)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	boxModel := &Box{UserID: r.UserID}
+	for _, box := range createdBoxes {
+		boxID := strconv.Itoa(box.ItemID)
+		_, err = tx.Exec(`UPDATE boxes SET active=0 WHERE id=? AND user_id=?`, boxID, r.UserID)
+		if err == nil {
+			err = boxModel.recordChangeTx(tx, boxID, "deleted")
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	hiveModel := &Hive{UserID: r.UserID}
+	_, err = tx.Exec(`UPDATE hives SET active=0 WHERE id=? AND user_id=?`, change.TargetHiveID, r.UserID)
+	if err != nil {
+		return "", err
+	}
+	_, err = tx.Exec(`DELETE FROM hive_placements WHERE hive_id=? AND user_id=?`, change.TargetHiveID, r.UserID)
+	if err != nil {
+		return "", err
+	}
+	if err = hiveModel.recordChangeTx(tx, targetHiveID, "deleted"); err != nil {
+		return "", err
+	}
+
+	if err = r.markRevertedTx(tx, change.ID); err != nil {
+		return "", err
+	}
+
+	return sourceHiveID, hiveModel.recordChangeTx(tx, sourceHiveID, "split_reverted")
+}
+
+// RevertMergeTx undoes the latest merge of sourceHiveID into another hive
+func (r *HiveStructureChange) RevertMergeTx(tx *sqlx.Tx, sourceHiveID string) error {
+	change, err := r.getLatestTx(tx, HiveStructureChangeMerge, "source_hive_id", sourceHiveID)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return errors.New("hive has no merge that can be reverted")
+	}
+	if err = r.ensureNoLaterChangesTx(tx, change); err != nil {
+		return err
+	}
+
+	var mergedSource int
+	err = tx.Get(&mergedSource,
+		`SELECT COUNT(*) FROM hives
+		WHERE id=? AND user_id=? AND active=1 AND status='merged' AND merged_into_hive_id=?`,
+		change.SourceHiveID, r.UserID, change.TargetHiveID)
+	if err != nil {
+		return err
+	}
+	var restorableTarget int
+	err = tx.Get(&restorableTarget,
+		`SELECT COUNT(*) FROM hives
+		WHERE id=? AND user_id=? AND active=1 AND COALESCE(status, 'active') NOT IN ('merged', 'collapsed')`,
+		change.TargetHiveID, r.UserID)
+	if err != nil {
+		return err
+	}
+	if mergedSource == 0 || restorableTarget == 0 {
+		return errors.New("cannot revert merge: one of the hives was removed, merged or collapsed")
+	}
+
+	items, err := r.listItemsTx(tx, change.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, box := range items {
+		if err = r.ensureItemInPlaceTx(tx, box); err != nil {
+			return err
+		}
+	}
+
+	boxModel := &Box{UserID: r.UserID}
+	frameModel := &Frame{UserID: r.UserID}
+	for _, box := range items {
+		boxID := strconv.Itoa(box.ItemID)
+		_, err = tx.Exec(`UPDATE boxes SET hive_id=?, position=? WHERE id=? AND user_id=?`,
+			box.FromParentID, box.FromPosition, boxID, r.UserID)
+		if err == nil {
+			err = boxModel.recordChangeTx(tx, boxID, "moved")
+		}
+		if err == nil && box.FromParentID != nil {
+			// ensureItemInPlaceTx checked the box is still in the hive it was merged into
+			err = frameModel.recordBoxMoveTx(tx, boxID, box.ToParentID, strconv.Itoa(*box.FromParentID))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE hives SET status=?, merged_into_hive_id=NULL, merge_date=NULL, merge_type=NULL
+		WHERE id=? AND user_id=?`,
+		change.PreviousStatus, change.SourceHiveID, r.UserID)
+	if err != nil {
+		return err
+	}
+
+	if change.PreviousPlacement != nil {
+		placement := hivePlacementSnapshot{}
+		if err = json.Unmarshal([]byte(*change.PreviousPlacement), &placement); err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO hive_placements (user_id, apiary_id, hive_id, x, y, rotation)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE x=VALUES(x), y=VALUES(y), rotation=VALUES(rotation)`,
+			r.UserID, placement.ApiaryID, change.SourceHiveID, placement.X, placement.Y, placement.Rotation)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = r.markRevertedTx(tx, change.ID); err != nil {
+		return err
+	}
+
+	hiveModel := &Hive{UserID: r.UserID}
+	err = hiveModel.recordChangeTx(tx, strconv.Itoa(change.TargetHiveID), "merge_reverted")
+	if err != nil {
+		return err
+	}
+
+	return hiveModel.recordChangeTx(tx, sourceHiveID, "merge_reverted")
+}
+
+func (r *HiveStructureChange) getLatestTx(tx *sqlx.Tx, changeType string, hiveColumn string, hiveID string) (*HiveStructureChange, error) {
+	change := &HiveStructureChange{}
+	err := tx.Get(change,
+		`SELECT id, user_id, change_type, source_hive_id, target_hive_id, previous_status, previous_placement
+		FROM hive_structure_changes
+		WHERE `+hiveColumn+`=? AND user_id=? AND change_type=? AND reverted_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1
+		FOR UPDATE`, hiveID, r.UserID, changeType)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return change, err
+}
+
+// ensureNoLaterChangesTx refuses a revert while a later split or merge of either hive is in effect,
+// those have to be reverted first
+func (r *HiveStructureChange) ensureNoLaterChangesTx(tx *sqlx.Tx, change *HiveStructureChange) error {
+	var laterChanges int
+	err := tx.Get(&laterChanges,
+		`SELECT COUNT(*) FROM hive_structure_changes
+		WHERE user_id=? AND id>? AND reverted_at IS NULL
+		  AND (source_hive_id IN (?, ?) OR target_hive_id IN (?, ?))`,
+		r.UserID, change.ID,
+		change.SourceHiveID, change.TargetHiveID, change.SourceHiveID, change.TargetHiveID)
+	if err != nil {
+		return err
+	}
+	if laterChanges > 0 {
+		return errors.New("cannot revert: the hives were split or merged again afterwards, revert that first")
+	}
+
+	return nil
+}
+
+// ensureItemInPlaceTx checks that a moved box or frame is still where the change put it
+func (r *HiveStructureChange) ensureItemInPlaceTx(tx *sqlx.Tx, item hiveStructureChangeItem) error {
+	query := `SELECT COUNT(*) FROM frames WHERE id=? AND box_id=? AND user_id=? AND active=1`
+	if item.ItemType == hiveStructureItemBox {
+		query = `SELECT COUNT(*) FROM boxes WHERE id=? AND hive_id=? AND user_id=? AND active=1`
+	}
+
+	var inPlace int
+	err := tx.Get(&inPlace, query, item.ItemID, item.ToParentID, r.UserID)
+	if err != nil {
+		return err
+	}
+	if inPlace == 0 {
+		return fmt.Errorf("cannot revert: %s %d was moved or removed afterwards", map[string]string{
+			hiveStructureItemFrame: "frame",
+			hiveStructureItemBox:   "box",
+		}[item.ItemType], item.ItemID)
+	}
+
+	return nil
+}
+
+type splitQueenMove struct {
+	FamilyID   int    `db:"family_id"`
+	FromHiveID *int   `db:"from_hive_id"`
+	MoveType   string `db:"move_type"`
+}
+
+// listSplitQueenMovesTx reads the queen the split put into the hive from family_moves.
+// A split moves at most one queen in and none out, anything else happened after the split.
+func (r *HiveStructureChange) listSplitQueenMovesTx(tx *sqlx.Tx, hiveID int) ([]splitQueenMove, error) {
+	moves := []splitQueenMove{}
+	err := tx.Select(&moves,
+		`SELECT family_id, from_hive_id, move_type
+		FROM family_moves
+		WHERE user_id=? AND (to_hive_id=? OR from_hive_id=?)
+		ORDER BY id ASC`,
+		r.UserID, hiveID, hiveID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(moves) > 1 || (len(moves) == 1 && moves[0].FromHiveID != nil && *moves[0].FromHiveID == hiveID) {
+		return nil, errors.New("cannot revert split: queens of the split hive changed since the split")
+	}
+
+	for _, move := range moves {
+		var inHive int
+		err = tx.Get(&inHive,
+			`SELECT COUNT(*) FROM families WHERE id=? AND hive_id=? AND user_id=? AND active=1`,
+			move.FamilyID, hiveID, r.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if inHive == 0 {
+			return nil, errors.New("cannot revert split: queens of the split hive changed since the split")
+		}
+	}
+
+	return moves, nil
+}
+
+func (r *HiveStructureChange) listItemsTx(tx *sqlx.Tx, changeID int64) ([]hiveStructureChangeItem, error) {
+	items := []hiveStructureChangeItem{}
+	err := tx.Select(&items,
+		`SELECT item_type, item_id, from_parent_id, from_position, from_frame_spec_id, to_parent_id, to_position
+		FROM hive_structure_change_items
+		WHERE change_id=?
+		ORDER BY id ASC`, changeID)
+	return items, err
+}
+
+// markRevertedTx closes the change and removes the hive log entries it wrote
+func (r *HiveStructureChange) markRevertedTx(tx *sqlx.Tx, changeID int64) error {
+	_, err := tx.Exec(
+		`UPDATE hive_structure_changes SET reverted_at=CURRENT_TIMESTAMP WHERE id=? AND user_id=?`,
+		changeID, r.UserID)
+	if err != nil {
+		return err
+	}
+
+	return (&HiveLog{UserID: r.UserID}).DeleteByDedupePrefixTx(tx, hiveStructureLogPrefix(changeID))
+}
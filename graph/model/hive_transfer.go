@@ -0,0 +1,332 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// boxSystemProfileTx returns the system whose box profiles a system uses, removed systems stand for themselves
+func boxSystemProfileTx(tx *sqlx.Tx, userID string, systemID int) (int, error) {
+	profileID, err := resolveEffectiveBoxProfileSystemID(tx, userID, systemID)
+	if err == sql.ErrNoRows {
+		return systemID, nil
+	}
+
+	return profileID, err
+}
+
+// sameBoxSystemTx tells whether boxes of both systems fit together, an unknown system fits any
+func sameBoxSystemTx(tx *sqlx.Tx, userID string, left *int, right *int) (bool, error) {
+	if left == nil || right == nil || *left == *right {
+		return true, nil
+	}
+
+	leftProfile, err := boxSystemProfileTx(tx, userID, *left)
+	if err != nil {
+		return false, err
+	}
+	rightProfile, err := boxSystemProfileTx(tx, userID, *right)
+	if err != nil {
+		return false, err
+	}
+
+	return leftProfile == rightProfile, nil
+}
+
+// boxPlacement is the hive and box system of a box, the system of the hive when the box has none
+type boxPlacement struct {
+	ID          int  `db:"id"`
+	HiveID      int  `db:"hive_id"`
+	BoxSystemID *int `db:"box_system_id"`
+}
+
+func getBoxPlacementTx(tx *sqlx.Tx, userID string, boxID string) (*boxPlacement, error) {
+	placement := boxPlacement{}
+	err := tx.Get(&placement,
+		`SELECT b.id, b.hive_id, COALESCE(b.box_system_id, h.box_system_id) AS box_system_id
+		FROM boxes b
+		JOIN hives h ON h.id = b.hive_id AND h.user_id = b.user_id
+		WHERE b.id=? AND b.user_id=? AND b.active=1
+		LIMIT 1`, boxID, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &placement, nil
+}
+
+// createTransferLogsTx writes the hive log of both hives when something moved from one hive to the other
+func createTransferLogsTx(tx *sqlx.Tx, userID string, fromHiveID string, toHiveID string, sourceTitle string, targetTitle string, details string) error {
+	hiveModel := &Hive{UserID: userID}
+	fromHive, err := hiveModel.getTx(tx, fromHiveID)
+	if err != nil {
+		return err
+	}
+	toHive, err := hiveModel.getTx(tx, toHiveID)
+	if err != nil {
+		return err
+	}
+
+	source := "system"
+	logModel := &HiveLog{UserID: userID}
+	err = logModel.CreateTx(tx, HiveLogInput{
+		HiveID:       fromHiveID,
+		Action:       "move",
+		Title:        sourceTitle,
+		Details:      &details,
+		Source:       &source,
+		RelatedHives: []*HiveLogRelatedHiveInput{{ID: toHiveID, HiveNumber: toHive.HiveNumber}},
+	})
+	if err != nil {
+		return err
+	}
+
+	return logModel.CreateTx(tx, HiveLogInput{
+		HiveID:       toHiveID,
+		Action:       "move",
+		Title:        targetTitle,
+		Details:      &details,
+		Source:       &source,
+		RelatedHives: []*HiveLogRelatedHiveInput{{ID: fromHiveID, HiveNumber: fromHive.HiveNumber}},
+	})
+}
+
+// MoveToBox moves frames with their sides to targetBoxID, at positions when given or else after the last frame
+// of the box. Both hives get a log entry when frames come from another hive
+func (r *Frame) MoveToBox(frameIDs []string, targetBoxID string, positions []int) error {
+	if len(frameIDs) == 0 {
+		return errors.New("no frames to move")
+	}
+	if positions != nil && len(positions) != len(frameIDs) {
+		return errors.New("positions must be given for every frame")
+	}
+	movedFrames := map[string]bool{}
+	for _, frameID := range frameIDs {
+		if movedFrames[frameID] {
+			return errors.New("frame is listed more than once")
+		}
+		movedFrames[frameID] = true
+	}
+
+	tx := r.Db.MustBegin()
+
+	target, err := getBoxPlacementTx(tx, r.UserID, targetBoxID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if target == nil {
+		tx.Rollback()
+		return errors.New("target box not found")
+	}
+
+	// frames leaving a hive, in the order they were listed
+	movedByHive := map[int]int{}
+	sourceHives := []int{}
+	for _, frameID := range frameIDs {
+		boxID, err := r.boxIDTx(tx, frameID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if boxID == nil {
+			tx.Rollback()
+			return errors.New("frame not found")
+		}
+		source, err := getBoxPlacementTx(tx, r.UserID, strconv.Itoa(*boxID))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if source == nil {
+			tx.Rollback()
+			return errors.New("frame not found")
+		}
+
+		compatible, err := sameBoxSystemTx(tx, r.UserID, source.BoxSystemID, target.BoxSystemID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if !compatible {
+			tx.Rollback()
+			return errors.New("frames can not be moved between boxes of different box systems")
+		}
+
+		if source.HiveID != target.HiveID {
+			if movedByHive[source.HiveID] == 0 {
+				sourceHives = append(sourceHives, source.HiveID)
+			}
+			movedByHive[source.HiveID]++
+		}
+	}
+
+	occupied := []struct {
+		ID       string `db:"id"`
+		Position int    `db:"position"`
+	}{}
+	err = tx.Select(&occupied,
+		`SELECT id, COALESCE(position, 0) AS position FROM frames WHERE box_id=? AND user_id=? AND active=1`,
+		targetBoxID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	takenPositions := map[int]bool{}
+	lastPosition := 0
+	for _, frame := range occupied {
+		if movedFrames[frame.ID] {
+			continue
+		}
+		takenPositions[frame.Position] = true
+		if frame.Position > lastPosition {
+			lastPosition = frame.Position
+		}
+	}
+
+	if positions == nil {
+		positions = make([]int, len(frameIDs))
+		for i := range frameIDs {
+			positions[i] = lastPosition + i + 1
+		}
+	}
+	for _, position := range positions {
+		if position < 1 {
+			tx.Rollback()
+			return errors.New("positions must be positive")
+		}
+		if takenPositions[position] {
+			tx.Rollback()
+			return fmt.Errorf("position %d of the target box is taken by another frame", position)
+		}
+		takenPositions[position] = true
+	}
+
+	if err = r.moveFramesTx(tx, frameIDs, targetBoxID, positions); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	targetHiveID := strconv.Itoa(target.HiveID)
+	for _, hiveID := range sourceHives {
+		details := fmt.Sprintf("%d frames", movedByHive[hiveID])
+		err = createTransferLogsTx(tx, r.UserID, strconv.Itoa(hiveID), targetHiveID,
+			"Frames moved to another hive", "Frames moved from another hive", details)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// MoveToHive moves a box with its frames onto targetHiveID, at position when given or else on top.
+// Boxes above position move one up and the box no longer belongs to a queen of the hive it left
+func (r *Box) MoveToHive(boxID string, targetHiveID string, position *int) (*Box, error) {
+	if position != nil && *position < 0 {
+		return nil, errors.New("position must not be negative")
+	}
+
+	tx := r.Db.MustBegin()
+
+	box, err := getBoxPlacementTx(tx, r.UserID, boxID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if box == nil {
+		tx.Rollback()
+		return nil, errors.New("box not found")
+	}
+	sourceHiveID := strconv.Itoa(box.HiveID)
+	if sourceHiveID == targetHiveID {
+		tx.Rollback()
+		return nil, errors.New("box is already in the target hive")
+	}
+
+	var targetSystemID *int
+	err = tx.Get(&targetSystemID, `SELECT box_system_id FROM hives WHERE id=? AND user_id=? AND active=1 LIMIT 1`, targetHiveID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("target hive not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	compatible, err := sameBoxSystemTx(tx, r.UserID, box.BoxSystemID, targetSystemID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !compatible {
+		tx.Rollback()
+		return nil, errors.New("box does not fit the box system of the target hive")
+	}
+
+	maxPosition, err := r.GetMaxPositionTx(tx, targetHiveID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	targetPosition := maxPosition + 1
+	if position != nil && *position <= maxPosition {
+		targetPosition = *position
+		shiftedIDs := []string{}
+		err = tx.Select(&shiftedIDs,
+			`SELECT id FROM boxes WHERE hive_id=? AND user_id=? AND active=1 AND position>=?`,
+			targetHiveID, r.UserID, targetPosition)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		for _, shiftedID := range shiftedIDs {
+			_, err = tx.Exec(`UPDATE boxes SET position=position+1 WHERE id=? AND user_id=?`, shiftedID, r.UserID)
+			if err == nil {
+				err = r.recordChangeTx(tx, shiftedID, "updated")
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE boxes SET family_id=NULL WHERE id=? AND user_id=?`, boxID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = r.MoveBoxesToHiveTx(tx, []string{boxID}, targetHiveID, targetPosition); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	moved, err := r.getTx(tx, boxID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	details := fmt.Sprintf("Box %s (%s)", boxID, moved.Type)
+	err = createTransferLogsTx(tx, r.UserID, sourceHiveID, targetHiveID, "Box moved to another hive", "Box moved from another hive", details)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(boxID)
+}
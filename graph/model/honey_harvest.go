@@ -0,0 +1,458 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HoneyHarvest is honey taken from a hive, credited to the queen family living in it at the time
+type HoneyHarvest struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID           string   `json:"id" db:"id"`
+	HiveID       string   `json:"hiveId" db:"hive_id"`
+	FamilyID     *string  `json:"familyId" db:"family_id"`
+	ApiaryID     *string  `json:"apiaryId" db:"apiary_id"`
+	HarvestedAt  string   `json:"harvestedAt" db:"harvested_at"`
+	WeightKg     float64  `json:"weightKg" db:"weight_kg"`
+	Moisture     *float64 `json:"moisture" db:"moisture"`
+	FloralSource *string  `json:"floralSource" db:"floral_source"`
+	Frames       *int     `json:"frames" db:"frames"`
+	BoxIDs       []string `json:"boxIds" db:"-"`
+}
+
+// HoneyHarvestInput is a harvest to record, familyID is set by the caller from the hive
+type HoneyHarvestInput struct {
+	HiveID       string
+	BoxIDs       []string
+	Frames       *int
+	WeightKg     float64
+	Moisture     *float64
+	FloralSource *string
+	Date         *string
+}
+
+// YieldTotal sums harvests of one hive, family, apiary or season, the other keys are nil
+type YieldTotal struct {
+	HiveID          *string  `json:"hiveId" db:"-"`
+	FamilyID        *string  `json:"familyId" db:"-"`
+	ApiaryID        *string  `json:"apiaryId" db:"-"`
+	Season          *int     `json:"season" db:"-"`
+	GroupKey        *string  `json:"-" db:"group_key"`
+	Harvests        int      `json:"harvests" db:"harvests"`
+	TotalKg         float64  `json:"totalKg" db:"total_kg"`
+	AverageMoisture *float64 `json:"averageMoisture" db:"average_moisture"`
+	FirstHarvestAt  string   `json:"firstHarvestAt" db:"first_harvest_at"`
+	LastHarvestAt   string   `json:"lastHarvestAt" db:"last_harvest_at"`
+}
+
+// YieldFilter limits harvests summed by Yield, unset fields do not filter
+type YieldFilter struct {
+	HiveID   *string
+	FamilyID *string
+	ApiaryID *string
+	Season   *int
+}
+
+const honeyHarvestColumns = `id, user_id, hive_id, family_id, apiary_id, harvested_at, weight_kg, moisture, floral_source, frames`
+
+// harvestBoxTypes are boxes that hold honey frames
+var harvestBoxTypes = map[BoxType]bool{
+	BoxTypeSuper:                  true,
+	BoxTypeDeep:                   true,
+	BoxTypeLargeHorizontalSection: true,
+}
+
+// yieldGroupColumns are the expressions harvests are grouped by. Seasons are calendar years
+var yieldGroupColumns = map[YieldGrouping]string{
+	YieldGroupingHive:   `hive_id`,
+	YieldGroupingFamily: `family_id`,
+	YieldGroupingAPIAry: `apiary_id`,
+	YieldGroupingSeason: `YEAR(harvested_at)`,
+}
+
+func (r *HoneyHarvest) Get(id string) (*HoneyHarvest, error) {
+	harvest := HoneyHarvest{}
+	err := r.Db.Get(&harvest,
+		`SELECT `+honeyHarvestColumns+`
+		FROM honey_harvests
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &harvest, r.loadBoxIDs(&harvest)
+}
+
+// ListByHive returns harvests of the hive, newest first, of one season when set
+func (r *HoneyHarvest) ListByHive(hiveID string, season *int) ([]*HoneyHarvest, error) {
+	condition := ``
+	args := []interface{}{r.UserID, hiveID}
+	if season != nil {
+		condition = ` AND YEAR(harvested_at)=?`
+		args = append(args, *season)
+	}
+
+	list := []*HoneyHarvest{}
+	err := r.Db.Select(&list,
+		`SELECT `+honeyHarvestColumns+`
+		FROM honey_harvests
+		WHERE user_id=? AND hive_id=? AND active=1`+condition+`
+		ORDER BY harvested_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, r.loadBoxIDs(list...)
+}
+
+func (r *HoneyHarvest) loadBoxIDs(harvests ...*HoneyHarvest) error {
+	if len(harvests) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(harvests))
+	byID := map[string]*HoneyHarvest{}
+	for _, harvest := range harvests {
+		harvest.BoxIDs = []string{}
+		ids = append(ids, harvest.ID)
+		byID[harvest.ID] = harvest
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT harvest_id, box_id FROM honey_harvest_boxes WHERE harvest_id IN (?) ORDER BY box_id ASC`, ids)
+	if err != nil {
+		return err
+	}
+	rows := []struct {
+		HarvestID string `db:"harvest_id"`
+		BoxID     string `db:"box_id"`
+	}{}
+	err = r.Db.Select(&rows, r.Db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if harvest, ok := byID[row.HarvestID]; ok {
+			harvest.BoxIDs = append(harvest.BoxIDs, row.BoxID)
+		}
+	}
+
+	return nil
+}
+
+// uniqueStrings drops repeated values, keeping the first occurrence
+func uniqueStrings(values []string) []string {
+	list := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		list = append(list, value)
+	}
+
+	return list
+}
+
+func validateHoneyHarvestInput(input HoneyHarvestInput) error {
+	if input.WeightKg <= 0 || input.WeightKg > 1000 {
+		return errors.New("weightKg must be more than 0 and at most 1000")
+	}
+	if input.Moisture != nil && (*input.Moisture < 10 || *input.Moisture > 30) {
+		return errors.New("moisture must be between 10 and 30 percent")
+	}
+	if input.Frames != nil && (*input.Frames < 0 || *input.Frames > 500) {
+		return errors.New("frames must be between 0 and 500")
+	}
+	if input.FloralSource != nil && len(*input.FloralSource) > 100 {
+		return errors.New("floralSource must be at most 100 characters")
+	}
+
+	return nil
+}
+
+// Record saves a harvest of the hive, familyID is the queen family living in it
+func (r *HoneyHarvest) Record(input HoneyHarvestInput, familyID *int) (*HoneyHarvest, error) {
+	if err := validateHoneyHarvestInput(input); err != nil {
+		return nil, err
+	}
+	harvestedAt, err := parseOptionalDateTimeInput("date", input.Date)
+	if err != nil {
+		return nil, err
+	}
+	var floralSource *string
+	if input.FloralSource != nil && strings.TrimSpace(*input.FloralSource) != "" {
+		trimmed := strings.TrimSpace(*input.FloralSource)
+		floralSource = &trimmed
+	}
+
+	tx := r.Db.MustBegin()
+
+	var apiaryID *int
+	err = tx.Get(&apiaryID, `SELECT apiary_id FROM hives WHERE id=? AND user_id=? AND active=1 LIMIT 1`, input.HiveID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("hive not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	boxIDs := uniqueStrings(input.BoxIDs)
+	if len(boxIDs) > 0 {
+		query, args, err := sqlx.In(
+			`SELECT id, hive_id, type FROM boxes WHERE id IN (?) AND user_id=? AND active=1`, boxIDs, r.UserID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		boxes := []struct {
+			ID     string  `db:"id"`
+			HiveID int     `db:"hive_id"`
+			Type   BoxType `db:"type"`
+		}{}
+		err = tx.Select(&boxes, tx.Rebind(query), args...)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if len(boxes) != len(boxIDs) {
+			tx.Rollback()
+			return nil, errors.New("box not found")
+		}
+		for _, box := range boxes {
+			if strconv.Itoa(box.HiveID) != input.HiveID {
+				tx.Rollback()
+				return nil, fmt.Errorf("box %s belongs to another hive", box.ID)
+			}
+			if !harvestBoxTypes[box.Type] {
+				tx.Rollback()
+				return nil, fmt.Errorf("honey can not be harvested from a %s box", strings.ToLower(string(box.Type)))
+			}
+		}
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO honey_harvests (user_id, hive_id, family_id, apiary_id, harvested_at, weight_kg, moisture, floral_source, frames)
+		VALUES (:userID, :hiveID, :familyID, :apiaryID, COALESCE(:harvestedAt, NOW()), :weightKg, :moisture, :floralSource, :frames)`,
+		map[string]interface{}{
+			"userID":       r.UserID,
+			"hiveID":       input.HiveID,
+			"familyID":     familyID,
+			"apiaryID":     apiaryID,
+			"harvestedAt":  harvestedAt,
+			"weightKg":     input.WeightKg,
+			"moisture":     input.Moisture,
+			"floralSource": floralSource,
+			"frames":       input.Frames,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	harvestID := strconv.FormatInt(id, 10)
+
+	for _, boxID := range boxIDs {
+		_, err = tx.Exec(`INSERT INTO honey_harvest_boxes (harvest_id, box_id) VALUES (?, ?)`, id, boxID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	harvest := HoneyHarvest{}
+	err = tx.Get(&harvest, `SELECT `+honeyHarvestColumns+` FROM honey_harvests WHERE id=? LIMIT 1`, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	harvest.BoxIDs = boxIDs
+
+	source := "system"
+	details := fmt.Sprintf("%s kg harvested", strconv.FormatFloat(harvest.WeightKg, 'f', -1, 64))
+	if floralSource != nil {
+		details += ", " + *floralSource
+	}
+	dedupeKey := "honey-harvest:" + harvestID
+	err = (&HiveLog{UserID: r.UserID}).CreateTx(tx, HiveLogInput{
+		HiveID:    input.HiveID,
+		Action:    "harvest",
+		Title:     "Honey harvested",
+		Details:   &details,
+		Source:    &source,
+		DedupeKey: &dedupeKey,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, input.HiveID, "honey_harvest", harvestID, "created", harvest)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &harvest, nil
+}
+
+// Delete removes a harvest that is not bottled yet, lots keep tracing their jars to hives
+func (r *HoneyHarvest) Delete(id string) (bool, error) {
+	harvest, err := r.Get(id)
+	if err != nil || harvest == nil {
+		return false, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	// the harvest row lock keeps a concurrent lot from claiming the harvest meanwhile
+	_, err = tx.Exec(`SELECT id FROM honey_harvests WHERE id=? AND user_id=? FOR UPDATE`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	lotNumbers := []string{}
+	err = tx.Select(&lotNumbers,
+		`SELECT l.lot_number
+		FROM honey_lot_harvests lh
+		JOIN honey_lots l ON l.id = lh.lot_id AND l.active=1
+		WHERE lh.harvest_id=? AND l.user_id=?`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if len(lotNumbers) > 0 {
+		tx.Rollback()
+		return false, fmt.Errorf("harvest is in lot %s", lotNumbers[0])
+	}
+
+	_, err = tx.Exec(`UPDATE honey_harvests SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = (&HiveLog{UserID: r.UserID}).DeleteByDedupePrefixTx(tx, "honey-harvest:"+id)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, harvest.HiveID, "honey_harvest", id, "deleted", harvest)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Yield sums harvests by hive, family, apiary or season, biggest yield first, seasons in order.
+// Harvests count for the apiary and family of the hive at harvest time
+func (r *HoneyHarvest) Yield(groupBy YieldGrouping, filter YieldFilter) ([]*YieldTotal, error) {
+	groupColumn, ok := yieldGroupColumns[groupBy]
+	if !ok {
+		return nil, errors.New("invalid yield grouping")
+	}
+
+	condition := ``
+	args := []interface{}{r.UserID}
+	if filter.HiveID != nil {
+		condition += ` AND hive_id=?`
+		args = append(args, *filter.HiveID)
+	}
+	if filter.FamilyID != nil {
+		condition += ` AND family_id=?`
+		args = append(args, *filter.FamilyID)
+	}
+	if filter.ApiaryID != nil {
+		condition += ` AND apiary_id=?`
+		args = append(args, *filter.ApiaryID)
+	}
+	if filter.Season != nil {
+		condition += ` AND YEAR(harvested_at)=?`
+		args = append(args, *filter.Season)
+	}
+
+	order := `total_kg DESC, group_key ASC`
+	if groupBy == YieldGroupingSeason {
+		order = `group_key ASC`
+	}
+
+	list := []*YieldTotal{}
+	err := r.Db.Select(&list,
+		`SELECT CAST(`+groupColumn+` AS CHAR) AS group_key, COUNT(*) AS harvests, SUM(weight_kg) AS total_kg,
+			SUM(moisture * weight_kg) / NULLIF(SUM(CASE WHEN moisture IS NOT NULL THEN weight_kg END), 0) AS average_moisture,
+			MIN(harvested_at) AS first_harvest_at, MAX(harvested_at) AS last_harvest_at
+		FROM honey_harvests
+		WHERE user_id=? AND active=1`+condition+`
+		GROUP BY group_key
+		ORDER BY `+order, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, total := range list {
+		switch groupBy {
+		case YieldGroupingHive:
+			total.HiveID = total.GroupKey
+		case YieldGroupingFamily:
+			total.FamilyID = total.GroupKey
+		case YieldGroupingAPIAry:
+			total.ApiaryID = total.GroupKey
+		case YieldGroupingSeason:
+			if total.GroupKey != nil {
+				season, err := strconv.Atoi(*total.GroupKey)
+				if err != nil {
+					return nil, err
+				}
+				total.Season = &season
+			}
+		}
+	}
+
+	return list, nil
+}
+
+// TotalYield is the harvested weight of the hive in kg, of one season when set
+func (r *HoneyHarvest) TotalYield(hiveID string, season *int) (float64, error) {
+	condition := ``
+	args := []interface{}{r.UserID, hiveID}
+	if season != nil {
+		condition = ` AND YEAR(harvested_at)=?`
+		args = append(args, *season)
+	}
+
+	var total float64
+	err := r.Db.Get(&total,
+		`SELECT COALESCE(SUM(weight_kg), 0)
+		FROM honey_harvests
+		WHERE user_id=? AND hive_id=? AND active=1`+condition, args...)
+
+	return total, err
+}
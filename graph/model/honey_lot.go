@@ -0,0 +1,473 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HoneyLotTraceMonths is how far back before the harvests treatments are traced by default
+const HoneyLotTraceMonths = 6
+
+// HoneyLot is an extraction batch of one or more harvests, jarred under one lot number
+type HoneyLot struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID                   string   `json:"id" db:"id"`
+	LotNumber            string   `json:"lotNumber" db:"lot_number"`
+	ExtractedAt          string   `json:"extractedAt" db:"extracted_at"`
+	JarCount             int      `json:"jarCount" db:"jar_count"`
+	JarSizeG             *int     `json:"jarSizeG" db:"jar_size_g"`
+	Notes                *string  `json:"notes" db:"notes"`
+	WithdrawalOverridden bool     `json:"withdrawalOverridden" db:"withdrawal_overridden"`
+	TotalKg              float64  `json:"totalKg" db:"total_kg"`
+	HarvestIDs           []string `json:"harvestIds" db:"-"`
+}
+
+// LotWithdrawalWarning is a harvest of a lot taken inside the withdrawal period of a treatment or treatment course
+type LotWithdrawalWarning struct {
+	HarvestID         string  `json:"harvestId" db:"harvest_id"`
+	HiveID            string  `json:"hiveId" db:"hive_id"`
+	HarvestedAt       string  `json:"harvestedAt" db:"harvested_at"`
+	TreatmentID       *string `json:"treatmentId" db:"treatment_id"`
+	TreatmentCourseID *string `json:"treatmentCourseId" db:"treatment_course_id"`
+	WithdrawalEndsAt  string  `json:"withdrawalEndsAt" db:"withdrawal_ends_at"`
+}
+
+// HoneyLotSource is a hive and queen family honey of a lot came from
+type HoneyLotSource struct {
+	UserID string `json:"-"`
+
+	HiveID      string  `json:"hiveId" db:"hive_id"`
+	FamilyID    *string `json:"familyId" db:"family_id"`
+	HarvestedKg float64 `json:"harvestedKg" db:"harvested_kg"`
+}
+
+// HoneyLotTrace lists where honey of a lot came from and how the source colonies were treated
+type HoneyLotTrace struct {
+	Lot             *HoneyLot         `json:"lot"`
+	Harvests        []*HoneyHarvest   `json:"harvests"`
+	Sources         []*HoneyLotSource `json:"sources"`
+	TreatmentsSince string            `json:"treatmentsSince"`
+	Treatments      []*Treatment      `json:"treatments"`
+}
+
+const honeyLotSelect = `SELECT l.id, l.user_id, l.lot_number, l.extracted_at, l.jar_count, l.jar_size_g, l.notes, l.withdrawal_overridden,
+		COALESCE((
+			SELECT SUM(hh.weight_kg)
+			FROM honey_lot_harvests lh
+			JOIN honey_harvests hh ON hh.id = lh.harvest_id
+			WHERE lh.lot_id = l.id
+		), 0) AS total_kg
+	FROM honey_lots l`
+
+func (r *HoneyLot) Get(id string) (*HoneyLot, error) {
+	return r.getBy(`l.id=?`, id)
+}
+
+// GetByNumber finds a lot by the number printed on its jars
+func (r *HoneyLot) GetByNumber(lotNumber string) (*HoneyLot, error) {
+	return r.getBy(`l.lot_number=?`, strings.TrimSpace(lotNumber))
+}
+
+func (r *HoneyLot) getBy(condition string, value string) (*HoneyLot, error) {
+	lot := HoneyLot{}
+	err := r.Db.Get(&lot,
+		honeyLotSelect+`
+		WHERE `+condition+` AND l.user_id=? AND l.active=1
+		LIMIT 1`, value, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &lot, r.loadHarvestIDs(&lot)
+}
+
+// List returns lots newest first
+func (r *HoneyLot) List(limit *int) ([]*HoneyLot, error) {
+	count := 50
+	if limit != nil && *limit > 0 && *limit < 500 {
+		count = *limit
+	}
+
+	list := []*HoneyLot{}
+	err := r.Db.Select(&list,
+		honeyLotSelect+`
+		WHERE l.user_id=? AND l.active=1
+		ORDER BY l.extracted_at DESC, l.id DESC
+		LIMIT ?`, r.UserID, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, r.loadHarvestIDs(list...)
+}
+
+func (r *HoneyLot) loadHarvestIDs(lots ...*HoneyLot) error {
+	if len(lots) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(lots))
+	byID := map[string]*HoneyLot{}
+	for _, lot := range lots {
+		lot.HarvestIDs = []string{}
+		ids = append(ids, lot.ID)
+		byID[lot.ID] = lot
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT lot_id, harvest_id FROM honey_lot_harvests WHERE lot_id IN (?) ORDER BY harvest_id ASC`, ids)
+	if err != nil {
+		return err
+	}
+	rows := []struct {
+		LotID     string `db:"lot_id"`
+		HarvestID string `db:"harvest_id"`
+	}{}
+	err = r.Db.Select(&rows, r.Db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if lot, ok := byID[row.LotID]; ok {
+			lot.HarvestIDs = append(lot.HarvestIDs, row.HarvestID)
+		}
+	}
+
+	return nil
+}
+
+// withdrawalConflicts returns harvests taken after a treatment or course of their hive started and before
+// its withdrawal period ended. Course doses are covered by the course, so only standalone treatments are checked
+func withdrawalConflicts(q sqlx.Ext, userID string, harvestIDs []string) ([]*LotWithdrawalWarning, error) {
+	list := []*LotWithdrawalWarning{}
+	if len(harvestIDs) == 0 {
+		return list, nil
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT hh.id AS harvest_id, hh.hive_id, hh.harvested_at, w.treatment_id, w.treatment_course_id, w.withdrawal_ends_at
+		FROM honey_harvests hh
+		JOIN (
+			SELECT t.id AS treatment_id, NULL AS treatment_course_id, t.hive_id,
+				COALESCE(t.start_date, t.added) AS started_at,
+				DATE_ADD(COALESCE(t.end_date, t.start_date, t.added), INTERVAL p.withdrawal_days DAY) AS withdrawal_ends_at
+			FROM treatments t
+			JOIN treatment_products p ON p.id = t.product_id
+			WHERE t.user_id=? AND t.course_id IS NULL
+			UNION ALL
+			SELECT NULL, courses.id, courses.hive_id, courses.started_at, courses.withdrawal_ends_at
+			FROM (`+treatmentCourseSelect+`
+				WHERE c.user_id=? AND c.active=1
+			) courses
+		) w ON w.hive_id = hh.hive_id AND hh.harvested_at >= w.started_at AND hh.harvested_at < w.withdrawal_ends_at
+		WHERE hh.user_id=? AND hh.id IN (?)
+		ORDER BY hh.harvested_at ASC, hh.id ASC, w.withdrawal_ends_at ASC`,
+		userID, userID, userID, userID, harvestIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sqlx.Select(q, &list, q.Rebind(query), args...)
+	return list, err
+}
+
+// WithdrawalWarnings lists harvests of the lot taken inside a treatment withdrawal period
+func (r *HoneyLot) WithdrawalWarnings(lot *HoneyLot) ([]*LotWithdrawalWarning, error) {
+	return withdrawalConflicts(r.Db, r.UserID, lot.HarvestIDs)
+}
+
+func validateHoneyLotInput(input HoneyLotInput) error {
+	if len(input.HarvestIds) == 0 {
+		return errors.New("a lot needs at least one harvest")
+	}
+	if input.LotNumber != nil && len(strings.TrimSpace(*input.LotNumber)) > 50 {
+		return errors.New("lotNumber must be at most 50 characters")
+	}
+	if input.JarCount < 0 || input.JarCount > 100000 {
+		return errors.New("jarCount must be between 0 and 100000")
+	}
+	if input.JarSizeG != nil && (*input.JarSizeG <= 0 || *input.JarSizeG > 50000) {
+		return errors.New("jarSizeG must be more than 0 and at most 50000")
+	}
+
+	return nil
+}
+
+// nextLotNumberTx numbers lots per year of extraction, e.g. 2026-007.
+// The per-year counter row stays locked until tx ends, numbers typed in by hand are skipped
+func (r *HoneyLot) nextLotNumberTx(tx *sqlx.Tx, extractedAt *string) (string, error) {
+	year := time.Now().UTC().Year()
+	if extractedAt != nil {
+		parsed, err := parseDBDateTime(*extractedAt)
+		if err != nil {
+			return "", err
+		}
+		year = parsed.Year()
+	}
+
+	var highest int
+	err := tx.Get(&highest,
+		`SELECT COALESCE(MAX(CAST(SUBSTRING_INDEX(lot_number, '-', -1) AS UNSIGNED)), 0)
+		FROM honey_lots
+		WHERE user_id=? AND lot_number REGEXP ?`,
+		r.UserID, "^"+strconv.Itoa(year)+"-[0-9]+$")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO honey_lot_counters (user_id, year, last_number)
+		VALUES (?, ?, LAST_INSERT_ID(? + 1))
+		ON DUPLICATE KEY UPDATE last_number=LAST_INSERT_ID(GREATEST(last_number, ?) + 1)`,
+		r.UserID, year, highest, highest)
+	if err != nil {
+		return "", err
+	}
+
+	var next int
+	err = tx.Get(&next, `SELECT LAST_INSERT_ID()`)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d-%03d", year, next), nil
+}
+
+// Create jars harvests under one lot number. A harvest belongs to one lot only.
+// Harvests taken inside a treatment withdrawal period block the lot unless input.IgnoreWithdrawal is set
+func (r *HoneyLot) Create(input HoneyLotInput) (*HoneyLot, error) {
+	if err := validateHoneyLotInput(input); err != nil {
+		return nil, err
+	}
+	extractedAt, err := parseOptionalDateTimeInput("extractedAt", input.ExtractedAt)
+	if err != nil {
+		return nil, err
+	}
+	harvestIDs := uniqueStrings(input.HarvestIds)
+	ignoreWithdrawal := input.IgnoreWithdrawal != nil && *input.IgnoreWithdrawal
+
+	tx := r.Db.MustBegin()
+
+	query, args, err := sqlx.In(
+		`SELECT hh.id, l.lot_number
+		FROM honey_harvests hh
+		LEFT JOIN honey_lot_harvests lh ON lh.harvest_id = hh.id
+		LEFT JOIN honey_lots l ON l.id = lh.lot_id AND l.active=1
+		WHERE hh.user_id=? AND hh.active=1 AND hh.id IN (?)
+		FOR UPDATE`, r.UserID, harvestIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	harvests := []struct {
+		ID        string  `db:"id"`
+		LotNumber *string `db:"lot_number"`
+	}{}
+	err = tx.Select(&harvests, tx.Rebind(query), args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	found := map[string]bool{}
+	for _, harvest := range harvests {
+		if harvest.LotNumber != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("harvest %s is already in lot %s", harvest.ID, *harvest.LotNumber)
+		}
+		found[harvest.ID] = true
+	}
+	if len(found) != len(harvestIDs) {
+		tx.Rollback()
+		return nil, errors.New("harvest not found")
+	}
+
+	conflicts, err := withdrawalConflicts(tx, r.UserID, harvestIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(conflicts) > 0 && !ignoreWithdrawal {
+		tx.Rollback()
+		conflict := conflicts[0]
+		return nil, fmt.Errorf("hive %s was harvested on %s inside a treatment withdrawal period ending %s, set ignoreWithdrawal to create the lot anyway",
+			conflict.HiveID, conflict.HarvestedAt, conflict.WithdrawalEndsAt)
+	}
+
+	lotNumber := ""
+	if input.LotNumber != nil {
+		lotNumber = strings.TrimSpace(*input.LotNumber)
+	}
+	if lotNumber == "" {
+		lotNumber, err = r.nextLotNumberTx(tx, extractedAt)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	var taken int
+	err = tx.Get(&taken, `SELECT COUNT(*) FROM honey_lots WHERE user_id=? AND lot_number=?`, r.UserID, lotNumber)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if taken > 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("lot number %s is already used", lotNumber)
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO honey_lots (user_id, lot_number, extracted_at, jar_count, jar_size_g, notes, withdrawal_overridden)
+		VALUES (:userID, :lotNumber, COALESCE(:extractedAt, NOW()), :jarCount, :jarSizeG, :notes, :withdrawalOverridden)`,
+		map[string]interface{}{
+			"userID":               r.UserID,
+			"lotNumber":            lotNumber,
+			"extractedAt":          extractedAt,
+			"jarCount":             input.JarCount,
+			"jarSizeG":             input.JarSizeG,
+			"notes":                input.Notes,
+			"withdrawalOverridden": len(conflicts) > 0,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for _, harvestID := range harvestIDs {
+		_, err = tx.Exec(`INSERT INTO honey_lot_harvests (lot_id, harvest_id) VALUES (?, ?)`, id, harvestID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(strconv.FormatInt(id, 10))
+}
+
+// Delete removes the lot, its harvests can be jarred again. The lot number stays taken
+func (r *HoneyLot) Delete(id string) (bool, error) {
+	tx := r.Db.MustBegin()
+
+	result, err := tx.Exec(`UPDATE honey_lots SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM honey_lot_harvests WHERE lot_id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Trace lists harvests, source hives and families of the lot, and every treatment of those hives and families
+// from months before the first harvest until the last one
+func (r *HoneyLot) Trace(lot *HoneyLot, months int) (*HoneyLotTrace, error) {
+	if months <= 0 || months > 60 {
+		return nil, errors.New("months must be between 1 and 60")
+	}
+
+	trace := &HoneyLotTrace{
+		Lot:        lot,
+		Harvests:   []*HoneyHarvest{},
+		Sources:    []*HoneyLotSource{},
+		Treatments: []*Treatment{},
+	}
+	if len(lot.HarvestIDs) == 0 {
+		trace.TreatmentsSince = lot.ExtractedAt
+		return trace, nil
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT `+honeyHarvestColumns+`
+		FROM honey_harvests
+		WHERE user_id=? AND id IN (?)
+		ORDER BY harvested_at ASC, id ASC`, r.UserID, lot.HarvestIDs)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Db.Select(&trace.Harvests, r.Db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	err = (&HoneyHarvest{Db: r.Db, UserID: r.UserID}).loadBoxIDs(trace.Harvests...)
+	if err != nil {
+		return nil, err
+	}
+	if len(trace.Harvests) == 0 {
+		trace.TreatmentsSince = lot.ExtractedAt
+		return trace, nil
+	}
+
+	query, args, err = sqlx.In(
+		`SELECT hive_id, family_id, SUM(weight_kg) AS harvested_kg
+		FROM honey_harvests
+		WHERE user_id=? AND id IN (?)
+		GROUP BY hive_id, family_id
+		ORDER BY harvested_kg DESC, hive_id ASC`, r.UserID, lot.HarvestIDs)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Db.Select(&trace.Sources, r.Db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	hiveIDs := []string{}
+	familyIDs := []string{}
+	for _, source := range trace.Sources {
+		source.UserID = r.UserID
+		hiveIDs = append(hiveIDs, source.HiveID)
+		if source.FamilyID != nil {
+			familyIDs = append(familyIDs, *source.FamilyID)
+		}
+	}
+
+	firstHarvest, err := parseDBDateTime(trace.Harvests[0].HarvestedAt)
+	if err != nil {
+		return nil, err
+	}
+	lastHarvest, err := parseDBDateTime(trace.Harvests[len(trace.Harvests)-1].HarvestedAt)
+	if err != nil {
+		return nil, err
+	}
+	trace.TreatmentsSince = firstHarvest.AddDate(0, -months, 0).Format(mysqlDateTimeFormat)
+
+	trace.Treatments, err = (&Treatment{Db: r.Db, UserID: r.UserID}).ListByHivesAndFamiliesBetween(
+		uniqueStrings(hiveIDs), uniqueStrings(familyIDs), trace.TreatmentsSince, lastHarvest.Format(mysqlDateTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	return trace, nil
+}
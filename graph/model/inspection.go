@@ -2,7 +2,10 @@ package model
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -14,47 +17,81 @@ type Inspection struct {
 	HiveID string `json:"hiveId" db:"hive_id"`
 	Data   string `json:"data" db:"data"`
 	Added  string `json:"added" db:"added"`
+
+	SchemaVersion int                     `json:"schemaVersion" db:"schema_version"`
+	Observations  *InspectionObservations `json:"observations" db:"-"`
+
+	HiveSnapshotRaw *string       `json:"-" db:"hive_snapshot"`
+	HiveSnapshot    *HiveSnapshot `json:"hiveSnapshot" db:"-"`
 }
 
+const inspectionColumns = `id, user_id, hive_id, data, added, schema_version, hive_snapshot`
+
 func (r *Inspection) Get(ID string) (*Inspection, error) {
 	currentInspection := Inspection{}
 	err := r.Db.Get(&currentInspection,
-		`SELECT *
+		`SELECT `+inspectionColumns+`
 		FROM inspections
-		WHERE id=? AND user_id=?
+		WHERE id=? AND user_id=? AND active=1
 		LIMIT 1`, ID, r.UserID)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
+	if err != nil {
+		return nil, err
+	}
 
-	return &currentInspection, err
+	r.decode(&currentInspection)
+
+	return &currentInspection, nil
 }
 
 func (r *Inspection) GetLatestByHiveId(hiveID string) (*Inspection, error) {
 	currentInspection := Inspection{}
 	err := r.Db.Get(&currentInspection,
-		`SELECT *
+		`SELECT `+inspectionColumns+`
 		FROM inspections
-		WHERE hive_id=? AND user_id=?
+		WHERE hive_id=? AND user_id=? AND active=1
+		ORDER BY added DESC, id DESC
 		LIMIT 1`, hiveID, r.UserID)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
+	if err != nil {
+		return nil, err
+	}
 
-	return &currentInspection, err
+	r.decode(&currentInspection)
+
+	return &currentInspection, nil
 }
 
-func (r *Inspection) ListByHiveId(hiveID string) ([]*Inspection, error) {
+// ListByHiveId lists the newest inspections of a hive, 200 unless limit is set, at most 1000
+func (r *Inspection) ListByHiveId(hiveID string, limit *int) ([]*Inspection, error) {
+	size := 200
+	if limit != nil && *limit > 0 {
+		size = *limit
+		if size > 1000 {
+			size = 1000
+		}
+	}
+
 	list := []*Inspection{}
 	err := r.Db.Select(&list,
-		`SELECT *
+		`SELECT `+inspectionColumns+`
 		FROM inspections
-		WHERE user_id=? AND hive_id=?
-		ORDER BY added DESC`, r.UserID, hiveID)
+		WHERE user_id=? AND hive_id=? AND active=1
+		ORDER BY added DESC, id DESC
+		LIMIT ?`, r.UserID, hiveID, size)
+	if err != nil {
+		return nil, err
+	}
 
-	return list, err
+	r.decode(list...)
+
+	return list, nil
 }
 
 func (r *Inspection) CountByHiveId(hiveID string) (int, error) {
@@ -66,7 +103,7 @@ func (r *Inspection) CountByHiveId(hiveID string) (int, error) {
 	err := r.Db.Select(&result,
 		`SELECT COUNT(*) as count
 		FROM inspections
-		WHERE user_id=? AND hive_id=?
+		WHERE user_id=? AND hive_id=? AND active=1
 		LIMIT 1`, r.UserID, hiveID)
 
 	if len(result) == 0 {
@@ -76,23 +113,347 @@ func (r *Inspection) CountByHiveId(hiveID string) (int, error) {
 	return *result[0].Count, err
 }
 
+// Create validates data as an inspection document and stores it in the current schema version
 func (r *Inspection) Create(data string, hiveID int) (*string, error) {
-	result, err := r.Db.NamedExec(
-		`INSERT INTO inspections (user_id, hive_id, data, added)
-		VALUES (:userID, :hiveID, :data, NOW())`,
-		map[string]interface{}{
-			"userID": r.UserID,
-			"data":   data,
-			"hiveID": hiveID,
-		},
+	doc, err := ParseInspectionDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	data, err = doc.JSON()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	// the structure is read in the same transaction, so the snapshot matches the moment of the inspection
+	snapshot, err := captureHiveSnapshotTx(tx, r.UserID, hiveID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	values := doc.searchColumns()
+	values["userID"] = r.UserID
+	values["data"] = data
+	values["hiveID"] = hiveID
+	values["hiveSnapshot"] = string(snapshotJSON)
+
+	result, err := tx.NamedExec(
+		`INSERT INTO inspections (user_id, hive_id, data, added, schema_version,
+			queen_seen, eggs_seen, queen_cells_seen, temperament, brood_pattern, population, hive_snapshot)
+		VALUES (:userID, :hiveID, :data, NOW(), :schemaVersion,
+			:queenSeen, :eggsSeen, :queenCellsSeen, :temperament, :broodPattern, :population, :hiveSnapshot)`,
+		values,
 	)
 
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
 	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	strId := strconv.Itoa(int(id))
-	return &strId, err
+
+	inspection := Inspection{}
+	err = tx.Get(&inspection, `SELECT `+inspectionColumns+` FROM inspections WHERE id=? LIMIT 1`, id)
+	if err == nil {
+		inspection.Observations = &doc.Observations
+		inspection.HiveSnapshot = snapshot
+		err = recordHiveEventTx(tx, r.UserID, strconv.Itoa(hiveID), "inspection", strId, "created", inspection)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &strId, tx.Commit()
+}
+
+// Update replaces the document of an inspection when data is set and applies observations on top.
+// The hive snapshot and the added date stay as they were recorded.
+func (r *Inspection) Update(id string, data *string, observations *InspectionObservationsInput) error {
+	current, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("inspection not found")
+	}
+
+	source := current.Data
+	if data != nil {
+		source = *data
+	}
+	doc, err := ParseInspectionDocument(source)
+	if err != nil {
+		return err
+	}
+	if err = doc.ApplyInput(observations); err != nil {
+		return err
+	}
+
+	values := doc.searchColumns()
+	values["id"] = id
+	values["userID"] = r.UserID
+	values["data"], err = doc.JSON()
+	if err != nil {
+		return err
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.NamedExec(
+		`UPDATE inspections
+		SET data=:data, schema_version=:schemaVersion, queen_seen=:queenSeen, eggs_seen=:eggsSeen,
+			queen_cells_seen=:queenCellsSeen, temperament=:temperament, brood_pattern=:broodPattern, population=:population
+		WHERE id=:id AND user_id=:userID AND active=1`,
+		values,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	current.Data = values["data"].(string)
+	current.SchemaVersion = InspectionSchemaVersion
+	current.Observations = &doc.Observations
+	err = recordHiveEventTx(tx, r.UserID, current.HiveID, "inspection", id, "updated", current)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deactivates an inspection, it no longer counts as the latest one of the hive
+func (r *Inspection) Delete(id string) (bool, error) {
+	current, err := r.Get(id)
+	if err != nil {
+		return false, err
+	}
+	if current == nil {
+		return false, nil
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.Exec(
+		`UPDATE inspections SET active=0 WHERE id=? AND user_id=? AND active=1`,
+		id, r.UserID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, current.HiveID, "inspection", id, "deleted", current)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// decode sets the typed observations and hive snapshot of inspections read from the database.
+// Rows stored before the current schema version are only upgraded in memory, UpgradeLegacyBatch rewrites them.
+func (r *Inspection) decode(inspections ...*Inspection) {
+	for _, inspection := range inspections {
+		inspection.HiveSnapshot = parseHiveSnapshot(inspection.HiveSnapshotRaw)
+
+		doc, err := ParseInspectionDocument(inspection.Data)
+		if err != nil {
+			// data that never was an object has no observations, it is kept as it is
+			doc = &InspectionDocument{SchemaVersion: InspectionSchemaVersion}
+		}
+		inspection.Observations = &doc.Observations
+	}
+}
+
+// UpgradeLegacyBatch rewrites up to size inspections of any user still stored in an older schema version,
+// so search finds them by their observations. It returns how many rows were read.
+func (r *Inspection) UpgradeLegacyBatch(size int) (int, error) {
+	legacy := []*Inspection{}
+	err := r.Db.Select(&legacy,
+		`SELECT `+inspectionColumns+`
+		FROM inspections
+		WHERE schema_version < ?
+		ORDER BY schema_version, id
+		LIMIT ?`, InspectionSchemaVersion, size)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, inspection := range legacy {
+		doc, err := ParseInspectionDocument(inspection.Data)
+		if err != nil {
+			// data that never was an object is only marked as upgraded
+			doc = &InspectionDocument{SchemaVersion: InspectionSchemaVersion}
+		}
+
+		values := doc.searchColumns()
+		values["data"] = inspection.Data
+		if err == nil {
+			values["data"], err = doc.JSON()
+			if err != nil {
+				return 0, err
+			}
+		}
+		values["id"] = inspection.ID
+		values["userID"] = inspection.UserID
+
+		_, err = r.Db.NamedExec(
+			`UPDATE inspections
+			SET data=:data, schema_version=:schemaVersion, queen_seen=:queenSeen, eggs_seen=:eggsSeen,
+				queen_cells_seen=:queenCellsSeen, temperament=:temperament, brood_pattern=:broodPattern, population=:population
+			WHERE id=:id AND user_id=:userID AND schema_version < :schemaVersion`,
+			values,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(legacy), nil
+}
+
+// Search lists inspections of the user matching the filter, newest first
+func (r *Inspection) Search(filter InspectionSearchFilter) ([]*Inspection, error) {
+	prefix := ""
+	prefixArgs := []interface{}{}
+	conditions := []string{"i.user_id=?", "i.active=1"}
+	args := []interface{}{r.UserID}
+
+	if len(filter.HiveIds) > 0 {
+		query, inArgs, err := sqlx.In("i.hive_id IN (?)", filter.HiveIds)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, query)
+		args = append(args, inArgs...)
+	}
+	if filter.ApiaryID != nil {
+		conditions = append(conditions, "h.apiary_id=?")
+		args = append(args, *filter.ApiaryID)
+	}
+	if filter.AddedAfter != nil {
+		conditions = append(conditions, "i.added>=?")
+		args = append(args, *filter.AddedAfter)
+	}
+	if filter.AddedBefore != nil {
+		conditions = append(conditions, "i.added<?")
+		args = append(args, *filter.AddedBefore)
+	}
+	if filter.QueenSeen != nil {
+		conditions = append(conditions, "i.queen_seen=?")
+		args = append(args, *filter.QueenSeen)
+	}
+	if filter.EggsSeen != nil {
+		conditions = append(conditions, "i.eggs_seen=?")
+		args = append(args, *filter.EggsSeen)
+	}
+	if filter.QueenCellsSeen != nil {
+		conditions = append(conditions, "i.queen_cells_seen=?")
+		args = append(args, *filter.QueenCellsSeen)
+	}
+	if len(filter.Temperament) > 0 {
+		query, inArgs, err := sqlx.In("i.temperament IN (?)", filter.Temperament)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, query)
+		args = append(args, inArgs...)
+	}
+	if len(filter.BroodPattern) > 0 {
+		query, inArgs, err := sqlx.In("i.brood_pattern IN (?)", filter.BroodPattern)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, query)
+		args = append(args, inArgs...)
+	}
+	if len(filter.Population) > 0 {
+		query, inArgs, err := sqlx.In("i.population IN (?)", filter.Population)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, query)
+		args = append(args, inArgs...)
+	}
+	if filter.QueenNotSeenInLast != nil && *filter.QueenNotSeenInLast > 0 {
+		lastN := *filter.QueenNotSeenInLast
+		prefix = `WITH ranked AS (
+			SELECT id, hive_id, queen_seen,
+				ROW_NUMBER() OVER (PARTITION BY hive_id ORDER BY added DESC, id DESC) AS rn
+			FROM inspections
+			WHERE user_id=? AND active=1
+		) `
+		prefixArgs = append(prefixArgs, r.UserID)
+		conditions = append(conditions, `i.id IN (
+			SELECT ranked.id FROM ranked
+			WHERE ranked.rn<=? AND ranked.hive_id IN (
+				SELECT hive_id FROM ranked
+				WHERE rn<=?
+				GROUP BY hive_id
+				HAVING COUNT(*)=? AND SUM(queen_seen=0)=?
+			)
+		)`)
+		args = append(args, lastN, lastN, lastN, lastN)
+	}
+
+	limit := 100
+	if filter.Limit != nil && *filter.Limit > 0 {
+		limit = *filter.Limit
+		if limit > 1000 {
+			limit = 1000
+		}
+	}
+	args = append(args, limit)
+
+	list := []*Inspection{}
+	err := r.Db.Select(&list,
+		prefix+`SELECT i.id, i.user_id, i.hive_id, i.data, i.added, i.schema_version, i.hive_snapshot
+		FROM inspections i
+		JOIN hives h ON h.id=i.hive_id AND h.user_id=i.user_id AND h.active=1
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY i.added DESC, i.id DESC
+		LIMIT ?`,
+		append(prefixArgs, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	r.decode(list...)
+
+	return list, nil
+}
+
+// Compare returns the structural changes of the hive between two of its inspections
+func (r *Inspection) Compare(fromID string, toID string) (*InspectionComparison, error) {
+	from, err := r.Get(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := r.Get(toID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, errors.New("inspection not found")
+	}
+	if from.HiveID != to.HiveID {
+		return nil, errors.New("only inspections of the same hive can be compared")
+	}
+
+	return CompareHiveSnapshots(from, to)
 }
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	inspectionPageSize    = 20
+	inspectionMaxPageSize = 100
+)
+
+// inspectionCursor is the position of an inspection in the (added DESC, id DESC) order
+type inspectionCursor struct {
+	Added string
+	ID    int
+}
+
+func encodeInspectionCursor(inspection *Inspection) string {
+	added := inspection.Added
+	// connections with parseTime scan DATETIME as RFC3339, the cursor is compared in MySQL format
+	if parsed, err := time.Parse(time.RFC3339Nano, added); err == nil {
+		added = parsed.Format(mysqlDateTimeFormat)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(added + "|" + inspection.ID))
+}
+
+func decodeInspectionCursor(cursor string) (*inspectionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid inspection cursor")
+	}
+
+	separator := strings.LastIndex(string(raw), "|")
+	if separator <= 0 {
+		return nil, errors.New("invalid inspection cursor")
+	}
+	id, err := strconv.Atoi(string(raw[separator+1:]))
+	if err != nil {
+		return nil, errors.New("invalid inspection cursor")
+	}
+
+	return &inspectionCursor{Added: string(raw[:separator]), ID: id}, nil
+}
+
+// ConnectionByHiveId returns a page of inspections of the hive, newest first, starting after the given cursor
+func (r *Inspection) ConnectionByHiveId(hiveID string, first *int, after *string) (*InspectionConnection, error) {
+	size := inspectionPageSize
+	if first != nil {
+		if *first < 0 {
+			return nil, errors.New("first must not be negative")
+		}
+		size = *first
+		if size > inspectionMaxPageSize {
+			size = inspectionMaxPageSize
+		}
+	}
+
+	condition := ""
+	args := []interface{}{r.UserID, hiveID}
+	if after != nil && *after != "" {
+		cursor, err := decodeInspectionCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		condition = ` AND (added < ? OR (added = ? AND id < ?))`
+		args = append(args, cursor.Added, cursor.Added, cursor.ID)
+	}
+	// one more row than requested tells whether there is a next page
+	args = append(args, size+1)
+
+	list := []*Inspection{}
+	err := r.Db.Select(&list,
+		`SELECT `+inspectionColumns+`
+		FROM inspections
+		WHERE user_id=? AND hive_id=? AND active=1`+condition+`
+		ORDER BY added DESC, id DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNextPage := len(list) > size
+	if hasNextPage {
+		list = list[:size]
+	}
+	r.decode(list...)
+
+	totalCount, err := r.CountByHiveId(hiveID)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &InspectionConnection{
+		Edges:      make([]*InspectionEdge, 0, len(list)),
+		PageInfo:   &PageInfo{HasNextPage: hasNextPage},
+		TotalCount: totalCount,
+	}
+	for _, inspection := range list {
+		connection.Edges = append(connection.Edges, &InspectionEdge{
+			Cursor: encodeInspectionCursor(inspection),
+			Node:   inspection,
+		})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection, nil
+}
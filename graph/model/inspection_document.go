@@ -0,0 +1,212 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// InspectionSchemaVersion is the current version of the inspection document.
+// Version 0 is the legacy free-form document stored before typed observations existed.
+const InspectionSchemaVersion = 1
+
+// InspectionObservations are the typed colony observations of an inspection document
+type InspectionObservations struct {
+	QueenSeen      *bool                   `json:"queenSeen,omitempty"`
+	EggsSeen       *bool                   `json:"eggsSeen,omitempty"`
+	QueenCellsSeen *bool                   `json:"queenCellsSeen,omitempty"`
+	Temperament    *InspectionTemperament  `json:"temperament,omitempty"`
+	BroodPattern   *InspectionBroodPattern `json:"broodPattern,omitempty"`
+	Population     *InspectionPopulation   `json:"population,omitempty"`
+	Notes          *string                 `json:"notes,omitempty"`
+}
+
+// InspectionDocument is the parsed inspection data. Keys other than schemaVersion and observations
+// (hive snapshot, frame statistics, ...) are kept verbatim.
+type InspectionDocument struct {
+	SchemaVersion int
+	Observations  InspectionObservations
+
+	fields map[string]json.RawMessage
+}
+
+// ParseInspectionDocument reads stored or submitted data, upgrading legacy documents to the current version.
+// Documents that claim a version are validated strictly, legacy ones keep only the observations that are valid.
+func ParseInspectionDocument(data string) (*InspectionDocument, error) {
+	doc := &InspectionDocument{
+		SchemaVersion: InspectionSchemaVersion,
+		fields:        map[string]json.RawMessage{},
+	}
+
+	trimmed := strings.TrimSpace(data)
+	if trimmed == "" || trimmed == "null" {
+		return doc, nil
+	}
+
+	if err := json.Unmarshal([]byte(trimmed), &doc.fields); err != nil {
+		return nil, errors.New("inspection data must be a JSON object")
+	}
+	if doc.fields == nil {
+		doc.fields = map[string]json.RawMessage{}
+	}
+
+	version := 0
+	if rawVersion, ok := doc.fields["schemaVersion"]; ok {
+		if err := json.Unmarshal(rawVersion, &version); err != nil {
+			return nil, errors.New("inspection schemaVersion must be an integer")
+		}
+	}
+	delete(doc.fields, "schemaVersion")
+
+	switch {
+	case version == 0:
+		doc.upgradeLegacy()
+	case version == 1:
+		if err := doc.parseV1(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported inspection schemaVersion %d, latest is %d", version, InspectionSchemaVersion)
+	}
+
+	return doc, nil
+}
+
+func (d *InspectionDocument) parseV1() error {
+	rawObservations, ok := d.fields["observations"]
+	delete(d.fields, "observations")
+	if !ok || string(rawObservations) == "null" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(rawObservations))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&d.Observations); err != nil {
+		return fmt.Errorf("invalid inspection observations: %v", err)
+	}
+
+	return d.Observations.Validate()
+}
+
+// upgradeLegacy picks up observations legacy clients stored as top-level keys,
+// the keys themselves stay in place for clients still reading them
+func (d *InspectionDocument) upgradeLegacy() {
+	legacyBool := func(key string) *bool {
+		var value bool
+		if raw, ok := d.fields[key]; ok && json.Unmarshal(raw, &value) == nil {
+			return &value
+		}
+		return nil
+	}
+	legacyString := func(key string) *string {
+		var value string
+		if raw, ok := d.fields[key]; ok && json.Unmarshal(raw, &value) == nil && value != "" {
+			return &value
+		}
+		return nil
+	}
+
+	d.Observations.QueenSeen = legacyBool("queenSeen")
+	d.Observations.EggsSeen = legacyBool("eggsSeen")
+	d.Observations.QueenCellsSeen = legacyBool("queenCellsSeen")
+	d.Observations.Notes = legacyString("notes")
+
+	if value := legacyString("temperament"); value != nil {
+		temperament := InspectionTemperament(strings.ToUpper(*value))
+		if temperament.IsValid() {
+			d.Observations.Temperament = &temperament
+		}
+	}
+	if value := legacyString("broodPattern"); value != nil {
+		broodPattern := InspectionBroodPattern(strings.ToUpper(*value))
+		if broodPattern.IsValid() {
+			d.Observations.BroodPattern = &broodPattern
+		}
+	}
+	if value := legacyString("population"); value != nil {
+		population := InspectionPopulation(strings.ToUpper(*value))
+		if population.IsValid() {
+			d.Observations.Population = &population
+		}
+	}
+}
+
+func (o *InspectionObservations) Validate() error {
+	if o.Temperament != nil && !o.Temperament.IsValid() {
+		return fmt.Errorf("invalid inspection temperament %q", *o.Temperament)
+	}
+	if o.BroodPattern != nil && !o.BroodPattern.IsValid() {
+		return fmt.Errorf("invalid inspection broodPattern %q", *o.BroodPattern)
+	}
+	if o.Population != nil && !o.Population.IsValid() {
+		return fmt.Errorf("invalid inspection population %q", *o.Population)
+	}
+	if o.Notes != nil && len(*o.Notes) > 10000 {
+		return errors.New("inspection notes must be at most 10000 characters")
+	}
+
+	return nil
+}
+
+// ApplyInput overrides observations with the ones set in input
+func (d *InspectionDocument) ApplyInput(input *InspectionObservationsInput) error {
+	if input == nil {
+		return nil
+	}
+
+	if input.QueenSeen != nil {
+		d.Observations.QueenSeen = input.QueenSeen
+	}
+	if input.EggsSeen != nil {
+		d.Observations.EggsSeen = input.EggsSeen
+	}
+	if input.QueenCellsSeen != nil {
+		d.Observations.QueenCellsSeen = input.QueenCellsSeen
+	}
+	if input.Temperament != nil {
+		d.Observations.Temperament = input.Temperament
+	}
+	if input.BroodPattern != nil {
+		d.Observations.BroodPattern = input.BroodPattern
+	}
+	if input.Population != nil {
+		d.Observations.Population = input.Population
+	}
+	if input.Notes != nil {
+		d.Observations.Notes = input.Notes
+	}
+
+	return d.Observations.Validate()
+}
+
+// JSON serializes the document in the current schema version
+func (d *InspectionDocument) JSON() (string, error) {
+	fields := make(map[string]interface{}, len(d.fields)+2)
+	for key, value := range d.fields {
+		fields[key] = value
+	}
+	fields["schemaVersion"] = InspectionSchemaVersion
+	fields["observations"] = d.Observations
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// searchColumns are the observation values copied to inspections columns for filtering
+func (d *InspectionDocument) searchColumns() map[string]interface{} {
+	return map[string]interface{}{
+		"schemaVersion":  InspectionSchemaVersion,
+		"queenSeen":      d.Observations.QueenSeen,
+		"eggsSeen":       d.Observations.EggsSeen,
+		"queenCellsSeen": d.Observations.QueenCellsSeen,
+		"temperament":    d.Observations.Temperament,
+		"broodPattern":   d.Observations.BroodPattern,
+		"population":     d.Observations.Population,
+	}
+}
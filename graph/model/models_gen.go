@@ -15,10 +15,20 @@ type ApiaryInput struct {
 	Name string `json:"name"`
 	// Apiary mode: fixed location or transported mobile setup
 	Type *ApiaryType `json:"type,omitempty"`
-	// Latitude coordinate as string
+	// Latitude in decimal degrees, -90 to 90. Stored with 6 decimals, set together with lng
 	Lat *string `json:"lat,omitempty"`
-	// Longitude coordinate as string
+	// Longitude in decimal degrees, -180 to 180
 	Lng *string `json:"lng,omitempty"`
+	// For mobile apiaries, when the apiary arrived at new coordinates (now by default)
+	ArrivedAt *string `json:"arrivedAt,omitempty"`
+	// For mobile apiaries, forage or crop of the honey flow at the location, e.g. 'rapeseed'
+	Forage *string `json:"forage,omitempty"`
+}
+
+type ApiaryInvite struct {
+	Member *ApiaryMember `json:"member"`
+	// Secret to accept the invite with, it is returned only once
+	Token string `json:"token"`
 }
 
 // Input for creating or updating an apiary obstacle
@@ -41,6 +51,29 @@ type ApiaryObstacleInput struct {
 	Label *string `json:"label,omitempty"`
 }
 
+type ApiaryRelocationInput struct {
+	// Latitude in decimal degrees, -90 to 90
+	Lat string `json:"lat"`
+	// Longitude in decimal degrees, -180 to 180
+	Lng string `json:"lng"`
+	// When the apiary arrived at the location, now by default. The previous location is departed at the same time
+	ArrivedAt *string `json:"arrivedAt,omitempty"`
+	// Forage or crop of the honey flow at the location, e.g. 'rapeseed'
+	Forage *string `json:"forage,omitempty"`
+}
+
+type ApiaryVarroaSummary struct {
+	// Hives of the apiary with a count in the period
+	HivesCounted           int      `json:"hivesCounted"`
+	AverageInfestationRate *float64 `json:"averageInfestationRate,omitempty"`
+	MaxInfestationRate     *float64 `json:"maxInfestationRate,omitempty"`
+	Threshold              float64  `json:"threshold"`
+	// Hives whose latest infestation rate is above the threshold
+	HivesAboveThreshold []string `json:"hivesAboveThreshold"`
+	// Latest count of each counted hive
+	LatestCounts []*VarroaCount `json:"latestCounts"`
+}
+
 // Input for creating or updating a box in a hive
 type BoxInput struct {
 	ID *string `json:"id,omitempty"`
@@ -55,6 +88,23 @@ type BoxInput struct {
 	Family *FamilyInput `json:"family,omitempty"`
 }
 
+// Notification about a changed entity, data contains the entity state after the change
+type ChangeEvent struct {
+	// Unique id of the event, the same event may be delivered more than once
+	EventID string `json:"eventId"`
+	// Envelope version
+	Version int `json:"version"`
+	// Changed entity kind (hive, box, frame, family, treatment, inspection, apiary, warehouse)
+	Entity   string `json:"entity"`
+	EntityID string `json:"entityId"`
+	// What happened to the entity (created, updated, deleted, moved, split, ...)
+	Action     string  `json:"action"`
+	HiveID     *string `json:"hiveId,omitempty"`
+	ApiaryID   *string `json:"apiaryId,omitempty"`
+	Data       *string `json:"data,omitempty"`
+	OccurredAt string  `json:"occurredAt"`
+}
+
 type DeviceInput struct {
 	// Display name of the device
 	Name string `json:"name"`
@@ -93,6 +143,8 @@ type FamilyInput struct {
 	Added *string `json:"added,omitempty"`
 	// Custom queen marking color (overrides standard year-based color)
 	Color *string `json:"color,omitempty"`
+	// Queen rearing batch a virgin queen emerged from, the batch mother becomes her mother. Only for warehouse queens
+	RearingBatchID *string `json:"rearingBatchId,omitempty"`
 }
 
 // Input for updating frame properties
@@ -160,6 +212,26 @@ type HiveLogUpdateInput struct {
 	RelatedHives []*HiveLogRelatedHiveInput `json:"relatedHives,omitempty"`
 }
 
+type HiveSnapshotBoxMove struct {
+	BoxID        string `json:"boxId"`
+	FromPosition *int   `json:"fromPosition,omitempty"`
+	ToPosition   *int   `json:"toPosition,omitempty"`
+}
+
+type HiveSnapshotFrameMove struct {
+	FrameID      string `json:"frameId"`
+	FromBoxID    string `json:"fromBoxId"`
+	FromPosition *int   `json:"fromPosition,omitempty"`
+	ToBoxID      string `json:"toBoxId"`
+	ToPosition   *int   `json:"toPosition,omitempty"`
+}
+
+type HiveSnapshotFrameTypeChange struct {
+	FrameID  string    `json:"frameId"`
+	FromType FrameType `json:"fromType"`
+	ToType   FrameType `json:"toType"`
+}
+
 // Input for updating existing hive details
 type HiveUpdateInput struct {
 	ID string `json:"id"`
@@ -171,25 +243,234 @@ type HiveUpdateInput struct {
 	Family *FamilyInput `json:"family,omitempty"`
 }
 
+type HoneyLotInput struct {
+	// Generated when empty
+	LotNumber  *string  `json:"lotNumber,omitempty"`
+	HarvestIds []string `json:"harvestIds"`
+	// Defaults to now
+	ExtractedAt *string `json:"extractedAt,omitempty"`
+	JarCount    int     `json:"jarCount"`
+	// Jar size in grams
+	JarSizeG *int    `json:"jarSizeG,omitempty"`
+	Notes    *string `json:"notes,omitempty"`
+	// Create the lot although a harvest was taken inside a treatment withdrawal period
+	IgnoreWithdrawal *bool `json:"ignoreWithdrawal,omitempty"`
+}
+
+// Changes of hive structure from one inspection to another
+type InspectionComparison struct {
+	From         *Inspection        `json:"from"`
+	To           *Inspection        `json:"to"`
+	BoxesAdded   []*HiveSnapshotBox `json:"boxesAdded"`
+	BoxesRemoved []*HiveSnapshotBox `json:"boxesRemoved"`
+	// Boxes that changed position within the hive
+	BoxesMoved    []*HiveSnapshotBoxMove `json:"boxesMoved"`
+	FramesAdded   []*HiveSnapshotFrame   `json:"framesAdded"`
+	FramesRemoved []*HiveSnapshotFrame   `json:"framesRemoved"`
+	// Frames that changed box or position
+	FramesMoved       []*HiveSnapshotFrameMove       `json:"framesMoved"`
+	FramesTypeChanged []*HiveSnapshotFrameTypeChange `json:"framesTypeChanged"`
+	QueensAdded       []*HiveSnapshotFamily          `json:"queensAdded"`
+	QueensRemoved     []*HiveSnapshotFamily          `json:"queensRemoved"`
+	// True when the set of queens differs
+	QueenChanged bool `json:"queenChanged"`
+}
+
+type InspectionConnection struct {
+	Edges    []*InspectionEdge `json:"edges"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+	// Number of inspections of the hive
+	TotalCount int `json:"totalCount"`
+}
+
+type InspectionEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   *Inspection `json:"node"`
+}
+
 type InspectionInput struct {
-	HiveID int    `json:"hiveId"`
-	Data   string `json:"data"`
+	HiveID int `json:"hiveId"`
+	// Inspection document. Objects with schemaVersion are validated against that version,
+	// objects without it are upgraded from the legacy format
+	Data string `json:"data"`
+	// Typed observations, override the observations inside data
+	Observations *InspectionObservationsInput `json:"observations,omitempty"`
+}
+
+type InspectionObservationsInput struct {
+	QueenSeen      *bool                   `json:"queenSeen,omitempty"`
+	EggsSeen       *bool                   `json:"eggsSeen,omitempty"`
+	QueenCellsSeen *bool                   `json:"queenCellsSeen,omitempty"`
+	Temperament    *InspectionTemperament  `json:"temperament,omitempty"`
+	BroodPattern   *InspectionBroodPattern `json:"broodPattern,omitempty"`
+	Population     *InspectionPopulation   `json:"population,omitempty"`
+	Notes          *string                 `json:"notes,omitempty"`
+}
+
+type InspectionSearchFilter struct {
+	// Limit to these hives, all hives of the user otherwise
+	HiveIds        []string                 `json:"hiveIds,omitempty"`
+	ApiaryID       *string                  `json:"apiaryId,omitempty"`
+	AddedAfter     *string                  `json:"addedAfter,omitempty"`
+	AddedBefore    *string                  `json:"addedBefore,omitempty"`
+	QueenSeen      *bool                    `json:"queenSeen,omitempty"`
+	EggsSeen       *bool                    `json:"eggsSeen,omitempty"`
+	QueenCellsSeen *bool                    `json:"queenCellsSeen,omitempty"`
+	Temperament    []InspectionTemperament  `json:"temperament,omitempty"`
+	BroodPattern   []InspectionBroodPattern `json:"broodPattern,omitempty"`
+	Population     []InspectionPopulation   `json:"population,omitempty"`
+	// Only the latest N inspections of hives where the queen was recorded as not seen in each of them
+	QueenNotSeenInLast *int `json:"queenNotSeenInLast,omitempty"`
+	// Defaults to 100, at most 1000
+	Limit *int `json:"limit,omitempty"`
+}
+
+type InspectionUpdateInput struct {
+	// Replaces the inspection document, validated like in addInspection
+	Data *string `json:"data,omitempty"`
+	// Typed observations, override the observations inside data
+	Observations *InspectionObservationsInput `json:"observations,omitempty"`
 }
 
 // The mutation type, represents all updates we can make to our data
 type Mutation struct {
 }
 
+// Relay-style pagination state
+type PageInfo struct {
+	HasNextPage bool `json:"hasNextPage"`
+	// Cursor of the last edge, pass it as after to get the next page
+	EndCursor *string `json:"endCursor,omitempty"`
+}
+
+// Queen in an ancestry tree with her mother and drone source colony
+type PedigreeNode struct {
+	Family *Family `json:"family"`
+	// 0 for the queen the tree starts from, 1 for her mother and drone source, and so on
+	Generation  int           `json:"generation"`
+	Mother      *PedigreeNode `json:"mother,omitempty"`
+	DroneSource *PedigreeNode `json:"droneSource,omitempty"`
+}
+
+type PollinationContractInput struct {
+	// Mobile apiary whose hives are rented out
+	ApiaryID      string  `json:"apiaryId"`
+	Grower        string  `json:"grower"`
+	GrowerContact *string `json:"growerContact,omitempty"`
+	// Crop to pollinate, e.g. 'blueberry'
+	Crop      string  `json:"crop"`
+	FieldName *string `json:"fieldName,omitempty"`
+	// Latitude of the field in decimal degrees, set together with fieldLng
+	FieldLat      *string               `json:"fieldLat,omitempty"`
+	FieldLng      *string               `json:"fieldLng,omitempty"`
+	RequiredHives int                   `json:"requiredHives"`
+	MinStrength   *InspectionPopulation `json:"minStrength,omitempty"`
+	StartsAt      string                `json:"startsAt"`
+	EndsAt        string                `json:"endsAt"`
+	Fee           *float64              `json:"fee,omitempty"`
+	FeeCurrency   *string               `json:"feeCurrency,omitempty"`
+	Notes         *string               `json:"notes,omitempty"`
+}
+
+type QueenEventInput struct {
+	Type QueenEventType `json:"type"`
+	// Defaults to now
+	Date  *string `json:"date,omitempty"`
+	Notes *string `json:"notes,omitempty"`
+}
+
+type QueenPedigreeInput struct {
+	MotherID      *string     `json:"motherId,omitempty"`
+	MatingType    *MatingType `json:"matingType,omitempty"`
+	MatingStation *string     `json:"matingStation,omitempty"`
+	DroneSourceID *string     `json:"droneSourceId,omitempty"`
+	DroneOrigin   *string     `json:"droneOrigin,omitempty"`
+}
+
+type QueenRatingInput struct {
+	// Inspection of the hive the queen is in, sets ratedAt to the inspection date when date is empty
+	InspectionID *string `json:"inspectionId,omitempty"`
+	// Defaults to now
+	Date            *string `json:"date,omitempty"`
+	Temperament     *int    `json:"temperament,omitempty"`
+	Calmness        *int    `json:"calmness,omitempty"`
+	Swarming        *int    `json:"swarming,omitempty"`
+	Hygiene         *int    `json:"hygiene,omitempty"`
+	HoneyYield      *int    `json:"honeyYield,omitempty"`
+	VarroaTolerance *int    `json:"varroaTolerance,omitempty"`
+	Notes           *string `json:"notes,omitempty"`
+}
+
+// Weights of the traits in the ranking score, 1 for each trait by default. A weight of 0 leaves the trait out
+type QueenRatingWeights struct {
+	Temperament     *float64 `json:"temperament,omitempty"`
+	Calmness        *float64 `json:"calmness,omitempty"`
+	Swarming        *float64 `json:"swarming,omitempty"`
+	Hygiene         *float64 `json:"hygiene,omitempty"`
+	HoneyYield      *float64 `json:"honeyYield,omitempty"`
+	VarroaTolerance *float64 `json:"varroaTolerance,omitempty"`
+}
+
+type QueenRearingBatchInput struct {
+	MotherID string `json:"motherId"`
+	// Defaults to now
+	GraftedAt    *string `json:"graftedAt,omitempty"`
+	CellsGrafted int     `json:"cellsGrafted"`
+	Notes        *string `json:"notes,omitempty"`
+}
+
+type QueenRearingProgressInput struct {
+	CellsAccepted *int    `json:"cellsAccepted,omitempty"`
+	CellsCapped   *int    `json:"cellsCapped,omitempty"`
+	CellsEmerged  *int    `json:"cellsEmerged,omitempty"`
+	Notes         *string `json:"notes,omitempty"`
+}
+
 // The query type, represents all of the entry points into our object graph
 type Query struct {
 }
 
+// Real-time updates delivered over graphql-ws WebSocket transport
+type Subscription struct {
+}
+
+type TreatmentCourseInput struct {
+	HiveID              string `json:"hiveId"`
+	ProductID           string `json:"productId"`
+	PlannedApplications int    `json:"plannedApplications"`
+	// Days between applications
+	IntervalDays int `json:"intervalDays"`
+	// First application date, defaults to now
+	StartedAt      *string `json:"startedAt,omitempty"`
+	HoneySupersOff *bool   `json:"honeySupersOff,omitempty"`
+}
+
+type TreatmentEfficacy struct {
+	Treatment *Treatment   `json:"treatment"`
+	Before    *VarroaCount `json:"before,omitempty"`
+	After     *VarroaCount `json:"after,omitempty"`
+	// How much the infestation dropped, in percent. Null unless both counts use comparable methods
+	ReductionPercent *float64 `json:"reductionPercent,omitempty"`
+}
+
 // Input for treating a specific box with anti-varroa medication
 type TreatmentOfBoxInput struct {
 	HiveID string `json:"hiveId"`
 	BoxID  string `json:"boxId"`
 	// Type of treatment (e.g., 'oxalic_acid', 'formic_acid', 'amitraz')
 	Type string `json:"type"`
+	// Catalog product, defaults to the product of the course
+	ProductID *string `json:"productId,omitempty"`
+	// Course this dose belongs to
+	CourseID *string `json:"courseId,omitempty"`
+	// Amount in the dosage unit of the product, defaults to the product default dose
+	Dose *float64 `json:"dose,omitempty"`
+	// When the treatment was applied, defaults to now
+	StartDate *string `json:"startDate,omitempty"`
+	// When the treatment was removed, for strips and evaporators
+	EndDate *string `json:"endDate,omitempty"`
+	// Honey supers were taken off for the treatment, defaults to the course setting
+	HoneySupersOff *bool `json:"honeySupersOff,omitempty"`
 }
 
 // Input for treating entire hive with anti-varroa medication
@@ -197,6 +478,167 @@ type TreatmentOfHiveInput struct {
 	HiveID string `json:"hiveId"`
 	// Type of treatment (e.g., 'oxalic_acid', 'formic_acid', 'amitraz')
 	Type string `json:"type"`
+	// Catalog product, defaults to the product of the course
+	ProductID *string `json:"productId,omitempty"`
+	// Course this dose belongs to
+	CourseID *string `json:"courseId,omitempty"`
+	// Amount in the dosage unit of the product, defaults to the product default dose
+	Dose *float64 `json:"dose,omitempty"`
+	// When the treatment was applied, defaults to now
+	StartDate *string `json:"startDate,omitempty"`
+	// When the treatment was removed, for strips and evaporators
+	EndDate *string `json:"endDate,omitempty"`
+	// Honey supers were taken off for the treatment, defaults to the course setting
+	HoneySupersOff *bool `json:"honeySupersOff,omitempty"`
+}
+
+type TreatmentProductInput struct {
+	Name             string              `json:"name"`
+	ActiveIngredient *string             `json:"activeIngredient,omitempty"`
+	DosageUnit       TreatmentDosageUnit `json:"dosageUnit"`
+	DefaultDose      *float64            `json:"defaultDose,omitempty"`
+	WithdrawalDays   int                 `json:"withdrawalDays"`
+}
+
+type VarroaCountInput struct {
+	HiveID string            `json:"hiveId"`
+	Method VarroaCountMethod `json:"method"`
+	// Bees in the sample (300 is about half a cup), or days the sticky board was in
+	SampleSize int `json:"sampleSize"`
+	MitesFound int `json:"mitesFound"`
+	// Defaults to now
+	CountedAt *string `json:"countedAt,omitempty"`
+	Notes     *string `json:"notes,omitempty"`
+	// Treatment of the hive this count was done before or after
+	TreatmentID *string `json:"treatmentId,omitempty"`
+	// Required with treatmentId
+	TreatmentPhase *VarroaCountTreatmentPhase `json:"treatmentPhase,omitempty"`
+}
+
+type VarroaTrend struct {
+	// Counts of the period, oldest first
+	Points                []*VarroaCount `json:"points"`
+	LatestInfestationRate *float64       `json:"latestInfestationRate,omitempty"`
+	// Change of the infestation rate per week, fitted over the bee sample counts of the period
+	WeeklyChange *float64 `json:"weeklyChange,omitempty"`
+	// Null with less than two bee sample counts
+	Direction *VarroaTrendDirection `json:"direction,omitempty"`
+}
+
+type ApiaryMemberStatus string
+
+const (
+	ApiaryMemberStatusPending  ApiaryMemberStatus = "PENDING"
+	ApiaryMemberStatusAccepted ApiaryMemberStatus = "ACCEPTED"
+	ApiaryMemberStatusRevoked  ApiaryMemberStatus = "REVOKED"
+)
+
+var AllApiaryMemberStatus = []ApiaryMemberStatus{
+	ApiaryMemberStatusPending,
+	ApiaryMemberStatusAccepted,
+	ApiaryMemberStatusRevoked,
+}
+
+func (e ApiaryMemberStatus) IsValid() bool {
+	switch e {
+	case ApiaryMemberStatusPending, ApiaryMemberStatusAccepted, ApiaryMemberStatusRevoked:
+		return true
+	}
+	return false
+}
+
+func (e ApiaryMemberStatus) String() string {
+	return string(e)
+}
+
+func (e *ApiaryMemberStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ApiaryMemberStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ApiaryMemberStatus", str)
+	}
+	return nil
+}
+
+func (e ApiaryMemberStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ApiaryMemberStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ApiaryMemberStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ApiaryRole string
+
+const (
+	ApiaryRoleOwner ApiaryRole = "OWNER"
+	// Can change hives, boxes, frames, queens, inspections and treatments
+	ApiaryRoleEditor ApiaryRole = "EDITOR"
+	// Can only read
+	ApiaryRoleViewer ApiaryRole = "VIEWER"
+)
+
+var AllApiaryRole = []ApiaryRole{
+	ApiaryRoleOwner,
+	ApiaryRoleEditor,
+	ApiaryRoleViewer,
+}
+
+func (e ApiaryRole) IsValid() bool {
+	switch e {
+	case ApiaryRoleOwner, ApiaryRoleEditor, ApiaryRoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e ApiaryRole) String() string {
+	return string(e)
+}
+
+func (e *ApiaryRole) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ApiaryRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ApiaryRole", str)
+	}
+	return nil
+}
+
+func (e ApiaryRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ApiaryRole) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ApiaryRole) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
 
 // Box types with different heights and purposes
@@ -333,6 +775,192 @@ func (e DeviceType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type FamilyHistoryKind string
+
+const (
+	FamilyHistoryKindEvent     FamilyHistoryKind = "EVENT"
+	FamilyHistoryKindMove      FamilyHistoryKind = "MOVE"
+	FamilyHistoryKindTreatment FamilyHistoryKind = "TREATMENT"
+)
+
+var AllFamilyHistoryKind = []FamilyHistoryKind{
+	FamilyHistoryKindEvent,
+	FamilyHistoryKindMove,
+	FamilyHistoryKindTreatment,
+}
+
+func (e FamilyHistoryKind) IsValid() bool {
+	switch e {
+	case FamilyHistoryKindEvent, FamilyHistoryKindMove, FamilyHistoryKindTreatment:
+		return true
+	}
+	return false
+}
+
+func (e FamilyHistoryKind) String() string {
+	return string(e)
+}
+
+func (e *FamilyHistoryKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FamilyHistoryKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FamilyHistoryKind", str)
+	}
+	return nil
+}
+
+func (e FamilyHistoryKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FamilyHistoryKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FamilyHistoryKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type FeedType string
+
+const (
+	// Sugar and water 1:1 by weight, stimulates brood rearing
+	FeedTypeSyrup1_1 FeedType = "SYRUP_1_1"
+	// Sugar and water 2:1 by weight, for winter stores
+	FeedTypeSyrup2_1 FeedType = "SYRUP_2_1"
+	// Ready-made inverted sugar syrup
+	FeedTypeInvertSyrup FeedType = "INVERT_SYRUP"
+	FeedTypeFondant     FeedType = "FONDANT"
+	FeedTypeDrySugar    FeedType = "DRY_SUGAR"
+	FeedTypePollenPatty FeedType = "POLLEN_PATTY"
+	// Protein feed made without pollen
+	FeedTypePollenSubstitute FeedType = "POLLEN_SUBSTITUTE"
+)
+
+var AllFeedType = []FeedType{
+	FeedTypeSyrup1_1,
+	FeedTypeSyrup2_1,
+	FeedTypeInvertSyrup,
+	FeedTypeFondant,
+	FeedTypeDrySugar,
+	FeedTypePollenPatty,
+	FeedTypePollenSubstitute,
+}
+
+func (e FeedType) IsValid() bool {
+	switch e {
+	case FeedTypeSyrup1_1, FeedTypeSyrup2_1, FeedTypeInvertSyrup, FeedTypeFondant, FeedTypeDrySugar, FeedTypePollenPatty, FeedTypePollenSubstitute:
+		return true
+	}
+	return false
+}
+
+func (e FeedType) String() string {
+	return string(e)
+}
+
+func (e *FeedType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FeedType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FeedType", str)
+	}
+	return nil
+}
+
+func (e FeedType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FeedType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FeedType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type FeedUnit string
+
+const (
+	FeedUnitKg FeedUnit = "KG"
+	FeedUnitG  FeedUnit = "G"
+	// Liters, for syrups only
+	FeedUnitL  FeedUnit = "L"
+	FeedUnitMl FeedUnit = "ML"
+)
+
+var AllFeedUnit = []FeedUnit{
+	FeedUnitKg,
+	FeedUnitG,
+	FeedUnitL,
+	FeedUnitMl,
+}
+
+func (e FeedUnit) IsValid() bool {
+	switch e {
+	case FeedUnitKg, FeedUnitG, FeedUnitL, FeedUnitMl:
+		return true
+	}
+	return false
+}
+
+func (e FeedUnit) String() string {
+	return string(e)
+}
+
+func (e *FeedUnit) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FeedUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FeedUnit", str)
+	}
+	return nil
+}
+
+func (e FeedUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FeedUnit) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FeedUnit) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 // Frame content types indicating what's inside the frame
 type FrameType string
 
@@ -521,51 +1149,52 @@ func (e HiveType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// Shape types for apiary obstacles
-type ObstacleType string
+type InspectionBroodPattern string
 
 const (
-	// Circular obstacle (tree, well, etc.)
-	ObstacleTypeCircle ObstacleType = "CIRCLE"
-	// Rectangular obstacle (building, fence, etc.)
-	ObstacleTypeRectangle ObstacleType = "RECTANGLE"
+	InspectionBroodPatternSolid  InspectionBroodPattern = "SOLID"
+	InspectionBroodPatternPatchy InspectionBroodPattern = "PATCHY"
+	InspectionBroodPatternSpotty InspectionBroodPattern = "SPOTTY"
+	InspectionBroodPatternNone   InspectionBroodPattern = "NONE"
 )
 
-var AllObstacleType = []ObstacleType{
-	ObstacleTypeCircle,
-	ObstacleTypeRectangle,
+var AllInspectionBroodPattern = []InspectionBroodPattern{
+	InspectionBroodPatternSolid,
+	InspectionBroodPatternPatchy,
+	InspectionBroodPatternSpotty,
+	InspectionBroodPatternNone,
 }
 
-func (e ObstacleType) IsValid() bool {
+func (e InspectionBroodPattern) IsValid() bool {
 	switch e {
-	case ObstacleTypeCircle, ObstacleTypeRectangle:
+	case InspectionBroodPatternSolid, InspectionBroodPatternPatchy, InspectionBroodPatternSpotty, InspectionBroodPatternNone:
 		return true
 	}
 	return false
 }
 
-func (e ObstacleType) String() string {
+func (e InspectionBroodPattern) String() string {
 	return string(e)
 }
 
-func (e *ObstacleType) UnmarshalGQL(v any) error {
+func (e *InspectionBroodPattern) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = ObstacleType(str)
+	*e = InspectionBroodPattern(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid ObstacleType", str)
+		return fmt.Errorf("%s is not a valid InspectionBroodPattern", str)
 	}
 	return nil
 }
 
-func (e ObstacleType) MarshalGQL(w io.Writer) {
+func (e InspectionBroodPattern) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *ObstacleType) UnmarshalJSON(b []byte) error {
+func (e *InspectionBroodPattern) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -573,21 +1202,528 @@ func (e *ObstacleType) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e ObstacleType) MarshalJSON() ([]byte, error) {
+func (e InspectionBroodPattern) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type RoofStyle string
+type InspectionPopulation string
 
 const (
-	RoofStyleFlat    RoofStyle = "FLAT"
-	RoofStyleAngular RoofStyle = "ANGULAR"
+	InspectionPopulationWeak   InspectionPopulation = "WEAK"
+	InspectionPopulationMedium InspectionPopulation = "MEDIUM"
+	InspectionPopulationStrong InspectionPopulation = "STRONG"
 )
 
-var AllRoofStyle = []RoofStyle{
-	RoofStyleFlat,
+var AllInspectionPopulation = []InspectionPopulation{
+	InspectionPopulationWeak,
+	InspectionPopulationMedium,
+	InspectionPopulationStrong,
+}
+
+func (e InspectionPopulation) IsValid() bool {
+	switch e {
+	case InspectionPopulationWeak, InspectionPopulationMedium, InspectionPopulationStrong:
+		return true
+	}
+	return false
+}
+
+func (e InspectionPopulation) String() string {
+	return string(e)
+}
+
+func (e *InspectionPopulation) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = InspectionPopulation(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid InspectionPopulation", str)
+	}
+	return nil
+}
+
+func (e InspectionPopulation) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *InspectionPopulation) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e InspectionPopulation) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type InspectionTemperament string
+
+const (
+	InspectionTemperamentCalm       InspectionTemperament = "CALM"
+	InspectionTemperamentNervous    InspectionTemperament = "NERVOUS"
+	InspectionTemperamentAggressive InspectionTemperament = "AGGRESSIVE"
+)
+
+var AllInspectionTemperament = []InspectionTemperament{
+	InspectionTemperamentCalm,
+	InspectionTemperamentNervous,
+	InspectionTemperamentAggressive,
+}
+
+func (e InspectionTemperament) IsValid() bool {
+	switch e {
+	case InspectionTemperamentCalm, InspectionTemperamentNervous, InspectionTemperamentAggressive:
+		return true
+	}
+	return false
+}
+
+func (e InspectionTemperament) String() string {
+	return string(e)
+}
+
+func (e *InspectionTemperament) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = InspectionTemperament(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid InspectionTemperament", str)
+	}
+	return nil
+}
+
+func (e InspectionTemperament) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *InspectionTemperament) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e InspectionTemperament) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MatingType string
+
+const (
+	// Mated freely with local drones
+	MatingTypeOpen MatingType = "OPEN"
+	// Mated at an isolated mating station with selected drones
+	MatingTypeMatingStation            MatingType = "MATING_STATION"
+	MatingTypeInstrumentalInsemination MatingType = "INSTRUMENTAL_INSEMINATION"
+)
+
+var AllMatingType = []MatingType{
+	MatingTypeOpen,
+	MatingTypeMatingStation,
+	MatingTypeInstrumentalInsemination,
+}
+
+func (e MatingType) IsValid() bool {
+	switch e {
+	case MatingTypeOpen, MatingTypeMatingStation, MatingTypeInstrumentalInsemination:
+		return true
+	}
+	return false
+}
+
+func (e MatingType) String() string {
+	return string(e)
+}
+
+func (e *MatingType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MatingType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MatingType", str)
+	}
+	return nil
+}
+
+func (e MatingType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MatingType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MatingType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Shape types for apiary obstacles
+type ObstacleType string
+
+const (
+	// Circular obstacle (tree, well, etc.)
+	ObstacleTypeCircle ObstacleType = "CIRCLE"
+	// Rectangular obstacle (building, fence, etc.)
+	ObstacleTypeRectangle ObstacleType = "RECTANGLE"
+)
+
+var AllObstacleType = []ObstacleType{
+	ObstacleTypeCircle,
+	ObstacleTypeRectangle,
+}
+
+func (e ObstacleType) IsValid() bool {
+	switch e {
+	case ObstacleTypeCircle, ObstacleTypeRectangle:
+		return true
+	}
+	return false
+}
+
+func (e ObstacleType) String() string {
+	return string(e)
+}
+
+func (e *ObstacleType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ObstacleType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ObstacleType", str)
+	}
+	return nil
+}
+
+func (e ObstacleType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ObstacleType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ObstacleType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PollinationContractStatus string
+
+const (
+	// Hives are not due in the field yet
+	PollinationContractStatusPlanned PollinationContractStatus = "PLANNED"
+	// Contract started but the hives were not placed
+	PollinationContractStatusOverduePlacement PollinationContractStatus = "OVERDUE_PLACEMENT"
+	PollinationContractStatusPlaced           PollinationContractStatus = "PLACED"
+	// Contract ended but the hives were not taken back
+	PollinationContractStatusOverdueRemoval PollinationContractStatus = "OVERDUE_REMOVAL"
+	PollinationContractStatusCompleted      PollinationContractStatus = "COMPLETED"
+)
+
+var AllPollinationContractStatus = []PollinationContractStatus{
+	PollinationContractStatusPlanned,
+	PollinationContractStatusOverduePlacement,
+	PollinationContractStatusPlaced,
+	PollinationContractStatusOverdueRemoval,
+	PollinationContractStatusCompleted,
+}
+
+func (e PollinationContractStatus) IsValid() bool {
+	switch e {
+	case PollinationContractStatusPlanned, PollinationContractStatusOverduePlacement, PollinationContractStatusPlaced, PollinationContractStatusOverdueRemoval, PollinationContractStatusCompleted:
+		return true
+	}
+	return false
+}
+
+func (e PollinationContractStatus) String() string {
+	return string(e)
+}
+
+func (e *PollinationContractStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PollinationContractStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PollinationContractStatus", str)
+	}
+	return nil
+}
+
+func (e PollinationContractStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PollinationContractStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PollinationContractStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type QueenEventType string
+
+const (
+	// Virgin queen emerged from her cell
+	QueenEventTypeEmerged QueenEventType = "EMERGED"
+	// Queen put into an introduction cage
+	QueenEventTypeCaged QueenEventType = "CAGED"
+	// Caged queen put into a colony
+	QueenEventTypeIntroduced QueenEventType = "INTRODUCED"
+	// Queen released from the cage
+	QueenEventTypeReleased QueenEventType = "RELEASED"
+	// Colony accepted the queen
+	QueenEventTypeAccepted QueenEventType = "ACCEPTED"
+	// Colony killed or balled the queen
+	QueenEventTypeRejected QueenEventType = "REJECTED"
+	// Queen returned from mating flights
+	QueenEventTypeMated QueenEventType = "MATED"
+	// Queen seen laying eggs
+	QueenEventTypeLaying QueenEventType = "LAYING"
+	// Wing clipped
+	QueenEventTypeClipped QueenEventType = "CLIPPED"
+	QueenEventTypeMarked  QueenEventType = "MARKED"
+	// Replaced by a daughter raised by the colony
+	QueenEventTypeSuperseded QueenEventType = "SUPERSEDED"
+	// Queen left with a swarm
+	QueenEventTypeSwarmed QueenEventType = "SWARMED"
+	// Queen missing or dead
+	QueenEventTypeLost QueenEventType = "LOST"
+)
+
+var AllQueenEventType = []QueenEventType{
+	QueenEventTypeEmerged,
+	QueenEventTypeCaged,
+	QueenEventTypeIntroduced,
+	QueenEventTypeReleased,
+	QueenEventTypeAccepted,
+	QueenEventTypeRejected,
+	QueenEventTypeMated,
+	QueenEventTypeLaying,
+	QueenEventTypeClipped,
+	QueenEventTypeMarked,
+	QueenEventTypeSuperseded,
+	QueenEventTypeSwarmed,
+	QueenEventTypeLost,
+}
+
+func (e QueenEventType) IsValid() bool {
+	switch e {
+	case QueenEventTypeEmerged, QueenEventTypeCaged, QueenEventTypeIntroduced, QueenEventTypeReleased, QueenEventTypeAccepted, QueenEventTypeRejected, QueenEventTypeMated, QueenEventTypeLaying, QueenEventTypeClipped, QueenEventTypeMarked, QueenEventTypeSuperseded, QueenEventTypeSwarmed, QueenEventTypeLost:
+		return true
+	}
+	return false
+}
+
+func (e QueenEventType) String() string {
+	return string(e)
+}
+
+func (e *QueenEventType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QueenEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QueenEventType", str)
+	}
+	return nil
+}
+
+func (e QueenEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *QueenEventType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e QueenEventType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type QueenRearingMilestoneType string
+
+const (
+	QueenRearingMilestoneTypeGraft QueenRearingMilestoneType = "GRAFT"
+	// Cells are capped about 5 days after grafting
+	QueenRearingMilestoneTypeCapping QueenRearingMilestoneType = "CAPPING"
+	// Virgin queens emerge about 12 days after grafting
+	QueenRearingMilestoneTypeEmergence QueenRearingMilestoneType = "EMERGENCE"
+	// Mated queens are laying about 26 days after grafting
+	QueenRearingMilestoneTypeMatingCheck QueenRearingMilestoneType = "MATING_CHECK"
+)
+
+var AllQueenRearingMilestoneType = []QueenRearingMilestoneType{
+	QueenRearingMilestoneTypeGraft,
+	QueenRearingMilestoneTypeCapping,
+	QueenRearingMilestoneTypeEmergence,
+	QueenRearingMilestoneTypeMatingCheck,
+}
+
+func (e QueenRearingMilestoneType) IsValid() bool {
+	switch e {
+	case QueenRearingMilestoneTypeGraft, QueenRearingMilestoneTypeCapping, QueenRearingMilestoneTypeEmergence, QueenRearingMilestoneTypeMatingCheck:
+		return true
+	}
+	return false
+}
+
+func (e QueenRearingMilestoneType) String() string {
+	return string(e)
+}
+
+func (e *QueenRearingMilestoneType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QueenRearingMilestoneType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QueenRearingMilestoneType", str)
+	}
+	return nil
+}
+
+func (e QueenRearingMilestoneType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *QueenRearingMilestoneType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e QueenRearingMilestoneType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type QueenStatus string
+
+const (
+	QueenStatusVirgin     QueenStatus = "VIRGIN"
+	QueenStatusMated      QueenStatus = "MATED"
+	QueenStatusLaying     QueenStatus = "LAYING"
+	QueenStatusLost       QueenStatus = "LOST"
+	QueenStatusSuperseded QueenStatus = "SUPERSEDED"
+)
+
+var AllQueenStatus = []QueenStatus{
+	QueenStatusVirgin,
+	QueenStatusMated,
+	QueenStatusLaying,
+	QueenStatusLost,
+	QueenStatusSuperseded,
+}
+
+func (e QueenStatus) IsValid() bool {
+	switch e {
+	case QueenStatusVirgin, QueenStatusMated, QueenStatusLaying, QueenStatusLost, QueenStatusSuperseded:
+		return true
+	}
+	return false
+}
+
+func (e QueenStatus) String() string {
+	return string(e)
+}
+
+func (e *QueenStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QueenStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QueenStatus", str)
+	}
+	return nil
+}
+
+func (e QueenStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *QueenStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e QueenStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type RoofStyle string
+
+const (
+	RoofStyleFlat    RoofStyle = "FLAT"
+	RoofStyleAngular RoofStyle = "ANGULAR"
+)
+
+var AllRoofStyle = []RoofStyle{
+	RoofStyleFlat,
 	RoofStyleAngular,
 }
 
@@ -689,6 +1825,237 @@ func (e SortOrder) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type TreatmentDosageUnit string
+
+const (
+	TreatmentDosageUnitMl    TreatmentDosageUnit = "ML"
+	TreatmentDosageUnitG     TreatmentDosageUnit = "G"
+	TreatmentDosageUnitStrip TreatmentDosageUnit = "STRIP"
+	TreatmentDosageUnitPiece TreatmentDosageUnit = "PIECE"
+)
+
+var AllTreatmentDosageUnit = []TreatmentDosageUnit{
+	TreatmentDosageUnitMl,
+	TreatmentDosageUnitG,
+	TreatmentDosageUnitStrip,
+	TreatmentDosageUnitPiece,
+}
+
+func (e TreatmentDosageUnit) IsValid() bool {
+	switch e {
+	case TreatmentDosageUnitMl, TreatmentDosageUnitG, TreatmentDosageUnitStrip, TreatmentDosageUnitPiece:
+		return true
+	}
+	return false
+}
+
+func (e TreatmentDosageUnit) String() string {
+	return string(e)
+}
+
+func (e *TreatmentDosageUnit) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TreatmentDosageUnit(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TreatmentDosageUnit", str)
+	}
+	return nil
+}
+
+func (e TreatmentDosageUnit) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TreatmentDosageUnit) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TreatmentDosageUnit) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type VarroaCountMethod string
+
+const (
+	VarroaCountMethodSugarRoll   VarroaCountMethod = "SUGAR_ROLL"
+	VarroaCountMethodAlcoholWash VarroaCountMethod = "ALCOHOL_WASH"
+	VarroaCountMethodCo2         VarroaCountMethod = "CO2"
+	// Natural mite drop on a bottom board, sampleSize is the number of days
+	VarroaCountMethodStickyBoard VarroaCountMethod = "STICKY_BOARD"
+)
+
+var AllVarroaCountMethod = []VarroaCountMethod{
+	VarroaCountMethodSugarRoll,
+	VarroaCountMethodAlcoholWash,
+	VarroaCountMethodCo2,
+	VarroaCountMethodStickyBoard,
+}
+
+func (e VarroaCountMethod) IsValid() bool {
+	switch e {
+	case VarroaCountMethodSugarRoll, VarroaCountMethodAlcoholWash, VarroaCountMethodCo2, VarroaCountMethodStickyBoard:
+		return true
+	}
+	return false
+}
+
+func (e VarroaCountMethod) String() string {
+	return string(e)
+}
+
+func (e *VarroaCountMethod) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VarroaCountMethod(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VarroaCountMethod", str)
+	}
+	return nil
+}
+
+func (e VarroaCountMethod) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *VarroaCountMethod) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e VarroaCountMethod) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type VarroaCountTreatmentPhase string
+
+const (
+	VarroaCountTreatmentPhaseBefore VarroaCountTreatmentPhase = "BEFORE"
+	VarroaCountTreatmentPhaseAfter  VarroaCountTreatmentPhase = "AFTER"
+)
+
+var AllVarroaCountTreatmentPhase = []VarroaCountTreatmentPhase{
+	VarroaCountTreatmentPhaseBefore,
+	VarroaCountTreatmentPhaseAfter,
+}
+
+func (e VarroaCountTreatmentPhase) IsValid() bool {
+	switch e {
+	case VarroaCountTreatmentPhaseBefore, VarroaCountTreatmentPhaseAfter:
+		return true
+	}
+	return false
+}
+
+func (e VarroaCountTreatmentPhase) String() string {
+	return string(e)
+}
+
+func (e *VarroaCountTreatmentPhase) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VarroaCountTreatmentPhase(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VarroaCountTreatmentPhase", str)
+	}
+	return nil
+}
+
+func (e VarroaCountTreatmentPhase) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *VarroaCountTreatmentPhase) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e VarroaCountTreatmentPhase) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type VarroaTrendDirection string
+
+const (
+	VarroaTrendDirectionRising  VarroaTrendDirection = "RISING"
+	VarroaTrendDirectionFalling VarroaTrendDirection = "FALLING"
+	VarroaTrendDirectionStable  VarroaTrendDirection = "STABLE"
+)
+
+var AllVarroaTrendDirection = []VarroaTrendDirection{
+	VarroaTrendDirectionRising,
+	VarroaTrendDirectionFalling,
+	VarroaTrendDirectionStable,
+}
+
+func (e VarroaTrendDirection) IsValid() bool {
+	switch e {
+	case VarroaTrendDirectionRising, VarroaTrendDirectionFalling, VarroaTrendDirectionStable:
+		return true
+	}
+	return false
+}
+
+func (e VarroaTrendDirection) String() string {
+	return string(e)
+}
+
+func (e *VarroaTrendDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VarroaTrendDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VarroaTrendDirection", str)
+	}
+	return nil
+}
+
+func (e VarroaTrendDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *VarroaTrendDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e VarroaTrendDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type WarehouseModuleType string
 
 const (
@@ -763,3 +2130,64 @@ func (e WarehouseModuleType) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type YieldGrouping string
+
+const (
+	YieldGroupingHive YieldGrouping = "HIVE"
+	// Queen family the harvests are credited to
+	YieldGroupingFamily YieldGrouping = "FAMILY"
+	YieldGroupingAPIAry YieldGrouping = "APIARY"
+	// Calendar year
+	YieldGroupingSeason YieldGrouping = "SEASON"
+)
+
+var AllYieldGrouping = []YieldGrouping{
+	YieldGroupingHive,
+	YieldGroupingFamily,
+	YieldGroupingAPIAry,
+	YieldGroupingSeason,
+}
+
+func (e YieldGrouping) IsValid() bool {
+	switch e {
+	case YieldGroupingHive, YieldGroupingFamily, YieldGroupingAPIAry, YieldGroupingSeason:
+		return true
+	}
+	return false
+}
+
+func (e YieldGrouping) String() string {
+	return string(e)
+}
+
+func (e *YieldGrouping) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = YieldGrouping(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid YieldGrouping", str)
+	}
+	return nil
+}
+
+func (e YieldGrouping) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *YieldGrouping) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e YieldGrouping) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,246 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// OutboxEventVersion is the envelope version consumers can branch on, bump it on breaking payload changes
+const OutboxEventVersion = 1
+
+const (
+	outboxAggregateHive      = "hive"
+	outboxAggregateApiary    = "apiary"
+	outboxAggregateInventory = "inventory"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change it describes.
+// The relay publishes pending events to redis, in id order per aggregate.
+type OutboxEvent struct {
+	Db *sqlx.DB
+
+	ID            int64   `db:"id"`
+	EventID       string  `db:"event_id"`
+	Version       int     `db:"version"`
+	UserID        string  `db:"user_id"`
+	AggregateType string  `db:"aggregate_type"`
+	AggregateID   string  `db:"aggregate_id"`
+	Entity        string  `db:"entity"`
+	EntityID      string  `db:"entity_id"`
+	Action        string  `db:"action"`
+	HiveID        *string `db:"hive_id"`
+	ApiaryID      *string `db:"apiary_id"`
+	Inventory     bool    `db:"inventory"`
+	Payload       *string `db:"payload"`
+	OccurredAt    string  `db:"occurred_at"`
+	Attempts      int     `db:"attempts"`
+	LastError     *string `db:"last_error"`
+}
+
+// AggregateKey identifies the stream whose events must be published in order
+func (e *OutboxEvent) AggregateKey() string {
+	return e.AggregateType + ":" + e.AggregateID
+}
+
+// recordEventTx stores the event within tx, so it exists only if the change it describes is committed
+func recordEventTx(tx *sqlx.Tx, event *OutboxEvent, data interface{}) error {
+	if data != nil {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		payloadStr := string(payload)
+		event.Payload = &payloadStr
+	}
+
+	event.EventID = uuid.NewString()
+	event.Version = OutboxEventVersion
+
+	result, err := tx.NamedExec(
+		`INSERT INTO outbox_events (event_id, version, user_id, aggregate_type, aggregate_id, entity, entity_id, action,
+			hive_id, apiary_id, inventory, payload, occurred_at, next_attempt_at)
+		VALUES (:event_id, :version, :user_id, :aggregate_type, :aggregate_id, :entity, :entity_id, :action,
+			:hive_id, :apiary_id, :inventory, :payload, UTC_TIMESTAMP(3), UTC_TIMESTAMP(3))`,
+		event,
+	)
+	if err != nil {
+		return err
+	}
+
+	event.ID, err = result.LastInsertId()
+	return err
+}
+
+// newHiveEventTx builds an event ordered by hive, including the apiary of the hive for apiary subscribers
+func newHiveEventTx(tx *sqlx.Tx, userID string, hiveID string, entity string, entityID string, action string) (*OutboxEvent, error) {
+	event := &OutboxEvent{
+		UserID:        userID,
+		AggregateType: outboxAggregateHive,
+		AggregateID:   hiveID,
+		Entity:        entity,
+		EntityID:      entityID,
+		Action:        action,
+		HiveID:        &hiveID,
+	}
+
+	var apiaryID sql.NullInt64
+	err := tx.Get(&apiaryID, `SELECT apiary_id FROM hives WHERE id=? AND user_id=? LIMIT 1`, hiveID, userID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if apiaryID.Valid {
+		apiaryIDStr := strconv.FormatInt(apiaryID.Int64, 10)
+		event.ApiaryID = &apiaryIDStr
+	}
+
+	return event, nil
+}
+
+func recordHiveEventTx(tx *sqlx.Tx, userID string, hiveID string, entity string, entityID string, action string, data interface{}) error {
+	event, err := newHiveEventTx(tx, userID, hiveID, entity, entityID, action)
+	if err != nil {
+		return err
+	}
+
+	return recordEventTx(tx, event, data)
+}
+
+// recordBoxEventTx records a change of a box or its content as an event of the hive the box belongs to,
+// boxes of other users are skipped
+func recordBoxEventTx(tx *sqlx.Tx, userID string, boxID string, entity string, entityID string, action string, data interface{}) error {
+	var hiveID int
+	err := tx.Get(&hiveID, `SELECT hive_id FROM boxes WHERE id=? AND user_id=? LIMIT 1`, boxID, userID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return recordHiveEventTx(tx, userID, strconv.Itoa(hiveID), entity, entityID, action, data)
+}
+
+func recordApiaryEventTx(tx *sqlx.Tx, userID string, apiaryID string, action string, data interface{}) error {
+	return recordEventTx(tx, &OutboxEvent{
+		UserID:        userID,
+		AggregateType: outboxAggregateApiary,
+		AggregateID:   apiaryID,
+		Entity:        "apiary",
+		EntityID:      apiaryID,
+		Action:        action,
+		ApiaryID:      &apiaryID,
+	}, data)
+}
+
+// recordInventoryEventTx records a change of the warehouse for inventory subscribers of the user
+func recordInventoryEventTx(tx *sqlx.Tx, userID string, entity string, entityID string, action string, data interface{}) error {
+	return recordEventTx(tx, &OutboxEvent{
+		UserID:        userID,
+		AggregateType: outboxAggregateInventory,
+		AggregateID:   userID,
+		Entity:        entity,
+		EntityID:      entityID,
+		Action:        action,
+		Inventory:     true,
+	}, data)
+}
+
+// recordFamilyEventTx records a family change for the hive it left or joined, and for inventory
+// subscribers when the family is (or was) kept in the warehouse
+func recordFamilyEventTx(tx *sqlx.Tx, userID string, hiveID *int, familyID int, action string, inventory bool, data interface{}) error {
+	familyIDStr := strconv.Itoa(familyID)
+	if hiveID == nil {
+		return recordInventoryEventTx(tx, userID, "family", familyIDStr, action, data)
+	}
+
+	event, err := newHiveEventTx(tx, userID, strconv.Itoa(*hiveID), "family", familyIDStr, action)
+	if err != nil {
+		return err
+	}
+	event.Inventory = inventory
+
+	return recordEventTx(tx, event, data)
+}
+
+// ListPending returns unpublished events that are due, in the order they were recorded.
+// Events behind an event of the same aggregate that waits for a retry are left out, so they keep their order
+// and a failing aggregate does not fill the batch
+func (r *OutboxEvent) ListPending(limit int) ([]*OutboxEvent, error) {
+	events := []*OutboxEvent{}
+	err := r.Db.Select(&events,
+		`SELECT e.id, e.event_id, e.version, e.user_id, e.aggregate_type, e.aggregate_id, e.entity, e.entity_id, e.action,
+			e.hive_id, e.apiary_id, e.inventory, e.payload, e.attempts, e.last_error,
+			DATE_FORMAT(e.occurred_at, '%Y-%m-%dT%TZ') AS occurred_at
+		FROM outbox_events e
+		WHERE e.published_at IS NULL
+		  AND e.next_attempt_at <= UTC_TIMESTAMP(3)
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM outbox_events waiting
+			WHERE waiting.aggregate_type = e.aggregate_type
+			  AND waiting.aggregate_id = e.aggregate_id
+			  AND waiting.published_at IS NULL
+			  AND waiting.next_attempt_at > UTC_TIMESTAMP(3)
+			  AND waiting.id < e.id
+		  )
+		ORDER BY e.id ASC
+		LIMIT ?`, limit)
+	return events, err
+}
+
+func (r *OutboxEvent) MarkPublished(id int64) error {
+	_, err := r.Db.Exec(
+		`UPDATE outbox_events
+		SET published_at=UTC_TIMESTAMP(3), attempts=attempts+1, last_error=NULL
+		WHERE id=?`, id)
+	return err
+}
+
+// MarkFailed keeps the event pending and postpones its next publish attempt by retryIn
+func (r *OutboxEvent) MarkFailed(id int64, publishErr error, retryIn time.Duration) error {
+	_, err := r.Db.Exec(
+		`UPDATE outbox_events
+		SET attempts=attempts+1, last_error=?, next_attempt_at=UTC_TIMESTAMP(3) + INTERVAL ? MICROSECOND
+		WHERE id=?`,
+		publishErr.Error(), retryIn.Microseconds(), id)
+	return err
+}
+
+// PendingStats returns the number of unpublished events and the age of the oldest one
+func (r *OutboxEvent) PendingStats() (int, time.Duration, error) {
+	var stats struct {
+		Count     int           `db:"cnt"`
+		OldestAge sql.NullInt64 `db:"oldest_age_ms"`
+	}
+	err := r.Db.Get(&stats,
+		`SELECT COUNT(id) AS cnt, TIMESTAMPDIFF(MICROSECOND, MIN(occurred_at), UTC_TIMESTAMP(3)) DIV 1000 AS oldest_age_ms
+		FROM outbox_events
+		WHERE published_at IS NULL`)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !stats.OldestAge.Valid {
+		return stats.Count, 0, nil
+	}
+
+	return stats.Count, time.Duration(stats.OldestAge.Int64) * time.Millisecond, nil
+}
+
+// DeletePublishedOlderThan prunes a batch of events that were delivered more than retention ago
+func (r *OutboxEvent) DeletePublishedOlderThan(retention time.Duration) (int64, error) {
+	result, err := r.Db.Exec(
+		`DELETE FROM outbox_events
+		WHERE published_at IS NOT NULL AND published_at < UTC_TIMESTAMP(3) - INTERVAL ? SECOND
+		LIMIT 1000`,
+		int64(retention.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,585 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PollinationContract is a rental of hives of a mobile apiary to a grower for the bloom of a crop
+type PollinationContract struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID            string                `json:"id" db:"id"`
+	ApiaryID      string                `json:"apiaryId" db:"apiary_id"`
+	Grower        string                `json:"grower" db:"grower"`
+	GrowerContact *string               `json:"growerContact" db:"grower_contact"`
+	Crop          string                `json:"crop" db:"crop"`
+	FieldName     *string               `json:"fieldName" db:"field_name"`
+	FieldLat      *string               `json:"fieldLat" db:"field_lat"`
+	FieldLng      *string               `json:"fieldLng" db:"field_lng"`
+	RequiredHives int                   `json:"requiredHives" db:"required_hives"`
+	MinStrength   *InspectionPopulation `json:"minStrength" db:"min_strength"`
+	StartsAt      string                `json:"startsAt" db:"starts_at"`
+	EndsAt        string                `json:"endsAt" db:"ends_at"`
+	Fee           *float64              `json:"fee" db:"fee"`
+	FeeCurrency   *string               `json:"feeCurrency" db:"fee_currency"`
+	Notes         *string               `json:"notes" db:"notes"`
+	PlacedAt      *string               `json:"placedAt" db:"placed_at"`
+	RemovedAt     *string               `json:"removedAt" db:"removed_at"`
+
+	AssignedHives   int `json:"assignedHives" db:"-"`
+	QualifyingHives int `json:"qualifyingHives" db:"-"`
+}
+
+const pollinationContractColumns = `id, user_id, apiary_id, grower, grower_contact, crop, field_name, field_lat, field_lng,
+	required_hives, min_strength, starts_at, ends_at, fee, fee_currency, notes, placed_at, removed_at`
+
+// PollinationUpcomingDays is how far ahead upcoming placements are listed by default
+const PollinationUpcomingDays = 14
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// populationRank orders inspection populations, hives without a recorded population rank below WEAK
+var populationRank = map[InspectionPopulation]int{
+	InspectionPopulationWeak:   1,
+	InspectionPopulationMedium: 2,
+	InspectionPopulationStrong: 3,
+}
+
+// FieldLocation names the place of the field from the bundled offline place list
+func (r *PollinationContract) FieldLocation() *string {
+	return describeLocation(r.FieldLat, r.FieldLng)
+}
+
+// HiveShortfall is how many more qualifying hives the apiary needs to fulfil the contract
+func (r *PollinationContract) HiveShortfall() int {
+	if r.QualifyingHives >= r.RequiredHives {
+		return 0
+	}
+	return r.RequiredHives - r.QualifyingHives
+}
+
+// Status tells where the contract is, placements and removals are overdue once their date has passed
+func (r *PollinationContract) Status() PollinationContractStatus {
+	now := time.Now().UTC()
+	switch {
+	case r.RemovedAt != nil:
+		return PollinationContractStatusCompleted
+	case r.PlacedAt != nil:
+		if endsAt, err := parseDBDateTime(r.EndsAt); err == nil && endsAt.Before(now) {
+			return PollinationContractStatusOverdueRemoval
+		}
+		return PollinationContractStatusPlaced
+	}
+
+	if startsAt, err := parseDBDateTime(r.StartsAt); err == nil && !startsAt.After(now) {
+		return PollinationContractStatusOverduePlacement
+	}
+	return PollinationContractStatusPlanned
+}
+
+func (r *PollinationContract) Get(id string) (*PollinationContract, error) {
+	contract := PollinationContract{}
+	err := r.Db.Get(&contract,
+		`SELECT `+pollinationContractColumns+`
+		FROM pollination_contracts
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &contract, r.countHives(&contract)
+}
+
+// List returns contracts of the user or of one apiary by start date. Completed ones only when asked
+func (r *PollinationContract) List(apiaryID *string, includeCompleted bool) ([]*PollinationContract, error) {
+	condition := ``
+	args := []interface{}{r.UserID}
+	if apiaryID != nil {
+		condition += ` AND apiary_id=?`
+		args = append(args, *apiaryID)
+	}
+	if !includeCompleted {
+		condition += ` AND removed_at IS NULL`
+	}
+
+	return r.list(condition, args...)
+}
+
+// ListUpcoming returns contracts whose hives are due to be placed within the next days
+func (r *PollinationContract) ListUpcoming(apiaryID *string, days int) ([]*PollinationContract, error) {
+	if days < 1 || days > 365 {
+		return nil, errors.New("days must be between 1 and 365")
+	}
+
+	condition := ` AND placed_at IS NULL AND starts_at > NOW() AND starts_at <= DATE_ADD(NOW(), INTERVAL ? DAY)`
+	args := []interface{}{r.UserID, days}
+	if apiaryID != nil {
+		condition += ` AND apiary_id=?`
+		args = append(args, *apiaryID)
+	}
+
+	return r.list(condition, args...)
+}
+
+// ListOverdue returns contracts whose hives should already be in the field but are not,
+// or should have been taken back after the contract ended
+func (r *PollinationContract) ListOverdue(apiaryID *string) ([]*PollinationContract, error) {
+	condition := ` AND removed_at IS NULL
+		AND ((placed_at IS NULL AND starts_at <= NOW()) OR (placed_at IS NOT NULL AND ends_at < NOW()))`
+	args := []interface{}{r.UserID}
+	if apiaryID != nil {
+		condition += ` AND apiary_id=?`
+		args = append(args, *apiaryID)
+	}
+
+	return r.list(condition, args...)
+}
+
+func (r *PollinationContract) list(condition string, args ...interface{}) ([]*PollinationContract, error) {
+	list := []*PollinationContract{}
+	err := r.Db.Select(&list,
+		`SELECT `+pollinationContractColumns+`
+		FROM pollination_contracts
+		WHERE user_id=? AND active=1`+condition+`
+		ORDER BY starts_at ASC, id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, r.countHives(list...)
+}
+
+type apiaryPopulationRow struct {
+	ApiaryID   string                `db:"apiary_id"`
+	Population *InspectionPopulation `db:"population"`
+	Hives      int                   `db:"hives"`
+}
+
+type contractCommitmentRow struct {
+	ContractID string `db:"contract_id"`
+	Hives      int    `db:"hives"`
+}
+
+// countHives sets the hives assigned to apiaries of the contracts and how many of them are strong enough.
+// Strength is the population of the latest inspection that recorded it.
+// Hives of other contracts placed in the field during overlapping dates are not available
+func (r *PollinationContract) countHives(contracts ...*PollinationContract) error {
+	if len(contracts) == 0 {
+		return nil
+	}
+
+	apiaryIDs := make([]string, 0, len(contracts))
+	contractIDs := make([]string, 0, len(contracts))
+	for _, contract := range contracts {
+		apiaryIDs = append(apiaryIDs, contract.ApiaryID)
+		contractIDs = append(contractIDs, contract.ID)
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT h.apiary_id, latest.population, COUNT(*) AS hives
+		FROM hives h
+		LEFT JOIN (
+			SELECT hive_id, population, ROW_NUMBER() OVER (PARTITION BY hive_id ORDER BY added DESC, id DESC) AS rn
+			FROM inspections
+			WHERE user_id=? AND active=1 AND population IS NOT NULL
+		) latest ON latest.hive_id = h.id AND latest.rn=1
+		WHERE h.user_id=? AND h.active=1 AND h.collapse_date IS NULL AND h.merged_into_hive_id IS NULL
+		  AND h.apiary_id IN (?)
+		GROUP BY h.apiary_id, latest.population`, r.UserID, r.UserID, apiaryIDs)
+	if err != nil {
+		return err
+	}
+	rows := []*apiaryPopulationRow{}
+	err = r.Db.Select(&rows, r.Db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+
+	query, args, err = sqlx.In(
+		`SELECT c.id AS contract_id, SUM(other.required_hives) AS hives
+		FROM pollination_contracts c
+		INNER JOIN pollination_contracts other ON other.apiary_id = c.apiary_id AND other.user_id = c.user_id
+			AND other.id <> c.id AND other.active=1
+			AND other.placed_at IS NOT NULL AND other.removed_at IS NULL
+			AND other.starts_at < c.ends_at AND other.ends_at > c.starts_at
+		WHERE c.user_id=? AND c.id IN (?)
+		GROUP BY c.id`, r.UserID, contractIDs)
+	if err != nil {
+		return err
+	}
+	commitments := []*contractCommitmentRow{}
+	err = r.Db.Select(&commitments, r.Db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	committedHives := map[string]int{}
+	for _, commitment := range commitments {
+		committedHives[commitment.ContractID] = commitment.Hives
+	}
+
+	for _, contract := range contracts {
+		contract.AssignedHives = 0
+		contract.QualifyingHives = 0
+		for _, row := range rows {
+			if row.ApiaryID != contract.ApiaryID {
+				continue
+			}
+			contract.AssignedHives += row.Hives
+			if contract.MinStrength == nil ||
+				(row.Population != nil && populationRank[*row.Population] >= populationRank[*contract.MinStrength]) {
+				contract.QualifyingHives += row.Hives
+			}
+		}
+
+		// committed hives may be the strong ones, so they are taken from the qualifying hives too
+		committed := committedHives[contract.ID]
+		contract.AssignedHives = max(contract.AssignedHives-committed, 0)
+		contract.QualifyingHives = max(contract.QualifyingHives-committed, 0)
+	}
+
+	return nil
+}
+
+type pollinationContractValues struct {
+	startsAt string
+	endsAt   string
+	fieldLat *string
+	fieldLng *string
+	currency *string
+}
+
+func validatePollinationContractInput(input PollinationContractInput) (*pollinationContractValues, error) {
+	if strings.TrimSpace(input.Grower) == "" {
+		return nil, errors.New("grower is required")
+	}
+	if strings.TrimSpace(input.Crop) == "" {
+		return nil, errors.New("crop is required")
+	}
+	if len(input.Grower) > 255 || (input.GrowerContact != nil && len(*input.GrowerContact) > 255) {
+		return nil, errors.New("grower and growerContact must be at most 255 characters")
+	}
+	if len(input.Crop) > 100 {
+		return nil, errors.New("crop must be at most 100 characters")
+	}
+	if input.FieldName != nil && len(*input.FieldName) > 255 {
+		return nil, errors.New("fieldName must be at most 255 characters")
+	}
+	if input.Notes != nil && len(*input.Notes) > 10000 {
+		return nil, errors.New("notes must be at most 10000 characters")
+	}
+	if input.RequiredHives < 1 || input.RequiredHives > 10000 {
+		return nil, errors.New("required hives must be between 1 and 10000")
+	}
+	if input.MinStrength != nil && !input.MinStrength.IsValid() {
+		return nil, errors.New("invalid minimum strength")
+	}
+	if input.Fee != nil && (*input.Fee < 0 || *input.Fee > 99999999) {
+		return nil, errors.New("fee must be between 0 and 99999999")
+	}
+
+	values := &pollinationContractValues{}
+	if input.FeeCurrency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*input.FeeCurrency))
+		if !currencyCodePattern.MatchString(currency) {
+			return nil, errors.New("feeCurrency must be an ISO 4217 code, e.g. EUR")
+		}
+		values.currency = &currency
+	}
+
+	var err error
+	values.fieldLat, values.fieldLng, err = normalizeCoordinates(input.FieldLat, input.FieldLng)
+	if err != nil {
+		return nil, err
+	}
+
+	startsAt, err := ParseDateTimeInput("startsAt", input.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	endsAt, err := ParseDateTimeInput("endsAt", input.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	if !endsAt.After(startsAt) {
+		return nil, errors.New("contract must end after it starts")
+	}
+	values.startsAt = startsAt.UTC().Format(mysqlDateTimeFormat)
+	values.endsAt = endsAt.UTC().Format(mysqlDateTimeFormat)
+
+	return values, nil
+}
+
+// ensureMobileApiaryTx checks that hives can be rented from the apiary
+func (r *PollinationContract) ensureMobileApiaryTx(tx *sqlx.Tx, apiaryID string) error {
+	var apiaryType ApiaryType
+	err := tx.Get(&apiaryType, `SELECT type FROM apiaries WHERE id=? AND user_id=? AND active=1 LIMIT 1`, apiaryID, r.UserID)
+	if err == sql.ErrNoRows {
+		return errors.New("apiary not found")
+	}
+	if err != nil {
+		return err
+	}
+	if apiaryType != ApiaryTypeMobile {
+		return errors.New("pollination contracts need a mobile apiary")
+	}
+
+	return nil
+}
+
+func (r *PollinationContract) Create(input PollinationContractInput) (*PollinationContract, error) {
+	values, err := validatePollinationContractInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+	err = r.ensureMobileApiaryTx(tx, input.ApiaryID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO pollination_contracts (user_id, apiary_id, grower, grower_contact, crop, field_name, field_lat, field_lng,
+			required_hives, min_strength, starts_at, ends_at, fee, fee_currency, notes)
+		VALUES (:userID, :apiaryID, :grower, :growerContact, :crop, :fieldName, :fieldLat, :fieldLng,
+			:requiredHives, :minStrength, :startsAt, :endsAt, :fee, :feeCurrency, :notes)`,
+		map[string]interface{}{
+			"userID":        r.UserID,
+			"apiaryID":      input.ApiaryID,
+			"grower":        strings.TrimSpace(input.Grower),
+			"growerContact": input.GrowerContact,
+			"crop":          strings.TrimSpace(input.Crop),
+			"fieldName":     input.FieldName,
+			"fieldLat":      values.fieldLat,
+			"fieldLng":      values.fieldLng,
+			"requiredHives": input.RequiredHives,
+			"minStrength":   input.MinStrength,
+			"startsAt":      values.startsAt,
+			"endsAt":        values.endsAt,
+			"fee":           input.Fee,
+			"feeCurrency":   values.currency,
+			"notes":         input.Notes,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	contractID := strconv.FormatInt(id, 10)
+
+	err = r.recordChangeTx(tx, contractID, "pollination_contract_created")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(contractID)
+}
+
+// Update changes the terms of a contract, hives already placed stay placed
+func (r *PollinationContract) Update(id string, input PollinationContractInput) (*PollinationContract, error) {
+	values, err := validatePollinationContractInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+	err = r.ensureMobileApiaryTx(tx, input.ApiaryID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result, err := tx.NamedExec(
+		`UPDATE pollination_contracts
+		SET apiary_id=:apiaryID, grower=:grower, grower_contact=:growerContact, crop=:crop, field_name=:fieldName,
+			field_lat=:fieldLat, field_lng=:fieldLng, required_hives=:requiredHives, min_strength=:minStrength,
+			starts_at=:startsAt, ends_at=:endsAt, fee=:fee, fee_currency=:feeCurrency, notes=:notes
+		WHERE id=:id AND user_id=:userID AND active=1`,
+		map[string]interface{}{
+			"id":            id,
+			"userID":        r.UserID,
+			"apiaryID":      input.ApiaryID,
+			"grower":        strings.TrimSpace(input.Grower),
+			"growerContact": input.GrowerContact,
+			"crop":          strings.TrimSpace(input.Crop),
+			"fieldName":     input.FieldName,
+			"fieldLat":      values.fieldLat,
+			"fieldLng":      values.fieldLng,
+			"requiredHives": input.RequiredHives,
+			"minStrength":   input.MinStrength,
+			"startsAt":      values.startsAt,
+			"endsAt":        values.endsAt,
+			"fee":           input.Fee,
+			"feeCurrency":   values.currency,
+			"notes":         input.Notes,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		var exists int
+		err = tx.Get(&exists, `SELECT COUNT(*) FROM pollination_contracts WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+		if err != nil || exists == 0 {
+			tx.Rollback()
+			return nil, errors.New("pollination contract not found")
+		}
+	}
+
+	err = r.recordChangeTx(tx, id, "pollination_contract_updated")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(id)
+}
+
+func (r *PollinationContract) Delete(id string) (bool, error) {
+	contract, err := r.Get(id)
+	if err != nil || contract == nil {
+		return false, err
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.Exec(`UPDATE pollination_contracts SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = recordApiaryEventTx(tx, r.UserID, contract.ApiaryID, "pollination_contract_deleted", contract)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Place records that the hives were delivered to the field at placedAt, now by default.
+// The apiary must hold enough hives of the required strength
+func (r *PollinationContract) Place(id string, placedAt *string) (*PollinationContract, error) {
+	when, err := parseOptionalDateTimeInput("placedAt", placedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		return nil, errors.New("pollination contract not found")
+	}
+	if contract.PlacedAt != nil {
+		return nil, errors.New("hives of the contract are already placed")
+	}
+	if shortfall := contract.HiveShortfall(); shortfall > 0 {
+		return nil, fmt.Errorf("apiary has %d of %d required hives meeting the contract, %d missing",
+			contract.QualifyingHives, contract.RequiredHives, shortfall)
+	}
+
+	return r.setDateAndRecord(id, "placed_at", when, "pollination_hives_placed")
+}
+
+// Remove records that the hives were taken back from the field at removedAt, now by default
+func (r *PollinationContract) Remove(id string, removedAt *string) (*PollinationContract, error) {
+	when, err := parseOptionalDateTimeInput("removedAt", removedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		return nil, errors.New("pollination contract not found")
+	}
+	if contract.PlacedAt == nil {
+		return nil, errors.New("hives of the contract were not placed yet")
+	}
+	if contract.RemovedAt != nil {
+		return nil, errors.New("hives of the contract are already removed")
+	}
+	if when != nil {
+		placed, err := parseDBDateTime(*contract.PlacedAt)
+		if err != nil {
+			return nil, err
+		}
+		removed, err := parseDBDateTime(*when)
+		if err != nil {
+			return nil, err
+		}
+		if removed.Before(placed) {
+			return nil, errors.New("hives can not be removed before they were placed")
+		}
+	}
+
+	return r.setDateAndRecord(id, "removed_at", when, "pollination_hives_removed")
+}
+
+// setDateAndRecord sets placed_at or removed_at, now when no date is given
+func (r *PollinationContract) setDateAndRecord(id string, column string, when *string, action string) (*PollinationContract, error) {
+	tx := r.Db.MustBegin()
+	_, err := tx.Exec(
+		`UPDATE pollination_contracts SET `+column+`=COALESCE(?, NOW()) WHERE id=? AND user_id=? AND active=1 AND `+column+` IS NULL`,
+		when, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = r.recordChangeTx(tx, id, action)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(id)
+}
+
+// recordChangeTx records the contract state after the change as an event of its apiary
+func (r *PollinationContract) recordChangeTx(tx *sqlx.Tx, id string, action string) error {
+	contract := PollinationContract{}
+	err := tx.Get(&contract, `SELECT `+pollinationContractColumns+` FROM pollination_contracts WHERE id=? AND user_id=? LIMIT 1`, id, r.UserID)
+	if err != nil {
+		return err
+	}
+
+	return recordApiaryEventTx(tx, r.UserID, contract.ApiaryID, action, contract)
+}
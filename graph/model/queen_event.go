@@ -0,0 +1,277 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueenEvent is something that happened to a queen, like her introduction, mating or loss
+type QueenEvent struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID         string         `json:"id" db:"id"`
+	FamilyID   string         `json:"familyId" db:"family_id"`
+	HiveID     *string        `json:"hiveId" db:"hive_id"`
+	Type       QueenEventType `json:"type" db:"event_type"`
+	OccurredAt string         `json:"occurredAt" db:"occurred_at"`
+	Notes      *string        `json:"notes" db:"notes"`
+}
+
+// FamilyHistoryEntry is a queen event, a hive move or a treatment of the family
+type FamilyHistoryEntry struct {
+	Kind       FamilyHistoryKind `json:"kind"`
+	At         string            `json:"at"`
+	Event      *QueenEvent       `json:"event"`
+	MoveType   *string           `json:"moveType"`
+	FromHiveID *string           `json:"fromHiveId"`
+	ToHiveID   *string           `json:"toHiveId"`
+	Treatment  *Treatment        `json:"treatment"`
+}
+
+// queenEventStatuses are the statuses events tell, other events leave the status as it was
+var queenEventStatuses = map[QueenEventType]QueenStatus{
+	QueenEventTypeEmerged:    QueenStatusVirgin,
+	QueenEventTypeMated:      QueenStatusMated,
+	QueenEventTypeLaying:     QueenStatusLaying,
+	QueenEventTypeRejected:   QueenStatusLost,
+	QueenEventTypeSwarmed:    QueenStatusLost,
+	QueenEventTypeLost:       QueenStatusLost,
+	QueenEventTypeSuperseded: QueenStatusSuperseded,
+}
+
+const queenEventColumns = `id, user_id, family_id, hive_id, event_type, occurred_at, notes`
+
+// QueenStatusFromEvents returns the status told by the latest event that tells one, events are oldest first
+func QueenStatusFromEvents(events []*QueenEvent) *QueenStatus {
+	var status *QueenStatus
+	for _, event := range events {
+		if eventStatus, ok := queenEventStatuses[event.Type]; ok {
+			status = &eventStatus
+		}
+	}
+
+	return status
+}
+
+func (r *QueenEvent) Get(id string) (*QueenEvent, error) {
+	event := QueenEvent{}
+	err := r.Db.Get(&event,
+		`SELECT `+queenEventColumns+`
+		FROM queen_events
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// ListByFamily returns events of the queen, oldest first
+func (r *QueenEvent) ListByFamily(familyID string) ([]*QueenEvent, error) {
+	list := []*QueenEvent{}
+	err := r.Db.Select(&list,
+		`SELECT `+queenEventColumns+`
+		FROM queen_events
+		WHERE family_id=? AND user_id=? AND active=1
+		ORDER BY occurred_at ASC, id ASC`, familyID, r.UserID)
+
+	return list, err
+}
+
+// queenEventTitle reads like "Queen superseded" or "Queen lost with a swarm" in the hive log
+func queenEventTitle(eventType QueenEventType) string {
+	if eventType == QueenEventTypeSwarmed {
+		return "Queen left with a swarm"
+	}
+	return "Queen " + strings.ToLower(string(eventType))
+}
+
+// Create records an event of the queen on date or now, in the hive log of the hive she is in
+func (r *QueenEvent) Create(familyID string, eventType QueenEventType, date *string, notes *string) (*QueenEvent, error) {
+	if !eventType.IsValid() {
+		return nil, errors.New("invalid queen event type")
+	}
+	if notes != nil && len(*notes) > 2000 {
+		return nil, errors.New("notes must be at most 2000 characters")
+	}
+	occurredAt, err := parseOptionalDateTimeInput("date", date)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	var hiveID *int
+	err = tx.Get(&hiveID, `SELECT hive_id FROM families WHERE id=? AND user_id=? AND active=1 LIMIT 1`, familyID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("queen not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO queen_events (user_id, family_id, hive_id, event_type, occurred_at, notes)
+		VALUES (:userID, :familyID, :hiveID, :eventType, COALESCE(:occurredAt, NOW()), :notes)`,
+		map[string]interface{}{
+			"userID":     r.UserID,
+			"familyID":   familyID,
+			"hiveID":     hiveID,
+			"eventType":  eventType,
+			"occurredAt": occurredAt,
+			"notes":      notes,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	eventID := strconv.FormatInt(id, 10)
+
+	event := QueenEvent{}
+	err = tx.Get(&event, `SELECT `+queenEventColumns+` FROM queen_events WHERE id=? LIMIT 1`, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if hiveID != nil {
+		hive := strconv.Itoa(*hiveID)
+		source := "system"
+		dedupeKey := "queen-event:" + eventID
+		err = (&HiveLog{UserID: r.UserID}).CreateTx(tx, HiveLogInput{
+			HiveID:    hive,
+			Action:    "queen_event",
+			Title:     queenEventTitle(eventType),
+			Details:   notes,
+			Source:    &source,
+			DedupeKey: &dedupeKey,
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = recordHiveEventTx(tx, r.UserID, hive, "queen_event", eventID, "created", event)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (r *QueenEvent) Delete(id string) (bool, error) {
+	event, err := r.Get(id)
+	if err != nil || event == nil {
+		return false, err
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.Exec(`UPDATE queen_events SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if event.HiveID != nil {
+		err = (&HiveLog{UserID: r.UserID}).DeleteByDedupePrefixTx(tx, "queen-event:"+id)
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+
+		err = recordHiveEventTx(tx, r.UserID, *event.HiveID, "queen_event", id, "deleted", event)
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// History merges queen events, hive moves and treatments of the family, oldest first
+func (r *QueenEvent) History(familyID string) ([]*FamilyHistoryEntry, error) {
+	events, err := r.ListByFamily(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := []struct {
+		MoveType   string  `db:"move_type"`
+		FromHiveID *string `db:"from_hive_id"`
+		ToHiveID   *string `db:"to_hive_id"`
+		MovedAt    string  `db:"moved_at"`
+	}{}
+	err = r.Db.Select(&moves,
+		`SELECT move_type, from_hive_id, to_hive_id, moved_at
+		FROM family_moves
+		WHERE family_id=? AND user_id=?
+		ORDER BY moved_at ASC, id ASC`, familyID, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	treatments, err := (&Treatment{Db: r.Db, UserID: r.UserID}).ListAllFamilyTreatments(familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*FamilyHistoryEntry, 0, len(events)+len(moves)+len(treatments))
+	for _, event := range events {
+		history = append(history, &FamilyHistoryEntry{Kind: FamilyHistoryKindEvent, At: event.OccurredAt, Event: event})
+	}
+	for i := range moves {
+		move := moves[i]
+		history = append(history, &FamilyHistoryEntry{
+			Kind:       FamilyHistoryKindMove,
+			At:         move.MovedAt,
+			MoveType:   &move.MoveType,
+			FromHiveID: move.FromHiveID,
+			ToHiveID:   move.ToHiveID,
+		})
+	}
+	for _, treatment := range treatments {
+		at := treatment.Added
+		if treatment.StartDate != nil {
+			at = *treatment.StartDate
+		}
+		history = append(history, &FamilyHistoryEntry{Kind: FamilyHistoryKindTreatment, At: at, Treatment: treatment})
+	}
+
+	// entries of each kind are already in order, a stable sort keeps it for equal times
+	sort.SliceStable(history, func(i, j int) bool {
+		left, leftErr := parseDBDateTime(history[i].At)
+		right, rightErr := parseDBDateTime(history[j].At)
+		if leftErr != nil || rightErr != nil {
+			return history[i].At < history[j].At
+		}
+		return left.Before(right)
+	})
+
+	return history, nil
+}
@@ -0,0 +1,409 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueenRating is a performance rating of a queen, each trait from 1 (poor) to 5 (excellent)
+type QueenRating struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID              string  `json:"id" db:"id"`
+	FamilyID        string  `json:"familyId" db:"family_id"`
+	HiveID          *string `json:"hiveId" db:"hive_id"`
+	InspectionID    *string `json:"inspectionId" db:"inspection_id"`
+	RatedAt         string  `json:"ratedAt" db:"rated_at"`
+	Temperament     *int    `json:"temperament" db:"temperament"`
+	Calmness        *int    `json:"calmness" db:"calmness"`
+	Swarming        *int    `json:"swarming" db:"swarming"`
+	Hygiene         *int    `json:"hygiene" db:"hygiene"`
+	HoneyYield      *int    `json:"honeyYield" db:"honey_yield"`
+	VarroaTolerance *int    `json:"varroaTolerance" db:"varroa_tolerance"`
+	Notes           *string `json:"notes" db:"notes"`
+}
+
+// QueenTraitScores are average ratings of a queen per trait, nil for traits never rated
+type QueenTraitScores struct {
+	Temperament     *float64 `json:"temperament" db:"temperament"`
+	Calmness        *float64 `json:"calmness" db:"calmness"`
+	Swarming        *float64 `json:"swarming" db:"swarming"`
+	Hygiene         *float64 `json:"hygiene" db:"hygiene"`
+	HoneyYield      *float64 `json:"honeyYield" db:"honey_yield"`
+	VarroaTolerance *float64 `json:"varroaTolerance" db:"varroa_tolerance"`
+}
+
+// QueenRank is a queen placed in the breeder selection ranking
+type QueenRank struct {
+	Rank        int               `json:"rank"`
+	Family      *Family           `json:"family"`
+	Score       *float64          `json:"score"`
+	RatingCount int               `json:"ratingCount"`
+	Traits      *QueenTraitScores `json:"traits"`
+}
+
+const queenRatingColumns = `id, user_id, family_id, hive_id, inspection_id, rated_at,
+	temperament, calmness, swarming, hygiene, honey_yield, varroa_tolerance, notes`
+
+// QueenScore returns the weighted average of the rated traits, nil when no weighted trait was rated.
+// Weights left empty count as 1
+func QueenScore(traits *QueenTraitScores, weights *QueenRatingWeights) (*float64, error) {
+	if weights == nil {
+		weights = &QueenRatingWeights{}
+	}
+
+	total := 0.0
+	weightSum := 0.0
+	for _, trait := range []struct {
+		Score  *float64
+		Weight *float64
+	}{
+		{traits.Temperament, weights.Temperament},
+		{traits.Calmness, weights.Calmness},
+		{traits.Swarming, weights.Swarming},
+		{traits.Hygiene, weights.Hygiene},
+		{traits.HoneyYield, weights.HoneyYield},
+		{traits.VarroaTolerance, weights.VarroaTolerance},
+	} {
+		weight := 1.0
+		if trait.Weight != nil {
+			weight = *trait.Weight
+		}
+		if weight < 0 || weight > 100 {
+			return nil, errors.New("weights must be between 0 and 100")
+		}
+		if trait.Score == nil || weight == 0 {
+			continue
+		}
+		total += *trait.Score * weight
+		weightSum += weight
+	}
+
+	if weightSum == 0 {
+		return nil, nil
+	}
+
+	score := roundRating(total / weightSum)
+	return &score, nil
+}
+
+func roundRating(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func (r *QueenRating) Get(id string) (*QueenRating, error) {
+	rating := QueenRating{}
+	err := r.Db.Get(&rating,
+		`SELECT `+queenRatingColumns+`
+		FROM queen_ratings
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &rating, nil
+}
+
+// ListByFamily returns ratings of the queen, newest first
+func (r *QueenRating) ListByFamily(familyID string) ([]*QueenRating, error) {
+	list := []*QueenRating{}
+	err := r.Db.Select(&list,
+		`SELECT `+queenRatingColumns+`
+		FROM queen_ratings
+		WHERE family_id=? AND user_id=? AND active=1
+		ORDER BY rated_at DESC, id DESC`, familyID, r.UserID)
+
+	return list, err
+}
+
+func validateQueenRatingInput(input QueenRatingInput) error {
+	rated := 0
+	for _, trait := range []struct {
+		Name  string
+		Value *int
+	}{
+		{"temperament", input.Temperament},
+		{"calmness", input.Calmness},
+		{"swarming", input.Swarming},
+		{"hygiene", input.Hygiene},
+		{"honeyYield", input.HoneyYield},
+		{"varroaTolerance", input.VarroaTolerance},
+	} {
+		if trait.Value == nil {
+			continue
+		}
+		if *trait.Value < 1 || *trait.Value > 5 {
+			return errors.New(trait.Name + " must be between 1 and 5")
+		}
+		rated++
+	}
+	if rated == 0 {
+		return errors.New("at least one trait must be rated")
+	}
+	if input.Notes != nil && len(*input.Notes) > 2000 {
+		return errors.New("notes must be at most 2000 characters")
+	}
+
+	return nil
+}
+
+// Create rates the queen on date, the inspection date or now. An inspection must be of the hive the queen is in
+func (r *QueenRating) Create(familyID string, input QueenRatingInput) (*QueenRating, error) {
+	if err := validateQueenRatingInput(input); err != nil {
+		return nil, err
+	}
+	ratedAt, err := parseOptionalDateTimeInput("date", input.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	var hiveID *int
+	err = tx.Get(&hiveID, `SELECT hive_id FROM families WHERE id=? AND user_id=? AND active=1 LIMIT 1`, familyID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("queen not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if input.InspectionID != nil {
+		inspection := struct {
+			HiveID int    `db:"hive_id"`
+			Added  string `db:"added"`
+		}{}
+		err = tx.Get(&inspection, `SELECT hive_id, added FROM inspections WHERE id=? AND user_id=? AND active=1 LIMIT 1`, *input.InspectionID, r.UserID)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, errors.New("inspection not found")
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if hiveID == nil || inspection.HiveID != *hiveID {
+			tx.Rollback()
+			return nil, errors.New("inspection is not of the hive the queen is in")
+		}
+		if ratedAt == nil {
+			inspectedAt, err := parseDBDateTime(inspection.Added)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			formatted := inspectedAt.Format(mysqlDateTimeFormat)
+			ratedAt = &formatted
+		}
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO queen_ratings (user_id, family_id, hive_id, inspection_id, rated_at,
+			temperament, calmness, swarming, hygiene, honey_yield, varroa_tolerance, notes)
+		VALUES (:userID, :familyID, :hiveID, :inspectionID, COALESCE(:ratedAt, NOW()),
+			:temperament, :calmness, :swarming, :hygiene, :honeyYield, :varroaTolerance, :notes)`,
+		map[string]interface{}{
+			"userID":          r.UserID,
+			"familyID":        familyID,
+			"hiveID":          hiveID,
+			"inspectionID":    input.InspectionID,
+			"ratedAt":         ratedAt,
+			"temperament":     input.Temperament,
+			"calmness":        input.Calmness,
+			"swarming":        input.Swarming,
+			"hygiene":         input.Hygiene,
+			"honeyYield":      input.HoneyYield,
+			"varroaTolerance": input.VarroaTolerance,
+			"notes":           input.Notes,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	rating := QueenRating{}
+	err = tx.Get(&rating, `SELECT `+queenRatingColumns+` FROM queen_ratings WHERE id=? LIMIT 1`, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if hiveID != nil {
+		err = recordHiveEventTx(tx, r.UserID, strconv.Itoa(*hiveID), "queen_rating", rating.ID, "created", rating)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &rating, nil
+}
+
+func (r *QueenRating) Delete(id string) (bool, error) {
+	rating, err := r.Get(id)
+	if err != nil || rating == nil {
+		return false, err
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.Exec(`UPDATE queen_ratings SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if rating.HiveID != nil {
+		err = recordHiveEventTx(tx, r.UserID, *rating.HiveID, "queen_rating", id, "deleted", rating)
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// Ranking ranks queens in hives of the apiary, or in all hives and the warehouse, by QueenScore.
+// Queens without a score come last, lost and superseded queens are left out
+func (r *QueenRating) Ranking(apiaryID *string, race *string, weights *QueenRatingWeights) ([]*QueenRank, error) {
+	// checks the weights before reading any queen
+	if _, err := QueenScore(&QueenTraitScores{}, weights); err != nil {
+		return nil, err
+	}
+
+	condition := `(h.id IS NOT NULL OR f.hive_id IS NULL)`
+	args := []interface{}{r.UserID}
+	if apiaryID != nil {
+		condition = `h.apiary_id=?`
+		args = append(args, *apiaryID)
+	}
+	if race != nil && strings.TrimSpace(*race) != "" {
+		condition += ` AND f.race=?`
+		args = append(args, strings.TrimSpace(*race))
+	}
+
+	families := []*Family{}
+	err := r.Db.Select(&families,
+		`SELECT f.*
+		FROM families f
+		LEFT JOIN hives h ON h.id = f.hive_id AND h.user_id = f.user_id AND h.active=1
+		WHERE f.user_id=? AND f.active=1 AND `+condition+`
+		ORDER BY f.id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(families) == 0 {
+		return []*QueenRank{}, nil
+	}
+	setFamilyAges(families...)
+
+	familyIDs := make([]string, 0, len(families))
+	for _, family := range families {
+		familyIDs = append(familyIDs, family.ID)
+	}
+
+	query, queryArgs, err := sqlx.In(
+		`SELECT family_id, event_type
+		FROM queen_events
+		WHERE user_id=? AND active=1 AND family_id IN (?)
+		ORDER BY occurred_at ASC, id ASC`, r.UserID, familyIDs)
+	if err != nil {
+		return nil, err
+	}
+	events := []*QueenEvent{}
+	if err = r.Db.Select(&events, r.Db.Rebind(query), queryArgs...); err != nil {
+		return nil, err
+	}
+	eventsByFamily := map[string][]*QueenEvent{}
+	for _, event := range events {
+		eventsByFamily[event.FamilyID] = append(eventsByFamily[event.FamilyID], event)
+	}
+
+	query, queryArgs, err = sqlx.In(
+		`SELECT family_id, COUNT(*) AS rating_count,
+			AVG(temperament) AS temperament, AVG(calmness) AS calmness, AVG(swarming) AS swarming,
+			AVG(hygiene) AS hygiene, AVG(honey_yield) AS honey_yield, AVG(varroa_tolerance) AS varroa_tolerance
+		FROM queen_ratings
+		WHERE user_id=? AND active=1 AND family_id IN (?)
+		GROUP BY family_id`, r.UserID, familyIDs)
+	if err != nil {
+		return nil, err
+	}
+	averages := []struct {
+		FamilyID    string `db:"family_id"`
+		RatingCount int    `db:"rating_count"`
+		QueenTraitScores
+	}{}
+	if err = r.Db.Select(&averages, r.Db.Rebind(query), queryArgs...); err != nil {
+		return nil, err
+	}
+
+	ranking := []*QueenRank{}
+	for _, family := range families {
+		status := QueenStatusFromEvents(eventsByFamily[family.ID])
+		if status != nil && (*status == QueenStatusLost || *status == QueenStatusSuperseded) {
+			continue
+		}
+
+		rank := &QueenRank{Family: family, Traits: &QueenTraitScores{}}
+		for i := range averages {
+			if averages[i].FamilyID != family.ID {
+				continue
+			}
+			rank.RatingCount = averages[i].RatingCount
+			traits := averages[i].QueenTraitScores
+			for _, average := range []*float64{traits.Temperament, traits.Calmness, traits.Swarming, traits.Hygiene, traits.HoneyYield, traits.VarroaTolerance} {
+				if average != nil {
+					*average = roundRating(*average)
+				}
+			}
+			rank.Traits = &traits
+		}
+
+		rank.Score, err = QueenScore(rank.Traits, weights)
+		if err != nil {
+			return nil, err
+		}
+		ranking = append(ranking, rank)
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		left, right := ranking[i], ranking[j]
+		if left.Score == nil || right.Score == nil {
+			return left.Score != nil
+		}
+		if *left.Score != *right.Score {
+			return *left.Score > *right.Score
+		}
+		return left.RatingCount > right.RatingCount
+	})
+	for i, rank := range ranking {
+		rank.Rank = i + 1
+	}
+
+	return ranking, nil
+}
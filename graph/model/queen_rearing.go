@@ -0,0 +1,439 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueenRearingBatch is queen cells grafted from larvae of one mother queen on one day
+type QueenRearingBatch struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID            string  `json:"id" db:"id"`
+	MotherID      string  `json:"motherId" db:"mother_id"`
+	GraftedAt     string  `json:"graftedAt" db:"grafted_at"`
+	CellsGrafted  int     `json:"cellsGrafted" db:"cells_grafted"`
+	CellsAccepted *int    `json:"cellsAccepted" db:"cells_accepted"`
+	CellsCapped   *int    `json:"cellsCapped" db:"cells_capped"`
+	CellsEmerged  *int    `json:"cellsEmerged" db:"cells_emerged"`
+	Notes         *string `json:"notes" db:"notes"`
+}
+
+// QueenRearingMilestone is a day of a batch counted from grafting
+type QueenRearingMilestone struct {
+	Batch *QueenRearingBatch        `json:"batch"`
+	Type  QueenRearingMilestoneType `json:"type"`
+	Day   int                       `json:"day"`
+	Date  string                    `json:"date"`
+}
+
+// queenRearingDays are the usual days after grafting, milestones vary by a day with weather and bee race
+var queenRearingDays = []struct {
+	Type QueenRearingMilestoneType
+	Day  int
+}{
+	{QueenRearingMilestoneTypeGraft, 0},
+	{QueenRearingMilestoneTypeCapping, 5},
+	{QueenRearingMilestoneTypeEmergence, 12},
+	{QueenRearingMilestoneTypeMatingCheck, 26},
+}
+
+// lastQueenRearingDay is the day of the mating check, the end of a batch
+const lastQueenRearingDay = 26
+
+// DefaultQueenRearingCalendarDays is how far ahead the calendar looks by default
+const DefaultQueenRearingCalendarDays = 30
+
+const queenRearingBatchColumns = `id, user_id, mother_id, grafted_at, cells_grafted, cells_accepted, cells_capped, cells_emerged, notes`
+
+// QueenRearingMilestones returns graft, capping, emergence and mating check dates of the batch
+func QueenRearingMilestones(batch *QueenRearingBatch) ([]*QueenRearingMilestone, error) {
+	graftedAt, err := parseDBDateTime(batch.GraftedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones := make([]*QueenRearingMilestone, 0, len(queenRearingDays))
+	for _, milestone := range queenRearingDays {
+		milestones = append(milestones, &QueenRearingMilestone{
+			Batch: batch,
+			Type:  milestone.Type,
+			Day:   milestone.Day,
+			Date:  graftedAt.AddDate(0, 0, milestone.Day).Format(mysqlDateTimeFormat),
+		})
+	}
+
+	return milestones, nil
+}
+
+func (r *QueenRearingBatch) Get(id string) (*QueenRearingBatch, error) {
+	batch := QueenRearingBatch{}
+	err := r.Db.Get(&batch,
+		`SELECT `+queenRearingBatchColumns+`
+		FROM queen_rearing_batches
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// List returns batches newest graft first. In progress batches have emerged cells not counted yet
+// or the mating check still ahead
+func (r *QueenRearingBatch) List(inProgress bool) ([]*QueenRearingBatch, error) {
+	condition := ``
+	args := []interface{}{r.UserID}
+	if inProgress {
+		condition = ` AND (cells_emerged IS NULL OR grafted_at > NOW() - INTERVAL ? DAY)`
+		args = append(args, lastQueenRearingDay)
+	}
+
+	list := []*QueenRearingBatch{}
+	err := r.Db.Select(&list,
+		`SELECT `+queenRearingBatchColumns+`
+		FROM queen_rearing_batches
+		WHERE user_id=? AND active=1`+condition+`
+		ORDER BY grafted_at DESC, id DESC`, args...)
+
+	return list, err
+}
+
+// Calendar returns milestones of all batches dated between from and to, soonest first
+func (r *QueenRearingBatch) Calendar(from *string, to *string) ([]*QueenRearingMilestone, error) {
+	fromDate, err := parseOptionalDateTimeInput("from", from)
+	if err != nil {
+		return nil, err
+	}
+	toDate, err := parseOptionalDateTimeInput("to", to)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	if fromDate != nil {
+		start, _ = time.Parse(mysqlDateTimeFormat, *fromDate)
+	}
+	end := start.AddDate(0, 0, DefaultQueenRearingCalendarDays)
+	if toDate != nil {
+		end, _ = time.Parse(mysqlDateTimeFormat, *toDate)
+	}
+	if end.Before(start) {
+		return nil, errors.New("to must not be before from")
+	}
+
+	batches := []*QueenRearingBatch{}
+	err = r.Db.Select(&batches,
+		`SELECT `+queenRearingBatchColumns+`
+		FROM queen_rearing_batches
+		WHERE user_id=? AND active=1 AND grafted_at BETWEEN ? AND ?
+		ORDER BY grafted_at ASC, id ASC`,
+		r.UserID, start.AddDate(0, 0, -lastQueenRearingDay).Format(mysqlDateTimeFormat), end.Format(mysqlDateTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := []*QueenRearingMilestone{}
+	for _, batch := range batches {
+		milestones, err := QueenRearingMilestones(batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, milestone := range milestones {
+			date, _ := time.Parse(mysqlDateTimeFormat, milestone.Date)
+			if date.Before(start) || date.After(end) {
+				continue
+			}
+			calendar = append(calendar, milestone)
+		}
+	}
+
+	sort.SliceStable(calendar, func(i, j int) bool {
+		return calendar[i].Date < calendar[j].Date
+	})
+
+	return calendar, nil
+}
+
+func validateQueenRearingNotes(notes *string) error {
+	if notes != nil && len(*notes) > 2000 {
+		return errors.New("notes must be at most 2000 characters")
+	}
+	return nil
+}
+
+// validateQueenCellCounts checks that each count is at most the one of the step before it
+func validateQueenCellCounts(grafted int, accepted *int, capped *int, emerged *int) error {
+	limit := grafted
+	limitName := "cellsGrafted"
+	for _, count := range []struct {
+		Name  string
+		Value *int
+	}{
+		{"cellsAccepted", accepted},
+		{"cellsCapped", capped},
+		{"cellsEmerged", emerged},
+	} {
+		if count.Value == nil {
+			continue
+		}
+		if *count.Value < 0 || *count.Value > limit {
+			return errors.New(count.Name + " must be between 0 and " + limitName)
+		}
+		limit = *count.Value
+		limitName = count.Name
+	}
+
+	return nil
+}
+
+// Create starts a batch grafted from larvae of the mother queen, on graftedAt or now
+func (r *QueenRearingBatch) Create(input QueenRearingBatchInput) (*QueenRearingBatch, error) {
+	if input.CellsGrafted < 1 || input.CellsGrafted > 1000 {
+		return nil, errors.New("cellsGrafted must be between 1 and 1000")
+	}
+	if err := validateQueenRearingNotes(input.Notes); err != nil {
+		return nil, err
+	}
+	graftedAt, err := parseOptionalDateTimeInput("graftedAt", input.GraftedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	mother, err := (&Family{Db: r.Db, UserID: r.UserID}).GetAny(input.MotherID)
+	if err != nil {
+		return nil, err
+	}
+	if mother == nil {
+		return nil, errors.New("mother queen not found")
+	}
+
+	result, err := r.Db.NamedExec(
+		`INSERT INTO queen_rearing_batches (user_id, mother_id, grafted_at, cells_grafted, notes)
+		VALUES (:userID, :motherID, COALESCE(:graftedAt, NOW()), :cellsGrafted, :notes)`,
+		map[string]interface{}{
+			"userID":       r.UserID,
+			"motherID":     input.MotherID,
+			"graftedAt":    graftedAt,
+			"cellsGrafted": input.CellsGrafted,
+			"notes":        input.Notes,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Get(strconv.FormatInt(id, 10))
+}
+
+// UpdateProgress records cell counts, counts left empty keep their value
+func (r *QueenRearingBatch) UpdateProgress(id string, input QueenRearingProgressInput) (*QueenRearingBatch, error) {
+	if err := validateQueenRearingNotes(input.Notes); err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	batch := QueenRearingBatch{}
+	err := tx.Get(&batch,
+		`SELECT `+queenRearingBatchColumns+`
+		FROM queen_rearing_batches
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1
+		FOR UPDATE`, id, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("queen rearing batch not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if input.CellsAccepted != nil {
+		batch.CellsAccepted = input.CellsAccepted
+	}
+	if input.CellsCapped != nil {
+		batch.CellsCapped = input.CellsCapped
+	}
+	if input.CellsEmerged != nil {
+		batch.CellsEmerged = input.CellsEmerged
+	}
+	if input.Notes != nil {
+		batch.Notes = input.Notes
+	}
+	if err = validateQueenCellCounts(batch.CellsGrafted, batch.CellsAccepted, batch.CellsCapped, batch.CellsEmerged); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if batch.CellsEmerged != nil {
+		var queens int
+		err = tx.Get(&queens, `SELECT COUNT(*) FROM families WHERE rearing_batch_id=? AND user_id=?`, id, r.UserID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if *batch.CellsEmerged < queens {
+			tx.Rollback()
+			return nil, errors.New("cellsEmerged is less than the queens already created from the batch")
+		}
+	}
+
+	_, err = tx.NamedExec(
+		`UPDATE queen_rearing_batches
+		SET cells_accepted=:cellsAccepted, cells_capped=:cellsCapped, cells_emerged=:cellsEmerged, notes=:notes
+		WHERE id=:id AND user_id=:userID`,
+		map[string]interface{}{
+			"id":            id,
+			"userID":        r.UserID,
+			"cellsAccepted": batch.CellsAccepted,
+			"cellsCapped":   batch.CellsCapped,
+			"cellsEmerged":  batch.CellsEmerged,
+			"notes":         batch.Notes,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(id)
+}
+
+func (r *QueenRearingBatch) Delete(id string) (bool, error) {
+	result, err := r.Db.Exec(
+		`UPDATE queen_rearing_batches SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// CreateQueen adds an emerged virgin queen of the batch to the warehouse with the batch mother as her mother.
+// Race defaults to the race of the mother and added to the emergence year
+func (r *QueenRearingBatch) CreateQueen(batchID string, queen FamilyInput) (*int, error) {
+	tx := r.Db.MustBegin()
+
+	batch := QueenRearingBatch{}
+	err := tx.Get(&batch,
+		`SELECT `+queenRearingBatchColumns+`
+		FROM queen_rearing_batches
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1
+		FOR UPDATE`, batchID, r.UserID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, errors.New("queen rearing batch not found")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var queens int
+	err = tx.Get(&queens, `SELECT COUNT(*) FROM families WHERE rearing_batch_id=? AND user_id=?`, batchID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if batch.CellsEmerged == nil || queens >= *batch.CellsEmerged {
+		tx.Rollback()
+		return nil, errors.New("no emerged queens of the batch are left, record cellsEmerged first")
+	}
+
+	race := queen.Race
+	if race == nil {
+		err = tx.Get(&race, `SELECT race FROM families WHERE id=? AND user_id=?`, batch.MotherID, r.UserID)
+		if err != nil && err != sql.ErrNoRows {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	added := queen.Added
+	if added == nil {
+		milestones, err := QueenRearingMilestones(&batch)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		for _, milestone := range milestones {
+			if milestone.Type == QueenRearingMilestoneTypeEmergence {
+				year := milestone.Date[:4]
+				added = &year
+			}
+		}
+	}
+
+	result, err := tx.NamedExec(
+		`INSERT INTO families (user_id, name, race, added, color, mother_id, rearing_batch_id)
+		VALUES (:userID, :name, :race, :added, :color, :motherID, :rearingBatchID)`,
+		map[string]interface{}{
+			"userID":         r.UserID,
+			"name":           queen.Name,
+			"race":           race,
+			"added":          added,
+			"color":          queen.Color,
+			"motherID":       batch.MotherID,
+			"rearingBatchID": batchID,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	familyID := int(id)
+
+	if err = (&Family{Db: r.Db, UserID: r.UserID}).recordChangeTx(tx, familyID, nil, "created", true); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &familyID, tx.Commit()
+}
+
+// ListQueens returns queens created from the batch that are still kept
+func (r *QueenRearingBatch) ListQueens(batchID string) ([]*Family, error) {
+	families := []*Family{}
+	err := r.Db.Select(&families,
+		`SELECT *
+		FROM families
+		WHERE rearing_batch_id=? AND user_id=? AND active=1
+		ORDER BY id ASC`, batchID, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	setFamilyAges(families...)
+	return families, nil
+}
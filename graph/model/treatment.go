@@ -2,7 +2,9 @@ package model
 
 import (
 	"database/sql"
+	"errors"
 	"strconv"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
@@ -18,100 +20,312 @@ type Treatment struct {
 	FamilyId string  `json:"family_id" db:"family_id"`
 	Added    string  `json:"added" db:"added"`
 	Type     string  `json:"type" db:"type"`
+
+	ProductID        *string              `json:"product_id" db:"product_id"`
+	CourseID         *string              `json:"course_id" db:"course_id"`
+	Dose             *float64             `json:"dose" db:"dose"`
+	DoseUnit         *TreatmentDosageUnit `json:"dose_unit" db:"dose_unit"`
+	StartDate        *string              `json:"start_date" db:"start_date"`
+	EndDate          *string              `json:"end_date" db:"end_date"`
+	HoneySupersOff   bool                 `json:"honey_supers_off" db:"honey_supers_off"`
+	WithdrawalEndsAt *string              `json:"withdrawal_ends_at" db:"withdrawal_ends_at"`
+
+	Product *TreatmentProduct `json:"product" db:"-"`
 }
 
 func (Treatment) IsEntity() {}
 
+// treatmentSelect reads treatments with the end of their withdrawal period,
+// treatments without a catalog product have none
+const treatmentSelect = `SELECT t.id, t.user_id, t.box_id, t.hive_id, t.family_id, t.added, t.type,
+		t.product_id, t.course_id, t.dose, t.dose_unit, t.start_date, t.end_date, t.honey_supers_off,
+		CASE WHEN p.id IS NULL THEN NULL
+			ELSE DATE_ADD(COALESCE(t.end_date, t.start_date, t.added), INTERVAL p.withdrawal_days DAY)
+		END AS withdrawal_ends_at
+	FROM treatments t
+	LEFT JOIN treatment_products p ON p.id = t.product_id`
+
 func (r *Treatment) Get(id string) (*Treatment, error) {
 	result := Treatment{}
 	err2 := r.Db.Get(&result,
-		`SELECT * 
-		FROM treatments 
-		WHERE id=? AND user_id=?
+		treatmentSelect+`
+		WHERE t.id=? AND t.user_id=?
 		LIMIT 1`, id, r.UserID)
 
 	if err2 == sql.ErrNoRows {
 		return nil, nil
 	}
+	if err2 != nil {
+		return nil, err2
+	}
 
-	return &result, err2
+	return &result, r.hydrate(&result)
 }
 
 func (r *Treatment) ListFamilyTreatments(familyId string) ([]*Treatment, error) {
 	results := []*Treatment{}
 	err2 := r.Db.Select(&results,
-		`SELECT * 
-		FROM treatments
-		WHERE user_id=? AND family_id=?
-		ORDER BY added DESC
+		treatmentSelect+`
+		WHERE t.user_id=? AND t.family_id=?
+		ORDER BY t.added DESC, t.id DESC
 		LIMIT 30`, r.UserID, familyId)
+	if err2 != nil {
+		return nil, err2
+	}
 
-	return results, err2
+	return results, r.hydrate(results...)
+}
+
+// ListAllFamilyTreatments returns every treatment of the family, oldest first
+func (r *Treatment) ListAllFamilyTreatments(familyId string) ([]*Treatment, error) {
+	results := []*Treatment{}
+	err := r.Db.Select(&results,
+		treatmentSelect+`
+		WHERE t.user_id=? AND t.family_id=?
+		ORDER BY COALESCE(t.start_date, t.added) ASC, t.id ASC`, r.UserID, familyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, r.hydrate(results...)
 }
 
 func (r *Treatment) GetLastFamilyTreatment(familyId string) (*Treatment, error) {
 	result := Treatment{}
 	err2 := r.Db.Get(&result,
-		`SELECT * 
-		FROM treatments
-		WHERE user_id=? AND family_id=?
-		ORDER BY added DESC
+		treatmentSelect+`
+		WHERE t.user_id=? AND t.family_id=?
+		ORDER BY t.added DESC, t.id DESC
 		LIMIT 1`, r.UserID, familyId)
 
 	if err2 == sql.ErrNoRows {
 		return nil, nil
 	}
+	if err2 != nil {
+		return nil, err2
+	}
 
-	return &result, err2
+	return &result, r.hydrate(&result)
 }
 
-func (r *Treatment) TreatHive(input TreatmentOfHiveInput, familyId *int) (*Treatment, error) {
-	tx := r.Db.MustBegin()
+// ListByHivesAndFamiliesBetween returns treatments of any of the hives or families applied from since until until, oldest first
+func (r *Treatment) ListByHivesAndFamiliesBetween(hiveIDs []string, familyIDs []string, since string, until string) ([]*Treatment, error) {
+	results := []*Treatment{}
+	if len(hiveIDs) == 0 && len(familyIDs) == 0 {
+		return results, nil
+	}
 
-	result, err := tx.NamedExec(
-		"INSERT INTO treatments (type, hive_id, user_id, family_id) VALUES (:type, :hiveId, :userID, :familyId)",
-		map[string]interface{}{
-			"userID":   r.UserID,
-			"type":     input.Type,
-			"hiveId":   input.HiveID,
-			"familyId": familyId,
-		})
+	sources := []string{}
+	args := []interface{}{r.UserID, since, until}
+	if len(hiveIDs) > 0 {
+		sources = append(sources, `t.hive_id IN (?)`)
+		args = append(args, hiveIDs)
+	}
+	if len(familyIDs) > 0 {
+		sources = append(sources, `t.family_id IN (?)`)
+		args = append(args, familyIDs)
+	}
 
+	query, args, err := sqlx.In(
+		treatmentSelect+`
+		WHERE t.user_id=? AND COALESCE(t.start_date, t.added) BETWEEN ? AND ?
+		  AND (`+strings.Join(sources, ` OR `)+`)
+		ORDER BY COALESCE(t.start_date, t.added) ASC, t.id ASC`, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	id, err := result.LastInsertId()
-	tx.Commit()
+	if err = r.Db.Select(&results, query, args...); err != nil {
+		return nil, err
+	}
+
+	return results, r.hydrate(results...)
+}
 
+// listByCourses returns the doses recorded for the courses, oldest first
+func (r *Treatment) listByCourses(courseIDs []string) ([]*Treatment, error) {
+	query, args, err := sqlx.In(
+		treatmentSelect+`
+		WHERE t.user_id=? AND t.course_id IN (?)
+		ORDER BY COALESCE(t.start_date, t.added) ASC, t.id ASC`, r.UserID, courseIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.Get(strconv.Itoa(int(id)))
+	results := []*Treatment{}
+	if err = r.Db.Select(&results, query, args...); err != nil {
+		return nil, err
+	}
+
+	return results, r.hydrate(results...)
+}
+
+func (r *Treatment) hydrate(treatments ...*Treatment) error {
+	productIDs := []string{}
+	for _, treatment := range treatments {
+		if treatment.ProductID != nil {
+			productIDs = append(productIDs, *treatment.ProductID)
+		}
+	}
+
+	products, err := loadTreatmentProducts(r.Db, r.UserID, productIDs)
+	if err != nil {
+		return err
+	}
+	for _, treatment := range treatments {
+		if treatment.ProductID != nil {
+			treatment.Product = products[*treatment.ProductID]
+		}
+	}
+
+	return nil
+}
+
+func (r *Treatment) recordCreatedTx(tx *sqlx.Tx, id int64, values map[string]interface{}) error {
+	hiveID := values["hiveId"].(string)
+	return recordHiveEventTx(tx, r.UserID, hiveID, "treatment", strconv.FormatInt(id, 10), "created", map[string]interface{}{
+		"id":               id,
+		"hive_id":          hiveID,
+		"box_id":           values["boxId"],
+		"family_id":        values["familyId"],
+		"type":             values["type"],
+		"product_id":       values["productId"],
+		"course_id":        values["courseId"],
+		"dose":             values["dose"],
+		"dose_unit":        values["doseUnit"],
+		"start_date":       values["startDate"],
+		"end_date":         values["endDate"],
+		"honey_supers_off": values["honeySupersOff"],
+	})
+}
+
+func (r *Treatment) TreatHive(input TreatmentOfHiveInput, familyId *int) (*Treatment, error) {
+	return r.create(input, nil, familyId)
 }
 
 func (r *Treatment) TreatHiveBox(input TreatmentOfBoxInput, familyId *int) (*Treatment, error) {
+	return r.create(TreatmentOfHiveInput{
+		HiveID:         input.HiveID,
+		Type:           input.Type,
+		ProductID:      input.ProductID,
+		CourseID:       input.CourseID,
+		Dose:           input.Dose,
+		StartDate:      input.StartDate,
+		EndDate:        input.EndDate,
+		HoneySupersOff: input.HoneySupersOff,
+	}, &input.BoxID, familyId)
+}
+
+// create records a treatment dose. Doses of a course take the course product and settings,
+// the course ends with its last planned dose.
+func (r *Treatment) create(input TreatmentOfHiveInput, boxID *string, familyId *int) (*Treatment, error) {
+	startDate, err := parseOptionalDateTimeInput("startDate", input.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := parseOptionalDateTimeInput("endDate", input.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if startDate != nil && endDate != nil && *endDate < *startDate {
+		return nil, errors.New("treatment endDate must not be before startDate")
+	}
+	if input.Dose != nil && *input.Dose <= 0 {
+		return nil, errors.New("treatment dose must be positive")
+	}
+
 	tx := r.Db.MustBegin()
 
+	var course *TreatmentCourse
+	productID := input.ProductID
+	honeySupersOff := input.HoneySupersOff != nil && *input.HoneySupersOff
+	if input.CourseID != nil {
+		course, err = (&TreatmentCourse{Db: r.Db, UserID: r.UserID}).lockRunningTx(tx, *input.CourseID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if course.HiveID != input.HiveID {
+			tx.Rollback()
+			return nil, errors.New("treatment course belongs to another hive")
+		}
+		if productID == nil {
+			productID = &course.ProductID
+		} else if *productID != course.ProductID {
+			tx.Rollback()
+			return nil, errors.New("treatment product differs from the product of the course")
+		}
+		if input.HoneySupersOff == nil {
+			honeySupersOff = course.HoneySupersOff
+		}
+	}
+
+	dose := input.Dose
+	var doseUnit *TreatmentDosageUnit
+	if productID != nil {
+		product, err := (&TreatmentProduct{Db: r.Db, UserID: r.UserID}).getTx(tx, *productID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if product == nil {
+			tx.Rollback()
+			return nil, errors.New("treatment product not found")
+		}
+		doseUnit = &product.DosageUnit
+		if dose == nil {
+			dose = product.DefaultDose
+		}
+	}
+
+	values := map[string]interface{}{
+		"userID":         r.UserID,
+		"type":           input.Type,
+		"hiveId":         input.HiveID,
+		"boxId":          boxID,
+		"familyId":       familyId,
+		"productId":      productID,
+		"courseId":       input.CourseID,
+		"dose":           dose,
+		"doseUnit":       doseUnit,
+		"startDate":      startDate,
+		"endDate":        endDate,
+		"honeySupersOff": honeySupersOff,
+	}
 	result, err := tx.NamedExec(
-		"INSERT INTO treatments (type, hive_id, box_id, user_id, family_id) VALUES (:type, :hiveId, :boxId, :userID, familyId)",
-		map[string]interface{}{
-			"userID":   r.UserID,
-			"type":     input.Type,
-			"hiveId":   input.HiveID,
-			"familyId": familyId,
-			"boxId":    input.BoxID,
-		})
+		`INSERT INTO treatments (type, hive_id, box_id, user_id, family_id, product_id, course_id, dose, dose_unit, start_date, end_date, honey_supers_off)
+		VALUES (:type, :hiveId, :boxId, :userID, :familyId, :productId, :courseId, :dose, :doseUnit, :startDate, :endDate, :honeySupersOff)`,
+		values)
 
 	if err != nil {
+		tx.Rollback()
 		return nil, err
 	}
 
 	id, err := result.LastInsertId()
-	tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if course != nil && course.ApplicationCount+1 >= course.PlannedApplications {
+		_, err = tx.Exec(
+			`UPDATE treatment_courses
+			SET ended_at=COALESCE(?, ?, NOW())
+			WHERE id=? AND user_id=?`, endDate, startDate, course.ID, r.UserID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
+	err = r.recordCreatedTx(tx, id, values)
 	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
 
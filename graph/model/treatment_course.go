@@ -0,0 +1,323 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TreatmentCourse is a planned series of treatment applications of one product in a hive
+type TreatmentCourse struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID                  string  `json:"id" db:"id"`
+	HiveID              string  `json:"hiveId" db:"hive_id"`
+	FamilyID            *string `json:"familyId" db:"family_id"`
+	ProductID           string  `json:"-" db:"product_id"`
+	PlannedApplications int     `json:"plannedApplications" db:"planned_applications"`
+	IntervalDays        int     `json:"intervalDays" db:"interval_days"`
+	HoneySupersOff      bool    `json:"honeySupersOff" db:"honey_supers_off"`
+	StartedAt           string  `json:"startedAt" db:"started_at"`
+	EndedAt             *string `json:"endedAt" db:"ended_at"`
+
+	ApplicationCount  int     `json:"-" db:"application_count"`
+	NextApplicationAt *string `json:"nextApplicationAt" db:"next_application_at"`
+	WithdrawalEndsAt  *string `json:"withdrawalEndsAt" db:"withdrawal_ends_at"`
+
+	Product      *TreatmentProduct `json:"product" db:"-"`
+	Applications []*Treatment      `json:"applications" db:"-"`
+}
+
+// HiveWithdrawal is a hive where honey can not be harvested until WithdrawalEndsAt
+type HiveWithdrawal struct {
+	HiveID           string  `json:"hiveId" db:"hive_id"`
+	ApiaryID         *string `json:"apiaryId" db:"apiary_id"`
+	WithdrawalEndsAt string  `json:"withdrawalEndsAt" db:"withdrawal_ends_at"`
+	DaysRemaining    int     `json:"daysRemaining" db:"days_remaining"`
+}
+
+// treatmentCourseSelect computes progress of courses from their recorded applications.
+// A running course is expected to last until its last planned dose, so its withdrawal covers all of them.
+const treatmentCourseSelect = `SELECT c.id, c.user_id, c.hive_id, c.family_id, c.product_id, c.planned_applications,
+		c.interval_days, c.honey_supers_off, c.started_at, c.ended_at,
+		COALESCE(a.application_count, 0) AS application_count,
+		CASE
+			WHEN c.ended_at IS NOT NULL OR COALESCE(a.application_count, 0) >= c.planned_applications THEN NULL
+			WHEN a.last_start IS NULL THEN c.started_at
+			ELSE DATE_ADD(a.last_start, INTERVAL c.interval_days DAY)
+		END AS next_application_at,
+		CASE
+			WHEN c.ended_at IS NOT NULL THEN DATE_ADD(a.last_end, INTERVAL p.withdrawal_days DAY)
+			ELSE DATE_ADD(GREATEST(
+				DATE_ADD(c.started_at, INTERVAL (c.planned_applications - 1) * c.interval_days DAY),
+				COALESCE(a.last_end, c.started_at)
+			), INTERVAL p.withdrawal_days DAY)
+		END AS withdrawal_ends_at
+	FROM treatment_courses c
+	JOIN treatment_products p ON p.id = c.product_id
+	LEFT JOIN (
+		SELECT course_id, COUNT(*) AS application_count,
+			MAX(COALESCE(start_date, added)) AS last_start,
+			MAX(COALESCE(end_date, start_date, added)) AS last_end
+		FROM treatments
+		WHERE user_id=? AND course_id IS NOT NULL
+		GROUP BY course_id
+	) a ON a.course_id = c.id`
+
+func (r *TreatmentCourse) Get(id string) (*TreatmentCourse, error) {
+	course := TreatmentCourse{}
+	err := r.Db.Get(&course,
+		treatmentCourseSelect+`
+		WHERE c.id=? AND c.user_id=? AND c.active=1
+		LIMIT 1`, r.UserID, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &course, r.hydrate(&course)
+}
+
+// ListByHive returns courses of the hive, newest first. Running courses only, unless includeFinished is set
+func (r *TreatmentCourse) ListByHive(hiveID string, includeFinished bool) ([]*TreatmentCourse, error) {
+	condition := ` AND c.ended_at IS NULL`
+	if includeFinished {
+		condition = ``
+	}
+
+	list := []*TreatmentCourse{}
+	err := r.Db.Select(&list,
+		treatmentCourseSelect+`
+		WHERE c.user_id=? AND c.hive_id=? AND c.active=1`+condition+`
+		ORDER BY c.started_at DESC, c.id DESC`, r.UserID, r.UserID, hiveID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, r.hydrate(list...)
+}
+
+// hydrate loads the product and recorded applications of courses
+func (r *TreatmentCourse) hydrate(courses ...*TreatmentCourse) error {
+	if len(courses) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(courses))
+	byID := map[string]*TreatmentCourse{}
+	for _, course := range courses {
+		course.Applications = []*Treatment{}
+		ids = append(ids, course.ID)
+		byID[course.ID] = course
+	}
+
+	treatmentModel := &Treatment{Db: r.Db, UserID: r.UserID}
+	applications, err := treatmentModel.listByCourses(ids)
+	if err != nil {
+		return err
+	}
+	for _, application := range applications {
+		if course, ok := byID[*application.CourseID]; ok {
+			course.Applications = append(course.Applications, application)
+		}
+	}
+
+	productIDs := make([]string, 0, len(courses))
+	for _, course := range courses {
+		productIDs = append(productIDs, course.ProductID)
+	}
+	products, err := loadTreatmentProducts(r.Db, r.UserID, productIDs)
+	if err != nil {
+		return err
+	}
+	for _, course := range courses {
+		course.Product = products[course.ProductID]
+	}
+
+	return nil
+}
+
+// Start plans a course of the product in the hive, familyID is the queen family being treated
+func (r *TreatmentCourse) Start(input TreatmentCourseInput, familyID *int) (*TreatmentCourse, error) {
+	if input.PlannedApplications < 1 || input.PlannedApplications > 52 {
+		return nil, errors.New("planned applications must be between 1 and 52")
+	}
+	if input.IntervalDays < 0 || input.IntervalDays > 365 {
+		return nil, errors.New("interval days must be between 0 and 365")
+	}
+	if input.PlannedApplications > 1 && input.IntervalDays == 0 {
+		return nil, errors.New("interval days are required for courses of several applications")
+	}
+	startedAt, err := parseOptionalDateTimeInput("startedAt", input.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := (&TreatmentProduct{Db: r.Db, UserID: r.UserID}).Get(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("treatment product not found")
+	}
+
+	tx := r.Db.MustBegin()
+
+	var hiveCount int
+	err = tx.Get(&hiveCount, `SELECT COUNT(*) FROM hives WHERE id=? AND user_id=? AND active=1`, input.HiveID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if hiveCount == 0 {
+		tx.Rollback()
+		return nil, errors.New("hive not found")
+	}
+
+	honeySupersOff := input.HoneySupersOff != nil && *input.HoneySupersOff
+	result, err := tx.NamedExec(
+		`INSERT INTO treatment_courses (user_id, hive_id, family_id, product_id, planned_applications, interval_days, honey_supers_off, started_at)
+		VALUES (:userID, :hiveID, :familyID, :productID, :plannedApplications, :intervalDays, :honeySupersOff, COALESCE(:startedAt, NOW()))`,
+		map[string]interface{}{
+			"userID":              r.UserID,
+			"hiveID":              input.HiveID,
+			"familyID":            familyID,
+			"productID":           product.ID,
+			"plannedApplications": input.PlannedApplications,
+			"intervalDays":        input.IntervalDays,
+			"honeySupersOff":      honeySupersOff,
+			"startedAt":           startedAt,
+		})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	courseID := strconv.FormatInt(id, 10)
+
+	err = recordHiveEventTx(tx, r.UserID, input.HiveID, "treatment_course", courseID, "created", map[string]interface{}{
+		"id":                   id,
+		"hive_id":              input.HiveID,
+		"family_id":            familyID,
+		"product_id":           product.ID,
+		"planned_applications": input.PlannedApplications,
+		"interval_days":        input.IntervalDays,
+		"honey_supers_off":     honeySupersOff,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(courseID)
+}
+
+// Finish ends a running course early, doses already given still count for the withdrawal period
+func (r *TreatmentCourse) Finish(id string) (*TreatmentCourse, error) {
+	course, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if course == nil {
+		return nil, errors.New("treatment course not found")
+	}
+	if course.EndedAt != nil {
+		return course, nil
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.Exec(
+		`UPDATE treatment_courses SET ended_at=NOW() WHERE id=? AND user_id=? AND ended_at IS NULL`,
+		id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, course.HiveID, "treatment_course", id, "finished", map[string]interface{}{
+		"id":      id,
+		"hive_id": course.HiveID,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.Get(id)
+}
+
+// lockRunningTx reads a running course of the user for update, so concurrent doses can not exceed the plan
+func (r *TreatmentCourse) lockRunningTx(tx *sqlx.Tx, id string) (*TreatmentCourse, error) {
+	course := TreatmentCourse{}
+	err := tx.Get(&course,
+		`SELECT c.id, c.user_id, c.hive_id, c.family_id, c.product_id, c.planned_applications,
+			c.interval_days, c.honey_supers_off, c.started_at, c.ended_at,
+			(SELECT COUNT(*) FROM treatments t WHERE t.course_id = c.id AND t.user_id = c.user_id) AS application_count
+		FROM treatment_courses c
+		WHERE c.id=? AND c.user_id=? AND c.active=1
+		LIMIT 1
+		FOR UPDATE`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("treatment course not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if course.EndedAt != nil || course.ApplicationCount >= course.PlannedApplications {
+		return nil, errors.New("treatment course already ended")
+	}
+
+	return &course, nil
+}
+
+// ListHivesInWithdrawal returns active hives with a treatment whose withdrawal period has not ended yet
+func (r *Treatment) ListHivesInWithdrawal(apiaryID *string) ([]*HiveWithdrawal, error) {
+	condition := ``
+	args := []interface{}{r.UserID, r.UserID, r.UserID, r.UserID}
+	if apiaryID != nil {
+		condition = ` AND h.apiary_id=?`
+		args = append(args, *apiaryID)
+	}
+
+	list := []*HiveWithdrawal{}
+	err := r.Db.Select(&list,
+		`SELECT w.hive_id, h.apiary_id, MAX(w.withdrawal_ends_at) AS withdrawal_ends_at,
+			CEIL(TIMESTAMPDIFF(MINUTE, NOW(), MAX(w.withdrawal_ends_at)) / 1440) AS days_remaining
+		FROM (
+			SELECT t.hive_id, DATE_ADD(COALESCE(t.end_date, t.start_date, t.added), INTERVAL p.withdrawal_days DAY) AS withdrawal_ends_at
+			FROM treatments t
+			JOIN treatment_products p ON p.id = t.product_id
+			WHERE t.user_id=?
+			UNION ALL
+			SELECT courses.hive_id, courses.withdrawal_ends_at
+			FROM (`+treatmentCourseSelect+`
+				WHERE c.user_id=? AND c.active=1
+			) courses
+		) w
+		JOIN hives h ON h.id = w.hive_id AND h.user_id=? AND h.active=1
+		WHERE w.withdrawal_ends_at > NOW()`+condition+`
+		GROUP BY w.hive_id, h.apiary_id
+		ORDER BY withdrawal_ends_at DESC, w.hive_id ASC`, args...)
+
+	return list, err
+}
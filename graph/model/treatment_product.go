@@ -0,0 +1,197 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TreatmentProduct is a varroa treatment from the catalog.
+// Built-in products have no user and are shared by everyone, users can add their own.
+type TreatmentProduct struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"-"`
+
+	ID               string              `json:"id" db:"id"`
+	OwnerID          *string             `json:"-" db:"user_id"`
+	Name             string              `json:"name" db:"name"`
+	ActiveIngredient *string             `json:"activeIngredient" db:"active_ingredient"`
+	DosageUnit       TreatmentDosageUnit `json:"dosageUnit" db:"dosage_unit"`
+	DefaultDose      *float64            `json:"defaultDose" db:"default_dose"`
+	WithdrawalDays   int                 `json:"withdrawalDays" db:"withdrawal_days"`
+	Added            string              `json:"-" db:"added"`
+}
+
+func (r *TreatmentProduct) Custom() bool {
+	return r.OwnerID != nil
+}
+
+const treatmentProductColumns = `id, user_id, name, active_ingredient, dosage_unit, default_dose, withdrawal_days, added`
+
+// Get returns a built-in product or a product of the user
+func (r *TreatmentProduct) Get(id string) (*TreatmentProduct, error) {
+	return r.getTx(r.Db, id)
+}
+
+func (r *TreatmentProduct) getTx(q sqlx.Queryer, id string) (*TreatmentProduct, error) {
+	product := TreatmentProduct{}
+	err := sqlx.Get(q, &product,
+		`SELECT `+treatmentProductColumns+`
+		FROM treatment_products
+		WHERE id=? AND (user_id IS NULL OR user_id=?) AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// List returns built-in products followed by the products of the user, by name
+func (r *TreatmentProduct) List() ([]*TreatmentProduct, error) {
+	list := []*TreatmentProduct{}
+	err := r.Db.Select(&list,
+		`SELECT `+treatmentProductColumns+`
+		FROM treatment_products
+		WHERE (user_id IS NULL OR user_id=?) AND active=1
+		ORDER BY user_id IS NOT NULL, name ASC, id ASC`, r.UserID)
+
+	return list, err
+}
+
+func validateTreatmentProductInput(input TreatmentProductInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.New("treatment product name is required")
+	}
+	if len(input.Name) > 100 {
+		return errors.New("treatment product name must be at most 100 characters")
+	}
+	if input.ActiveIngredient != nil && len(*input.ActiveIngredient) > 100 {
+		return errors.New("active ingredient must be at most 100 characters")
+	}
+	if !input.DosageUnit.IsValid() {
+		return errors.New("invalid dosage unit")
+	}
+	if input.DefaultDose != nil && *input.DefaultDose <= 0 {
+		return errors.New("default dose must be positive")
+	}
+	if input.WithdrawalDays < 0 || input.WithdrawalDays > 365 {
+		return errors.New("withdrawal days must be between 0 and 365")
+	}
+
+	return nil
+}
+
+// loadTreatmentProducts reads products by id, including ones removed from the catalog after use
+func loadTreatmentProducts(q sqlx.Queryer, userID string, ids []string) (map[string]*TreatmentProduct, error) {
+	products := map[string]*TreatmentProduct{}
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT `+treatmentProductColumns+`
+		FROM treatment_products
+		WHERE id IN (?) AND (user_id IS NULL OR user_id=?)`, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*TreatmentProduct{}
+	if err = sqlx.Select(q, &list, query, args...); err != nil {
+		return nil, err
+	}
+	for _, product := range list {
+		products[product.ID] = product
+	}
+
+	return products, nil
+}
+
+// Create adds a product to the catalog of the user
+func (r *TreatmentProduct) Create(input TreatmentProductInput) (*TreatmentProduct, error) {
+	if err := validateTreatmentProductInput(input); err != nil {
+		return nil, err
+	}
+
+	result, err := r.Db.NamedExec(
+		`INSERT INTO treatment_products (user_id, name, active_ingredient, dosage_unit, default_dose, withdrawal_days)
+		VALUES (:userID, :name, :activeIngredient, :dosageUnit, :defaultDose, :withdrawalDays)`,
+		map[string]interface{}{
+			"userID":           r.UserID,
+			"name":             strings.TrimSpace(input.Name),
+			"activeIngredient": input.ActiveIngredient,
+			"dosageUnit":       input.DosageUnit,
+			"defaultDose":      input.DefaultDose,
+			"withdrawalDays":   input.WithdrawalDays,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Get(strconv.FormatInt(id, 10))
+}
+
+// Update changes a product of the user, built-in products can not be changed
+func (r *TreatmentProduct) Update(id string, input TreatmentProductInput) (*TreatmentProduct, error) {
+	if err := validateTreatmentProductInput(input); err != nil {
+		return nil, err
+	}
+
+	result, err := r.Db.NamedExec(
+		`UPDATE treatment_products
+		SET name=:name, active_ingredient=:activeIngredient, dosage_unit=:dosageUnit,
+			default_dose=:defaultDose, withdrawal_days=:withdrawalDays
+		WHERE id=:id AND user_id=:userID AND active=1`,
+		map[string]interface{}{
+			"id":               id,
+			"userID":           r.UserID,
+			"name":             strings.TrimSpace(input.Name),
+			"activeIngredient": input.ActiveIngredient,
+			"dosageUnit":       input.DosageUnit,
+			"defaultDose":      input.DefaultDose,
+			"withdrawalDays":   input.WithdrawalDays,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 && (product == nil || !product.Custom()) {
+		return nil, errors.New("treatment product not found")
+	}
+
+	return product, nil
+}
+
+// Delete hides a product of the user from the catalog, treatments recorded with it keep referencing it
+func (r *TreatmentProduct) Delete(id string) (bool, error) {
+	result, err := r.Db.Exec(
+		`UPDATE treatment_products SET active=0 WHERE id=? AND user_id=? AND active=1`,
+		id, r.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// UserBillingPlan keeps the last billing plan seen for apiary owners.
+// Plans come with the request token, so hives added by collaborators are limited by the stored plan of the owner.
+type UserBillingPlan struct {
+	Db *sqlx.DB
+}
+
+func (r *UserBillingPlan) Remember(userID string, plan string) error {
+	_, err := r.Db.Exec(
+		`INSERT INTO user_billing_plans (user_id, billing_plan) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE billing_plan=VALUES(billing_plan)`, userID, plan)
+
+	return err
+}
+
+// Get returns nil when no plan of the user was seen yet
+func (r *UserBillingPlan) Get(userID string) (*string, error) {
+	var plan string
+	err := r.Db.Get(&plan, `SELECT billing_plan FROM user_billing_plans WHERE user_id=? LIMIT 1`, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
@@ -0,0 +1,273 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// VarroaCount is a mite count of a hive. Bee samples give an infestation rate,
+// sticky boards only a daily mite drop.
+type VarroaCount struct {
+	Db     *sqlx.DB `json:"-"`
+	UserID string   `json:"-" db:"user_id"`
+
+	ID             string                     `json:"id" db:"id"`
+	HiveID         string                     `json:"hiveId" db:"hive_id"`
+	FamilyID       *string                    `json:"familyId" db:"family_id"`
+	Method         VarroaCountMethod          `json:"method" db:"method"`
+	SampleSize     int                        `json:"sampleSize" db:"sample_size"`
+	MitesFound     int                        `json:"mitesFound" db:"mites_found"`
+	CountedAt      string                     `json:"countedAt" db:"counted_at"`
+	Notes          *string                    `json:"notes" db:"notes"`
+	TreatmentID    *string                    `json:"treatmentId" db:"treatment_id"`
+	TreatmentPhase *VarroaCountTreatmentPhase `json:"treatmentPhase" db:"treatment_phase"`
+}
+
+const varroaCountColumns = `id, user_id, hive_id, family_id, method, sample_size, mites_found, counted_at, notes, treatment_id, treatment_phase`
+
+// InfestationRate is the number of mites per 100 bees of a bee sample
+func (r *VarroaCount) InfestationRate() *float64 {
+	if r.Method == VarroaCountMethodStickyBoard || r.SampleSize == 0 {
+		return nil
+	}
+
+	rate := float64(r.MitesFound) * 100 / float64(r.SampleSize)
+	return &rate
+}
+
+// DailyMiteDrop is the number of mites per day fallen on a sticky board
+func (r *VarroaCount) DailyMiteDrop() *float64 {
+	if r.Method != VarroaCountMethodStickyBoard || r.SampleSize == 0 {
+		return nil
+	}
+
+	drop := float64(r.MitesFound) / float64(r.SampleSize)
+	return &drop
+}
+
+func (r *VarroaCount) Get(id string) (*VarroaCount, error) {
+	count := VarroaCount{}
+	err := r.Db.Get(&count,
+		`SELECT `+varroaCountColumns+`
+		FROM varroa_counts
+		WHERE id=? AND user_id=? AND active=1
+		LIMIT 1`, id, r.UserID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &count, nil
+}
+
+// ListByHive returns counts of the hive, newest first, 200 unless limit is set, at most 1000
+func (r *VarroaCount) ListByHive(hiveID string, limit *int) ([]*VarroaCount, error) {
+	size := 200
+	if limit != nil && *limit > 0 {
+		size = *limit
+		if size > 1000 {
+			size = 1000
+		}
+	}
+
+	list := []*VarroaCount{}
+	err := r.Db.Select(&list,
+		`SELECT `+varroaCountColumns+`
+		FROM varroa_counts
+		WHERE user_id=? AND hive_id=? AND active=1
+		ORDER BY counted_at DESC, id DESC
+		LIMIT ?`, r.UserID, hiveID, size)
+
+	return list, err
+}
+
+// ListByHiveSince returns counts of the hive of the last days, oldest first
+func (r *VarroaCount) ListByHiveSince(hiveID string, days int) ([]*VarroaCount, error) {
+	list := []*VarroaCount{}
+	err := r.Db.Select(&list,
+		`SELECT `+varroaCountColumns+`
+		FROM varroa_counts
+		WHERE user_id=? AND hive_id=? AND active=1 AND counted_at >= DATE_SUB(NOW(), INTERVAL ? DAY)
+		ORDER BY counted_at ASC, id ASC`, r.UserID, hiveID, days)
+
+	return list, err
+}
+
+// ListLatestByApiary returns the latest count of each active hive of the apiary counted in the last days
+func (r *VarroaCount) ListLatestByApiary(apiaryID string, days int) ([]*VarroaCount, error) {
+	list := []*VarroaCount{}
+	err := r.Db.Select(&list,
+		`SELECT `+varroaCountColumns+`
+		FROM (
+			SELECT v.*, ROW_NUMBER() OVER (PARTITION BY v.hive_id ORDER BY v.counted_at DESC, v.id DESC) AS rn
+			FROM varroa_counts v
+			JOIN hives h ON h.id = v.hive_id AND h.user_id = v.user_id AND h.active=1
+			WHERE v.user_id=? AND h.apiary_id=? AND v.active=1 AND v.counted_at >= DATE_SUB(NOW(), INTERVAL ? DAY)
+		) latest
+		WHERE rn=1
+		ORDER BY hive_id ASC`, r.UserID, apiaryID, days)
+
+	return list, err
+}
+
+// ListByTreatments returns counts linked to the treatments, newest first
+func (r *VarroaCount) ListByTreatments(treatmentIDs []string) ([]*VarroaCount, error) {
+	list := []*VarroaCount{}
+	if len(treatmentIDs) == 0 {
+		return list, nil
+	}
+
+	query, args, err := sqlx.In(
+		`SELECT `+varroaCountColumns+`
+		FROM varroa_counts
+		WHERE user_id=? AND active=1 AND treatment_id IN (?)
+		ORDER BY counted_at DESC, id DESC`, r.UserID, treatmentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Db.Select(&list, query, args...)
+	return list, err
+}
+
+func validateVarroaCountInput(input VarroaCountInput) error {
+	if !input.Method.IsValid() {
+		return errors.New("invalid varroa count method")
+	}
+	if input.SampleSize < 1 {
+		return errors.New("sample size must be positive")
+	}
+	if input.MitesFound < 0 {
+		return errors.New("mites found must not be negative")
+	}
+	if input.Method != VarroaCountMethodStickyBoard && input.MitesFound > input.SampleSize {
+		return errors.New("mites found can not exceed the number of bees in the sample")
+	}
+	if input.Notes != nil && len(*input.Notes) > 10000 {
+		return errors.New("notes must be at most 10000 characters")
+	}
+	if (input.TreatmentID == nil) != (input.TreatmentPhase == nil) {
+		return errors.New("treatmentId and treatmentPhase must be set together")
+	}
+	if input.TreatmentPhase != nil && !input.TreatmentPhase.IsValid() {
+		return errors.New("invalid treatment phase")
+	}
+
+	return nil
+}
+
+// Create records a count of the hive, familyID is the queen family living in it
+func (r *VarroaCount) Create(input VarroaCountInput, familyID *int) (*VarroaCount, error) {
+	if err := validateVarroaCountInput(input); err != nil {
+		return nil, err
+	}
+	countedAt, err := parseOptionalDateTimeInput("countedAt", input.CountedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	var hiveCount int
+	err = tx.Get(&hiveCount, `SELECT COUNT(*) FROM hives WHERE id=? AND user_id=? AND active=1`, input.HiveID, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if hiveCount == 0 {
+		tx.Rollback()
+		return nil, errors.New("hive not found")
+	}
+
+	if input.TreatmentID != nil {
+		var treatmentHiveID string
+		err = tx.Get(&treatmentHiveID, `SELECT hive_id FROM treatments WHERE id=? AND user_id=? LIMIT 1`, *input.TreatmentID, r.UserID)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, errors.New("treatment not found")
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if treatmentHiveID != input.HiveID {
+			tx.Rollback()
+			return nil, errors.New("treatment belongs to another hive")
+		}
+	}
+
+	values := map[string]interface{}{
+		"userID":         r.UserID,
+		"hiveID":         input.HiveID,
+		"familyID":       familyID,
+		"method":         input.Method,
+		"sampleSize":     input.SampleSize,
+		"mitesFound":     input.MitesFound,
+		"countedAt":      countedAt,
+		"notes":          input.Notes,
+		"treatmentID":    input.TreatmentID,
+		"treatmentPhase": input.TreatmentPhase,
+	}
+	result, err := tx.NamedExec(
+		`INSERT INTO varroa_counts (user_id, hive_id, family_id, method, sample_size, mites_found, counted_at, notes, treatment_id, treatment_phase)
+		VALUES (:userID, :hiveID, :familyID, :method, :sampleSize, :mitesFound, COALESCE(:countedAt, NOW()), :notes, :treatmentID, :treatmentPhase)`,
+		values)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	countID := strconv.FormatInt(id, 10)
+
+	count := VarroaCount{}
+	err = tx.Get(&count, `SELECT `+varroaCountColumns+` FROM varroa_counts WHERE id=? LIMIT 1`, id)
+	if err == nil {
+		err = recordHiveEventTx(tx, r.UserID, input.HiveID, "varroa_count", countID, "created", count)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &count, nil
+}
+
+func (r *VarroaCount) Delete(id string) (bool, error) {
+	count, err := r.Get(id)
+	if err != nil {
+		return false, err
+	}
+	if count == nil {
+		return false, nil
+	}
+
+	tx := r.Db.MustBegin()
+	_, err = tx.Exec(`UPDATE varroa_counts SET active=0 WHERE id=? AND user_id=? AND active=1`, id, r.UserID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	err = recordHiveEventTx(tx, r.UserID, count.HiveID, "varroa_count", id, "deleted", count)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"strconv"
+)
+
+// varroaTrendStableChange is the weekly change of the infestation rate below which a trend counts as stable
+const varroaTrendStableChange = 0.1
+
+// NewVarroaTrend fits a line through the infestation rates of counts ordered oldest first
+func NewVarroaTrend(points []*VarroaCount) (*VarroaTrend, error) {
+	trend := &VarroaTrend{Points: points}
+
+	xs := []float64{}
+	ys := []float64{}
+	var first float64
+	for _, point := range points {
+		rate := point.InfestationRate()
+		if rate == nil {
+			continue
+		}
+		countedAt, err := parseDBDateTime(point.CountedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		days := float64(countedAt.Unix()) / 86400
+		if len(xs) == 0 {
+			first = days
+		}
+		xs = append(xs, days-first)
+		ys = append(ys, *rate)
+		trend.LatestInfestationRate = rate
+	}
+
+	slope, ok := leastSquaresSlope(xs, ys)
+	if !ok {
+		return trend, nil
+	}
+
+	weeklyChange := slope * 7
+	direction := VarroaTrendDirectionStable
+	if weeklyChange >= varroaTrendStableChange {
+		direction = VarroaTrendDirectionRising
+	} else if weeklyChange <= -varroaTrendStableChange {
+		direction = VarroaTrendDirectionFalling
+	}
+	trend.WeeklyChange = &weeklyChange
+	trend.Direction = &direction
+
+	return trend, nil
+}
+
+// leastSquaresSlope is false when there are less than two distinct x values
+func leastSquaresSlope(xs []float64, ys []float64) (float64, bool) {
+	if len(xs) < 2 {
+		return 0, false
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var covariance, variance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+		variance += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if variance == 0 {
+		return 0, false
+	}
+
+	return covariance / variance, true
+}
+
+// NewApiaryVarroaSummary aggregates the latest count of each hive, threshold is in mites per 100 bees
+func NewApiaryVarroaSummary(latest []*VarroaCount, threshold float64) *ApiaryVarroaSummary {
+	summary := &ApiaryVarroaSummary{
+		HivesCounted:        len(latest),
+		Threshold:           threshold,
+		HivesAboveThreshold: []string{},
+		LatestCounts:        latest,
+	}
+
+	var total float64
+	rated := 0
+	for _, count := range latest {
+		rate := count.InfestationRate()
+		if rate == nil {
+			continue
+		}
+
+		total += *rate
+		rated++
+		if summary.MaxInfestationRate == nil || *rate > *summary.MaxInfestationRate {
+			highest := *rate
+			summary.MaxInfestationRate = &highest
+		}
+		if *rate > threshold {
+			summary.HivesAboveThreshold = append(summary.HivesAboveThreshold, count.HiveID)
+		}
+	}
+	if rated > 0 {
+		average := total / float64(rated)
+		summary.AverageInfestationRate = &average
+	}
+
+	return summary
+}
+
+// NewTreatmentEfficacy pairs treatments with their latest before and after counts, newest treatment first
+func NewTreatmentEfficacy(treatments []*Treatment, counts []*VarroaCount) []*TreatmentEfficacy {
+	before := map[string]*VarroaCount{}
+	after := map[string]*VarroaCount{}
+	// counts are newest first, so the first one of each phase is the latest
+	for _, count := range counts {
+		if count.TreatmentID == nil || count.TreatmentPhase == nil {
+			continue
+		}
+		phase := after
+		if *count.TreatmentPhase == VarroaCountTreatmentPhaseBefore {
+			phase = before
+		}
+		if _, ok := phase[*count.TreatmentID]; !ok {
+			phase[*count.TreatmentID] = count
+		}
+	}
+
+	result := make([]*TreatmentEfficacy, 0, len(treatments))
+	for _, treatment := range treatments {
+		id := strconv.Itoa(treatment.ID)
+		efficacy := &TreatmentEfficacy{
+			Treatment: treatment,
+			Before:    before[id],
+			After:     after[id],
+		}
+		if efficacy.Before != nil && efficacy.After != nil {
+			efficacy.ReductionPercent = varroaReductionPercent(efficacy.Before, efficacy.After)
+		}
+		result = append(result, efficacy)
+	}
+
+	return result
+}
+
+// varroaReductionPercent compares infestation rates of bee samples or mite drops of sticky boards,
+// a bee sample can not be compared with a sticky board
+func varroaReductionPercent(before *VarroaCount, after *VarroaCount) *float64 {
+	beforeLevel, afterLevel := before.InfestationRate(), after.InfestationRate()
+	if beforeLevel == nil || afterLevel == nil {
+		beforeLevel, afterLevel = before.DailyMiteDrop(), after.DailyMiteDrop()
+	}
+	if beforeLevel == nil || afterLevel == nil || *beforeLevel == 0 {
+		return nil
+	}
+
+	reduction := (*beforeLevel - *afterLevel) / *beforeLevel * 100
+	return &reduction
+}
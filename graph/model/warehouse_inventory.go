@@ -141,28 +141,57 @@ func (r *WarehouseInventory) List() ([]*WarehouseInventoryItem, error) {
 	}
 
 	for _, row := range frameRows {
-		specID := strconv.Itoa(row.ID)
-		systemID := strconv.Itoa(row.SystemID)
-		spec := &FrameSpec{
+		items = append(items, row.inventoryItem())
+	}
+
+	return items, nil
+}
+
+// frameSpecItemTx reads the inventory item of a single frame spec within tx
+func (r *WarehouseInventory) frameSpecItemTx(tx *sqlx.Tx, specID int) (*WarehouseInventoryItem, error) {
+	row := warehouseFrameSpecRow{}
+	err := tx.Get(&row, `
+		SELECT
+			fs.id,
+			fs.system_id,
+			fs.code,
+			fs.frame_type,
+			fs.display_name,
+			COALESCE(wfi.count, 0) AS count
+		FROM frame_specs fs
+		INNER JOIN box_systems bs ON bs.id = fs.system_id AND bs.active = 1
+		LEFT JOIN warehouse_frame_inventory wfi ON wfi.frame_spec_id = fs.id AND wfi.user_id = ?
+		WHERE fs.id = ?
+		  AND fs.active = 1
+		  AND fs.frame_type IN ('FOUNDATION', 'EMPTY_COMB', 'VOID', 'PARTITION', 'FEEDER')
+		  AND (bs.user_id = ? OR bs.user_id IS NULL)
+		LIMIT 1
+	`, r.UserID, specID, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return row.inventoryItem(), nil
+}
+
+func (row warehouseFrameSpecRow) inventoryItem() *WarehouseInventoryItem {
+	specID := strconv.Itoa(row.ID)
+	return &WarehouseInventoryItem{
+		Key:         warehouseItemKeyPrefixFrameSpec + specID,
+		Kind:        WarehouseInventoryItemKindFrameSpec,
+		Count:       row.Count,
+		GroupKey:    "FRAMES",
+		Title:       row.DisplayName,
+		Description: "Frames compatible with a specific hive section size and system.",
+		FrameSpec: &FrameSpec{
 			ID:          specID,
-			SystemID:    systemID,
+			SystemID:    strconv.Itoa(row.SystemID),
 			Code:        row.Code,
 			FrameType:   row.FrameType,
 			DisplayName: row.DisplayName,
 			Active:      true,
-		}
-		items = append(items, &WarehouseInventoryItem{
-			Key:         warehouseItemKeyPrefixFrameSpec + specID,
-			Kind:        WarehouseInventoryItemKindFrameSpec,
-			Count:       row.Count,
-			GroupKey:    "FRAMES",
-			Title:       row.DisplayName,
-			Description: "Frames compatible with a specific hive section size and system.",
-			FrameSpec:   spec,
-		})
+		},
 	}
-
-	return items, nil
 }
 
 func (r *WarehouseInventory) UpsertByKey(itemKey string, count int) (*WarehouseInventoryItem, error) {
@@ -176,16 +205,18 @@ func (r *WarehouseInventory) UpsertByKey(itemKey string, count int) (*WarehouseI
 			return nil, err
 		}
 
+		tx := r.Db.MustBegin()
 		updated, err := (&WarehouseModule{
 			Db:     r.Db,
 			UserID: r.UserID,
-		}).UpsertForSystem(moduleType, systemID, count)
+		}).upsertForSystemTx(tx, moduleType, systemID, count)
 		if err != nil {
+			tx.Rollback()
 			return nil, err
 		}
 
 		mt := updated.ModuleType
-		return &WarehouseInventoryItem{
+		item := &WarehouseInventoryItem{
 			Key:         buildBoxInventoryKey(mt, systemID),
 			Kind:        WarehouseInventoryItemKindBoxModule,
 			Count:       updated.Count,
@@ -193,7 +224,8 @@ func (r *WarehouseInventory) UpsertByKey(itemKey string, count int) (*WarehouseI
 			Title:       mapBoxModuleTitle(mt),
 			Description: mapBoxModuleDescription(mt),
 			ModuleType:  &mt,
-		}, nil
+		}
+		return item, r.commitItemChangeTx(tx, item)
 	}
 
 	if strings.HasPrefix(itemKey, warehouseItemKeyPrefixFrameSpec) {
@@ -217,25 +249,33 @@ func (r *WarehouseInventory) UpsertByKey(itemKey string, count int) (*WarehouseI
 			tx.Rollback()
 			return nil, err
 		}
-		if err := tx.Commit(); err != nil {
-			return nil, err
-		}
 
-		items, err := r.List()
+		item, err := r.frameSpecItemTx(tx, specID)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			return nil, fmt.Errorf("updated item not found: %s", itemKey)
+		}
 		if err != nil {
+			tx.Rollback()
 			return nil, err
 		}
-		for _, item := range items {
-			if item.Key == itemKey {
-				return item, nil
-			}
-		}
-		return nil, fmt.Errorf("updated item not found: %s", itemKey)
+		return item, r.commitItemChangeTx(tx, item)
 	}
 
 	return nil, fmt.Errorf("unsupported warehouse inventory key: %s", itemKey)
 }
 
+// commitItemChangeTx records the changed item for inventory subscribers and commits tx
+func (r *WarehouseInventory) commitItemChangeTx(tx *sqlx.Tx, item *WarehouseInventoryItem) error {
+	err := recordInventoryEventTx(tx, r.UserID, "warehouse_inventory", item.Key, "updated", item)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *WarehouseInventory) UpdateFrameSpecByDelta(frameSpecID int, delta int) (*WarehouseInventoryItem, error) {
 	var current int
 	err := r.Db.Get(&current, `
@@ -33,6 +33,28 @@ func (r *WarehouseModule) Upsert(moduleType WarehouseModuleType, count int) (*Wa
 }
 
 func (r *WarehouseModule) UpsertForSystem(moduleType WarehouseModuleType, boxSystemID *int, count int) (*WarehouseModule, error) {
+	tx := r.Db.MustBegin()
+	current, err := r.upsertForSystemTx(tx, moduleType, boxSystemID, count)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordInventoryEventTx(tx, r.UserID, "warehouse_module", moduleType.String(), "updated", current)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// upsertForSystemTx sets the module count within tx and returns the stored row
+func (r *WarehouseModule) upsertForSystemTx(tx *sqlx.Tx, moduleType WarehouseModuleType, boxSystemID *int, count int) (*WarehouseModule, error) {
 	if count < 0 {
 		count = 0
 	}
@@ -41,7 +63,6 @@ func (r *WarehouseModule) UpsertForSystem(moduleType WarehouseModuleType, boxSys
 		systemID = *boxSystemID
 	}
 
-	tx := r.Db.MustBegin()
 	_, err := tx.NamedExec(
 		`INSERT INTO warehouse_modules (user_id, module_type, box_system_id, count)
 		VALUES (:userID, :moduleType, :boxSystemID, :count)
@@ -54,16 +75,11 @@ func (r *WarehouseModule) UpsertForSystem(moduleType WarehouseModuleType, boxSys
 		},
 	)
 	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	current := WarehouseModule{}
-	err = r.Db.Get(&current,
+	err = tx.Get(&current,
 		`SELECT user_id, module_type, box_system_id, count
 		FROM warehouse_modules
 		WHERE user_id=? AND module_type=? AND box_system_id=?
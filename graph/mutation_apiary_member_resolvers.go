@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// InviteApiaryMember is the resolver for the inviteApiaryMember field.
+func (r *mutationResolver) InviteApiaryMember(ctx context.Context, apiaryID string, role model.ApiaryRole) (*model.ApiaryInvite, error) {
+	uid := ctx.Value("userID").(string)
+
+	// members add hives against the plan of the owner, so it is stored before anyone joins
+	err := (&model.UserBillingPlan{Db: r.Db}).Remember(uid, getBillingPlanFromContext(ctx))
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	member, token, err := (&model.ApiaryMember{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Invite(apiaryID, role)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return &model.ApiaryInvite{
+		Member: member,
+		Token:  token,
+	}, nil
+}
+
+// AcceptApiaryInvite is the resolver for the acceptApiaryInvite field.
+func (r *mutationResolver) AcceptApiaryInvite(ctx context.Context, token string) (*model.Apiary, error) {
+	uid := ctx.Value("userID").(string)
+	apiaryID, err := (&model.ApiaryMember{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Accept(token)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	ownerID, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.Apiary{
+		Db:     r.Resolver.Db,
+		UserID: ownerID,
+	}).Get(apiaryID)
+}
+
+// RevokeApiaryMember is the resolver for the revokeApiaryMember field.
+func (r *mutationResolver) RevokeApiaryMember(ctx context.Context, apiaryID string, memberID string) (bool, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.ApiaryMember{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Revoke(apiaryID, memberID)
+}
@@ -2,11 +2,9 @@ package graph
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/Gratheon/log-lib-go"
 	"github.com/Gratheon/swarm-api/graph/model"
-	"github.com/Gratheon/swarm-api/redisPubSub"
 )
 
 // AddApiary is the resolver for the addApiary field.
@@ -22,14 +20,15 @@ func (r *mutationResolver) AddApiary(ctx context.Context, apiary model.ApiaryInp
 		return nil, err
 	}
 
-	redisPubSub.PublishEvent(uid, "apiary", strconv.Itoa(createdApiary.ID), "created", createdApiary)
-
 	return createdApiary, err
 }
 
 // UpdateApiary is the resolver for the updateApiary field.
 func (r *mutationResolver) UpdateApiary(ctx context.Context, id string, apiary model.ApiaryInput) (*model.Apiary, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, id, accessOwner)
+	if err != nil {
+		return nil, err
+	}
 	updatedApiary, err := (&model.Apiary{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -40,26 +39,48 @@ func (r *mutationResolver) UpdateApiary(ctx context.Context, id string, apiary m
 		return nil, err
 	}
 
-	redisPubSub.PublishEvent(uid, "apiary", id, "updated", updatedApiary)
-
 	return updatedApiary, err
 }
 
 // DeactivateApiary is the resolver for the deactivateApiary field.
 func (r *mutationResolver) DeactivateApiary(ctx context.Context, id string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, id, accessOwner)
+	if err != nil {
+		return nil, err
+	}
 	result, err := (&model.Apiary{
 		Db:     r.Resolver.Db,
 		UserID: uid,
 	}).Deactivate(id)
 
-	redisPubSub.PublishEvent(uid, "apiary", id, "deleted", "")
 	return result, err
 }
 
+// RelocateApiary is the resolver for the relocateApiary field.
+func (r *mutationResolver) RelocateApiary(ctx context.Context, id string, relocation model.ApiaryRelocationInput) (*model.Apiary, error) {
+	uid, err := r.actingUserID(ctx, model.AccessApiary, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	relocatedApiary, err := (&model.Apiary{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Relocate(id, relocation)
+
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return relocatedApiary, nil
+}
+
 // UpdateHivePlacement is the resolver for the updateHivePlacement field.
 func (r *mutationResolver) UpdateHivePlacement(ctx context.Context, apiaryID string, hiveID string, x float64, y float64, rotation float64) (*model.HivePlacement, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	placement, err := (&model.HivePlacement{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -75,7 +96,10 @@ func (r *mutationResolver) UpdateHivePlacement(ctx context.Context, apiaryID str
 
 // AddApiaryObstacle is the resolver for the addApiaryObstacle field.
 func (r *mutationResolver) AddApiaryObstacle(ctx context.Context, apiaryID string, obstacle model.ApiaryObstacleInput) (*model.ApiaryObstacle, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	created, err := (&model.ApiaryObstacle{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -91,7 +115,10 @@ func (r *mutationResolver) AddApiaryObstacle(ctx context.Context, apiaryID strin
 
 // UpdateApiaryObstacle is the resolver for the updateApiaryObstacle field.
 func (r *mutationResolver) UpdateApiaryObstacle(ctx context.Context, id string, obstacle model.ApiaryObstacleInput) (*model.ApiaryObstacle, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiaryObstacle, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	updated, err := (&model.ApiaryObstacle{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -107,7 +134,10 @@ func (r *mutationResolver) UpdateApiaryObstacle(ctx context.Context, id string,
 
 // DeleteApiaryObstacle is the resolver for the deleteApiaryObstacle field.
 func (r *mutationResolver) DeleteApiaryObstacle(ctx context.Context, id string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiaryObstacle, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	deleted, err := (&model.ApiaryObstacle{
 		Db:     r.Resolver.Db,
 		UserID: uid,
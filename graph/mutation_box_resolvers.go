@@ -11,7 +11,10 @@ import (
 
 // AddBox is the resolver for the addBox field.
 func (r *mutationResolver) AddBox(ctx context.Context, hiveID string, position int, color *string, typeArg model.BoxType, holeCount *int) (*model.Box, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 
 	hiveModel := &model.Hive{
 		Db:     r.Resolver.Db,
@@ -42,7 +45,10 @@ func (r *mutationResolver) AddBox(ctx context.Context, hiveID string, position i
 
 // UpdateBoxColor is the resolver for the updateBoxColor field.
 func (r *mutationResolver) UpdateBoxColor(ctx context.Context, id string, color *string) (bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessBox, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
 	boxModel := &model.Box{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -61,7 +67,10 @@ func (r *mutationResolver) UpdateBoxColor(ctx context.Context, id string, color
 
 // UpdateBoxHoleCount is the resolver for the updateBoxHoleCount field.
 func (r *mutationResolver) UpdateBoxHoleCount(ctx context.Context, id string, holeCount int) (bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessBox, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
 	boxModel := &model.Box{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -84,7 +93,10 @@ func (r *mutationResolver) UpdateBoxHoleCount(ctx context.Context, id string, ho
 
 // UpdateBoxRoofStyle is the resolver for the updateBoxRoofStyle field.
 func (r *mutationResolver) UpdateBoxRoofStyle(ctx context.Context, id string, roofStyle model.RoofStyle) (bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessBox, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
 	boxModel := &model.Box{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -107,7 +119,10 @@ func (r *mutationResolver) UpdateBoxRoofStyle(ctx context.Context, id string, ro
 
 // DeactivateBox is the resolver for the deactivateBox field.
 func (r *mutationResolver) DeactivateBox(ctx context.Context, id string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessBox, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.Box{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -116,9 +131,66 @@ func (r *mutationResolver) DeactivateBox(ctx context.Context, id string) (*bool,
 
 // SwapBoxPositions is the resolver for the swapBoxPositions field.
 func (r *mutationResolver) SwapBoxPositions(ctx context.Context, id string, id2 string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessBox, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	uid2, err := r.actingUserID(ctx, model.AccessBox, id2, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if uid2 != uid {
+		return nil, errors.New("boxes to swap must belong to the same owner")
+	}
 	return (&model.Box{
 		Db:     r.Resolver.Db,
 		UserID: uid,
 	}).SwapBoxPositions(id, id2)
 }
+
+// AssignBoxFamily is the resolver for the assignBoxFamily field.
+func (r *mutationResolver) AssignBoxFamily(ctx context.Context, boxID string, familyID *string) (*model.Box, error) {
+	uid, err := r.actingUserID(ctx, model.AccessBox, boxID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	err = (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).AssignBox(boxID, familyID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return (&model.Box{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(boxID)
+}
+
+// MoveBox is the resolver for the moveBox field.
+func (r *mutationResolver) MoveBox(ctx context.Context, boxID string, targetHiveID string, position *int) (*model.Box, error) {
+	uid, err := r.actingUserID(ctx, model.AccessBox, boxID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	targetUID, err := r.actingUserID(ctx, model.AccessHive, targetHiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if targetUID != uid {
+		return nil, errors.New("boxes can only be moved between hives of the same owner")
+	}
+
+	box, err := (&model.Box{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).MoveToHive(boxID, targetHiveID, position)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return box, nil
+}
@@ -2,7 +2,7 @@ package graph
 
 import (
 	"context"
-	"strconv"
+	"errors"
 
 	"github.com/Gratheon/log-lib-go"
 	"github.com/Gratheon/swarm-api/graph/model"
@@ -10,7 +10,13 @@ import (
 
 // AddQueenToHive is the resolver for the addQueenToHive field.
 func (r *mutationResolver) AddQueenToHive(ctx context.Context, hiveID string, queen model.FamilyInput) (*model.Family, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if queen.RearingBatchID != nil {
+		return nil, errors.New("queens of a rearing batch are added to the warehouse first")
+	}
 	familyModel := &model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -33,7 +39,16 @@ func (r *mutationResolver) AddWarehouseQueen(ctx context.Context, queen model.Fa
 		UserID: uid,
 	}
 
-	familyID, err := familyModel.Create(queen.Name, queen.Race, queen.Added, queen.Color)
+	var familyID *int
+	var err error
+	if queen.RearingBatchID != nil {
+		familyID, err = (&model.QueenRearingBatch{
+			Db:     r.Resolver.Db,
+			UserID: uid,
+		}).CreateQueen(*queen.RearingBatchID, queen)
+	} else {
+		familyID, err = familyModel.Create(queen.Name, queen.Race, queen.Added, queen.Color)
+	}
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
@@ -44,7 +59,10 @@ func (r *mutationResolver) AddWarehouseQueen(ctx context.Context, queen model.Fa
 
 // RemoveQueenFromHive is the resolver for the removeQueenFromHive field.
 func (r *mutationResolver) RemoveQueenFromHive(ctx context.Context, hiveID string, familyID string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	success, err := (&model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -56,113 +74,89 @@ func (r *mutationResolver) RemoveQueenFromHive(ctx context.Context, hiveID strin
 	return &success, nil
 }
 
-// TreatHive is the resolver for the treatHive field.
-func (r *mutationResolver) TreatHive(ctx context.Context, treatment model.TreatmentOfHiveInput) (*bool, error) {
-	uid := ctx.Value("userID").(string)
-	treatmentModel := &model.Treatment{
-		Db:     r.Resolver.Db,
-		UserID: uid,
+// MoveQueenToWarehouse is the resolver for the moveQueenToWarehouse field.
+func (r *mutationResolver) MoveQueenToWarehouse(ctx context.Context, hiveID string, familyID string) (*model.Family, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
 	}
-
-	familyModel := &model.Family{
+	moved, err := (&model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
-	}
-	families, err := familyModel.ListByHive(treatment.HiveID)
+	}).MoveToWarehouse(hiveID, familyID)
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
-		ok := false
-		return &ok, err
-	}
-	var familyID *int
-	if err == nil && len(families) > 0 {
-		familyIDInt, _ := strconv.Atoi(families[0].ID)
-		familyID = &familyIDInt
-	}
-
-	_, err2 := treatmentModel.TreatHive(treatment, familyID)
-	ok := err2 == nil
-
-	if err2 != nil {
-		logger.ErrorWithContext(ctx, err2.Error())
+		return nil, err
 	}
 
-	return &ok, err
+	return moved, nil
 }
 
-// TreatBox is the resolver for the treatBox field.
-func (r *mutationResolver) TreatBox(ctx context.Context, treatment model.TreatmentOfBoxInput) (*bool, error) {
-	uid := ctx.Value("userID").(string)
-	treatmentModel := &model.Treatment{
-		Db:     r.Resolver.Db,
-		UserID: uid,
+// AssignQueenFromWarehouse is the resolver for the assignQueenFromWarehouse field.
+func (r *mutationResolver) AssignQueenFromWarehouse(ctx context.Context, hiveID string, familyID string) (*model.Family, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
 	}
-
-	familyModel := &model.Family{
+	assigned, err := (&model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
-	}
-	families, err := familyModel.ListByHive(treatment.HiveID)
+	}).AssignFromWarehouse(hiveID, familyID)
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
-		ok := false
-		return &ok, err
-	}
-	var familyID *int
-	if err == nil && len(families) > 0 {
-		familyIDInt, _ := strconv.Atoi(families[0].ID)
-		familyID = &familyIDInt
+		return nil, err
 	}
 
-	_, err2 := treatmentModel.TreatHiveBox(treatment, familyID)
-	ok := err2 == nil
-	if err2 != nil {
-		logger.ErrorWithContext(ctx, err2.Error())
-	}
-	return &ok, err
+	return assigned, nil
 }
 
-// MoveQueenToWarehouse is the resolver for the moveQueenToWarehouse field.
-func (r *mutationResolver) MoveQueenToWarehouse(ctx context.Context, hiveID string, familyID string) (*model.Family, error) {
+// DeleteWarehouseQueen is the resolver for the deleteWarehouseQueen field.
+func (r *mutationResolver) DeleteWarehouseQueen(ctx context.Context, familyID string) (*bool, error) {
 	uid := ctx.Value("userID").(string)
-	moved, err := (&model.Family{
+	success, err := (&model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
-	}).MoveToWarehouse(hiveID, familyID)
+	}).DeleteFromWarehouse(familyID)
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
-	return moved, nil
+	return &success, nil
 }
 
-// AssignQueenFromWarehouse is the resolver for the assignQueenFromWarehouse field.
-func (r *mutationResolver) AssignQueenFromWarehouse(ctx context.Context, hiveID string, familyID string) (*model.Family, error) {
-	uid := ctx.Value("userID").(string)
-	assigned, err := (&model.Family{
+// SetQueenPedigree is the resolver for the setQueenPedigree field.
+func (r *mutationResolver) SetQueenPedigree(ctx context.Context, familyID string, pedigree model.QueenPedigreeInput) (*model.Family, error) {
+	uid, err := r.actingUserID(ctx, model.AccessFamily, familyID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	family, err := (&model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
-	}).AssignFromWarehouse(hiveID, familyID)
+	}).SetPedigree(familyID, pedigree)
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
-	return assigned, nil
+	return family, nil
 }
 
-// DeleteWarehouseQueen is the resolver for the deleteWarehouseQueen field.
-func (r *mutationResolver) DeleteWarehouseQueen(ctx context.Context, familyID string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
-	success, err := (&model.Family{
+// SetPrimaryQueen is the resolver for the setPrimaryQueen field.
+func (r *mutationResolver) SetPrimaryQueen(ctx context.Context, hiveID string, familyID string) (*model.Family, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	family, err := (&model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
-	}).DeleteFromWarehouse(familyID)
+	}).SetPrimary(hiveID, familyID)
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
-	return &success, nil
+	return family, nil
 }
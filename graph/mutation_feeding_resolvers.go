@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// RecordFeeding is the resolver for the recordFeeding field.
+func (r *mutationResolver) RecordFeeding(ctx context.Context, hiveID string, feedType model.FeedType, amount float64, unit model.FeedUnit, boxID *string, date *string, notes *string) (*model.Feeding, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := (&model.WarehouseSettings{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get()
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	feeding, err := (&model.Feeding{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Record(model.FeedingInput{
+		HiveID:   hiveID,
+		FeedType: feedType,
+		Amount:   amount,
+		Unit:     unit,
+		BoxID:    boxID,
+		Date:     date,
+		Notes:    notes,
+	}, settings.AutoUpdateFromHives)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return feeding, nil
+}
+
+// DeleteFeeding is the resolver for the deleteFeeding field.
+func (r *mutationResolver) DeleteFeeding(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessFeeding, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
+	return (&model.Feeding{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	"github.com/Gratheon/log-lib-go"
@@ -10,7 +11,10 @@ import (
 
 // AddFrame is the resolver for the addFrame field.
 func (r *mutationResolver) AddFrame(ctx context.Context, boxID string, typeArg string, position int) (*model.Frame, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessBox, boxID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	frameType := model.FrameType(typeArg)
 
 	frameModel := &model.Frame{
@@ -79,16 +83,26 @@ func (r *mutationResolver) AddFrame(ctx context.Context, boxID string, typeArg s
 
 // UpdateFrames is the resolver for the updateFrames field.
 func (r *mutationResolver) UpdateFrames(ctx context.Context, frames []*model.FrameInput) ([]*model.Frame, error) {
-	uid := ctx.Value("userID").(string)
-	frameModel := &model.Frame{
-		Db:     r.Resolver.Db,
-		UserID: uid,
-	}
-
 	// Initialize an empty results slice
 	results := []*model.Frame{}
 
 	for _, frame := range frames {
+		uid, err := r.actingUserID(ctx, model.AccessFrame, frame.ID, accessWrite)
+		if err != nil {
+			return nil, err
+		}
+		boxUID, err := r.actingUserID(ctx, model.AccessBox, frame.BoxID, accessWrite)
+		if err != nil {
+			return nil, err
+		}
+		if boxUID != uid {
+			return nil, errors.New("frames can only be moved between boxes of the same owner")
+		}
+		frameModel := &model.Frame{
+			Db:     r.Resolver.Db,
+			UserID: uid,
+		}
+
 		if _, err := frameModel.Update(frame.ID, frame.BoxID, frame.Position); err != nil {
 			return nil, err
 		}
@@ -110,9 +124,73 @@ func (r *mutationResolver) UpdateFrames(ctx context.Context, frames []*model.Fra
 
 // DeactivateFrame is the resolver for the deactivateFrame field.
 func (r *mutationResolver) DeactivateFrame(ctx context.Context, id string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessFrame, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.Frame{
 		Db:     r.Resolver.Db,
 		UserID: uid,
 	}).Deactivate(id)
 }
+
+// SetFrameCombBuiltAt is the resolver for the setFrameCombBuiltAt field.
+func (r *mutationResolver) SetFrameCombBuiltAt(ctx context.Context, frameID string, date *string) (*model.Frame, error) {
+	uid, err := r.actingUserID(ctx, model.AccessFrame, frameID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := (&model.Frame{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).SetCombBuiltAt(frameID, date)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+// MoveFrames is the resolver for the moveFrames field.
+func (r *mutationResolver) MoveFrames(ctx context.Context, frameIds []string, targetBoxID string, positions []int) ([]*model.Frame, error) {
+	uid, err := r.actingUserID(ctx, model.AccessBox, targetBoxID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	for _, frameID := range frameIds {
+		frameUID, err := r.actingUserID(ctx, model.AccessFrame, frameID, accessWrite)
+		if err != nil {
+			return nil, err
+		}
+		if frameUID != uid {
+			return nil, errors.New("frames can only be moved between boxes of the same owner")
+		}
+	}
+
+	frameModel := &model.Frame{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}
+	if err = frameModel.MoveToBox(frameIds, targetBoxID, positions); err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	frames := make([]*model.Frame, 0, len(frameIds))
+	for _, frameID := range frameIds {
+		id, err := strconv.ParseInt(frameID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		frame, err := frameModel.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if frame != nil {
+			frames = append(frames, frame)
+		}
+	}
+
+	return frames, nil
+}
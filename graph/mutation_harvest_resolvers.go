@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// RecordHarvest is the resolver for the recordHarvest field.
+func (r *mutationResolver) RecordHarvest(ctx context.Context, hiveID string, boxIds []string, frames *int, weightKg float64, moisture *float64, floralSource *string, date *string) (*model.HoneyHarvest, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := r.hiveFamilyID(uid, hiveID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	harvest, err := (&model.HoneyHarvest{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Record(model.HoneyHarvestInput{
+		HiveID:       hiveID,
+		BoxIDs:       boxIds,
+		Frames:       frames,
+		WeightKg:     weightKg,
+		Moisture:     moisture,
+		FloralSource: floralSource,
+		Date:         date,
+	}, familyID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return harvest, nil
+}
+
+// DeleteHarvest is the resolver for the deleteHarvest field.
+func (r *mutationResolver) DeleteHarvest(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHarvest, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
+	return (&model.HoneyHarvest{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+}
+
+// AddHoneyLot is the resolver for the addHoneyLot field.
+func (r *mutationResolver) AddHoneyLot(ctx context.Context, lot model.HoneyLotInput) (*model.HoneyLot, error) {
+	uid := ctx.Value("userID").(string)
+	for _, harvestID := range lot.HarvestIds {
+		harvestUID, err := r.actingUserID(ctx, model.AccessHarvest, harvestID, accessWrite)
+		if err != nil {
+			return nil, err
+		}
+		if harvestUID != uid {
+			return nil, errors.New("a lot can only hold your own harvests")
+		}
+	}
+
+	created, err := (&model.HoneyLot{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Create(lot)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// DeleteHoneyLot is the resolver for the deleteHoneyLot field.
+func (r *mutationResolver) DeleteHoneyLot(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHoneyLot, id, accessOwner)
+	if err != nil {
+		return false, err
+	}
+	return (&model.HoneyLot{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+}
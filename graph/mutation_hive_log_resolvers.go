@@ -8,7 +8,10 @@ import (
 
 // AddHiveLog is the resolver for the addHiveLog field.
 func (r *mutationResolver) AddHiveLog(ctx context.Context, log model.HiveLogInput) (*model.HiveLog, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, log.HiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.HiveLog{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -17,7 +20,10 @@ func (r *mutationResolver) AddHiveLog(ctx context.Context, log model.HiveLogInpu
 
 // UpdateHiveLog is the resolver for the updateHiveLog field.
 func (r *mutationResolver) UpdateHiveLog(ctx context.Context, id string, log model.HiveLogUpdateInput) (*model.HiveLog, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHiveLog, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.HiveLog{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -26,7 +32,10 @@ func (r *mutationResolver) UpdateHiveLog(ctx context.Context, id string, log mod
 
 // DeleteHiveLog is the resolver for the deleteHiveLog field.
 func (r *mutationResolver) DeleteHiveLog(ctx context.Context, id string) (bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHiveLog, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
 	return (&model.HiveLog{
 		Db:     r.Resolver.Db,
 		UserID: uid,
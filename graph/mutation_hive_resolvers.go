@@ -9,12 +9,15 @@ import (
 
 	"github.com/Gratheon/log-lib-go"
 	"github.com/Gratheon/swarm-api/graph/model"
-	"github.com/Gratheon/swarm-api/redisPubSub"
+	"github.com/jmoiron/sqlx"
 )
 
 // AddHive is the resolver for the addHive field.
 func (r *mutationResolver) AddHive(ctx context.Context, hive model.HiveInput) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, hive.ApiaryID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	hiveModel := &model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -134,7 +137,10 @@ func (r *mutationResolver) AddHive(ctx context.Context, hive model.HiveInput) (*
 
 // UpdateHive is the resolver for the updateHive field.
 func (r *mutationResolver) UpdateHive(ctx context.Context, hive model.HiveUpdateInput) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, hive.ID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 
 	hiveModel := &model.Hive{
 		Db:     r.Resolver.Db,
@@ -162,7 +168,10 @@ func (r *mutationResolver) UpdateHive(ctx context.Context, hive model.HiveUpdate
 
 // DeactivateHive is the resolver for the deactivateHive field.
 func (r *mutationResolver) DeactivateHive(ctx context.Context, id string) (*bool, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -171,19 +180,19 @@ func (r *mutationResolver) DeactivateHive(ctx context.Context, id string) (*bool
 
 // MarkHiveAsCollapsed is the resolver for the markHiveAsCollapsed field.
 func (r *mutationResolver) MarkHiveAsCollapsed(ctx context.Context, id string, collapseDate string, collapseCause string) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	hiveModel := &model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
 	}
 
-	parsedCollapseDate, err := time.Parse(time.RFC3339, collapseDate)
+	parsedCollapseDate, err := model.ParseDateTimeInput("collapseDate", collapseDate)
 	if err != nil {
-		parsedCollapseDate, err = time.Parse("2006-01-02", collapseDate)
-		if err != nil {
-			logger.ErrorWithContext(ctx, "Invalid collapseDate format: "+err.Error())
-			return nil, errors.New("Invalid collapseDate format, must be RFC3339 or YYYY-MM-DD")
-		}
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
 	}
 
 	err = hiveModel.MarkAsCollapsed(id, parsedCollapseDate, collapseCause)
@@ -201,13 +210,52 @@ func (r *mutationResolver) MarkHiveAsCollapsed(ctx context.Context, id string, c
 	return updatedHive, nil
 }
 
+// MoveHiveToApiary is the resolver for the moveHiveToApiary field.
+func (r *mutationResolver) MoveHiveToApiary(ctx context.Context, hiveID string, targetApiaryID string, date *string) (*model.Hive, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	targetUID, err := r.actingUserID(ctx, model.AccessApiary, targetApiaryID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if targetUID != uid {
+		return nil, errors.New("hives can only be moved between apiaries of the same owner")
+	}
+
+	hiveModel := &model.Hive{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}
+	err = hiveModel.MoveToApiary(hiveID, targetApiaryID, date)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return hiveModel.Get(hiveID)
+}
+
 // SplitHive is the resolver for the splitHive field.
 func (r *mutationResolver) SplitHive(ctx context.Context, sourceHiveID string, queenName *string, queenAction string, frameIds []string) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, sourceHiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(frameIds) == 0 || len(frameIds) > 10 {
 		return nil, errors.New("must select between 1 and 10 frames to split")
 	}
+	for _, frameID := range frameIds {
+		frameUID, err := r.actingUserID(ctx, model.AccessFrame, frameID, accessWrite)
+		if err != nil {
+			return nil, err
+		}
+		if frameUID != uid {
+			return nil, errors.New("frames to split must belong to the owner of the source hive")
+		}
+	}
 
 	validQueenActions := map[string]bool{
 		"new_queen":      true,
@@ -228,6 +276,10 @@ func (r *mutationResolver) SplitHive(ctx context.Context, sourceHiveID string, q
 		return nil, err
 	}
 
+	if queenAction == "new_queen" && (queenName == nil || *queenName == "") {
+		return nil, errors.New("queenName is required when queenAction is new_queen")
+	}
+
 	sourceHive, err := hiveModel.Get(sourceHiveID)
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
@@ -237,27 +289,25 @@ func (r *mutationResolver) SplitHive(ctx context.Context, sourceHiveID string, q
 		return nil, errors.New("source hive not found")
 	}
 
-	newHive, err := hiveModel.Split(sourceHiveID, sourceHive.ApiaryID)
+	framesInSource, err := (&model.Frame{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).CountInHive(frameIds, sourceHiveID)
 	if err != nil {
 		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
+	if framesInSource != len(frameIds) {
+		return nil, errors.New("frames to split must be in the source hive")
+	}
 
 	familyModel := &model.Family{
 		Db:     r.Resolver.Db,
 		UserID: uid,
 	}
 
-	if queenAction == "new_queen" {
-		if queenName == nil || *queenName == "" {
-			return nil, errors.New("queenName is required when queenAction is new_queen")
-		}
-		_, err = familyModel.CreateForHive(newHive.ID, queenName, nil, nil, nil)
-		if err != nil {
-			logger.ErrorWithContext(ctx, err.Error())
-			return nil, err
-		}
-	} else if queenAction == "take_old_queen" {
+	var oldQueen *model.Family
+	if queenAction == "take_old_queen" {
 		logger.Info("Attempting to take old queen from hive " + sourceHiveID + " for user " + uid)
 
 		families, err := familyModel.ListByHive(sourceHiveID)
@@ -272,12 +322,69 @@ func (r *mutationResolver) SplitHive(ctx context.Context, sourceHiveID string, q
 			return nil, errors.New("source hive has no queen to take")
 		}
 
-		oldQueen := families[0]
-		logger.Info("Moving queen " + oldQueen.ID + " from hive " + sourceHiveID + " to hive " + newHive.ID)
+		oldQueen = families[0]
+	}
+
+	// all steps share one transaction, so a failed step leaves no half-created hive behind
+	tx := r.Db.MustBegin()
+
+	newHive, err := r.splitHiveTx(tx, uid, sourceHive, queenAction, queenName, oldQueen, frameIds)
+	if err != nil {
+		tx.Rollback()
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return newHive, nil
+}
+
+func (r *mutationResolver) splitHiveTx(tx *sqlx.Tx, uid string, sourceHive *model.Hive, queenAction string, queenName *string, oldQueen *model.Family, frameIds []string) (*model.Hive, error) {
+	hiveModel := &model.Hive{
+		Db:     r.Db,
+		UserID: uid,
+	}
+
+	newHive, err := hiveModel.SplitTx(tx, sourceHive.ID, sourceHive.ApiaryID)
+	if err != nil {
+		return nil, err
+	}
+
+	changeModel := &model.HiveStructureChange{
+		Db:     r.Db,
+		UserID: uid,
+	}
+
+	changeID, err := changeModel.CreateTx(tx, model.HiveStructureChangeSplit, sourceHive.ID, newHive.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	familyModel := &model.Family{
+		Db:     r.Db,
+		UserID: uid,
+	}
+
+	if queenAction == "new_queen" {
+		_, err = familyModel.CreateForHiveTx(tx, newHive.ID, queenName, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	} else if oldQueen != nil {
+		logger.Info("Moving queen " + oldQueen.ID + " from hive " + sourceHive.ID + " to hive " + newHive.ID)
 
-		err = familyModel.MoveBetweenHives(oldQueen.ID, sourceHiveID, newHive.ID)
+		err = familyModel.MoveBetweenHivesTx(tx, oldQueen.ID, sourceHive.ID, newHive.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else if queenAction == "no_queen" {
+		// the split raises an emergency queen from the source colony brood
+		err = familyModel.RememberSplitMotherTx(tx, newHive.ID, sourceHive.ID)
 		if err != nil {
-			logger.ErrorWithContext(ctx, "Error moving queen to split hive: "+err.Error())
 			return nil, err
 		}
 	}
@@ -287,37 +394,53 @@ func (r *mutationResolver) SplitHive(ctx context.Context, sourceHiveID string, q
 		UserID: uid,
 	}
 
-	newBoxID, err := boxModel.CreateSingleBox(newHive.ID, 0, "#ffc848", model.BoxTypeDeep)
+	newBoxID, err := boxModel.CreateSingleBoxTx(tx, newHive.ID, 0, "#ffc848", model.BoxTypeDeep)
 	if err != nil {
-		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
-	_, err = boxModel.CreateSingleBox(newHive.ID, 1, "#363636", model.BoxTypeRoof)
+	roofBoxID, err := boxModel.CreateSingleBoxTx(tx, newHive.ID, 1, "#363636", model.BoxTypeRoof)
 	if err != nil {
-		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
+	for _, boxID := range []string{newBoxID, roofBoxID} {
+		if err = changeModel.RecordCreatedBoxTx(tx, changeID, boxID, newHive.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	frameModel := &model.Frame{
 		Db:     r.Db,
 		UserID: uid,
 	}
 
-	err = frameModel.MoveFramesToBox(frameIds, newBoxID)
+	err = changeModel.RecordFrameMovesTx(tx, changeID, frameIds, newBoxID)
 	if err != nil {
-		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
-	redisPubSub.PublishEvent(uid, "hive", newHive.ID, "split", newHive)
+	err = frameModel.MoveFramesToBoxTx(tx, frameIds, newBoxID)
+	if err != nil {
+		return nil, err
+	}
 
 	return newHive, nil
 }
 
 // JoinHives is the resolver for the joinHives field.
 func (r *mutationResolver) JoinHives(ctx context.Context, sourceHiveID string, targetHiveID string, mergeType string) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, targetHiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	sourceUID, err := r.actingUserID(ctx, model.AccessHive, sourceHiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if sourceUID != uid {
+		return nil, errors.New("hives to join must belong to the same owner")
+	}
 
 	validMergeTypes := map[string]bool{
 		"both_queens":       true,
@@ -382,35 +505,127 @@ func (r *mutationResolver) JoinHives(ctx context.Context, sourceHiveID string, t
 		}
 	}
 
+	// all steps share one transaction, so a failed step leaves both hives untouched
+	tx := r.Db.MustBegin()
+
+	err = r.joinHivesTx(tx, uid, sourceHiveID, targetHiveID, boxIDsToMove, mergeType)
+	if err != nil {
+		tx.Rollback()
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	updatedTargetHive, err := hiveModel.Get(targetHiveID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return updatedTargetHive, nil
+}
+
+func (r *mutationResolver) joinHivesTx(tx *sqlx.Tx, uid string, sourceHiveID string, targetHiveID string, boxIDsToMove []string, mergeType string) error {
+	changeModel := &model.HiveStructureChange{
+		Db:     r.Db,
+		UserID: uid,
+	}
+
+	changeID, err := changeModel.CreateTx(tx, model.HiveStructureChangeMerge, sourceHiveID, targetHiveID)
+	if err != nil {
+		return err
+	}
+
 	if len(boxIDsToMove) > 0 {
-		maxPos, err := boxModel.GetMaxPosition(targetHiveID)
+		boxModel := &model.Box{
+			Db:     r.Db,
+			UserID: uid,
+		}
+
+		maxPos, err := boxModel.GetMaxPositionTx(tx, targetHiveID)
 		if err != nil {
-			logger.ErrorWithContext(ctx, err.Error())
-			return nil, err
+			return err
 		}
 
-		err = boxModel.MoveBoxesToHive(boxIDsToMove, targetHiveID, maxPos+1)
+		err = changeModel.RecordBoxMovesTx(tx, changeID, boxIDsToMove, targetHiveID, maxPos+1)
 		if err != nil {
-			logger.ErrorWithContext(ctx, err.Error())
-			return nil, err
+			return err
 		}
+
+		err = boxModel.MoveBoxesToHiveTx(tx, boxIDsToMove, targetHiveID, maxPos+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	hiveModel := &model.Hive{
+		Db:     r.Db,
+		UserID: uid,
+	}
+
+	return hiveModel.MarkAsMergedTx(tx, sourceHiveID, targetHiveID, time.Now(), mergeType)
+}
+
+// RevertSplit is the resolver for the revertSplit field.
+func (r *mutationResolver) RevertSplit(ctx context.Context, hiveID string) (*model.Hive, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessWrite)
+	if err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
-	err = hiveModel.MarkAsMerged(sourceHiveID, targetHiveID, now, mergeType)
+	tx := r.Db.MustBegin()
+
+	sourceHiveID, err := (&model.HiveStructureChange{
+		Db:     r.Db,
+		UserID: uid,
+	}).RevertSplitTx(tx, hiveID)
 	if err != nil {
+		tx.Rollback()
 		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
-	updatedTargetHive, err := hiveModel.Get(targetHiveID)
+	if err = tx.Commit(); err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return (&model.Hive{
+		Db:     r.Db,
+		UserID: uid,
+	}).Get(sourceHiveID)
+}
+
+// RevertMerge is the resolver for the revertMerge field.
+func (r *mutationResolver) RevertMerge(ctx context.Context, sourceHiveID string) (*model.Hive, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, sourceHiveID, accessWrite)
 	if err != nil {
+		return nil, err
+	}
+
+	tx := r.Db.MustBegin()
+
+	err = (&model.HiveStructureChange{
+		Db:     r.Db,
+		UserID: uid,
+	}).RevertMergeTx(tx, sourceHiveID)
+	if err != nil {
+		tx.Rollback()
 		logger.ErrorWithContext(ctx, err.Error())
 		return nil, err
 	}
 
-	redisPubSub.PublishEvent(uid, "hive", targetHiveID, "join", updatedTargetHive)
-	redisPubSub.PublishEvent(uid, "hive", sourceHiveID, "merged", sourceHive)
+	if err = tx.Commit(); err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
 
-	return updatedTargetHive, nil
+	return (&model.Hive{
+		Db:     r.Db,
+		UserID: uid,
+	}).Get(sourceHiveID)
 }
@@ -2,22 +2,71 @@ package graph
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/Gratheon/swarm-api/graph/model"
 )
 
 // AddInspection is the resolver for the addInspection field.
 func (r *mutationResolver) AddInspection(ctx context.Context, inspection model.InspectionInput) (*model.Inspection, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, strconv.Itoa(inspection.HiveID), accessWrite)
+	if err != nil {
+		return nil, err
+	}
 	inspectionModel := &model.Inspection{
 		Db:     r.Resolver.Db,
 		UserID: uid,
 	}
 
-	id, err := inspectionModel.Create(inspection.Data, inspection.HiveID)
+	data := inspection.Data
+	if inspection.Observations != nil {
+		doc, err := model.ParseInspectionDocument(data)
+		if err != nil {
+			return nil, err
+		}
+		if err = doc.ApplyInput(inspection.Observations); err != nil {
+			return nil, err
+		}
+		if data, err = doc.JSON(); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := inspectionModel.Create(data, inspection.HiveID)
 	if err != nil {
 		return nil, err
 	}
 
 	return inspectionModel.Get(*id)
 }
+
+// UpdateInspection is the resolver for the updateInspection field.
+func (r *mutationResolver) UpdateInspection(ctx context.Context, id string, inspection model.InspectionUpdateInput) (*model.Inspection, error) {
+	uid, err := r.actingUserID(ctx, model.AccessInspection, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	inspectionModel := &model.Inspection{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}
+
+	err = inspectionModel.Update(id, inspection.Data, inspection.Observations)
+	if err != nil {
+		return nil, err
+	}
+
+	return inspectionModel.Get(id)
+}
+
+// DeleteInspection is the resolver for the deleteInspection field.
+func (r *mutationResolver) DeleteInspection(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessInspection, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
+	return (&model.Inspection{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+}
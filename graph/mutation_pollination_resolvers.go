@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// AddPollinationContract is the resolver for the addPollinationContract field.
+func (r *mutationResolver) AddPollinationContract(ctx context.Context, contract model.PollinationContractInput) (*model.PollinationContract, error) {
+	uid, err := r.actingUserID(ctx, model.AccessApiary, contract.ApiaryID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Create(contract)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// UpdatePollinationContract is the resolver for the updatePollinationContract field.
+func (r *mutationResolver) UpdatePollinationContract(ctx context.Context, id string, contract model.PollinationContractInput) (*model.PollinationContract, error) {
+	uid, err := r.actingUserID(ctx, model.AccessPollination, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	apiaryUID, err := r.actingUserID(ctx, model.AccessApiary, contract.ApiaryID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if apiaryUID != uid {
+		return nil, errors.New("contracts can only be moved between apiaries of the same owner")
+	}
+
+	updated, err := (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Update(id, contract)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeletePollinationContract is the resolver for the deletePollinationContract field.
+func (r *mutationResolver) DeletePollinationContract(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessPollination, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+}
+
+// PlacePollinationHives is the resolver for the placePollinationHives field.
+func (r *mutationResolver) PlacePollinationHives(ctx context.Context, id string, date *string) (*model.PollinationContract, error) {
+	uid, err := r.actingUserID(ctx, model.AccessPollination, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Place(id, date)
+}
+
+// RemovePollinationHives is the resolver for the removePollinationHives field.
+func (r *mutationResolver) RemovePollinationHives(ctx context.Context, id string, date *string) (*model.PollinationContract, error) {
+	uid, err := r.actingUserID(ctx, model.AccessPollination, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Remove(id, date)
+}
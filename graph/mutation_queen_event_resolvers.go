@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// AddQueenEvent is the resolver for the addQueenEvent field.
+func (r *mutationResolver) AddQueenEvent(ctx context.Context, familyID string, event model.QueenEventInput) (*model.QueenEvent, error) {
+	uid, err := r.actingUserID(ctx, model.AccessFamily, familyID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	created, err := (&model.QueenEvent{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Create(familyID, event.Type, event.Date, event.Notes)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// DeleteQueenEvent is the resolver for the deleteQueenEvent field.
+func (r *mutationResolver) DeleteQueenEvent(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessQueenEvent, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := (&model.QueenEvent{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return false, err
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// AddQueenRating is the resolver for the addQueenRating field.
+func (r *mutationResolver) AddQueenRating(ctx context.Context, familyID string, rating model.QueenRatingInput) (*model.QueenRating, error) {
+	uid, err := r.actingUserID(ctx, model.AccessFamily, familyID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	created, err := (&model.QueenRating{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Create(familyID, rating)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// DeleteQueenRating is the resolver for the deleteQueenRating field.
+func (r *mutationResolver) DeleteQueenRating(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessQueenRating, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := (&model.QueenRating{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return false, err
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// AddQueenRearingBatch is the resolver for the addQueenRearingBatch field.
+func (r *mutationResolver) AddQueenRearingBatch(ctx context.Context, batch model.QueenRearingBatchInput) (*model.QueenRearingBatch, error) {
+	uid := ctx.Value("userID").(string)
+	created, err := (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Create(batch)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// UpdateQueenRearingBatch is the resolver for the updateQueenRearingBatch field.
+func (r *mutationResolver) UpdateQueenRearingBatch(ctx context.Context, id string, progress model.QueenRearingProgressInput) (*model.QueenRearingBatch, error) {
+	uid := ctx.Value("userID").(string)
+	batch, err := (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).UpdateProgress(id, progress)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// DeleteQueenRearingBatch is the resolver for the deleteQueenRearingBatch field.
+func (r *mutationResolver) DeleteQueenRearingBatch(ctx context.Context, id string) (bool, error) {
+	uid := ctx.Value("userID").(string)
+	deleted, err := (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return false, err
+	}
+
+	return deleted, nil
+}
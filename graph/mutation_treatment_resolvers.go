@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// hiveFamilyID is the primary queen of the hive, treatments and mite counts of the hive are recorded for her
+func (r *mutationResolver) hiveFamilyID(uid string, hiveID string) (*int, error) {
+	families, err := (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByHive(hiveID)
+	if err != nil {
+		return nil, err
+	}
+	if len(families) == 0 {
+		return nil, nil
+	}
+
+	familyID, _ := strconv.Atoi(families[0].ID)
+	return &familyID, nil
+}
+
+// TreatHive is the resolver for the treatHive field.
+func (r *mutationResolver) TreatHive(ctx context.Context, treatment model.TreatmentOfHiveInput) (*bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, treatment.HiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	treatmentModel := &model.Treatment{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}
+
+	ok := false
+	familyID, err := r.hiveFamilyID(uid, treatment.HiveID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return &ok, err
+	}
+
+	_, err = treatmentModel.TreatHive(treatment, familyID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return &ok, err
+	}
+
+	ok = true
+	return &ok, nil
+}
+
+// TreatBox is the resolver for the treatBox field.
+func (r *mutationResolver) TreatBox(ctx context.Context, treatment model.TreatmentOfBoxInput) (*bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessBox, treatment.BoxID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	treatmentModel := &model.Treatment{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}
+
+	ok := false
+	// in multi-queen hives the box may be occupied by another queen than the primary one
+	familyID, err := (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).BoxOccupantID(treatment.BoxID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return &ok, err
+	}
+
+	_, err = treatmentModel.TreatHiveBox(treatment, familyID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return &ok, err
+	}
+
+	ok = true
+	return &ok, nil
+}
+
+// AddTreatmentProduct is the resolver for the addTreatmentProduct field.
+func (r *mutationResolver) AddTreatmentProduct(ctx context.Context, product model.TreatmentProductInput) (*model.TreatmentProduct, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.TreatmentProduct{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Create(product)
+}
+
+// UpdateTreatmentProduct is the resolver for the updateTreatmentProduct field.
+func (r *mutationResolver) UpdateTreatmentProduct(ctx context.Context, id string, product model.TreatmentProductInput) (*model.TreatmentProduct, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.TreatmentProduct{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Update(id, product)
+}
+
+// DeleteTreatmentProduct is the resolver for the deleteTreatmentProduct field.
+func (r *mutationResolver) DeleteTreatmentProduct(ctx context.Context, id string) (bool, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.TreatmentProduct{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+}
+
+// StartTreatmentCourse is the resolver for the startTreatmentCourse field.
+func (r *mutationResolver) StartTreatmentCourse(ctx context.Context, course model.TreatmentCourseInput) (*model.TreatmentCourse, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, course.HiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := r.hiveFamilyID(uid, course.HiveID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	started, err := (&model.TreatmentCourse{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Start(course, familyID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return started, nil
+}
+
+// FinishTreatmentCourse is the resolver for the finishTreatmentCourse field.
+func (r *mutationResolver) FinishTreatmentCourse(ctx context.Context, id string) (*model.TreatmentCourse, error) {
+	uid, err := r.actingUserID(ctx, model.AccessTreatmentCourse, id, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.TreatmentCourse{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Finish(id)
+}
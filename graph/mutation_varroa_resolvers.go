@@ -0,0 +1,45 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// AddVarroaCount is the resolver for the addVarroaCount field.
+func (r *mutationResolver) AddVarroaCount(ctx context.Context, count model.VarroaCountInput) (*model.VarroaCount, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, count.HiveID, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := r.hiveFamilyID(uid, count.HiveID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	created, err := (&model.VarroaCount{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Create(count, familyID)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// DeleteVarroaCount is the resolver for the deleteVarroaCount field.
+func (r *mutationResolver) DeleteVarroaCount(ctx context.Context, id string) (bool, error) {
+	uid, err := r.actingUserID(ctx, model.AccessVarroaCount, id, accessWrite)
+	if err != nil {
+		return false, err
+	}
+	return (&model.VarroaCount{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Delete(id)
+}
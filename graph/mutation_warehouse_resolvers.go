@@ -73,3 +73,18 @@ func (r *mutationResolver) SetWarehouseAutoUpdateFromHives(ctx context.Context,
 
 	return updated, nil
 }
+
+// SetWarehouseFeedStock is the resolver for the setWarehouseFeedStock field.
+func (r *mutationResolver) SetWarehouseFeedStock(ctx context.Context, feedType model.FeedType, amountKg float64) (*model.FeedStock, error) {
+	uid := ctx.Value("userID").(string)
+	updated, err := (&model.WarehouseFeedStock{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Set(feedType, amountKg)
+	if err != nil {
+		logger.ErrorWithContext(ctx, err.Error())
+		return nil, err
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,255 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outboxTestRow struct {
+	ID            int64   `db:"id"`
+	EventID       string  `db:"event_id"`
+	AggregateType string  `db:"aggregate_type"`
+	AggregateID   string  `db:"aggregate_id"`
+	Entity        string  `db:"entity"`
+	Action        string  `db:"action"`
+	ApiaryID      *string `db:"apiary_id"`
+	Payload       *string `db:"payload"`
+	Published     bool    `db:"published"`
+	Attempts      int     `db:"attempts"`
+	LastError     *string `db:"last_error"`
+}
+
+func listOutboxRows(t *testing.T, db *sqlx.DB, userID string) []outboxTestRow {
+	rows := []outboxTestRow{}
+	err := db.Select(&rows,
+		`SELECT id, event_id, aggregate_type, aggregate_id, entity, action, apiary_id, payload,
+			published_at IS NOT NULL AS published, attempts, last_error
+		FROM outbox_events
+		WHERE user_id=?
+		ORDER BY id`, userID)
+	require.NoError(t, err)
+	return rows
+}
+
+// relayUntilSettled runs batches until events of the user are no longer due, other tests may have pending events too
+func relayUntilSettled(t *testing.T, relay *OutboxRelay, db *sqlx.DB, userID string) {
+	for i := 0; i < 20; i++ {
+		_, err := relay.RelayPending()
+		require.NoError(t, err)
+
+		var due int
+		err = db.Get(&due,
+			`SELECT COUNT(id) FROM outbox_events
+			WHERE user_id=? AND published_at IS NULL AND attempts=0`, userID)
+		require.NoError(t, err)
+		if due == 0 {
+			return
+		}
+	}
+}
+
+func TestOutboxEvents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records hive update in the same transaction", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := strconv.Itoa(createTestHive(t, db, userID, apiaryID))
+		notes := "calm colony"
+
+		// ACT
+		err := (&model.Hive{Db: db, UserID: userID}).Update(hiveID, &notes, nil, nil)
+
+		// ASSERT
+		require.NoError(t, err)
+		rows := listOutboxRows(t, db, userID)
+		require.Len(t, rows, 1)
+		assert.NotEmpty(t, rows[0].EventID)
+		assert.Equal(t, "hive", rows[0].AggregateType)
+		assert.Equal(t, hiveID, rows[0].AggregateID)
+		assert.Equal(t, "updated", rows[0].Action)
+		require.NotNil(t, rows[0].ApiaryID)
+		assert.Equal(t, strconv.Itoa(apiaryID), *rows[0].ApiaryID)
+		require.NotNil(t, rows[0].Payload)
+		assert.Contains(t, *rows[0].Payload, notes)
+		assert.False(t, rows[0].Published)
+	})
+
+	t.Run("records a created event for every box of a new hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+
+		// ACT
+		hive, err := mutation.AddHive(ctx, model.HiveInput{ApiaryID: strconv.Itoa(apiaryID), BoxCount: 2, FrameCount: 4})
+
+		// ASSERT
+		require.NoError(t, err)
+		boxEvents := 0
+		for _, row := range listOutboxRows(t, db, userID) {
+			if row.Entity == "box" && row.Action == "created" {
+				boxEvents++
+				assert.Equal(t, hive.ID, row.AggregateID)
+			}
+		}
+		assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM boxes WHERE hive_id=? AND active=1", hive.ID), boxEvents)
+		assert.GreaterOrEqual(t, boxEvents, 2)
+	})
+
+	t.Run("does not record events of rolled back changes", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hive1ID := createTestHive(t, db, userID, apiaryID)
+		hive2ID := createTestHive(t, db, userID, apiaryID)
+		db.MustExec("UPDATE hives SET hive_number=1 WHERE id=?", hive1ID)
+		takenNumber := 1
+
+		// ACT
+		err := (&model.Hive{Db: db, UserID: userID}).Update(strconv.Itoa(hive2ID), nil, &takenNumber, nil)
+
+		// ASSERT
+		require.Error(t, err)
+		assert.Empty(t, listOutboxRows(t, db, userID))
+	})
+
+	t.Run("relay publishes events and marks them published", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		boxID := strconv.Itoa(createTestBox(t, db, userID, hiveID))
+		boxModel := &model.Box{Db: db, UserID: userID}
+		first := "#ff0000"
+		second := "#00ff00"
+		_, err := boxModel.Update(&boxID, 0, &first)
+		require.NoError(t, err)
+		_, err = boxModel.Update(&boxID, 0, &second)
+		require.NoError(t, err)
+
+		hiveChannel := hiveChangesChannel(userID, strconv.Itoa(hiveID))
+		var hivePayloads []string
+		relay := &OutboxRelay{
+			Db: db,
+			publish: func(channel string, payload []byte) error {
+				if channel == hiveChannel {
+					hivePayloads = append(hivePayloads, string(payload))
+				}
+				return nil
+			},
+		}
+
+		// ACT
+		relayUntilSettled(t, relay, db, userID)
+
+		// ASSERT
+		rows := listOutboxRows(t, db, userID)
+		require.Len(t, rows, 2)
+		assert.True(t, rows[0].Published)
+		assert.True(t, rows[1].Published)
+		require.Len(t, hivePayloads, 2)
+		assert.Contains(t, hivePayloads[0], rows[0].EventID)
+		assert.Contains(t, hivePayloads[1], rows[1].EventID)
+	})
+
+	t.Run("relay holds back later events of an aggregate after a failed publish", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		boxID := strconv.Itoa(createTestBox(t, db, userID, hiveID))
+		boxModel := &model.Box{Db: db, UserID: userID}
+		color := "#ff0000"
+		_, err := boxModel.Update(&boxID, 0, &color)
+		require.NoError(t, err)
+		_, err = boxModel.Update(&boxID, 1, &color)
+		require.NoError(t, err)
+
+		hiveChannel := hiveChangesChannel(userID, strconv.Itoa(hiveID))
+		relay := &OutboxRelay{
+			Db: db,
+			publish: func(channel string, payload []byte) error {
+				if channel == hiveChannel {
+					return errors.New("redis is down")
+				}
+				return nil
+			},
+		}
+
+		// ACT
+		relayUntilSettled(t, relay, db, userID)
+
+		// ASSERT
+		rows := listOutboxRows(t, db, userID)
+		require.Len(t, rows, 2)
+		assert.False(t, rows[0].Published)
+		assert.Equal(t, 1, rows[0].Attempts)
+		require.NotNil(t, rows[0].LastError)
+		assert.Contains(t, *rows[0].LastError, "redis is down")
+		assert.False(t, rows[1].Published)
+		assert.Equal(t, 0, rows[1].Attempts)
+	})
+}
@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Gratheon/log-lib-go"
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/Gratheon/swarm-api/redisPubSub"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// only one instance relays at a time, otherwise per-aggregate ordering could not be kept
+	outboxRelayLockName      = "swarm_api_outbox_relay"
+	outboxRelayPollInterval  = 500 * time.Millisecond
+	outboxRelayBatchSize     = 200
+	outboxRetryBaseDelay     = time.Second
+	outboxRetryMaxDelay      = 5 * time.Minute
+	outboxRetention          = 7 * 24 * time.Hour
+	outboxRetentionFrequency = time.Hour
+)
+
+var outboxEventsPublishedTotal = promauto.With(dbMetricsRegisterer).NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "swarm_api_outbox_events_published_total",
+		Help: "Total number of outbox events published to redis",
+	},
+	[]string{"entity", "action"},
+)
+
+var outboxPublishFailuresTotal = promauto.With(dbMetricsRegisterer).NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "swarm_api_outbox_publish_failures_total",
+		Help: "Total number of failed outbox event publish attempts",
+	},
+	[]string{"entity", "action"},
+)
+
+var outboxPendingEvents = promauto.With(dbMetricsRegisterer).NewGauge(
+	prometheus.GaugeOpts{
+		Name: "swarm_api_outbox_pending_events",
+		Help: "Number of outbox events not yet published",
+	},
+)
+
+var outboxLagSeconds = promauto.With(dbMetricsRegisterer).NewGauge(
+	prometheus.GaugeOpts{
+		Name: "swarm_api_outbox_lag_seconds",
+		Help: "Age of the oldest unpublished outbox event in seconds",
+	},
+)
+
+// OutboxRelay publishes domain events recorded in outbox_events to redis.
+// Delivery is at-least-once, consumers dedupe by eventId.
+type OutboxRelay struct {
+	Db      *sqlx.DB
+	publish func(channel string, payload []byte) error
+
+	lastPrunedAt time.Time
+}
+
+func NewOutboxRelay(db *sqlx.DB) *OutboxRelay {
+	return &OutboxRelay{
+		Db:      db,
+		publish: redisPubSub.PublishPayload,
+	}
+}
+
+// Run polls for pending events until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxRelayPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.relayWithLock(ctx); err != nil {
+				logger.Error(fmt.Sprintf("Outbox relay failed: %v", err))
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) relayWithLock(ctx context.Context) error {
+	conn, err := r.Db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// GET_LOCK is bound to the connection, so the same connection has to release it
+	var locked sql.NullInt64
+	err = conn.GetContext(ctx, &locked, `SELECT GET_LOCK(?, 0)`, outboxRelayLockName)
+	if err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return nil
+	}
+	defer conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, outboxRelayLockName)
+
+	if _, err = r.RelayPending(); err != nil {
+		return err
+	}
+
+	r.updateMetrics()
+	r.prunePublished()
+
+	return nil
+}
+
+// RelayPending publishes one batch of due events and returns how many were published.
+// Once an event of an aggregate fails, later events of that aggregate are held back.
+func (r *OutboxRelay) RelayPending() (int, error) {
+	outboxModel := &model.OutboxEvent{Db: r.Db}
+	events, err := outboxModel.ListPending(outboxRelayBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blockedAggregates := map[string]bool{}
+	for _, event := range events {
+		aggregateKey := event.AggregateKey()
+		if blockedAggregates[aggregateKey] {
+			continue
+		}
+
+		publishErr := r.publishEvent(event)
+		if publishErr != nil {
+			blockedAggregates[aggregateKey] = true
+			outboxPublishFailuresTotal.WithLabelValues(event.Entity, event.Action).Inc()
+			logger.Error(fmt.Sprintf("Failed to publish outbox event %s (attempt %d): %v", event.EventID, event.Attempts+1, publishErr))
+
+			if err := outboxModel.MarkFailed(event.ID, publishErr, outboxRetryDelay(event.Attempts+1)); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := outboxModel.MarkPublished(event.ID); err != nil {
+			return published, err
+		}
+		outboxEventsPublishedTotal.WithLabelValues(event.Entity, event.Action).Inc()
+		published++
+	}
+
+	return published, nil
+}
+
+func (r *OutboxRelay) publishEvent(event *model.OutboxEvent) error {
+	envelope, err := json.Marshal(outboxEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range outboxSubscriptionChannels(event) {
+		if err := r.publish(channel, envelope); err != nil {
+			return err
+		}
+	}
+
+	// legacy channels keep the raw entity payload for existing event-stream consumers
+	legacyPayload := []byte("null")
+	if event.Payload != nil {
+		legacyPayload = []byte(*event.Payload)
+	}
+	legacyChannel := fmt.Sprintf("%s.%s.%s.%s", event.UserID, event.Entity, event.EntityID, event.Action)
+	if err := r.publish(legacyChannel, legacyPayload); err != nil {
+		return err
+	}
+
+	return r.publish(legacyChannel+"."+event.Action, legacyPayload)
+}
+
+func (r *OutboxRelay) updateMetrics() {
+	pending, lag, err := (&model.OutboxEvent{Db: r.Db}).PendingStats()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to read outbox stats: %v", err))
+		return
+	}
+
+	outboxPendingEvents.Set(float64(pending))
+	outboxLagSeconds.Set(lag.Seconds())
+}
+
+func (r *OutboxRelay) prunePublished() {
+	if time.Since(r.lastPrunedAt) < outboxRetentionFrequency {
+		return
+	}
+	r.lastPrunedAt = time.Now()
+
+	if _, err := (&model.OutboxEvent{Db: r.Db}).DeletePublishedOlderThan(outboxRetention); err != nil {
+		logger.Error(fmt.Sprintf("Failed to prune published outbox events: %v", err))
+	}
+}
+
+func outboxEnvelope(event *model.OutboxEvent) *model.ChangeEvent {
+	return &model.ChangeEvent{
+		EventID:    event.EventID,
+		Version:    event.Version,
+		Entity:     event.Entity,
+		EntityID:   event.EntityID,
+		Action:     event.Action,
+		HiveID:     event.HiveID,
+		ApiaryID:   event.ApiaryID,
+		Data:       event.Payload,
+		OccurredAt: event.OccurredAt,
+	}
+}
+
+func outboxSubscriptionChannels(event *model.OutboxEvent) []string {
+	channels := []string{}
+	if event.HiveID != nil {
+		channels = append(channels, hiveChangesChannel(event.UserID, *event.HiveID))
+	}
+	if event.ApiaryID != nil {
+		channels = append(channels, apiaryChangesChannel(event.UserID, *event.ApiaryID))
+	}
+	if event.Inventory {
+		channels = append(channels, inventoryChangesChannel(event.UserID))
+	}
+
+	return channels
+}
+
+// outboxRetryDelay doubles the delay with every failed attempt, up to outboxRetryMaxDelay
+func outboxRetryDelay(attempt int) time.Duration {
+	delay := outboxRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= outboxRetryMaxDelay {
+			return outboxRetryMaxDelay
+		}
+	}
+
+	return delay
+}
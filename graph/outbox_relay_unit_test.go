@@ -0,0 +1,116 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publishedMessage struct {
+	channel string
+	payload string
+}
+
+func newRecordingOutboxRelay(failOn string) (*OutboxRelay, *[]publishedMessage) {
+	published := []publishedMessage{}
+	relay := &OutboxRelay{
+		publish: func(channel string, payload []byte) error {
+			if channel == failOn {
+				return errors.New("redis is down")
+			}
+			published = append(published, publishedMessage{channel: channel, payload: string(payload)})
+			return nil
+		},
+	}
+
+	return relay, &published
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, outboxRetryDelay(1))
+	assert.Equal(t, 2*time.Second, outboxRetryDelay(2))
+	assert.Equal(t, 8*time.Second, outboxRetryDelay(4))
+	assert.Equal(t, outboxRetryMaxDelay, outboxRetryDelay(30))
+}
+
+func TestOutboxRelayPublishesEnvelopeAndLegacyPayload(t *testing.T) {
+	hiveID := "12"
+	apiaryID := "3"
+	payload := `{"id":"12","notes":"calm"}`
+	event := &model.OutboxEvent{
+		EventID:    "5b0c2a4e-8f56-4a8e-9d4f-3c6a1d0e7f11",
+		Version:    model.OutboxEventVersion,
+		UserID:     "7",
+		Entity:     "hive",
+		EntityID:   hiveID,
+		Action:     "updated",
+		HiveID:     &hiveID,
+		ApiaryID:   &apiaryID,
+		Payload:    &payload,
+		OccurredAt: "2026-10-18T10:00:00Z",
+	}
+	relay, published := newRecordingOutboxRelay("")
+
+	err := relay.publishEvent(event)
+
+	require.NoError(t, err)
+	require.Len(t, *published, 4)
+	assert.Equal(t, "7.subscription.hive.12", (*published)[0].channel)
+	assert.Equal(t, "7.subscription.apiary.3", (*published)[1].channel)
+	assert.Equal(t, "7.hive.12.updated", (*published)[2].channel)
+	assert.Equal(t, "7.hive.12.updated.updated", (*published)[3].channel)
+	assert.JSONEq(t, payload, (*published)[2].payload)
+
+	envelope := model.ChangeEvent{}
+	require.NoError(t, json.Unmarshal([]byte((*published)[0].payload), &envelope))
+	assert.Equal(t, event.EventID, envelope.EventID)
+	assert.Equal(t, model.OutboxEventVersion, envelope.Version)
+	assert.Equal(t, "updated", envelope.Action)
+	require.NotNil(t, envelope.Data)
+	assert.JSONEq(t, payload, *envelope.Data)
+}
+
+func TestOutboxRelayPublishesWarehouseFamilyToInventory(t *testing.T) {
+	event := &model.OutboxEvent{
+		EventID:   "e1",
+		UserID:    "7",
+		Entity:    "family",
+		EntityID:  "4",
+		Action:    "deleted",
+		Inventory: true,
+	}
+	relay, published := newRecordingOutboxRelay("")
+
+	err := relay.publishEvent(event)
+
+	require.NoError(t, err)
+	require.Len(t, *published, 3)
+	assert.Equal(t, "7.subscription.inventory", (*published)[0].channel)
+	assert.Equal(t, "null", (*published)[1].payload)
+}
+
+func TestOutboxRelayStopsOnPublishError(t *testing.T) {
+	hiveID := "12"
+	event := &model.OutboxEvent{
+		EventID:  "e1",
+		UserID:   "7",
+		Entity:   "box",
+		EntityID: "5",
+		Action:   "created",
+		HiveID:   &hiveID,
+	}
+	relay, published := newRecordingOutboxRelay("7.subscription.hive.12")
+
+	err := relay.publishEvent(event)
+
+	assert.Error(t, err)
+	assert.Empty(t, *published)
+}
@@ -0,0 +1,197 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollinationContracts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("hives can be placed only when the apiary holds enough strong hives", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryIDNum := createTestApiary(t, db, userID)
+		apiaryID := strconv.Itoa(apiaryIDNum)
+		db.MustExec("UPDATE apiaries SET type='MOBILE' WHERE id=?", apiaryIDNum)
+		strongHiveID := createTestHive(t, db, userID, apiaryIDNum)
+		weakHiveID := createTestHive(t, db, userID, apiaryIDNum)
+		db.MustExec("INSERT INTO inspections (user_id, hive_id, data, added, population) VALUES (?, ?, '{}', NOW(), 'STRONG')", userID, strongHiveID)
+		db.MustExec("INSERT INTO inspections (user_id, hive_id, data, added, population) VALUES (?, ?, '{}', NOW(), 'WEAK')", userID, weakHiveID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		strength := model.InspectionPopulationMedium
+		currency := "eur"
+		fee := 1200.0
+		input := model.PollinationContractInput{
+			ApiaryID:      apiaryID,
+			Grower:        "Berry farm",
+			Crop:          "blueberry",
+			RequiredHives: 2,
+			MinStrength:   &strength,
+			StartsAt:      time.Now().UTC().Add(-24 * time.Hour).Format(time.RFC3339),
+			EndsAt:        time.Now().UTC().Add(14 * 24 * time.Hour).Format(time.RFC3339),
+			Fee:           &fee,
+			FeeCurrency:   &currency,
+		}
+
+		contract, err := mutation.AddPollinationContract(ctx, input)
+		require.NoError(t, err)
+		contractID := contract.ID
+
+		// ACT
+		_, shortErr := mutation.PlacePollinationHives(ctx, contractID, nil)
+		input.RequiredHives = 1
+		_, err = mutation.UpdatePollinationContract(ctx, contractID, input)
+		require.NoError(t, err)
+		placed, placeErr := mutation.PlacePollinationHives(ctx, contractID, nil)
+
+		// ASSERT
+		assert.Equal(t, 2, contract.AssignedHives)
+		assert.Equal(t, 1, contract.QualifyingHives)
+		assert.Equal(t, 1, contract.HiveShortfall())
+		assert.Equal(t, model.PollinationContractStatusOverduePlacement, contract.Status())
+		require.NotNil(t, contract.FeeCurrency)
+		assert.Equal(t, "EUR", *contract.FeeCurrency)
+		require.Error(t, shortErr)
+		assert.Contains(t, shortErr.Error(), "1 of 2")
+
+		require.NoError(t, placeErr)
+		require.NotNil(t, placed.PlacedAt)
+		assert.Equal(t, model.PollinationContractStatusPlaced, placed.Status())
+	})
+
+	t.Run("hives placed for an overlapping contract are not available", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryIDNum := createTestApiary(t, db, userID)
+		db.MustExec("UPDATE apiaries SET type='MOBILE' WHERE id=?", apiaryIDNum)
+		createTestHive(t, db, userID, apiaryIDNum)
+		createTestHive(t, db, userID, apiaryIDNum)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		now := time.Now().UTC()
+		input := model.PollinationContractInput{
+			ApiaryID:      strconv.Itoa(apiaryIDNum),
+			Grower:        "Orchard",
+			Crop:          "apple",
+			RequiredHives: 2,
+			StartsAt:      now.Format(time.RFC3339),
+			EndsAt:        now.Add(10 * 24 * time.Hour).Format(time.RFC3339),
+		}
+		first, err := mutation.AddPollinationContract(ctx, input)
+		require.NoError(t, err)
+
+		input.Grower = "Neighbour orchard"
+		input.StartsAt = now.Add(5 * 24 * time.Hour).Format(time.RFC3339)
+		input.EndsAt = now.Add(20 * 24 * time.Hour).Format(time.RFC3339)
+		overlapping, err := mutation.AddPollinationContract(ctx, input)
+		require.NoError(t, err)
+
+		input.Grower = "Later orchard"
+		input.StartsAt = now.Add(30 * 24 * time.Hour).Format(time.RFC3339)
+		input.EndsAt = now.Add(40 * 24 * time.Hour).Format(time.RFC3339)
+		later, err := mutation.AddPollinationContract(ctx, input)
+		require.NoError(t, err)
+
+		// ACT
+		_, err = mutation.PlacePollinationHives(ctx, first.ID, nil)
+		require.NoError(t, err)
+		_, overlapErr := mutation.PlacePollinationHives(ctx, overlapping.ID, nil)
+		laterContract, laterErr := (&model.PollinationContract{Db: db, UserID: userID}).Get(later.ID)
+
+		// ASSERT
+		require.Error(t, overlapErr)
+		assert.Contains(t, overlapErr.Error(), "0 of 2")
+		require.NoError(t, laterErr)
+		assert.Equal(t, 2, laterContract.QualifyingHives)
+	})
+
+	t.Run("upcoming and overdue placements", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryIDNum := createTestApiary(t, db, userID)
+		staticApiaryID := strconv.Itoa(createTestApiary(t, db, userID))
+		apiaryID := strconv.Itoa(apiaryIDNum)
+		db.MustExec("UPDATE apiaries SET type='MOBILE' WHERE id=?", apiaryIDNum)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		contractStarting := func(startsIn time.Duration) model.PollinationContractInput {
+			return model.PollinationContractInput{
+				ApiaryID:      apiaryID,
+				Grower:        "Orchard",
+				Crop:          "apple",
+				RequiredHives: 1,
+				StartsAt:      time.Now().UTC().Add(startsIn).Format(time.RFC3339),
+				EndsAt:        time.Now().UTC().Add(startsIn + 10*24*time.Hour).Format(time.RFC3339),
+			}
+		}
+
+		upcoming, err := mutation.AddPollinationContract(ctx, contractStarting(3*24*time.Hour))
+		require.NoError(t, err)
+		late, err := mutation.AddPollinationContract(ctx, contractStarting(-2*24*time.Hour))
+		require.NoError(t, err)
+		_, err = mutation.AddPollinationContract(ctx, contractStarting(60*24*time.Hour))
+		require.NoError(t, err)
+		staticInput := contractStarting(time.Hour)
+		staticInput.ApiaryID = staticApiaryID
+
+		// ACT
+		_, staticErr := mutation.AddPollinationContract(ctx, staticInput)
+		upcomingList, upcomingErr := query.UpcomingPollinationPlacements(ctx, nil, nil)
+		overdueList, overdueErr := query.OverduePollinationPlacements(ctx, &apiaryID)
+
+		// ASSERT
+		assert.Error(t, staticErr, "only mobile apiaries take pollination contracts")
+		require.NoError(t, upcomingErr)
+		require.Len(t, upcomingList, 1)
+		assert.Equal(t, upcoming.ID, upcomingList[0].ID)
+		require.NoError(t, overdueErr)
+		require.Len(t, overdueList, 1)
+		assert.Equal(t, late.ID, overdueList[0].ID)
+	})
+}
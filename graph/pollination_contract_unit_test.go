@@ -0,0 +1,29 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollinationContractStatus(t *testing.T) {
+	past := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	yesterday := time.Now().UTC().Add(-24 * time.Hour).Format(time.RFC3339)
+	future := time.Now().UTC().Add(48 * time.Hour).Format(time.RFC3339)
+
+	assert.Equal(t, model.PollinationContractStatusPlanned, (&model.PollinationContract{StartsAt: future, EndsAt: future}).Status())
+	assert.Equal(t, model.PollinationContractStatusOverduePlacement, (&model.PollinationContract{StartsAt: past, EndsAt: future}).Status())
+	assert.Equal(t, model.PollinationContractStatusPlaced, (&model.PollinationContract{StartsAt: past, EndsAt: future, PlacedAt: &past}).Status())
+	assert.Equal(t, model.PollinationContractStatusOverdueRemoval, (&model.PollinationContract{StartsAt: past, EndsAt: yesterday, PlacedAt: &past}).Status())
+	assert.Equal(t, model.PollinationContractStatusCompleted, (&model.PollinationContract{StartsAt: past, EndsAt: yesterday, PlacedAt: &past, RemovedAt: &yesterday}).Status())
+}
+
+func TestPollinationContractHiveShortfall(t *testing.T) {
+	assert.Equal(t, 3, (&model.PollinationContract{RequiredHives: 5, QualifyingHives: 2}).HiveShortfall())
+	assert.Equal(t, 0, (&model.PollinationContract{RequiredHives: 5, QualifyingHives: 7}).HiveShortfall())
+}
@@ -0,0 +1,122 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueenEvents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("history merges events, moves and treatments and status follows the latest event", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		hive := strconv.Itoa(hiveID)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		familyFields := &familyResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenName := "Lady"
+
+		queen, err := mutation.AddQueenToHive(ctx, hive, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+		treatedOn := "2026-05-01T08:00:00Z"
+		_, err = mutation.TreatHive(ctx, model.TreatmentOfHiveInput{HiveID: hive, Type: "oxalic_acid", StartDate: &treatedOn})
+		require.NoError(t, err)
+
+		addEvent := func(eventType model.QueenEventType, date string) *model.QueenEvent {
+			event, err := mutation.AddQueenEvent(ctx, queen.ID, model.QueenEventInput{Type: eventType, Date: &date})
+			require.NoError(t, err)
+			return event
+		}
+		addEvent(model.QueenEventTypeAccepted, "2026-04-10")
+		addEvent(model.QueenEventTypeIntroduced, "2026-04-01")
+		addEvent(model.QueenEventTypeLaying, "2026-04-20")
+
+		// ACT
+		history, historyErr := familyFields.History(ctx, queen)
+		layingStatus, _ := familyFields.Status(ctx, queen)
+		superseded := addEvent(model.QueenEventTypeSuperseded, "2026-09-01")
+		supersededStatus, _ := familyFields.Status(ctx, queen)
+		deleted, deleteErr := mutation.DeleteQueenEvent(ctx, superseded.ID)
+		restoredStatus, _ := familyFields.Status(ctx, queen)
+
+		// ASSERT
+		require.NoError(t, historyErr)
+		require.Len(t, history, 5)
+		assert.Equal(t, model.QueenEventTypeIntroduced, history[0].Event.Type)
+		assert.Equal(t, model.QueenEventTypeAccepted, history[1].Event.Type)
+		require.NotNil(t, history[1].Event.HiveID)
+		assert.Equal(t, hive, *history[1].Event.HiveID)
+		assert.Equal(t, model.QueenEventTypeLaying, history[2].Event.Type)
+		assert.Equal(t, model.FamilyHistoryKindTreatment, history[3].Kind)
+		require.NotNil(t, history[3].Treatment)
+		assert.Equal(t, "oxalic_acid", history[3].Treatment.Type)
+		assert.Equal(t, model.FamilyHistoryKindMove, history[4].Kind)
+		require.NotNil(t, history[4].ToHiveID)
+		assert.Equal(t, hive, *history[4].ToHiveID)
+
+		require.NotNil(t, layingStatus)
+		assert.Equal(t, model.QueenStatusLaying, *layingStatus)
+		require.NotNil(t, supersededStatus)
+		assert.Equal(t, model.QueenStatusSuperseded, *supersededStatus)
+
+		require.NoError(t, deleteErr)
+		assert.True(t, deleted)
+		require.NotNil(t, restoredStatus)
+		assert.Equal(t, model.QueenStatusLaying, *restoredStatus)
+		assert.Equal(t, 3, countRows(t, db, "SELECT COUNT(*) FROM hive_logs WHERE user_id=? AND hive_id=? AND action='queen_event' AND active=1", userID, hiveID))
+	})
+
+	t.Run("warehouse queens get events without a hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenName := "Caged"
+
+		queen, err := mutation.AddWarehouseQueen(ctx, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+
+		// ACT
+		event, err := mutation.AddQueenEvent(ctx, queen.ID, model.QueenEventInput{Type: model.QueenEventTypeCaged})
+		_, invalidErr := mutation.AddQueenEvent(ctx, queen.ID, model.QueenEventInput{Type: model.QueenEventType("HATCHED")})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Nil(t, event.HiveID)
+		assert.Equal(t, model.QueenEventTypeCaged, event.Type)
+		assert.Error(t, invalidErr)
+	})
+}
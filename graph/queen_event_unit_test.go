@@ -0,0 +1,40 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueenStatusFromEvents(t *testing.T) {
+	assert.Nil(t, model.QueenStatusFromEvents(nil))
+	assert.Nil(t, model.QueenStatusFromEvents([]*model.QueenEvent{{Type: model.QueenEventTypeCaged}, {Type: model.QueenEventTypeAccepted}}))
+
+	status := model.QueenStatusFromEvents([]*model.QueenEvent{
+		{Type: model.QueenEventTypeEmerged},
+		{Type: model.QueenEventTypeMated},
+		{Type: model.QueenEventTypeLaying},
+		{Type: model.QueenEventTypeClipped},
+	})
+	require.NotNil(t, status)
+	assert.Equal(t, model.QueenStatusLaying, *status)
+
+	status = model.QueenStatusFromEvents([]*model.QueenEvent{
+		{Type: model.QueenEventTypeLaying},
+		{Type: model.QueenEventTypeSwarmed},
+	})
+	require.NotNil(t, status)
+	assert.Equal(t, model.QueenStatusLost, *status)
+
+	status = model.QueenStatusFromEvents([]*model.QueenEvent{
+		{Type: model.QueenEventTypeIntroduced},
+		{Type: model.QueenEventTypeRejected},
+	})
+	require.NotNil(t, status)
+	assert.Equal(t, model.QueenStatusLost, *status)
+}
@@ -0,0 +1,139 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueenRatings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ranking orders hive and warehouse queens by score and leaves out lost queens", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		otherApiaryID := createTestApiary(t, db, userID)
+		bestHiveID := createTestHive(t, db, userID, apiaryID)
+		best := strconv.Itoa(createTestQueen(t, db, userID, bestHiveID))
+		second := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+		lost := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+		unrated := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, otherApiaryID)))
+		db.MustExec("UPDATE families SET race='Buckfast' WHERE id=?", second)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		queenName := "Stored"
+		warehouseQueen, err := mutation.AddWarehouseQueen(ctx, model.FamilyInput{Name: &queenName})
+		require.NoError(t, err)
+
+		inspection, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: bestHiveID, Data: "{}"})
+		require.NoError(t, err)
+		db.MustExec("UPDATE inspections SET added='2026-06-01 10:00:00' WHERE id=?", inspection.ID)
+
+		rate := func(familyID string, input model.QueenRatingInput) *model.QueenRating {
+			rating, err := mutation.AddQueenRating(ctx, familyID, input)
+			require.NoError(t, err)
+			return rating
+		}
+		one, two, three, four, five := 1, 2, 3, 4, 5
+		inspectionRating := rate(best, model.QueenRatingInput{InspectionID: &inspection.ID, Calmness: &five, Hygiene: &five})
+		rate(second, model.QueenRatingInput{Calmness: &three, Hygiene: &four})
+		rate(second, model.QueenRatingInput{Calmness: &five})
+		rate(warehouseQueen.ID, model.QueenRatingInput{Hygiene: &two})
+		rate(lost, model.QueenRatingInput{Calmness: &five, Hygiene: &five, Temperament: &one})
+		_, err = mutation.AddQueenEvent(ctx, lost, model.QueenEventInput{Type: model.QueenEventTypeLost})
+		require.NoError(t, err)
+		apiary := strconv.Itoa(apiaryID)
+		race := "Buckfast"
+
+		// ACT
+		ranking, err := query.QueenRanking(ctx, nil, nil, nil)
+		apiaryRanking, apiaryErr := query.QueenRanking(ctx, &apiary, nil, nil)
+		raceRanking, raceErr := query.QueenRanking(ctx, nil, &race, nil)
+
+		// ASSERT
+		assert.Contains(t, inspectionRating.RatedAt, "2026-06-01")
+		require.NotNil(t, inspectionRating.HiveID)
+		assert.Equal(t, strconv.Itoa(bestHiveID), *inspectionRating.HiveID)
+
+		require.NoError(t, err)
+		require.Len(t, ranking, 4)
+		assert.Equal(t, best, ranking[0].Family.ID)
+		assert.Equal(t, 1, ranking[0].Rank)
+		require.NotNil(t, ranking[0].Score)
+		assert.Equal(t, 5.0, *ranking[0].Score)
+		assert.Equal(t, second, ranking[1].Family.ID)
+		assert.Equal(t, 2, ranking[1].RatingCount)
+		require.NotNil(t, ranking[1].Traits.Calmness)
+		assert.Equal(t, 4.0, *ranking[1].Traits.Calmness)
+		assert.Equal(t, 4.0, *ranking[1].Score)
+		assert.Equal(t, warehouseQueen.ID, ranking[2].Family.ID)
+		assert.Equal(t, unrated, ranking[3].Family.ID)
+		assert.Nil(t, ranking[3].Score)
+
+		require.NoError(t, apiaryErr)
+		require.Len(t, apiaryRanking, 2)
+		assert.Equal(t, best, apiaryRanking[0].Family.ID)
+		assert.Equal(t, second, apiaryRanking[1].Family.ID)
+
+		require.NoError(t, raceErr)
+		require.Len(t, raceRanking, 1)
+		assert.Equal(t, second, raceRanking[0].Family.ID)
+	})
+
+	t.Run("rating checks trait range and the inspection hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		queenHiveID := createTestHive(t, db, userID, apiaryID)
+		otherHiveID := createTestHive(t, db, userID, apiaryID)
+		queen := strconv.Itoa(createTestQueen(t, db, userID, queenHiveID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		inspection, err := mutation.AddInspection(ctx, model.InspectionInput{HiveID: otherHiveID, Data: "{}"})
+		require.NoError(t, err)
+		three, six := 3, 6
+
+		// ACT
+		_, otherHiveErr := mutation.AddQueenRating(ctx, queen, model.QueenRatingInput{InspectionID: &inspection.ID, Calmness: &three})
+		_, rangeErr := mutation.AddQueenRating(ctx, queen, model.QueenRatingInput{Calmness: &six})
+		_, emptyErr := mutation.AddQueenRating(ctx, queen, model.QueenRatingInput{})
+
+		// ASSERT
+		assert.Error(t, otherHiveErr)
+		assert.Error(t, rangeErr)
+		assert.Error(t, emptyErr)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM queen_ratings WHERE user_id=?", userID))
+	})
+}
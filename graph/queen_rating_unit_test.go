@@ -0,0 +1,36 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueenScore(t *testing.T) {
+	calm, hygienic, swarmy := 4.5, 5.0, 2.0
+	traits := &model.QueenTraitScores{Calmness: &calm, Hygiene: &hygienic, Swarming: &swarmy}
+
+	score, err := model.QueenScore(traits, nil)
+	require.NoError(t, err)
+	require.NotNil(t, score)
+	assert.Equal(t, 3.83, *score)
+
+	triple, none := 3.0, 0.0
+	score, err = model.QueenScore(traits, &model.QueenRatingWeights{Hygiene: &triple, Swarming: &none})
+	require.NoError(t, err)
+	require.NotNil(t, score)
+	assert.Equal(t, 4.88, *score)
+
+	score, err = model.QueenScore(&model.QueenTraitScores{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, score)
+
+	negative := -1.0
+	_, err = model.QueenScore(traits, &model.QueenRatingWeights{Calmness: &negative})
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueenRearing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("emerged virgin queens are added to the warehouse as daughters of the batch mother", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		mother := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+		db.MustExec("UPDATE families SET race='Carniolan' WHERE id=?", mother)
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		batchFields := &queenRearingBatchResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		graftedAt := "2026-05-28"
+		accepted, capped, emerged := 14, 12, 1
+		queenName := "Virgin"
+
+		batch, err := mutation.AddQueenRearingBatch(ctx, model.QueenRearingBatchInput{MotherID: mother, GraftedAt: &graftedAt, CellsGrafted: 20})
+		require.NoError(t, err)
+		_, earlyErr := mutation.AddWarehouseQueen(ctx, model.FamilyInput{Name: &queenName, RearingBatchID: &batch.ID})
+
+		// ACT
+		batch, err = mutation.UpdateQueenRearingBatch(ctx, batch.ID, model.QueenRearingProgressInput{CellsAccepted: &accepted, CellsCapped: &capped, CellsEmerged: &emerged})
+		require.NoError(t, err)
+		queen, queenErr := mutation.AddWarehouseQueen(ctx, model.FamilyInput{Name: &queenName, RearingBatchID: &batch.ID})
+		_, extraErr := mutation.AddWarehouseQueen(ctx, model.FamilyInput{Name: &queenName, RearingBatchID: &batch.ID})
+		queens, queensErr := batchFields.Queens(ctx, batch)
+
+		// ASSERT
+		assert.Error(t, earlyErr)
+
+		require.NoError(t, queenErr)
+		require.NotNil(t, queen.MotherID)
+		assert.Equal(t, mother, *queen.MotherID)
+		require.NotNil(t, queen.RearingBatchID)
+		assert.Equal(t, batch.ID, *queen.RearingBatchID)
+		require.NotNil(t, queen.Race)
+		assert.Equal(t, "Carniolan", *queen.Race)
+		require.NotNil(t, queen.Added)
+		assert.Equal(t, "2026", *queen.Added)
+		assert.Nil(t, queen.HiveID)
+
+		assert.Error(t, extraErr)
+
+		require.NoError(t, queensErr)
+		require.Len(t, queens, 1)
+		assert.Equal(t, queen.ID, queens[0].ID)
+	})
+
+	t.Run("cell counts can not grow from one step to the next", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		mother := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		accepted, tooManyCapped, tooManyAccepted := 10, 11, 21
+
+		batch, err := mutation.AddQueenRearingBatch(ctx, model.QueenRearingBatchInput{MotherID: mother, CellsGrafted: 20})
+		require.NoError(t, err)
+
+		// ACT
+		_, acceptedErr := mutation.UpdateQueenRearingBatch(ctx, batch.ID, model.QueenRearingProgressInput{CellsAccepted: &accepted})
+		_, cappedErr := mutation.UpdateQueenRearingBatch(ctx, batch.ID, model.QueenRearingProgressInput{CellsCapped: &tooManyCapped})
+		_, graftedErr := mutation.UpdateQueenRearingBatch(ctx, batch.ID, model.QueenRearingProgressInput{CellsAccepted: &tooManyAccepted})
+		_, zeroErr := mutation.AddQueenRearingBatch(ctx, model.QueenRearingBatchInput{MotherID: mother, CellsGrafted: 0})
+
+		// ASSERT
+		assert.NoError(t, acceptedErr)
+		assert.Error(t, cappedErr)
+		assert.Error(t, graftedErr)
+		assert.Error(t, zeroErr)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM queen_rearing_batches WHERE user_id=? AND cells_accepted=10 AND cells_capped IS NULL", userID))
+	})
+
+	t.Run("calendar lists milestones due in the range soonest first", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		mother := strconv.Itoa(createTestQueen(t, db, userID, createTestHive(t, db, userID, apiaryID)))
+
+		resolver := &Resolver{Db: db}
+		mutation := &mutationResolver{Resolver: resolver}
+		query := &queryResolver{Resolver: resolver}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+		firstGraft, secondGraft := "2026-05-01", "2026-05-20"
+		from, to := "2026-05-25", "2026-06-05"
+
+		_, err := mutation.AddQueenRearingBatch(ctx, model.QueenRearingBatchInput{MotherID: mother, GraftedAt: &firstGraft, CellsGrafted: 10})
+		require.NoError(t, err)
+		_, err = mutation.AddQueenRearingBatch(ctx, model.QueenRearingBatchInput{MotherID: mother, GraftedAt: &secondGraft, CellsGrafted: 10})
+		require.NoError(t, err)
+
+		// ACT
+		calendar, err := query.QueenRearingCalendar(ctx, &from, &to)
+
+		// ASSERT
+		require.NoError(t, err)
+		require.Len(t, calendar, 3)
+		assert.Equal(t, model.QueenRearingMilestoneTypeCapping, calendar[0].Type)
+		assert.Equal(t, "2026-05-25 00:00:00", calendar[0].Date)
+		assert.Equal(t, model.QueenRearingMilestoneTypeMatingCheck, calendar[1].Type)
+		assert.Equal(t, "2026-05-27 00:00:00", calendar[1].Date)
+		assert.Equal(t, model.QueenRearingMilestoneTypeEmergence, calendar[2].Type)
+		assert.Equal(t, "2026-06-01 00:00:00", calendar[2].Date)
+	})
+}
@@ -0,0 +1,32 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueenRearingMilestones(t *testing.T) {
+	milestones, err := model.QueenRearingMilestones(&model.QueenRearingBatch{GraftedAt: "2026-05-28 09:00:00"})
+	require.NoError(t, err)
+	require.Len(t, milestones, 4)
+
+	assert.Equal(t, model.QueenRearingMilestoneTypeGraft, milestones[0].Type)
+	assert.Equal(t, "2026-05-28 09:00:00", milestones[0].Date)
+	assert.Equal(t, model.QueenRearingMilestoneTypeCapping, milestones[1].Type)
+	assert.Equal(t, "2026-06-02 09:00:00", milestones[1].Date)
+	assert.Equal(t, model.QueenRearingMilestoneTypeEmergence, milestones[2].Type)
+	assert.Equal(t, 12, milestones[2].Day)
+	assert.Equal(t, "2026-06-09 09:00:00", milestones[2].Date)
+	assert.Equal(t, model.QueenRearingMilestoneTypeMatingCheck, milestones[3].Type)
+	assert.Equal(t, "2026-06-23 09:00:00", milestones[3].Date)
+
+	milestones, err = model.QueenRearingMilestones(&model.QueenRearingBatch{GraftedAt: "2026-05-28T09:00:00Z"})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-06-09 09:00:00", milestones[2].Date)
+}
@@ -8,7 +8,10 @@ import (
 
 // Apiary is the resolver for the apiary field.
 func (r *queryResolver) Apiary(ctx context.Context, id string) (*model.Apiary, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.Apiary{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -18,15 +21,51 @@ func (r *queryResolver) Apiary(ctx context.Context, id string) (*model.Apiary, e
 // Apiaries is the resolver for the apiaries field.
 func (r *queryResolver) Apiaries(ctx context.Context) ([]*model.Apiary, error) {
 	uid := ctx.Value("userID").(string)
-	return (&model.Apiary{
+	apiaries, err := (&model.Apiary{
 		Db:     r.Db,
 		UserID: uid,
 	}).List()
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := (&model.ApiaryMember{
+		Db:     r.Db,
+		UserID: uid,
+	}).ListSharedApiaries()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(apiaries, shared...), nil
+}
+
+// ApiariesNear is the resolver for the apiariesNear field.
+func (r *queryResolver) ApiariesNear(ctx context.Context, lat float64, lng float64, radiusKm float64) ([]*model.ApiaryDistance, error) {
+	return (&model.Apiary{
+		Db:     r.Db,
+		UserID: ctx.Value("userID").(string),
+	}).ListNear(lat, lng, radiusKm)
+}
+
+// ForageOverlap is the resolver for the forageOverlap field.
+func (r *queryResolver) ForageOverlap(ctx context.Context, forageRadiusKm *float64) ([]*model.ForageOverlap, error) {
+	radiusKm := model.ForageRadiusKm
+	if forageRadiusKm != nil {
+		radiusKm = *forageRadiusKm
+	}
+	return (&model.Apiary{
+		Db:     r.Db,
+		UserID: ctx.Value("userID").(string),
+	}).ListForageOverlaps(radiusKm)
 }
 
 // HivePlacements is the resolver for the hivePlacements field.
 func (r *queryResolver) HivePlacements(ctx context.Context, apiaryID string) ([]*model.HivePlacement, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.HivePlacement{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -35,7 +74,10 @@ func (r *queryResolver) HivePlacements(ctx context.Context, apiaryID string) ([]
 
 // ApiaryObstacles is the resolver for the apiaryObstacles field.
 func (r *queryResolver) ApiaryObstacles(ctx context.Context, apiaryID string) ([]*model.ApiaryObstacle, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.ApiaryObstacle{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// HiveFeedings is the resolver for the hiveFeedings field.
+func (r *queryResolver) HiveFeedings(ctx context.Context, hiveID string, season *int) ([]*model.Feeding, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.Feeding{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByHive(hiveID, season)
+}
+
+// ApiaryFeedings is the resolver for the apiaryFeedings field.
+func (r *queryResolver) ApiaryFeedings(ctx context.Context, apiaryID string, season *int) ([]*model.Feeding, error) {
+	uid, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.Feeding{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByApiary(apiaryID, season)
+}
+
+// FeedingReport is the resolver for the feedingReport field.
+func (r *queryResolver) FeedingReport(ctx context.Context, season *int, apiaryID *string) (*model.FeedingReport, error) {
+	uid := ctx.Value("userID").(string)
+	if apiaryID != nil {
+		var err error
+		uid, err = r.actingUserID(ctx, model.AccessApiary, *apiaryID, accessRead)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return (&model.Feeding{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Report(season, apiaryID)
+}
@@ -9,7 +9,10 @@ import (
 
 // HiveFrame is the resolver for the hiveFrame field.
 func (r *queryResolver) HiveFrame(ctx context.Context, id string) (*model.Frame, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessFrame, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	idNum, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		return nil, err
@@ -22,7 +25,10 @@ func (r *queryResolver) HiveFrame(ctx context.Context, id string) (*model.Frame,
 
 // HiveFrameSide is the resolver for the hiveFrameSide field.
 func (r *queryResolver) HiveFrameSide(ctx context.Context, id string) (*model.FrameSide, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessFrameSide, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	idNum, err := strconv.ParseInt(id, 10, 64)
 
 	if err != nil {
@@ -35,3 +41,15 @@ func (r *queryResolver) HiveFrameSide(ctx context.Context, id string) (*model.Fr
 		UserID: uid,
 	}).Get(&idNum2)
 }
+
+// CombsDueForRotation is the resolver for the combsDueForRotation field.
+func (r *queryResolver) CombsDueForRotation(ctx context.Context, apiaryID string, maxAgeDays *int) ([]*model.CombDueForRotation, error) {
+	uid, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.Frame{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListDueForRotation(apiaryID, maxAgeDays)
+}
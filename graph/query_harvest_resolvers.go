@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// Harvests is the resolver for the harvests field.
+func (r *queryResolver) Harvests(ctx context.Context, hiveID string, season *int) ([]*model.HoneyHarvest, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.HoneyHarvest{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByHive(hiveID, season)
+}
+
+// HarvestYield is the resolver for the harvestYield field.
+func (r *queryResolver) HarvestYield(ctx context.Context, groupBy model.YieldGrouping, apiaryID *string, season *int) ([]*model.YieldTotal, error) {
+	uid := ctx.Value("userID").(string)
+	if apiaryID != nil {
+		var err error
+		uid, err = r.actingUserID(ctx, model.AccessApiary, *apiaryID, accessRead)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return (&model.HoneyHarvest{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Yield(groupBy, model.YieldFilter{ApiaryID: apiaryID, Season: season})
+}
+
+// HoneyLot is the resolver for the honeyLot field.
+func (r *queryResolver) HoneyLot(ctx context.Context, id string) (*model.HoneyLot, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHoneyLot, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.HoneyLot{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(id)
+}
+
+// HoneyLotByNumber is the resolver for the honeyLotByNumber field.
+func (r *queryResolver) HoneyLotByNumber(ctx context.Context, lotNumber string) (*model.HoneyLot, error) {
+	return (&model.HoneyLot{
+		Db:     r.Resolver.Db,
+		UserID: ctx.Value("userID").(string),
+	}).GetByNumber(lotNumber)
+}
+
+// HoneyLots is the resolver for the honeyLots field.
+func (r *queryResolver) HoneyLots(ctx context.Context, limit *int) ([]*model.HoneyLot, error) {
+	return (&model.HoneyLot{
+		Db:     r.Resolver.Db,
+		UserID: ctx.Value("userID").(string),
+	}).List(limit)
+}
+
+// HoneyLotTrace is the resolver for the honeyLotTrace field.
+func (r *queryResolver) HoneyLotTrace(ctx context.Context, id string, months *int) (*model.HoneyLotTrace, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHoneyLot, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	lotModel := &model.HoneyLot{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}
+	lot, err := lotModel.Get(id)
+	if err != nil || lot == nil {
+		return nil, err
+	}
+
+	period := model.HoneyLotTraceMonths
+	if months != nil {
+		period = *months
+	}
+	return lotModel.Trace(lot, period)
+}
@@ -8,7 +8,10 @@ import (
 
 // HiveLogs is the resolver for the hiveLogs field.
 func (r *queryResolver) HiveLogs(ctx context.Context, hiveID string, limit *int) ([]*model.HiveLog, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.HiveLog{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -8,7 +8,10 @@ import (
 
 // Hive is the resolver for the hive field.
 func (r *queryResolver) Hive(ctx context.Context, id string) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessHive, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.Hive{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -2,13 +2,17 @@ package graph
 
 import (
 	"context"
+	"errors"
 
 	"github.com/Gratheon/swarm-api/graph/model"
 )
 
 // Inspection is the resolver for the inspection field.
 func (r *queryResolver) Inspection(ctx context.Context, inspectionID string) (*model.Inspection, error) {
-	uid := ctx.Value("userID").(string)
+	uid, err := r.actingUserID(ctx, model.AccessInspection, inspectionID, accessRead)
+	if err != nil {
+		return nil, err
+	}
 	return (&model.Inspection{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -17,9 +21,59 @@ func (r *queryResolver) Inspection(ctx context.Context, inspectionID string) (*m
 
 // Inspections is the resolver for the inspections field.
 func (r *queryResolver) Inspections(ctx context.Context, hiveID string, limit *int) ([]*model.Inspection, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.Inspection{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByHiveId(hiveID, limit)
+}
+
+// InspectionsConnection is the resolver for the inspectionsConnection field.
+func (r *queryResolver) InspectionsConnection(ctx context.Context, hiveID string, first *int, after *string) (*model.InspectionConnection, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.Inspection{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ConnectionByHiveId(hiveID, first, after)
+}
+
+// InspectionsSearch is the resolver for the inspectionsSearch field.
+func (r *queryResolver) InspectionsSearch(ctx context.Context, filter model.InspectionSearchFilter) ([]*model.Inspection, error) {
 	uid := ctx.Value("userID").(string)
+	if filter.ApiaryID != nil {
+		var err error
+		uid, err = r.actingUserID(ctx, model.AccessApiary, *filter.ApiaryID, accessRead)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return (&model.Inspection{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Search(filter)
+}
+
+// CompareInspections is the resolver for the compareInspections field.
+func (r *queryResolver) CompareInspections(ctx context.Context, a string, b string) (*model.InspectionComparison, error) {
+	uid, err := r.actingUserID(ctx, model.AccessInspection, a, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	uidB, err := r.actingUserID(ctx, model.AccessInspection, b, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	if uidB != uid {
+		return nil, errors.New("inspections to compare must belong to the same owner")
+	}
 	return (&model.Inspection{
 		Db:     r.Resolver.Db,
 		UserID: uid,
-	}).ListByHiveId(hiveID)
+	}).Compare(a, b)
 }
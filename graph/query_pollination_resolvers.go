@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// PollinationContract is the resolver for the pollinationContract field.
+func (r *queryResolver) PollinationContract(ctx context.Context, id string) (*model.PollinationContract, error) {
+	uid, err := r.actingUserID(ctx, model.AccessPollination, id, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(id)
+}
+
+// pollinationUserID is the user whose contracts are listed, the owner when an apiary is given
+func (r *queryResolver) pollinationUserID(ctx context.Context, apiaryID *string) (string, error) {
+	if apiaryID == nil {
+		return ctx.Value("userID").(string), nil
+	}
+	return r.actingUserID(ctx, model.AccessApiary, *apiaryID, accessRead)
+}
+
+// PollinationContracts is the resolver for the pollinationContracts field.
+func (r *queryResolver) PollinationContracts(ctx context.Context, apiaryID *string, includeCompleted *bool) ([]*model.PollinationContract, error) {
+	uid, err := r.pollinationUserID(ctx, apiaryID)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).List(apiaryID, includeCompleted != nil && *includeCompleted)
+}
+
+// UpcomingPollinationPlacements is the resolver for the upcomingPollinationPlacements field.
+func (r *queryResolver) UpcomingPollinationPlacements(ctx context.Context, apiaryID *string, days *int) ([]*model.PollinationContract, error) {
+	uid, err := r.pollinationUserID(ctx, apiaryID)
+	if err != nil {
+		return nil, err
+	}
+	period := model.PollinationUpcomingDays
+	if days != nil {
+		period = *days
+	}
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListUpcoming(apiaryID, period)
+}
+
+// OverduePollinationPlacements is the resolver for the overduePollinationPlacements field.
+func (r *queryResolver) OverduePollinationPlacements(ctx context.Context, apiaryID *string) ([]*model.PollinationContract, error) {
+	uid, err := r.pollinationUserID(ctx, apiaryID)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListOverdue(apiaryID)
+}
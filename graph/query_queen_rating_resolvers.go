@@ -0,0 +1,23 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// QueenRanking is the resolver for the queenRanking field.
+func (r *queryResolver) QueenRanking(ctx context.Context, apiaryID *string, race *string, weights *model.QueenRatingWeights) ([]*model.QueenRank, error) {
+	uid := ctx.Value("userID").(string)
+	if apiaryID != nil {
+		var err error
+		uid, err = r.actingUserID(ctx, model.AccessApiary, *apiaryID, accessRead)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return (&model.QueenRating{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Ranking(apiaryID, race, weights)
+}
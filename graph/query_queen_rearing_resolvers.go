@@ -0,0 +1,34 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// QueenRearingBatches is the resolver for the queenRearingBatches field.
+func (r *queryResolver) QueenRearingBatches(ctx context.Context, active *bool) ([]*model.QueenRearingBatch, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).List(active != nil && *active)
+}
+
+// QueenRearingBatch is the resolver for the queenRearingBatch field.
+func (r *queryResolver) QueenRearingBatch(ctx context.Context, id string) (*model.QueenRearingBatch, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(id)
+}
+
+// QueenRearingCalendar is the resolver for the queenRearingCalendar field.
+func (r *queryResolver) QueenRearingCalendar(ctx context.Context, from *string, to *string) ([]*model.QueenRearingMilestone, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Calendar(from, to)
+}
@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// TreatmentProducts is the resolver for the treatmentProducts field.
+func (r *queryResolver) TreatmentProducts(ctx context.Context) ([]*model.TreatmentProduct, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.TreatmentProduct{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).List()
+}
+
+// TreatmentCourses is the resolver for the treatmentCourses field.
+func (r *queryResolver) TreatmentCourses(ctx context.Context, hiveID string, includeFinished *bool) ([]*model.TreatmentCourse, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.TreatmentCourse{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByHive(hiveID, includeFinished != nil && *includeFinished)
+}
+
+// HivesInWithdrawal is the resolver for the hivesInWithdrawal field.
+func (r *queryResolver) HivesInWithdrawal(ctx context.Context, apiaryID *string) ([]*model.HiveWithdrawal, error) {
+	uid := ctx.Value("userID").(string)
+	if apiaryID != nil {
+		var err error
+		uid, err = r.actingUserID(ctx, model.AccessApiary, *apiaryID, accessRead)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return (&model.Treatment{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListHivesInWithdrawal(apiaryID)
+}
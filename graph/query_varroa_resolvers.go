@@ -0,0 +1,19 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// VarroaCounts is the resolver for the varroaCounts field.
+func (r *queryResolver) VarroaCounts(ctx context.Context, hiveID string, limit *int) ([]*model.VarroaCount, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.VarroaCount{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByHive(hiveID, limit)
+}
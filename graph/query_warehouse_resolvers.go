@@ -50,3 +50,12 @@ func (r *queryResolver) WarehouseInventoryStats(ctx context.Context, itemKey str
 		UserID: uid,
 	}).StatsByKey(itemKey)
 }
+
+// WarehouseFeedStock is the resolver for the warehouseFeedStock field.
+func (r *queryResolver) WarehouseFeedStock(ctx context.Context) ([]*model.FeedStock, error) {
+	uid := ctx.Value("userID").(string)
+	return (&model.WarehouseFeedStock{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).List()
+}
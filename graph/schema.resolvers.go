@@ -8,6 +8,7 @@ package graph
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/Gratheon/swarm-api/graph/generated"
 	"github.com/Gratheon/swarm-api/graph/model"
@@ -15,7 +16,7 @@ import (
 
 // Hives is the resolver for the hives field.
 func (r *apiaryResolver) Hives(ctx context.Context, obj *model.Apiary, sortBy *model.HiveSortBy, sortOrder *model.SortOrder) ([]*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	if sortBy != nil || sortOrder != nil {
 		effectiveSortBy := model.HiveSortByHiveNumber
 		if sortBy != nil {
@@ -43,6 +44,69 @@ func (r *apiaryResolver) Hives(ctx context.Context, obj *model.Apiary, sortBy *m
 	}).ListByApiary(obj.ID)
 }
 
+// VarroaSummary is the resolver for the varroaSummary field.
+func (r *apiaryResolver) VarroaSummary(ctx context.Context, obj *model.Apiary, days *int, threshold *float64) (*model.ApiaryVarroaSummary, error) {
+	uid := objectUserID(ctx, obj.UserID)
+	period := 30
+	if days != nil && *days > 0 {
+		period = *days
+	}
+	limit := 3.0
+	if threshold != nil {
+		limit = *threshold
+	}
+
+	latest, err := (&model.VarroaCount{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListLatestByApiary(strconv.Itoa(obj.ID), period)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewApiaryVarroaSummary(latest, limit), nil
+}
+
+// LocationHistory is the resolver for the locationHistory field.
+func (r *apiaryResolver) LocationHistory(ctx context.Context, obj *model.Apiary) ([]*model.ApiaryRelocation, error) {
+	uid := objectUserID(ctx, obj.UserID)
+	return (&model.Apiary{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).LocationHistory(strconv.Itoa(obj.ID))
+}
+
+// PollinationContracts is the resolver for the pollinationContracts field.
+func (r *apiaryResolver) PollinationContracts(ctx context.Context, obj *model.Apiary, includeCompleted *bool) ([]*model.PollinationContract, error) {
+	uid := objectUserID(ctx, obj.UserID)
+	apiaryID := strconv.Itoa(obj.ID)
+	return (&model.PollinationContract{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).List(&apiaryID, includeCompleted != nil && *includeCompleted)
+}
+
+// MyRole is the resolver for the myRole field.
+func (r *apiaryResolver) MyRole(ctx context.Context, obj *model.Apiary) (model.ApiaryRole, error) {
+	access, err := r.apiaryAccess(ctx, obj)
+	if err != nil {
+		return "", err
+	}
+	return access.Role, nil
+}
+
+// Members is the resolver for the members field.
+func (r *apiaryResolver) Members(ctx context.Context, obj *model.Apiary) ([]*model.ApiaryMember, error) {
+	access, err := r.apiaryAccess(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.ApiaryMember{
+		Db:     r.Resolver.Db,
+		UserID: ctx.Value("userID").(string),
+	}).ListByApiary(strconv.Itoa(obj.ID), access.Role)
+}
+
 // Type is the resolver for the type field.
 func (r *apiaryObstacleResolver) Type(ctx context.Context, obj *model.ApiaryObstacle) (model.ObstacleType, error) {
 	return model.ObstacleType(obj.Type), nil
@@ -50,7 +114,7 @@ func (r *apiaryObstacleResolver) Type(ctx context.Context, obj *model.ApiaryObst
 
 // Frames is the resolver for the frames field.
 func (r *boxResolver) Frames(ctx context.Context, obj *model.Box) ([]*model.Frame, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	loaders := GetLoaders(ctx)
 	if loaders != nil && loaders.FramesByBoxLoader != nil {
 		return loaders.FramesByBoxLoader.Load(ctx, *obj.ID, uid)
@@ -61,9 +125,26 @@ func (r *boxResolver) Frames(ctx context.Context, obj *model.Box) ([]*model.Fram
 	}).ListByBox(obj.ID)
 }
 
+// Family is the resolver for the family field.
+func (r *boxResolver) Family(ctx context.Context, obj *model.Box) (*model.Family, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+	familyModel := &model.Family{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}
+	familyID, err := familyModel.BoxOccupantID(*obj.ID)
+	if err != nil || familyID == nil {
+		return nil, err
+	}
+
+	return familyModel.GetById(familyID)
+}
+
 // LastTreatment is the resolver for the lastTreatment field.
 func (r *familyResolver) LastTreatment(ctx context.Context, obj *model.Family) (*string, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	treatmentModel := &model.Treatment{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -83,7 +164,7 @@ func (r *familyResolver) LastTreatment(ctx context.Context, obj *model.Family) (
 
 // Treatments is the resolver for the treatments field.
 func (r *familyResolver) Treatments(ctx context.Context, obj *model.Family) ([]*model.Treatment, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	treatmentModel := &model.Treatment{
 		Db:     r.Resolver.Db,
 		UserID: uid,
@@ -94,7 +175,7 @@ func (r *familyResolver) Treatments(ctx context.Context, obj *model.Family) ([]*
 
 // LastHive is the resolver for the lastHive field.
 func (r *familyResolver) LastHive(ctx context.Context, obj *model.Family) (*model.Hive, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 
 	lastHiveID, err := (&model.Family{
 		Db:     r.Resolver.Db,
@@ -113,9 +194,125 @@ func (r *familyResolver) LastHive(ctx context.Context, obj *model.Family) (*mode
 	}).Get(strconv.Itoa(*lastHiveID))
 }
 
+// TreatmentEfficacy is the resolver for the treatmentEfficacy field.
+func (r *familyResolver) TreatmentEfficacy(ctx context.Context, obj *model.Family) ([]*model.TreatmentEfficacy, error) {
+	uid := objectUserID(ctx, obj.UserID)
+	treatments, err := (&model.Treatment{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListFamilyTreatments(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	treatmentIDs := make([]string, 0, len(treatments))
+	for _, treatment := range treatments {
+		treatmentIDs = append(treatmentIDs, strconv.Itoa(treatment.ID))
+	}
+	counts, err := (&model.VarroaCount{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).ListByTreatments(treatmentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewTreatmentEfficacy(treatments, counts), nil
+}
+
+// YieldHistory is the resolver for the yieldHistory field.
+func (r *familyResolver) YieldHistory(ctx context.Context, obj *model.Family) ([]*model.YieldTotal, error) {
+	return (&model.HoneyHarvest{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).Yield(model.YieldGroupingSeason, model.YieldFilter{FamilyID: &obj.ID})
+}
+
+// Mother is the resolver for the mother field.
+func (r *familyResolver) Mother(ctx context.Context, obj *model.Family) (*model.Family, error) {
+	if obj.MotherID == nil {
+		return nil, nil
+	}
+	return (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).GetAny(*obj.MotherID)
+}
+
+// DroneSource is the resolver for the droneSource field.
+func (r *familyResolver) DroneSource(ctx context.Context, obj *model.Family) (*model.Family, error) {
+	if obj.DroneSourceID == nil {
+		return nil, nil
+	}
+	return (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).GetAny(*obj.DroneSourceID)
+}
+
+// Pedigree is the resolver for the pedigree field.
+func (r *familyResolver) Pedigree(ctx context.Context, obj *model.Family, depth *int) (*model.PedigreeNode, error) {
+	generations := model.DefaultPedigreeDepth
+	if depth != nil {
+		generations = *depth
+	}
+	return (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).Pedigree(obj, generations)
+}
+
+// Daughters is the resolver for the daughters field.
+func (r *familyResolver) Daughters(ctx context.Context, obj *model.Family) ([]*model.Family, error) {
+	return (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).ListDaughters(obj.ID)
+}
+
+// RearingBatch is the resolver for the rearingBatch field.
+func (r *familyResolver) RearingBatch(ctx context.Context, obj *model.Family) (*model.QueenRearingBatch, error) {
+	if obj.RearingBatchID == nil {
+		return nil, nil
+	}
+	return (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).Get(*obj.RearingBatchID)
+}
+
+// Status is the resolver for the status field.
+func (r *familyResolver) Status(ctx context.Context, obj *model.Family) (*model.QueenStatus, error) {
+	events, err := (&model.QueenEvent{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).ListByFamily(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.QueenStatusFromEvents(events), nil
+}
+
+// History is the resolver for the history field.
+func (r *familyResolver) History(ctx context.Context, obj *model.Family) ([]*model.FamilyHistoryEntry, error) {
+	return (&model.QueenEvent{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).History(obj.ID)
+}
+
+// Ratings is the resolver for the ratings field.
+func (r *familyResolver) Ratings(ctx context.Context, obj *model.Family) ([]*model.QueenRating, error) {
+	return (&model.QueenRating{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).ListByFamily(obj.ID)
+}
+
 // LeftSide is the resolver for the leftSide field.
 func (r *frameResolver) LeftSide(ctx context.Context, obj *model.Frame) (*model.FrameSide, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	loaders := GetLoaders(ctx)
 	if loaders != nil && loaders.FrameSideLoader != nil {
 		return loaders.FrameSideLoader.Load(ctx, obj.LeftID, uid)
@@ -128,7 +325,7 @@ func (r *frameResolver) LeftSide(ctx context.Context, obj *model.Frame) (*model.
 
 // RightSide is the resolver for the rightSide field.
 func (r *frameResolver) RightSide(ctx context.Context, obj *model.Frame) (*model.FrameSide, error) {
-	uid := ctx.Value("userID").(string)
+	uid := objectUserID(ctx, obj.UserID)
 	loaders := GetLoaders(ctx)
 	if loaders != nil && loaders.FrameSideLoader != nil {
 		return loaders.FrameSideLoader.Load(ctx, obj.RightID, uid)
@@ -139,6 +336,79 @@ func (r *frameResolver) RightSide(ctx context.Context, obj *model.Frame) (*model
 	}).Get(obj.RightID)
 }
 
+// CombAgeDays is the resolver for the combAgeDays field.
+func (r *frameResolver) CombAgeDays(ctx context.Context, obj *model.Frame) (*int, error) {
+	return model.CombAgeDays(obj.CombBuiltAt, time.Now()), nil
+}
+
+// History is the resolver for the history field.
+func (r *frameResolver) History(ctx context.Context, obj *model.Frame) ([]*model.FrameMove, error) {
+	return (&model.Frame{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).History(strconv.Itoa(obj.ID))
+}
+
+// WithdrawalWarnings is the resolver for the withdrawalWarnings field.
+func (r *honeyLotResolver) WithdrawalWarnings(ctx context.Context, obj *model.HoneyLot) ([]*model.LotWithdrawalWarning, error) {
+	return (&model.HoneyLot{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).WithdrawalWarnings(obj)
+}
+
+// Hive is the resolver for the hive field.
+func (r *honeyLotSourceResolver) Hive(ctx context.Context, obj *model.HoneyLotSource) (*model.Hive, error) {
+	return (&model.Hive{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).Get(obj.HiveID)
+}
+
+// Family is the resolver for the family field.
+func (r *honeyLotSourceResolver) Family(ctx context.Context, obj *model.HoneyLotSource) (*model.Family, error) {
+	if obj.FamilyID == nil {
+		return nil, nil
+	}
+	familyID, err := strconv.Atoi(*obj.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	return (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).GetById(&familyID)
+}
+
+// Apiary is the resolver for the apiary field.
+func (r *pollinationContractResolver) Apiary(ctx context.Context, obj *model.PollinationContract) (*model.Apiary, error) {
+	return (&model.Apiary{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).Get(obj.ApiaryID)
+}
+
+// Mother is the resolver for the mother field.
+func (r *queenRearingBatchResolver) Mother(ctx context.Context, obj *model.QueenRearingBatch) (*model.Family, error) {
+	return (&model.Family{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).GetAny(obj.MotherID)
+}
+
+// Milestones is the resolver for the milestones field.
+func (r *queenRearingBatchResolver) Milestones(ctx context.Context, obj *model.QueenRearingBatch) ([]*model.QueenRearingMilestone, error) {
+	return model.QueenRearingMilestones(obj)
+}
+
+// Queens is the resolver for the queens field.
+func (r *queenRearingBatchResolver) Queens(ctx context.Context, obj *model.QueenRearingBatch) ([]*model.Family, error) {
+	return (&model.QueenRearingBatch{
+		Db:     r.Resolver.Db,
+		UserID: objectUserID(ctx, obj.UserID),
+	}).ListQueens(obj.ID)
+}
+
 // Apiary returns generated.ApiaryResolver implementation.
 func (r *Resolver) Apiary() generated.ApiaryResolver { return &apiaryResolver{r} }
 
@@ -159,17 +429,43 @@ func (r *Resolver) Frame() generated.FrameResolver { return &frameResolver{r} }
 // Hive returns generated.HiveResolver implementation.
 func (r *Resolver) Hive() generated.HiveResolver { return &hiveResolver{r} }
 
+// HoneyLot returns generated.HoneyLotResolver implementation.
+func (r *Resolver) HoneyLot() generated.HoneyLotResolver { return &honeyLotResolver{r} }
+
+// HoneyLotSource returns generated.HoneyLotSourceResolver implementation.
+func (r *Resolver) HoneyLotSource() generated.HoneyLotSourceResolver {
+	return &honeyLotSourceResolver{r}
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// PollinationContract returns generated.PollinationContractResolver implementation.
+func (r *Resolver) PollinationContract() generated.PollinationContractResolver {
+	return &pollinationContractResolver{r}
+}
+
+// QueenRearingBatch returns generated.QueenRearingBatchResolver implementation.
+func (r *Resolver) QueenRearingBatch() generated.QueenRearingBatchResolver {
+	return &queenRearingBatchResolver{r}
+}
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
 type apiaryResolver struct{ *Resolver }
 type apiaryObstacleResolver struct{ *Resolver }
 type boxResolver struct{ *Resolver }
 type familyResolver struct{ *Resolver }
 type frameResolver struct{ *Resolver }
 type hiveResolver struct{ *Resolver }
+type honeyLotResolver struct{ *Resolver }
+type honeyLotSourceResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type pollinationContractResolver struct{ *Resolver }
+type queenRearingBatchResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
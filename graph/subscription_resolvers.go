@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+)
+
+// HiveUpdated is the resolver for the hiveUpdated field.
+func (r *subscriptionResolver) HiveUpdated(ctx context.Context, hiveID string) (<-chan *model.ChangeEvent, error) {
+	uid, err := r.actingUserID(ctx, model.AccessHive, hiveID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	hive, err := (&model.Hive{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(hiveID)
+	if err != nil {
+		return nil, err
+	}
+	if hive == nil {
+		return nil, errors.New("hive not found")
+	}
+
+	return subscribeToChanges(ctx, hiveChangesChannel(uid, hiveID)), nil
+}
+
+// ApiaryUpdated is the resolver for the apiaryUpdated field.
+func (r *subscriptionResolver) ApiaryUpdated(ctx context.Context, apiaryID string) (<-chan *model.ChangeEvent, error) {
+	uid, err := r.actingUserID(ctx, model.AccessApiary, apiaryID, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	apiary, err := (&model.Apiary{
+		Db:     r.Resolver.Db,
+		UserID: uid,
+	}).Get(apiaryID)
+	if err != nil {
+		return nil, err
+	}
+	if apiary == nil {
+		return nil, errors.New("apiary not found")
+	}
+
+	return subscribeToChanges(ctx, apiaryChangesChannel(uid, apiaryID)), nil
+}
+
+// InventoryChanged is the resolver for the inventoryChanged field.
+func (r *subscriptionResolver) InventoryChanged(ctx context.Context) (<-chan *model.ChangeEvent, error) {
+	uid := ctx.Value("userID").(string)
+	return subscribeToChanges(ctx, inventoryChangesChannel(uid)), nil
+}
@@ -51,6 +51,76 @@ func setupTestDB(t *testing.T) *sqlx.DB {
 		return nil
 	}
 
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS outbox_events (
+			id bigint unsigned NOT NULL AUTO_INCREMENT,
+			event_id char(36) NOT NULL,
+			version smallint unsigned NOT NULL DEFAULT 1,
+			user_id int unsigned NOT NULL,
+			aggregate_type varchar(32) NOT NULL,
+			aggregate_id varchar(64) NOT NULL,
+			entity varchar(32) NOT NULL,
+			entity_id varchar(64) NOT NULL,
+			action varchar(32) NOT NULL,
+			hive_id int unsigned DEFAULT NULL,
+			apiary_id int unsigned DEFAULT NULL,
+			inventory tinyint(1) NOT NULL DEFAULT 0,
+			payload json DEFAULT NULL,
+			occurred_at datetime(3) NOT NULL,
+			published_at datetime(3) DEFAULT NULL,
+			attempts int unsigned NOT NULL DEFAULT 0,
+			next_attempt_at datetime(3) NOT NULL,
+			last_error text DEFAULT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY uniq_outbox_events_event_id (event_id),
+			KEY idx_outbox_events_pending (published_at, id),
+			KEY idx_outbox_events_user (user_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure outbox_events table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS hive_structure_changes (
+			id bigint unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			change_type varchar(16) NOT NULL,
+			source_hive_id int unsigned NOT NULL,
+			target_hive_id int unsigned NOT NULL,
+			previous_status varchar(50) DEFAULT NULL,
+			previous_placement json DEFAULT NULL,
+			created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			reverted_at datetime DEFAULT NULL,
+			PRIMARY KEY (id),
+			KEY idx_hive_structure_changes_source (user_id, source_hive_id),
+			KEY idx_hive_structure_changes_target (user_id, target_hive_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err == nil {
+		_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS hive_structure_change_items (
+				id bigint unsigned NOT NULL AUTO_INCREMENT,
+				change_id bigint unsigned NOT NULL,
+				item_type varchar(16) NOT NULL,
+				item_id int unsigned NOT NULL,
+				from_parent_id int unsigned DEFAULT NULL,
+				from_position int DEFAULT NULL,
+				from_frame_spec_id int unsigned DEFAULT NULL,
+				to_parent_id int unsigned NOT NULL,
+				to_position int DEFAULT NULL,
+				PRIMARY KEY (id),
+				KEY idx_hive_structure_change_items_change (change_id),
+				CONSTRAINT fk_hive_structure_change_items_change FOREIGN KEY (change_id) REFERENCES hive_structure_changes (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+		`)
+	}
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure hive_structure_changes tables: %v", err)
+		return nil
+	}
+
 	var hasFamiliesActiveColumn int
 	err = db.Get(&hasFamiliesActiveColumn, `
 		SELECT COUNT(*)
@@ -74,16 +144,549 @@ func setupTestDB(t *testing.T) *sqlx.DB {
 		}
 	}
 
+	if err = ensureTestColumn(db, "inspections", "schema_version", `
+		ALTER TABLE inspections
+		ADD COLUMN schema_version smallint unsigned NOT NULL DEFAULT 0,
+		ADD COLUMN queen_seen tinyint(1) DEFAULT NULL,
+		ADD COLUMN eggs_seen tinyint(1) DEFAULT NULL,
+		ADD COLUMN queen_cells_seen tinyint(1) DEFAULT NULL,
+		ADD COLUMN temperament varchar(16) DEFAULT NULL,
+		ADD COLUMN brood_pattern varchar(16) DEFAULT NULL,
+		ADD COLUMN population varchar(16) DEFAULT NULL
+	`); err != nil {
+		t.Skipf("Skipping test - cannot ensure inspections observation columns: %v", err)
+		return nil
+	}
+
+	if err = ensureTestColumn(db, "inspections", "hive_snapshot", `
+		ALTER TABLE inspections ADD COLUMN hive_snapshot json DEFAULT NULL
+	`); err != nil {
+		t.Skipf("Skipping test - cannot ensure inspections.hive_snapshot column: %v", err)
+		return nil
+	}
+
+	if err = ensureTestColumn(db, "inspections", "active", `
+		ALTER TABLE inspections ADD COLUMN active tinyint(1) NOT NULL DEFAULT 1
+	`); err != nil {
+		t.Skipf("Skipping test - cannot ensure inspections.active column: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS treatment_products (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned DEFAULT NULL,
+			name varchar(100) NOT NULL,
+			active_ingredient varchar(100) DEFAULT NULL,
+			dosage_unit varchar(16) NOT NULL,
+			default_dose decimal(10,3) DEFAULT NULL,
+			withdrawal_days int unsigned NOT NULL DEFAULT 0,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_treatment_products_user (user_id, active)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err == nil {
+		_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS treatment_courses (
+				id int unsigned NOT NULL AUTO_INCREMENT,
+				user_id int unsigned NOT NULL,
+				hive_id int unsigned NOT NULL,
+				family_id int unsigned DEFAULT NULL,
+				product_id int unsigned NOT NULL,
+				planned_applications int unsigned NOT NULL,
+				interval_days int unsigned NOT NULL DEFAULT 0,
+				honey_supers_off tinyint(1) NOT NULL DEFAULT 0,
+				started_at datetime NOT NULL,
+				ended_at datetime DEFAULT NULL,
+				active tinyint(1) NOT NULL DEFAULT 1,
+				PRIMARY KEY (id),
+				KEY idx_treatment_courses_user_hive (user_id, hive_id, active)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+		`)
+	}
+	if err == nil {
+		err = ensureTestColumn(db, "treatments", "product_id", `
+			ALTER TABLE treatments
+			ADD COLUMN product_id int unsigned DEFAULT NULL,
+			ADD COLUMN course_id int unsigned DEFAULT NULL,
+			ADD COLUMN dose decimal(10,3) DEFAULT NULL,
+			ADD COLUMN dose_unit varchar(16) DEFAULT NULL,
+			ADD COLUMN start_date datetime DEFAULT NULL,
+			ADD COLUMN end_date datetime DEFAULT NULL,
+			ADD COLUMN honey_supers_off tinyint(1) NOT NULL DEFAULT 0
+		`)
+	}
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure treatment catalog tables: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS varroa_counts (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			hive_id int unsigned NOT NULL,
+			family_id int unsigned DEFAULT NULL,
+			method varchar(16) NOT NULL,
+			sample_size int unsigned NOT NULL,
+			mites_found int unsigned NOT NULL,
+			counted_at datetime NOT NULL,
+			notes text DEFAULT NULL,
+			treatment_id int unsigned DEFAULT NULL,
+			treatment_phase varchar(8) DEFAULT NULL,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_varroa_counts_user_hive_counted (user_id, hive_id, counted_at),
+			KEY idx_varroa_counts_treatment (treatment_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure varroa_counts table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS apiary_members (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			apiary_id int unsigned NOT NULL,
+			user_id int unsigned DEFAULT NULL,
+			role varchar(16) NOT NULL,
+			status varchar(16) NOT NULL DEFAULT 'PENDING',
+			invite_token_hash char(64) DEFAULT NULL,
+			invited_by int unsigned NOT NULL,
+			invited_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at datetime NOT NULL,
+			accepted_at datetime DEFAULT NULL,
+			revoked_at datetime DEFAULT NULL,
+			PRIMARY KEY (id),
+			UNIQUE KEY uniq_apiary_members_invite_token (invite_token_hash),
+			KEY idx_apiary_members_user (user_id, status),
+			KEY idx_apiary_members_apiary (apiary_id, status)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure apiary_members table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_billing_plans (
+			user_id int unsigned NOT NULL,
+			billing_plan varchar(32) NOT NULL,
+			updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure user_billing_plans table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS hive_apiary_moves (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			hive_id int unsigned NOT NULL,
+			from_apiary_id int unsigned DEFAULT NULL,
+			to_apiary_id int unsigned NOT NULL,
+			moved_at datetime NOT NULL,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_hive_apiary_moves_user_hive_moved (user_id, hive_id, moved_at),
+			KEY idx_hive_apiary_moves_to_apiary (to_apiary_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure hive_apiary_moves table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS apiary_relocations (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			apiary_id int unsigned NOT NULL,
+			lat decimal(9,6) DEFAULT NULL,
+			lng decimal(9,6) DEFAULT NULL,
+			arrived_at datetime DEFAULT NULL,
+			departed_at datetime DEFAULT NULL,
+			forage varchar(100) DEFAULT NULL,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_apiary_relocations_user_apiary (user_id, apiary_id, departed_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure apiary_relocations table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pollination_contracts (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			apiary_id int unsigned NOT NULL,
+			grower varchar(255) NOT NULL,
+			grower_contact varchar(255) DEFAULT NULL,
+			crop varchar(100) NOT NULL,
+			field_name varchar(255) DEFAULT NULL,
+			field_lat decimal(9,6) DEFAULT NULL,
+			field_lng decimal(9,6) DEFAULT NULL,
+			required_hives int unsigned NOT NULL,
+			min_strength enum('WEAK','MEDIUM','STRONG') DEFAULT NULL,
+			starts_at datetime NOT NULL,
+			ends_at datetime NOT NULL,
+			fee decimal(10,2) DEFAULT NULL,
+			fee_currency char(3) DEFAULT NULL,
+			notes text,
+			placed_at datetime DEFAULT NULL,
+			removed_at datetime DEFAULT NULL,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_pollination_contracts_user_starts (user_id, active, starts_at),
+			KEY idx_pollination_contracts_apiary (apiary_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure pollination_contracts table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS honey_harvests (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			hive_id int unsigned NOT NULL,
+			family_id int unsigned DEFAULT NULL,
+			apiary_id int unsigned DEFAULT NULL,
+			harvested_at datetime NOT NULL,
+			weight_kg decimal(8,2) NOT NULL,
+			moisture decimal(4,1) DEFAULT NULL,
+			floral_source varchar(100) DEFAULT NULL,
+			frames int unsigned DEFAULT NULL,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_honey_harvests_user_hive (user_id, hive_id, harvested_at),
+			KEY idx_honey_harvests_user_family (user_id, family_id),
+			KEY idx_honey_harvests_user_apiary (user_id, apiary_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure honey_harvests table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS honey_harvest_boxes (
+			harvest_id int unsigned NOT NULL,
+			box_id int unsigned NOT NULL,
+			PRIMARY KEY (harvest_id, box_id),
+			KEY idx_honey_harvest_boxes_box (box_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure honey_harvest_boxes table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS honey_lots (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			lot_number varchar(50) NOT NULL,
+			extracted_at datetime NOT NULL,
+			jar_count int unsigned NOT NULL,
+			jar_size_g int unsigned DEFAULT NULL,
+			notes text,
+			withdrawal_overridden tinyint(1) NOT NULL DEFAULT 0,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			UNIQUE KEY uniq_honey_lots_user_number (user_id, lot_number),
+			KEY idx_honey_lots_user_extracted (user_id, active, extracted_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure honey_lots table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS honey_lot_harvests (
+			lot_id int unsigned NOT NULL,
+			harvest_id int unsigned NOT NULL,
+			PRIMARY KEY (lot_id, harvest_id),
+			KEY idx_honey_lot_harvests_harvest (harvest_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure honey_lot_harvests table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS honey_lot_counters (
+			user_id int unsigned NOT NULL,
+			year smallint unsigned NOT NULL,
+			last_number int unsigned NOT NULL,
+			PRIMARY KEY (user_id, year)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure honey_lot_counters table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS feedings (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			hive_id int unsigned NOT NULL,
+			box_id int unsigned DEFAULT NULL,
+			apiary_id int unsigned DEFAULT NULL,
+			feed_type enum('SYRUP_1_1','SYRUP_2_1','INVERT_SYRUP','FONDANT','DRY_SUGAR','POLLEN_PATTY','POLLEN_SUBSTITUTE') NOT NULL,
+			amount decimal(8,3) NOT NULL,
+			unit enum('KG','G','L','ML') NOT NULL,
+			feed_kg decimal(8,3) NOT NULL,
+			sugar_kg decimal(8,3) NOT NULL,
+			stock_deducted_kg decimal(8,3) NOT NULL DEFAULT 0,
+			fed_at datetime NOT NULL,
+			notes text,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_feedings_user_hive (user_id, hive_id, fed_at),
+			KEY idx_feedings_user_apiary (user_id, apiary_id, fed_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure feedings table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS warehouse_feed_stock (
+			user_id int unsigned NOT NULL,
+			feed_type enum('SYRUP_1_1','SYRUP_2_1','INVERT_SYRUP','FONDANT','DRY_SUGAR','POLLEN_PATTY','POLLEN_SUBSTITUTE') NOT NULL,
+			amount_kg decimal(10,3) NOT NULL DEFAULT 0,
+			PRIMARY KEY (user_id, feed_type)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure warehouse_feed_stock table: %v", err)
+		return nil
+	}
+
+	err = ensureTestColumn(db, "apiaries", "geo_point", `
+		ALTER TABLE apiaries
+			MODIFY lat decimal(9,6) NULL DEFAULT NULL,
+			MODIFY lng decimal(9,6) NULL DEFAULT NULL,
+			ADD COLUMN geo_point POINT SRID 4326
+				GENERATED ALWAYS AS (IF(lat IS NULL OR lng IS NULL, NULL, ST_SRID(POINT(lat, lng), 4326))) STORED
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure apiaries.geo_point column: %v", err)
+		return nil
+	}
+
+	err = ensureTestColumn(db, "families", "mother_id", `
+		ALTER TABLE families
+			ADD COLUMN mother_id int unsigned DEFAULT NULL,
+			ADD COLUMN mating_type enum('OPEN','MATING_STATION','INSTRUMENTAL_INSEMINATION') DEFAULT NULL,
+			ADD COLUMN mating_station varchar(255) DEFAULT NULL,
+			ADD COLUMN drone_source_id int unsigned DEFAULT NULL,
+			ADD COLUMN drone_origin varchar(255) DEFAULT NULL
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure families pedigree columns: %v", err)
+		return nil
+	}
+
+	err = ensureTestColumn(db, "hives", "split_mother_id", `
+		ALTER TABLE hives ADD COLUMN split_mother_id int unsigned DEFAULT NULL
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure hives.split_mother_id column: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS queen_rearing_batches (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			mother_id int unsigned NOT NULL,
+			grafted_at datetime NOT NULL,
+			cells_grafted int unsigned NOT NULL,
+			cells_accepted int unsigned DEFAULT NULL,
+			cells_capped int unsigned DEFAULT NULL,
+			cells_emerged int unsigned DEFAULT NULL,
+			notes text,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_queen_rearing_batches_user_grafted (user_id, grafted_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure queen_rearing_batches table: %v", err)
+		return nil
+	}
+
+	err = ensureTestColumn(db, "families", "rearing_batch_id", `
+		ALTER TABLE families ADD COLUMN rearing_batch_id int unsigned DEFAULT NULL
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure families.rearing_batch_id column: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS queen_events (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			family_id int unsigned NOT NULL,
+			hive_id int unsigned DEFAULT NULL,
+			event_type enum('EMERGED','CAGED','INTRODUCED','RELEASED','ACCEPTED','REJECTED','MATED','LAYING','CLIPPED','MARKED','SUPERSEDED','SWARMED','LOST') NOT NULL,
+			occurred_at datetime NOT NULL,
+			notes text,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_queen_events_user_family (user_id, family_id, occurred_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure queen_events table: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS queen_ratings (
+			id int unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			family_id int unsigned NOT NULL,
+			hive_id int unsigned DEFAULT NULL,
+			inspection_id int unsigned DEFAULT NULL,
+			rated_at datetime NOT NULL,
+			temperament tinyint unsigned DEFAULT NULL,
+			calmness tinyint unsigned DEFAULT NULL,
+			swarming tinyint unsigned DEFAULT NULL,
+			hygiene tinyint unsigned DEFAULT NULL,
+			honey_yield tinyint unsigned DEFAULT NULL,
+			varroa_tolerance tinyint unsigned DEFAULT NULL,
+			notes text,
+			active tinyint(1) NOT NULL DEFAULT 1,
+			added datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_queen_ratings_user_family (user_id, family_id, rated_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure queen_ratings table: %v", err)
+		return nil
+	}
+
+	err = ensureTestColumn(db, "families", "is_primary", `
+		ALTER TABLE families ADD COLUMN is_primary tinyint(1) NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure families.is_primary column: %v", err)
+		return nil
+	}
+
+	err = ensureTestColumn(db, "boxes", "family_id", `
+		ALTER TABLE boxes ADD COLUMN family_id int unsigned DEFAULT NULL
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure boxes.family_id column: %v", err)
+		return nil
+	}
+
+	err = ensureTestColumn(db, "frames", "comb_built_at", `
+		ALTER TABLE frames ADD COLUMN comb_built_at datetime DEFAULT NULL
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure frames.comb_built_at column: %v", err)
+		return nil
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS frame_moves (
+			id bigint unsigned NOT NULL AUTO_INCREMENT,
+			user_id int unsigned NOT NULL,
+			frame_id int unsigned NOT NULL,
+			from_box_id int unsigned DEFAULT NULL,
+			from_hive_id int unsigned DEFAULT NULL,
+			to_box_id int unsigned DEFAULT NULL,
+			to_hive_id int unsigned DEFAULT NULL,
+			move_type varchar(32) NOT NULL DEFAULT 'MOVED',
+			moved_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_frame_moves_user_frame_time (user_id, frame_id, moved_at)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`)
+	if err != nil {
+		t.Skipf("Skipping test - cannot ensure frame_moves table: %v", err)
+		return nil
+	}
+
 	return db
 }
 
+// ensureTestColumn runs alterSQL when the column is missing, for databases created before the migration
+func ensureTestColumn(db *sqlx.DB, table string, column string, alterSQL string) error {
+	var count int
+	err := db.Get(&count, `
+		SELECT COUNT(*)
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE()
+		  AND TABLE_NAME = ?
+		  AND COLUMN_NAME = ?
+	`, table, column)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	_, err = db.Exec(alterSQL)
+	return err
+}
+
 func cleanupTestData(t *testing.T, db *sqlx.DB, userID string) {
+	db.Exec("DELETE FROM outbox_events WHERE user_id=?", userID)
+	db.Exec("DELETE FROM apiary_members WHERE invited_by=? OR user_id=?", userID, userID)
+	db.Exec("DELETE FROM user_billing_plans WHERE user_id=?", userID)
+	db.Exec("DELETE FROM hive_structure_changes WHERE user_id=?", userID)
+	db.Exec("DELETE FROM inspections WHERE user_id=?", userID)
+	db.Exec("DELETE FROM varroa_counts WHERE user_id=?", userID)
+	db.Exec("DELETE FROM treatments WHERE user_id=?", userID)
+	db.Exec("DELETE FROM treatment_courses WHERE user_id=?", userID)
+	db.Exec("DELETE FROM treatment_products WHERE user_id=?", userID)
+	db.Exec("DELETE FROM hive_apiary_moves WHERE user_id=?", userID)
 	db.Exec("DELETE FROM family_moves WHERE user_id=?", userID)
+	db.Exec("DELETE FROM frame_moves WHERE user_id=?", userID)
 	db.Exec("DELETE FROM frames WHERE user_id=?", userID)
 	db.Exec("DELETE FROM frames_sides WHERE user_id=?", userID)
 	db.Exec("DELETE FROM boxes WHERE user_id=?", userID)
 	db.Exec("DELETE FROM families WHERE user_id=?", userID)
 	db.Exec("DELETE FROM hives WHERE user_id=?", userID)
+	db.Exec("DELETE FROM apiary_relocations WHERE user_id=?", userID)
+	db.Exec("DELETE FROM pollination_contracts WHERE user_id=?", userID)
+	db.Exec("DELETE FROM feedings WHERE user_id=?", userID)
+	db.Exec("DELETE FROM warehouse_feed_stock WHERE user_id=?", userID)
+	db.Exec("DELETE FROM queen_rearing_batches WHERE user_id=?", userID)
+	db.Exec("DELETE FROM queen_events WHERE user_id=?", userID)
+	db.Exec("DELETE FROM queen_ratings WHERE user_id=?", userID)
+	db.Exec("DELETE FROM warehouse_settings WHERE user_id=?", userID)
+	db.Exec("DELETE FROM honey_lot_harvests WHERE lot_id IN (SELECT id FROM honey_lots WHERE user_id=?)", userID)
+	db.Exec("DELETE FROM honey_lots WHERE user_id=?", userID)
+	db.Exec("DELETE FROM honey_lot_counters WHERE user_id=?", userID)
+	db.Exec("DELETE FROM honey_harvest_boxes WHERE harvest_id IN (SELECT id FROM honey_harvests WHERE user_id=?)", userID)
+	db.Exec("DELETE FROM honey_harvests WHERE user_id=?", userID)
 	db.Exec("DELETE FROM apiaries WHERE user_id=?", userID)
 }
 
@@ -0,0 +1,178 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreatmentCourses(t *testing.T) {
+	t.Parallel()
+
+	t.Run("course ends with its last planned dose and keeps the hive in withdrawal", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		hiveID := createTestHive(t, db, userID, apiaryID)
+		hiveIDStr := strconv.Itoa(hiveID)
+		createTestQueen(t, db, userID, hiveID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		defaultDose := 5.0
+		product, err := mutation.AddTreatmentProduct(ctx, model.TreatmentProductInput{
+			Name:           "Oxalic dribble",
+			DosageUnit:     model.TreatmentDosageUnitMl,
+			DefaultDose:    &defaultDose,
+			WithdrawalDays: 30,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, product)
+		assert.True(t, product.Custom())
+
+		course, err := mutation.StartTreatmentCourse(ctx, model.TreatmentCourseInput{
+			HiveID:              hiveIDStr,
+			ProductID:           product.ID,
+			PlannedApplications: 3,
+			IntervalDays:        7,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, course.NextApplicationAt)
+
+		// ACT
+		for i := 0; i < 3; i++ {
+			_, err = mutation.TreatHive(ctx, model.TreatmentOfHiveInput{HiveID: hiveIDStr, Type: "oxalic_acid", CourseID: &course.ID})
+			require.NoError(t, err)
+		}
+		_, extraErr := mutation.TreatHive(ctx, model.TreatmentOfHiveInput{HiveID: hiveIDStr, Type: "oxalic_acid", CourseID: &course.ID})
+
+		// ASSERT
+		assert.Error(t, extraErr)
+
+		finished, err := query.TreatmentCourses(ctx, hiveIDStr, nil)
+		require.NoError(t, err)
+		assert.Empty(t, finished)
+
+		includeFinished := true
+		courses, err := query.TreatmentCourses(ctx, hiveIDStr, &includeFinished)
+		require.NoError(t, err)
+		require.Len(t, courses, 1)
+		assert.NotNil(t, courses[0].EndedAt)
+		assert.Nil(t, courses[0].NextApplicationAt)
+		require.Len(t, courses[0].Applications, 3)
+		require.NotNil(t, courses[0].Applications[0].Dose)
+		assert.Equal(t, 5.0, *courses[0].Applications[0].Dose)
+		assert.Equal(t, model.TreatmentDosageUnitMl, *courses[0].Applications[0].DoseUnit)
+		require.NotNil(t, courses[0].Applications[0].Product)
+		assert.Equal(t, product.ID, courses[0].Applications[0].Product.ID)
+
+		withdrawals, err := query.HivesInWithdrawal(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, withdrawals, 1)
+		assert.Equal(t, hiveIDStr, withdrawals[0].HiveID)
+		assert.Equal(t, 30, withdrawals[0].DaysRemaining)
+	})
+
+	t.Run("treatBox records dose of a product without a course", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		createTestQueen(t, db, userID, hiveID)
+		boxID := createTestBox(t, db, userID, hiveID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		query := &queryResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		product, err := mutation.AddTreatmentProduct(ctx, model.TreatmentProductInput{
+			Name:           "Thymol",
+			DosageUnit:     model.TreatmentDosageUnitG,
+			WithdrawalDays: 0,
+		})
+		require.NoError(t, err)
+
+		dose := 25.0
+		startDate := "2020-01-01"
+
+		// ACT
+		ok, err := mutation.TreatBox(ctx, model.TreatmentOfBoxInput{
+			HiveID:    strconv.Itoa(hiveID),
+			BoxID:     strconv.Itoa(boxID),
+			Type:      "thymol",
+			ProductID: &product.ID,
+			Dose:      &dose,
+			StartDate: &startDate,
+		})
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.True(t, *ok)
+		assert.Equal(t, 1, countRows(t, db, "SELECT COUNT(*) FROM treatments WHERE user_id=? AND box_id=? AND family_id IS NOT NULL AND dose=25", userID, boxID))
+
+		withdrawals, err := query.HivesInWithdrawal(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, withdrawals)
+	})
+
+	t.Run("built-in products can not be changed", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		result := db.MustExec("INSERT INTO treatment_products (user_id, name, dosage_unit, withdrawal_days) VALUES (NULL, 'Built-in test product', 'ML', 0)")
+		builtInID, _ := result.LastInsertId()
+		defer db.Exec("DELETE FROM treatment_products WHERE id=?", builtInID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		// ACT
+		_, updateErr := mutation.UpdateTreatmentProduct(ctx, strconv.FormatInt(builtInID, 10), model.TreatmentProductInput{
+			Name:       "Renamed",
+			DosageUnit: model.TreatmentDosageUnitMl,
+		})
+		deleted, deleteErr := mutation.DeleteTreatmentProduct(ctx, strconv.FormatInt(builtInID, 10))
+
+		// ASSERT
+		assert.Error(t, updateErr)
+		require.NoError(t, deleteErr)
+		assert.False(t, deleted)
+	})
+}
@@ -0,0 +1,154 @@
+//go:build integration
+// +build integration
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarroaCounts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("counts around a treatment report its efficacy for the family", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		hiveID := createTestHive(t, db, userID, createTestApiary(t, db, userID))
+		hiveIDStr := strconv.Itoa(hiveID)
+		familyID := createTestQueen(t, db, userID, hiveID)
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		family := &familyResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		_, err := mutation.TreatHive(ctx, model.TreatmentOfHiveInput{HiveID: hiveIDStr, Type: "oxalic_acid"})
+		require.NoError(t, err)
+		var treatmentID string
+		require.NoError(t, db.Get(&treatmentID, "SELECT id FROM treatments WHERE user_id=? LIMIT 1", userID))
+
+		before := model.VarroaCountTreatmentPhaseBefore
+		after := model.VarroaCountTreatmentPhaseAfter
+
+		// ACT
+		beforeCount, err := mutation.AddVarroaCount(ctx, model.VarroaCountInput{
+			HiveID: hiveIDStr, Method: model.VarroaCountMethodSugarRoll, SampleSize: 300, MitesFound: 12,
+			TreatmentID: &treatmentID, TreatmentPhase: &before,
+		})
+		require.NoError(t, err)
+		_, err = mutation.AddVarroaCount(ctx, model.VarroaCountInput{
+			HiveID: hiveIDStr, Method: model.VarroaCountMethodSugarRoll, SampleSize: 300, MitesFound: 3,
+			TreatmentID: &treatmentID, TreatmentPhase: &after,
+		})
+		require.NoError(t, err)
+
+		// ASSERT
+		require.NotNil(t, beforeCount.FamilyID)
+		assert.Equal(t, strconv.Itoa(familyID), *beforeCount.FamilyID)
+
+		efficacy, err := family.TreatmentEfficacy(ctx, &model.Family{ID: strconv.Itoa(familyID)})
+		require.NoError(t, err)
+		require.Len(t, efficacy, 1)
+		require.NotNil(t, efficacy[0].ReductionPercent)
+		assert.InDelta(t, 75.0, *efficacy[0].ReductionPercent, 0.0001)
+	})
+
+	t.Run("apiary summary uses the latest count of each hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		firstHive := strconv.Itoa(createTestHive(t, db, userID, apiaryID))
+		secondHive := strconv.Itoa(createTestHive(t, db, userID, apiaryID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		apiary := &apiaryResolver{Resolver: &Resolver{Db: db}}
+		hive := &hiveResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		_, err := mutation.AddVarroaCount(ctx, model.VarroaCountInput{HiveID: firstHive, Method: model.VarroaCountMethodAlcoholWash, SampleSize: 300, MitesFound: 30})
+		require.NoError(t, err)
+		db.MustExec("UPDATE varroa_counts SET counted_at=DATE_SUB(NOW(), INTERVAL 7 DAY) WHERE user_id=?", userID)
+		_, err = mutation.AddVarroaCount(ctx, model.VarroaCountInput{HiveID: firstHive, Method: model.VarroaCountMethodAlcoholWash, SampleSize: 300, MitesFound: 3})
+		require.NoError(t, err)
+		_, err = mutation.AddVarroaCount(ctx, model.VarroaCountInput{HiveID: secondHive, Method: model.VarroaCountMethodAlcoholWash, SampleSize: 300, MitesFound: 15})
+		require.NoError(t, err)
+
+		// ACT
+		summary, err := apiary.VarroaSummary(ctx, &model.Apiary{ID: apiaryID}, nil, nil)
+
+		// ASSERT
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.HivesCounted)
+		assert.Equal(t, []string{secondHive}, summary.HivesAboveThreshold)
+
+		trend, err := hive.VarroaTrend(ctx, &model.Hive{ID: firstHive}, nil)
+		require.NoError(t, err)
+		assert.Len(t, trend.Points, 2)
+		require.NotNil(t, trend.Direction)
+		assert.Equal(t, model.VarroaTrendDirectionFalling, *trend.Direction)
+	})
+
+	t.Run("count can not reference a treatment of another hive", func(t *testing.T) {
+		t.Parallel()
+
+		// ARRANGE
+		db := setupTestDB(t)
+		if db == nil {
+			return
+		}
+		defer db.Close()
+
+		userID := createTestUserID()
+		defer cleanupTestData(t, db, userID)
+
+		apiaryID := createTestApiary(t, db, userID)
+		treatedHiveID := createTestHive(t, db, userID, apiaryID)
+		treatedHive := strconv.Itoa(treatedHiveID)
+		countedHive := strconv.Itoa(createTestHive(t, db, userID, apiaryID))
+
+		mutation := &mutationResolver{Resolver: &Resolver{Db: db}}
+		ctx := context.WithValue(context.Background(), "userID", userID)
+
+		createTestQueen(t, db, userID, treatedHiveID)
+		_, err := mutation.TreatHive(ctx, model.TreatmentOfHiveInput{HiveID: treatedHive, Type: "formic_acid"})
+		require.NoError(t, err)
+		var treatmentID string
+		require.NoError(t, db.Get(&treatmentID, "SELECT id FROM treatments WHERE user_id=? LIMIT 1", userID))
+		phase := model.VarroaCountTreatmentPhaseAfter
+
+		// ACT
+		_, err = mutation.AddVarroaCount(ctx, model.VarroaCountInput{
+			HiveID: countedHive, Method: model.VarroaCountMethodSugarRoll, SampleSize: 300, MitesFound: 1,
+			TreatmentID: &treatmentID, TreatmentPhase: &phase,
+		})
+
+		// ASSERT
+		assert.Error(t, err)
+		assert.Equal(t, 0, countRows(t, db, "SELECT COUNT(*) FROM varroa_counts WHERE user_id=?", userID))
+	})
+}
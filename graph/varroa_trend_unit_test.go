@@ -0,0 +1,109 @@
+//go:build !integration
+// +build !integration
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/Gratheon/swarm-api/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func varroaSample(id string, hiveID string, countedAt string, mites int) *model.VarroaCount {
+	return &model.VarroaCount{
+		ID:         id,
+		HiveID:     hiveID,
+		Method:     model.VarroaCountMethodAlcoholWash,
+		SampleSize: 300,
+		MitesFound: mites,
+		CountedAt:  countedAt,
+	}
+}
+
+func TestVarroaCountRates(t *testing.T) {
+	wash := varroaSample("1", "10", "2026-06-01 10:00:00", 9)
+	require.NotNil(t, wash.InfestationRate())
+	assert.InDelta(t, 3.0, *wash.InfestationRate(), 0.0001)
+	assert.Nil(t, wash.DailyMiteDrop())
+
+	board := &model.VarroaCount{Method: model.VarroaCountMethodStickyBoard, SampleSize: 3, MitesFound: 12}
+	assert.Nil(t, board.InfestationRate())
+	require.NotNil(t, board.DailyMiteDrop())
+	assert.InDelta(t, 4.0, *board.DailyMiteDrop(), 0.0001)
+}
+
+func TestNewVarroaTrend(t *testing.T) {
+	t.Run("rising infestation", func(t *testing.T) {
+		trend, err := model.NewVarroaTrend([]*model.VarroaCount{
+			varroaSample("1", "10", "2026-06-01 10:00:00", 3),
+			{ID: "2", Method: model.VarroaCountMethodStickyBoard, SampleSize: 3, MitesFound: 30, CountedAt: "2026-06-04 10:00:00"},
+			varroaSample("3", "10", "2026-06-08T10:00:00Z", 6),
+			varroaSample("4", "10", "2026-06-15 10:00:00", 9),
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, trend.Points, 4)
+		require.NotNil(t, trend.LatestInfestationRate)
+		assert.InDelta(t, 3.0, *trend.LatestInfestationRate, 0.0001)
+		require.NotNil(t, trend.WeeklyChange)
+		assert.InDelta(t, 1.0, *trend.WeeklyChange, 0.0001)
+		assert.Equal(t, model.VarroaTrendDirectionRising, *trend.Direction)
+	})
+
+	t.Run("single count has no direction", func(t *testing.T) {
+		trend, err := model.NewVarroaTrend([]*model.VarroaCount{varroaSample("1", "10", "2026-06-01 10:00:00", 3)})
+
+		require.NoError(t, err)
+		assert.Nil(t, trend.WeeklyChange)
+		assert.Nil(t, trend.Direction)
+	})
+}
+
+func TestNewApiaryVarroaSummary(t *testing.T) {
+	summary := model.NewApiaryVarroaSummary([]*model.VarroaCount{
+		varroaSample("1", "10", "2026-06-01 10:00:00", 3),
+		varroaSample("2", "11", "2026-06-01 10:00:00", 15),
+		{ID: "3", HiveID: "12", Method: model.VarroaCountMethodStickyBoard, SampleSize: 1, MitesFound: 40},
+	}, 3)
+
+	assert.Equal(t, 3, summary.HivesCounted)
+	require.NotNil(t, summary.AverageInfestationRate)
+	assert.InDelta(t, 3.0, *summary.AverageInfestationRate, 0.0001)
+	assert.InDelta(t, 5.0, *summary.MaxInfestationRate, 0.0001)
+	assert.Equal(t, []string{"11"}, summary.HivesAboveThreshold)
+}
+
+func TestNewTreatmentEfficacy(t *testing.T) {
+	treatmentID := "5"
+	before := model.VarroaCountTreatmentPhaseBefore
+	after := model.VarroaCountTreatmentPhaseAfter
+
+	beforeCount := varroaSample("1", "10", "2026-06-01 10:00:00", 12)
+	beforeCount.TreatmentID, beforeCount.TreatmentPhase = &treatmentID, &before
+	afterCount := varroaSample("2", "10", "2026-06-20 10:00:00", 3)
+	afterCount.TreatmentID, afterCount.TreatmentPhase = &treatmentID, &after
+	boardAfter := &model.VarroaCount{ID: "3", Method: model.VarroaCountMethodStickyBoard, SampleSize: 1, MitesFound: 2, TreatmentID: &treatmentID, TreatmentPhase: &after}
+
+	t.Run("compares bee samples", func(t *testing.T) {
+		result := model.NewTreatmentEfficacy(
+			[]*model.Treatment{{ID: 5}, {ID: 6}},
+			[]*model.VarroaCount{afterCount, beforeCount},
+		)
+
+		require.Len(t, result, 2)
+		require.NotNil(t, result[0].ReductionPercent)
+		assert.InDelta(t, 75.0, *result[0].ReductionPercent, 0.0001)
+		assert.Nil(t, result[1].Before)
+		assert.Nil(t, result[1].ReductionPercent)
+	})
+
+	t.Run("bee sample is not compared with a sticky board", func(t *testing.T) {
+		result := model.NewTreatmentEfficacy([]*model.Treatment{{ID: 5}}, []*model.VarroaCount{boardAfter, beforeCount})
+
+		require.Len(t, result, 1)
+		assert.Equal(t, boardAfter, result[0].After)
+		assert.Nil(t, result[0].ReductionPercent)
+	})
+}
@@ -4,11 +4,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -150,6 +152,66 @@ func TestAuthMiddleware_RejectsTokenWithoutUserID(t *testing.T) {
 	assert.Contains(t, rr.Body.String(), "Unauthorized")
 }
 
+func TestAuthMiddleware_PassesWebsocketUpgradeWithoutToken(t *testing.T) {
+	viper.Set("jwt_key", "test-secret")
+	t.Cleanup(viper.Reset)
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	rr := httptest.NewRecorder()
+
+	var gotUserID interface{}
+	handler := authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID = r.Context().Value("userID")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Nil(t, gotUserID)
+}
+
+func TestWebsocketInitFunc_KeepsUserAuthenticatedByHeaders(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "userID", "internal-user")
+
+	gotCtx, _, err := websocketInitFunc(ctx, transport.InitPayload{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "internal-user", gotCtx.Value("userID"))
+}
+
+func TestWebsocketInitFunc_AcceptsTokenFromInitPayload(t *testing.T) {
+	viper.Set("jwt_key", "test-secret")
+	t.Cleanup(viper.Reset)
+
+	tokenString := signedJWT(t, map[string]interface{}{
+		"user_id":      42,
+		"billing_plan": "starter",
+	})
+
+	gotCtx, _, err := websocketInitFunc(context.Background(), transport.InitPayload{"token": tokenString})
+
+	require.NoError(t, err)
+	assert.Equal(t, "42", gotCtx.Value("userID"))
+	assert.Equal(t, "starter", gotCtx.Value("billingPlan"))
+}
+
+func TestWebsocketInitFunc_RejectsMissingOrInvalidToken(t *testing.T) {
+	viper.Set("jwt_key", "test-secret")
+	t.Cleanup(viper.Reset)
+
+	_, _, err := websocketInitFunc(context.Background(), transport.InitPayload{})
+	assert.EqualError(t, err, "Unauthorized")
+
+	_, _, err = websocketInitFunc(context.Background(), transport.InitPayload{"token": "not-a-token"})
+	assert.EqualError(t, err, "Unauthorized")
+
+	_, _, err = websocketInitFunc(context.Background(), transport.InitPayload{"internal-userId": "spoofed"})
+	assert.EqualError(t, err, "Unauthorized")
+}
+
 func signedJWT(t *testing.T, claims map[string]interface{}) string {
 	t.Helper()
 
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS `outbox_events` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `event_id` char(36) NOT NULL,
+  `version` smallint unsigned NOT NULL DEFAULT 1,
+  `user_id` int unsigned NOT NULL,
+  `aggregate_type` varchar(32) NOT NULL,
+  `aggregate_id` varchar(64) NOT NULL,
+  `entity` varchar(32) NOT NULL,
+  `entity_id` varchar(64) NOT NULL,
+  `action` varchar(32) NOT NULL,
+  `hive_id` int unsigned DEFAULT NULL,
+  `apiary_id` int unsigned DEFAULT NULL,
+  `inventory` tinyint(1) NOT NULL DEFAULT 0,
+  `payload` json DEFAULT NULL,
+  `occurred_at` datetime(3) NOT NULL,
+  `published_at` datetime(3) DEFAULT NULL,
+  `attempts` int unsigned NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime(3) NOT NULL,
+  `last_error` text DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_outbox_events_event_id` (`event_id`),
+  KEY `idx_outbox_events_pending` (`published_at`, `id`),
+  KEY `idx_outbox_events_user` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS `outbox_events`;
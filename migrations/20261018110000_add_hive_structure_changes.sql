@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS `hive_structure_changes` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int unsigned NOT NULL,
+  `change_type` varchar(16) NOT NULL,
+  `source_hive_id` int unsigned NOT NULL,
+  `target_hive_id` int unsigned NOT NULL,
+  `previous_status` varchar(50) DEFAULT NULL,
+  `previous_placement` json DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `reverted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_hive_structure_changes_source` (`user_id`, `source_hive_id`),
+  KEY `idx_hive_structure_changes_target` (`user_id`, `target_hive_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `hive_structure_change_items` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `change_id` bigint unsigned NOT NULL,
+  `item_type` varchar(16) NOT NULL,
+  `item_id` int unsigned NOT NULL,
+  `from_parent_id` int unsigned DEFAULT NULL,
+  `from_position` int DEFAULT NULL,
+  `from_frame_spec_id` int unsigned DEFAULT NULL,
+  `to_parent_id` int unsigned NOT NULL,
+  `to_position` int DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_hive_structure_change_items_change` (`change_id`),
+  CONSTRAINT `fk_hive_structure_change_items_change` FOREIGN KEY (`change_id`) REFERENCES `hive_structure_changes` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS `hive_structure_change_items`;
+DROP TABLE IF EXISTS `hive_structure_changes`;
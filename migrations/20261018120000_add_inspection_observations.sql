@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE `inspections`
+  ADD COLUMN `schema_version` smallint unsigned NOT NULL DEFAULT 0,
+  ADD COLUMN `queen_seen` tinyint(1) DEFAULT NULL,
+  ADD COLUMN `eggs_seen` tinyint(1) DEFAULT NULL,
+  ADD COLUMN `queen_cells_seen` tinyint(1) DEFAULT NULL,
+  ADD COLUMN `temperament` varchar(16) DEFAULT NULL,
+  ADD COLUMN `brood_pattern` varchar(16) DEFAULT NULL,
+  ADD COLUMN `population` varchar(16) DEFAULT NULL,
+  ADD INDEX `idx_inspections_user_hive_added` (`user_id`, `hive_id`, `added`),
+  ADD INDEX `idx_inspections_user_schema_version` (`user_id`, `schema_version`);
+
+-- +goose Down
+ALTER TABLE `inspections`
+  DROP INDEX `idx_inspections_user_schema_version`,
+  DROP INDEX `idx_inspections_user_hive_added`,
+  DROP COLUMN `population`,
+  DROP COLUMN `brood_pattern`,
+  DROP COLUMN `temperament`,
+  DROP COLUMN `queen_cells_seen`,
+  DROP COLUMN `eggs_seen`,
+  DROP COLUMN `queen_seen`,
+  DROP COLUMN `schema_version`;
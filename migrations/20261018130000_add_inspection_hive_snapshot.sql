@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE `inspections`
+  ADD COLUMN `hive_snapshot` json DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE `inspections`
+  DROP COLUMN `hive_snapshot`;
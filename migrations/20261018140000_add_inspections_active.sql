@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE `inspections`
+  ADD COLUMN `active` tinyint(1) NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE `inspections`
+  DROP COLUMN `active`;
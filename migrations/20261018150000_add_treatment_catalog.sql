@@ -0,0 +1,64 @@
+-- +goose Up
+CREATE TABLE `treatment_products` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned DEFAULT NULL COMMENT 'NULL for built-in products',
+    `name` varchar(100) NOT NULL,
+    `active_ingredient` varchar(100) DEFAULT NULL,
+    `dosage_unit` varchar(16) NOT NULL,
+    `default_dose` decimal(10,3) DEFAULT NULL,
+    `withdrawal_days` int unsigned NOT NULL DEFAULT 0,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_treatment_products_user` (`user_id`, `active`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO `treatment_products` (`user_id`, `name`, `active_ingredient`, `dosage_unit`, `default_dose`, `withdrawal_days`) VALUES
+    (NULL, 'Oxalic acid dribble', 'oxalic acid', 'ML', 5.000, 0),
+    (NULL, 'Oxalic acid sublimation', 'oxalic acid', 'G', 2.000, 0),
+    (NULL, 'Formic acid 60%', 'formic acid', 'ML', 30.000, 0),
+    (NULL, 'Thymol gel', 'thymol', 'G', 50.000, 28),
+    (NULL, 'Amitraz strips', 'amitraz', 'STRIP', 2.000, 14),
+    (NULL, 'Tau-fluvalinate strips', 'tau-fluvalinate', 'STRIP', 2.000, 14);
+
+CREATE TABLE `treatment_courses` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `hive_id` int unsigned NOT NULL,
+    `family_id` int unsigned DEFAULT NULL,
+    `product_id` int unsigned NOT NULL,
+    `planned_applications` int unsigned NOT NULL,
+    `interval_days` int unsigned NOT NULL DEFAULT 0,
+    `honey_supers_off` tinyint(1) NOT NULL DEFAULT 0,
+    `started_at` datetime NOT NULL,
+    `ended_at` datetime DEFAULT NULL,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    PRIMARY KEY (`id`),
+    KEY `idx_treatment_courses_user_hive` (`user_id`, `hive_id`, `active`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `treatments`
+    ADD COLUMN `product_id` int unsigned DEFAULT NULL,
+    ADD COLUMN `course_id` int unsigned DEFAULT NULL,
+    ADD COLUMN `dose` decimal(10,3) DEFAULT NULL,
+    ADD COLUMN `dose_unit` varchar(16) DEFAULT NULL,
+    ADD COLUMN `start_date` datetime DEFAULT NULL,
+    ADD COLUMN `end_date` datetime DEFAULT NULL,
+    ADD COLUMN `honey_supers_off` tinyint(1) NOT NULL DEFAULT 0,
+    ADD KEY `idx_treatments_user_hive` (`user_id`, `hive_id`),
+    ADD KEY `idx_treatments_course` (`course_id`);
+
+-- +goose Down
+ALTER TABLE `treatments`
+    DROP KEY `idx_treatments_course`,
+    DROP KEY `idx_treatments_user_hive`,
+    DROP COLUMN `honey_supers_off`,
+    DROP COLUMN `end_date`,
+    DROP COLUMN `start_date`,
+    DROP COLUMN `dose_unit`,
+    DROP COLUMN `dose`,
+    DROP COLUMN `course_id`,
+    DROP COLUMN `product_id`;
+
+DROP TABLE `treatment_courses`;
+DROP TABLE `treatment_products`;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE `varroa_counts` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `hive_id` int unsigned NOT NULL,
+    `family_id` int unsigned DEFAULT NULL,
+    `method` varchar(16) NOT NULL,
+    `sample_size` int unsigned NOT NULL COMMENT 'bees in the sample, days for sticky boards',
+    `mites_found` int unsigned NOT NULL,
+    `counted_at` datetime NOT NULL,
+    `notes` text DEFAULT NULL,
+    `treatment_id` int unsigned DEFAULT NULL,
+    `treatment_phase` varchar(8) DEFAULT NULL,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_varroa_counts_user_hive_counted` (`user_id`, `hive_id`, `counted_at`),
+    KEY `idx_varroa_counts_treatment` (`treatment_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE `varroa_counts`;
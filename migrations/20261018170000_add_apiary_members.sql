@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE `apiary_members` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `apiary_id` int unsigned NOT NULL,
+    `user_id` int unsigned DEFAULT NULL COMMENT 'NULL until the invite is accepted',
+    `role` varchar(16) NOT NULL,
+    `status` varchar(16) NOT NULL DEFAULT 'PENDING',
+    `invite_token_hash` char(64) DEFAULT NULL,
+    `invited_by` int unsigned NOT NULL,
+    `invited_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expires_at` datetime NOT NULL,
+    `accepted_at` datetime DEFAULT NULL,
+    `revoked_at` datetime DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uniq_apiary_members_invite_token` (`invite_token_hash`),
+    KEY `idx_apiary_members_user` (`user_id`, `status`),
+    KEY `idx_apiary_members_apiary` (`apiary_id`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- billing plan of apiary owners, hives added by collaborators count against it
+CREATE TABLE `user_billing_plans` (
+    `user_id` int unsigned NOT NULL,
+    `billing_plan` varchar(32) NOT NULL,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE `user_billing_plans`;
+DROP TABLE `apiary_members`;
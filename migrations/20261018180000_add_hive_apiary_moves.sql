@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE `hive_apiary_moves` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `hive_id` int unsigned NOT NULL,
+    `from_apiary_id` int unsigned DEFAULT NULL,
+    `to_apiary_id` int unsigned NOT NULL,
+    `moved_at` datetime NOT NULL,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_hive_apiary_moves_user_hive_moved` (`user_id`, `hive_id`, `moved_at`),
+    KEY `idx_hive_apiary_moves_to_apiary` (`to_apiary_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE `hive_apiary_moves`;
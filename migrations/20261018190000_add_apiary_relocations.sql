@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE `apiary_relocations` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `apiary_id` int unsigned NOT NULL,
+    `lat` varchar(20) NOT NULL,
+    `lng` varchar(20) NOT NULL,
+    `arrived_at` datetime DEFAULT NULL COMMENT 'NULL for locations recorded before relocations were logged',
+    `departed_at` datetime DEFAULT NULL,
+    `forage` varchar(100) DEFAULT NULL,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_apiary_relocations_user_apiary` (`user_id`, `apiary_id`, `departed_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- current location of mobile apiaries starts their history
+INSERT INTO `apiary_relocations` (`user_id`, `apiary_id`, `lat`, `lng`)
+SELECT `user_id`, `id`, `lat`, `lng`
+FROM `apiaries`
+WHERE `type` = 'MOBILE' AND `lat` IS NOT NULL AND `lat` <> '' AND `lng` IS NOT NULL AND `lng` <> ''
+  AND NOT (`lat` = '0' AND `lng` = '0');
+
+-- +goose Down
+DROP TABLE `apiary_relocations`;
@@ -0,0 +1,61 @@
+-- +goose Up
+-- coordinates were free text, keep the ones that parse as degrees within range
+UPDATE `apiaries` SET `lat` = NULLIF(REPLACE(TRIM(`lat`), ',', '.'), ''), `lng` = NULLIF(REPLACE(TRIM(`lng`), ',', '.'), '');
+
+UPDATE `apiaries` SET `lat` = NULL, `lng` = NULL
+WHERE `lat` IS NULL OR `lng` IS NULL
+   OR `lat` NOT REGEXP '^-?[0-9]{1,3}([.][0-9]+)?$'
+   OR `lng` NOT REGEXP '^-?[0-9]{1,3}([.][0-9]+)?$';
+
+-- 0,0 was the column default, it never meant an apiary in the gulf of Guinea
+UPDATE `apiaries` SET `lat` = NULL, `lng` = NULL
+WHERE `lat` IS NOT NULL
+  AND (ABS(CAST(`lat` AS DECIMAL(20,10))) > 90
+    OR ABS(CAST(`lng` AS DECIMAL(20,10))) > 180
+    OR (CAST(`lat` AS DECIMAL(20,10)) = 0 AND CAST(`lng` AS DECIMAL(20,10)) = 0));
+
+ALTER TABLE `apiaries`
+    MODIFY `lat` DECIMAL(9,6) NULL DEFAULT NULL,
+    MODIFY `lng` DECIMAL(9,6) NULL DEFAULT NULL;
+
+-- SRID 4326 has latitude as the first axis
+ALTER TABLE `apiaries`
+    ADD COLUMN `geo_point` POINT SRID 4326
+        GENERATED ALWAYS AS (IF(`lat` IS NULL OR `lng` IS NULL, NULL, ST_SRID(POINT(`lat`, `lng`), 4326))) STORED;
+
+-- relocations keep their dates and forage even when the coordinates do not parse
+ALTER TABLE `apiary_relocations`
+    MODIFY `lat` varchar(20) NULL,
+    MODIFY `lng` varchar(20) NULL;
+
+UPDATE `apiary_relocations` SET `lat` = NULLIF(REPLACE(TRIM(`lat`), ',', '.'), ''), `lng` = NULLIF(REPLACE(TRIM(`lng`), ',', '.'), '');
+
+UPDATE `apiary_relocations` SET `lat` = NULL, `lng` = NULL
+WHERE `lat` IS NULL OR `lng` IS NULL
+   OR `lat` NOT REGEXP '^-?[0-9]{1,3}([.][0-9]+)?$'
+   OR `lng` NOT REGEXP '^-?[0-9]{1,3}([.][0-9]+)?$';
+
+UPDATE `apiary_relocations` SET `lat` = NULL, `lng` = NULL
+WHERE `lat` IS NOT NULL
+  AND (ABS(CAST(`lat` AS DECIMAL(20,10))) > 90 OR ABS(CAST(`lng` AS DECIMAL(20,10))) > 180);
+
+ALTER TABLE `apiary_relocations`
+    MODIFY `lat` DECIMAL(9,6) NULL DEFAULT NULL,
+    MODIFY `lng` DECIMAL(9,6) NULL DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE `apiary_relocations`
+    MODIFY `lat` varchar(20) NULL,
+    MODIFY `lng` varchar(20) NULL;
+
+UPDATE `apiary_relocations` SET `lat` = COALESCE(`lat`, ''), `lng` = COALESCE(`lng`, '');
+
+ALTER TABLE `apiary_relocations`
+    MODIFY `lat` varchar(20) NOT NULL,
+    MODIFY `lng` varchar(20) NOT NULL;
+
+ALTER TABLE `apiaries` DROP COLUMN `geo_point`;
+
+ALTER TABLE `apiaries`
+    MODIFY `lat` varchar(20) DEFAULT '0',
+    MODIFY `lng` varchar(20) DEFAULT '0';
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE `pollination_contracts` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `apiary_id` int unsigned NOT NULL COMMENT 'mobile apiary whose hives are rented out',
+    `grower` varchar(255) NOT NULL,
+    `grower_contact` varchar(255) DEFAULT NULL,
+    `crop` varchar(100) NOT NULL,
+    `field_name` varchar(255) DEFAULT NULL,
+    `field_lat` DECIMAL(9,6) DEFAULT NULL,
+    `field_lng` DECIMAL(9,6) DEFAULT NULL,
+    `required_hives` int unsigned NOT NULL,
+    `min_strength` enum('WEAK','MEDIUM','STRONG') DEFAULT NULL COMMENT 'population of the latest inspection every rented hive needs',
+    `starts_at` datetime NOT NULL,
+    `ends_at` datetime NOT NULL,
+    `fee` DECIMAL(10,2) DEFAULT NULL,
+    `fee_currency` char(3) DEFAULT NULL,
+    `notes` text,
+    `placed_at` datetime DEFAULT NULL,
+    `removed_at` datetime DEFAULT NULL,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_pollination_contracts_user_starts` (`user_id`, `active`, `starts_at`),
+    KEY `idx_pollination_contracts_apiary` (`apiary_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE `pollination_contracts`;
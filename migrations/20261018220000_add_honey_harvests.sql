@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE `honey_harvests` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `hive_id` int unsigned NOT NULL,
+    `family_id` int unsigned DEFAULT NULL COMMENT 'queen family living in the hive when the honey was harvested',
+    `apiary_id` int unsigned DEFAULT NULL COMMENT 'apiary of the hive at harvest time, hives can move later',
+    `harvested_at` datetime NOT NULL,
+    `weight_kg` DECIMAL(8,2) NOT NULL,
+    `moisture` DECIMAL(4,1) DEFAULT NULL COMMENT 'water content in percent',
+    `floral_source` varchar(100) DEFAULT NULL,
+    `frames` int unsigned DEFAULT NULL,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_honey_harvests_user_hive` (`user_id`, `hive_id`, `harvested_at`),
+    KEY `idx_honey_harvests_user_family` (`user_id`, `family_id`),
+    KEY `idx_honey_harvests_user_apiary` (`user_id`, `apiary_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `honey_harvest_boxes` (
+    `harvest_id` int unsigned NOT NULL,
+    `box_id` int unsigned NOT NULL,
+    PRIMARY KEY (`harvest_id`, `box_id`),
+    KEY `idx_honey_harvest_boxes_box` (`box_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE `honey_harvest_boxes`;
+DROP TABLE `honey_harvests`;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE `honey_lots` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `lot_number` varchar(50) NOT NULL COMMENT 'printed on jar labels',
+    `extracted_at` datetime NOT NULL,
+    `jar_count` int unsigned NOT NULL,
+    `jar_size_g` int unsigned DEFAULT NULL,
+    `notes` text,
+    `withdrawal_overridden` tinyint(1) NOT NULL DEFAULT 0 COMMENT 'created although a harvest was taken inside a treatment withdrawal period',
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uniq_honey_lots_user_number` (`user_id`, `lot_number`),
+    KEY `idx_honey_lots_user_extracted` (`user_id`, `active`, `extracted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `honey_lot_harvests` (
+    `lot_id` int unsigned NOT NULL,
+    `harvest_id` int unsigned NOT NULL,
+    PRIMARY KEY (`lot_id`, `harvest_id`),
+    KEY `idx_honey_lot_harvests_harvest` (`harvest_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE `honey_lot_harvests`;
+DROP TABLE `honey_lots`;
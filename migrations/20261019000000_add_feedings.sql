@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE `feedings` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `hive_id` int unsigned NOT NULL,
+    `box_id` int unsigned DEFAULT NULL COMMENT 'feeder box or box with feeder frames the feed was put into',
+    `apiary_id` int unsigned DEFAULT NULL COMMENT 'apiary of the hive at feeding time, hives can move later',
+    `feed_type` enum('SYRUP_1_1','SYRUP_2_1','INVERT_SYRUP','FONDANT','DRY_SUGAR','POLLEN_PATTY','POLLEN_SUBSTITUTE') NOT NULL,
+    `amount` DECIMAL(8,3) NOT NULL,
+    `unit` enum('KG','G','L','ML') NOT NULL,
+    `feed_kg` DECIMAL(8,3) NOT NULL COMMENT 'amount converted to kg of feed',
+    `sugar_kg` DECIMAL(8,3) NOT NULL COMMENT 'sugar content of the feed in kg',
+    `stock_deducted_kg` DECIMAL(8,3) NOT NULL DEFAULT 0 COMMENT 'taken from warehouse feed stock, given back when the feeding is removed',
+    `fed_at` datetime NOT NULL,
+    `notes` text,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_feedings_user_hive` (`user_id`, `hive_id`, `fed_at`),
+    KEY `idx_feedings_user_apiary` (`user_id`, `apiary_id`, `fed_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `warehouse_feed_stock` (
+    `user_id` int unsigned NOT NULL,
+    `feed_type` enum('SYRUP_1_1','SYRUP_2_1','INVERT_SYRUP','FONDANT','DRY_SUGAR','POLLEN_PATTY','POLLEN_SUBSTITUTE') NOT NULL,
+    `amount_kg` DECIMAL(10,3) NOT NULL DEFAULT 0,
+    PRIMARY KEY (`user_id`, `feed_type`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE `warehouse_feed_stock`;
+DROP TABLE `feedings`;
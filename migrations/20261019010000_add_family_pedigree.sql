@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE `families`
+    ADD COLUMN `mother_id` int unsigned DEFAULT NULL COMMENT 'family of the mother queen',
+    ADD COLUMN `mating_type` enum('OPEN','MATING_STATION','INSTRUMENTAL_INSEMINATION') DEFAULT NULL,
+    ADD COLUMN `mating_station` varchar(255) DEFAULT NULL,
+    ADD COLUMN `drone_source_id` int unsigned DEFAULT NULL COMMENT 'family of the colony the drones came from',
+    ADD COLUMN `drone_origin` varchar(255) DEFAULT NULL COMMENT 'drone line when the drone colony is not tracked',
+    ADD KEY `idx_families_mother` (`mother_id`),
+    ADD KEY `idx_families_drone_source` (`drone_source_id`);
+
+ALTER TABLE `hives`
+    ADD COLUMN `split_mother_id` int unsigned DEFAULT NULL COMMENT 'queen of the source colony of a queenless split, mother of the queen raised in it';
+
+-- +goose Down
+ALTER TABLE `hives` DROP COLUMN `split_mother_id`;
+
+ALTER TABLE `families`
+    DROP KEY `idx_families_drone_source`,
+    DROP KEY `idx_families_mother`,
+    DROP COLUMN `drone_origin`,
+    DROP COLUMN `drone_source_id`,
+    DROP COLUMN `mating_station`,
+    DROP COLUMN `mating_type`,
+    DROP COLUMN `mother_id`;
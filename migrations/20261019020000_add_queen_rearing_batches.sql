@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS `queen_rearing_batches` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `mother_id` int unsigned NOT NULL COMMENT 'family of the queen the larvae were grafted from',
+    `grafted_at` datetime NOT NULL,
+    `cells_grafted` int unsigned NOT NULL,
+    `cells_accepted` int unsigned DEFAULT NULL,
+    `cells_capped` int unsigned DEFAULT NULL,
+    `cells_emerged` int unsigned DEFAULT NULL,
+    `notes` text,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_queen_rearing_batches_user_grafted` (`user_id`, `grafted_at`),
+    KEY `idx_queen_rearing_batches_mother` (`mother_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `families`
+    ADD COLUMN `rearing_batch_id` int unsigned DEFAULT NULL COMMENT 'queen rearing batch the queen emerged from',
+    ADD KEY `idx_families_rearing_batch` (`rearing_batch_id`);
+
+-- +goose Down
+ALTER TABLE `families`
+    DROP KEY `idx_families_rearing_batch`,
+    DROP COLUMN `rearing_batch_id`;
+
+DROP TABLE IF EXISTS `queen_rearing_batches`;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS `queen_events` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `family_id` int unsigned NOT NULL,
+    `hive_id` int unsigned DEFAULT NULL COMMENT 'hive the queen was in when the event happened',
+    `event_type` enum('EMERGED','CAGED','INTRODUCED','RELEASED','ACCEPTED','REJECTED','MATED','LAYING','CLIPPED','MARKED','SUPERSEDED','SWARMED','LOST') NOT NULL,
+    `occurred_at` datetime NOT NULL,
+    `notes` text,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_queen_events_user_family` (`user_id`, `family_id`, `occurred_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS `queen_events`;
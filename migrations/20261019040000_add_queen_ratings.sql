@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS `queen_ratings` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` int unsigned NOT NULL,
+    `family_id` int unsigned NOT NULL,
+    `hive_id` int unsigned DEFAULT NULL COMMENT 'hive the queen was in when rated',
+    `inspection_id` int unsigned DEFAULT NULL,
+    `rated_at` datetime NOT NULL,
+    `temperament` tinyint unsigned DEFAULT NULL,
+    `calmness` tinyint unsigned DEFAULT NULL,
+    `swarming` tinyint unsigned DEFAULT NULL COMMENT '5 means the colony hardly swarms',
+    `hygiene` tinyint unsigned DEFAULT NULL,
+    `honey_yield` tinyint unsigned DEFAULT NULL,
+    `varroa_tolerance` tinyint unsigned DEFAULT NULL,
+    `notes` text,
+    `active` tinyint(1) NOT NULL DEFAULT 1,
+    `added` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_queen_ratings_user_family` (`user_id`, `family_id`, `rated_at`),
+    KEY `idx_queen_ratings_inspection` (`inspection_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS `queen_ratings`;
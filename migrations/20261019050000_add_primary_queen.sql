@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE `families`
+    ADD COLUMN `is_primary` tinyint(1) NOT NULL DEFAULT 0 COMMENT 'primary queen of the hive, one per hive',
+    ADD KEY `idx_families_hive_primary` (`hive_id`, `is_primary`);
+
+ALTER TABLE `boxes`
+    ADD COLUMN `family_id` int unsigned DEFAULT NULL COMMENT 'queen family occupying the box in multi-queen hives, the primary queen when empty';
+
+-- the oldest queen of a hive was the one shown as its family so far
+UPDATE `families` f
+JOIN (
+    SELECT MIN(`id`) AS `id`
+    FROM `families`
+    WHERE `active`=1 AND `hive_id` IS NOT NULL
+    GROUP BY `user_id`, `hive_id`
+) oldest ON oldest.`id` = f.`id`
+SET f.`is_primary`=1;
+
+-- +goose Down
+ALTER TABLE `boxes` DROP COLUMN `family_id`;
+
+ALTER TABLE `families`
+    DROP KEY `idx_families_hive_primary`,
+    DROP COLUMN `is_primary`;
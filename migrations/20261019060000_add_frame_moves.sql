@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE `frames` ADD COLUMN `comb_built_at` datetime DEFAULT NULL AFTER `frame_spec_id`;
+
+CREATE TABLE IF NOT EXISTS `frame_moves` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int unsigned NOT NULL,
+  `frame_id` int unsigned NOT NULL,
+  `from_box_id` int unsigned DEFAULT NULL,
+  `from_hive_id` int unsigned DEFAULT NULL,
+  `to_box_id` int unsigned DEFAULT NULL,
+  `to_hive_id` int unsigned DEFAULT NULL,
+  `move_type` varchar(32) NOT NULL DEFAULT 'MOVED',
+  `moved_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_frame_moves_user_frame_time` (`user_id`, `frame_id`, `moved_at`),
+  KEY `idx_frame_moves_to_box` (`to_box_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO `frame_moves` (`user_id`, `frame_id`, `from_box_id`, `from_hive_id`, `to_box_id`, `to_hive_id`, `move_type`)
+SELECT f.`user_id`, f.`id`, NULL, NULL, f.`box_id`, b.`hive_id`, 'BACKFILL_PLACED'
+FROM `frames` f
+JOIN `boxes` b ON b.`id` = f.`box_id`
+WHERE f.`active` = 1;
+
+-- +goose Down
+DROP TABLE IF EXISTS `frame_moves`;
+ALTER TABLE `frames` DROP COLUMN `comb_built_at`;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE `outbox_events` ADD KEY `idx_outbox_events_aggregate` (`aggregate_type`, `aggregate_id`, `published_at`, `id`);
+
+-- +goose Down
+ALTER TABLE `outbox_events` DROP KEY `idx_outbox_events_aggregate`;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS `honey_lot_counters` (
+    `user_id` int unsigned NOT NULL,
+    `year` smallint unsigned NOT NULL,
+    `last_number` int unsigned NOT NULL COMMENT 'last generated lot number of the year',
+    PRIMARY KEY (`user_id`, `year`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS `honey_lot_counters`;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- geo_point is nullable and can not hold a spatial index, distance queries prefilter by a lat/lng box instead
+ALTER TABLE `apiaries` ADD KEY `idx_apiaries_lat_lng` (`lat`, `lng`);
+
+-- +goose Down
+ALTER TABLE `apiaries` DROP KEY `idx_apiaries_lat_lng`;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- legacy inspections are upgraded by a background job across all users
+ALTER TABLE `inspections`
+  ADD INDEX `idx_inspections_schema_version` (`schema_version`, `id`),
+  DROP INDEX `idx_inspections_user_schema_version`;
+
+-- +goose Down
+ALTER TABLE `inspections`
+  ADD INDEX `idx_inspections_user_schema_version` (`user_id`, `schema_version`),
+  DROP INDEX `idx_inspections_schema_version`;
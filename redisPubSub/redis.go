@@ -30,22 +30,63 @@ func InitRedis() *redis.Client {
 	return client
 }
 
-func PublishEvent(uid string, entity string, entityID string, verb string, data interface{}) {
+// Publish sends data as JSON to a single channel, used for GraphQL subscription events
+func Publish(channel string, data interface{}) {
 	if client == nil {
 		client = InitRedis()
 	}
-	payloadJSON, _ := json.Marshal(data)
-	channel := fmt.Sprintf("%s.%s.%s.%s", uid, entity, entityID, verb)
+	payloadJSON, err := json.Marshal(data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Redis payload marshal error on channel %s: %v", channel, err))
+		return
+	}
 
-	err := client.Publish(ctx, channel, payloadJSON).Err()
+	err = client.Publish(ctx, channel, payloadJSON).Err()
 
 	if err != nil {
 		logger.Error(fmt.Sprintf("Redis publish error on channel %s: %v", channel, err))
 	}
+}
 
-	err = client.Publish(ctx, channel+"."+verb, payloadJSON).Err()
+// PublishPayload sends an already encoded payload and reports failures, so the caller can retry
+func PublishPayload(channel string, payload []byte) error {
+	if client == nil {
+		client = InitRedis()
+	}
 
-	if err != nil {
-		logger.Error(fmt.Sprintf("Redis publish error on channel %s.%s: %v", channel, verb, err))
+	return client.Publish(ctx, channel, payload).Err()
+}
+
+// Subscribe forwards raw payloads of a channel until subscriberCtx is cancelled
+func Subscribe(subscriberCtx context.Context, channel string) <-chan string {
+	if client == nil {
+		client = InitRedis()
 	}
+
+	pubsub := client.Subscribe(subscriberCtx, channel)
+	redisMessages := pubsub.Channel()
+	messages := make(chan string)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		for {
+			select {
+			case <-subscriberCtx.Done():
+				return
+			case msg, ok := <-redisMessages:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- msg.Payload:
+				case <-subscriberCtx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages
 }
@@ -5,8 +5,13 @@ import (
 	_ "embed"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/Gratheon/log-lib-go"
 	"github.com/Gratheon/swarm-api/graph"
@@ -14,7 +19,9 @@ import (
 	"github.com/Gratheon/swarm-api/redisPubSub"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/gorilla/websocket"
 	"github.com/rs/cors"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
 //go:embed schema.graphql
@@ -70,14 +77,21 @@ func main() {
 	rootResolver := &graph.Resolver{}
 	rootResolver.ConnectToDB()
 
+	logger.Info("Starting outbox relay")
+	go graph.NewOutboxRelay(rootResolver.Db).Run(context.Background())
+
+	logger.Info("Starting inspection upgrader")
+	go graph.NewInspectionUpgrader(rootResolver.Db).Run(context.Background())
+
 	gqlGenConfig := generated.Config{Resolvers: rootResolver}
-	gqlGenServer := handler.NewDefaultServer(generated.NewExecutableSchema(gqlGenConfig))
+	gqlGenServer := newGraphQLServer(generated.NewExecutableSchema(gqlGenConfig))
 	gqlGenServer.AroundFields(graphqlResolverMetricsMiddleware)
 
 	dataLoaderMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			loaders := &graph.Loaders{
 				HivesByApiaryLoader: graph.NewHiveLoader(rootResolver.Db),
+				HiveByIDLoader:      graph.NewHiveByIDLoader(rootResolver.Db),
 				BoxesByHiveLoader:   graph.NewBoxLoader(rootResolver.Db),
 				FamilyByHiveLoader:  graph.NewFamilyLoader(rootResolver.Db),
 				FramesByBoxLoader:   graph.NewFrameLoader(rootResolver.Db),
@@ -103,6 +117,35 @@ func main() {
 	}
 }
 
+// newGraphQLServer mirrors handler.NewDefaultServer, but authenticates graphql-ws subscriptions
+func newGraphQLServer(schema graphql.ExecutableSchema) *handler.Server {
+	srv := handler.New(schema)
+
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		InitFunc:              websocketInitFunc,
+		Upgrader: websocket.Upgrader{
+			// CORS allows any origin for HTTP requests, keep websocket upgrades consistent
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New[string](100),
+	})
+
+	return srv
+}
+
 func serveStaticFiles(router *chi.Mux) {
 	root := "./public"
 	fs := http.FileServer(http.Dir(root))